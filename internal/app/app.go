@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/backtest"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/journal"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/notification"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/pipeline"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/stream"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/telegram"
@@ -24,10 +28,16 @@ type App struct {
 	telegramBot       *telegram.Bot
 	binanceClient     *binance.Client
 	binanceWSClient   *binance.WebSocketClient
+	userDataStream    *binance.UserDataStream
 	strategyManager   *strategy.StrategyManager
 	tradeExecutor     *trading.TradeExecutor
 	streamManager     *stream.StreamManager
 	notificationMgr   *notification.NotificationManager
+	eventBus          *pipeline.Bus
+	dispatcher        *SignalDispatcher
+	deadManSwitch     *DeadManSwitch
+	stopLogMirror     func()
+	startedAt         time.Time
 	mu                sync.RWMutex
 	isRunning         bool
 }
@@ -43,8 +53,10 @@ func New(cfg *config.Config, log logger.Logger) (*App, error) {
 	}
 
 	app := &App{
-		config: cfg,
-		logger: log,
+		config:    cfg,
+		logger:    log,
+		eventBus:  pipeline.NewBus(),
+		startedAt: time.Now(),
 	}
 
 	// 初始化数据库
@@ -54,8 +66,24 @@ func New(cfg *config.Config, log logger.Logger) (*App, error) {
 	}
 	app.db = db
 
+	// 将 Warn 及以上级别的日志镜像到 system_logs 表，便于事后排查
+	systemLogRepo := database.NewSystemLogRepository(db.GetDB())
+	app.stopLogMirror = logger.MirrorWarnings(log, func(record logger.Record) error {
+		return systemLogRepo.Create(&database.SystemLog{
+			Level:        record.Level,
+			Message:      record.Message,
+			Module:       record.Module,
+			ErrorDetails: record.ErrorDetails,
+			CreatedAt:    record.Time,
+		})
+	})
+
 	// 初始化Telegram机器人
-	telegramBot, err := telegram.New(&cfg.Telegram, log)
+	services := &telegram.Services{
+		AppConfig: cfg,
+		DB:        db,
+	}
+	telegramBot, err := telegram.New(&cfg.Telegram, log, services)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize telegram bot: %w", err)
 	}
@@ -67,9 +95,15 @@ func New(cfg *config.Config, log logger.Logger) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize binance client: %w", err)
 	}
 	app.binanceClient = binanceClient
+	services.Binance = binanceClient
+
+	// 初始化回测器：每次回测使用与实盘参数相同的独立策略实例
+	services.Backtester = backtest.New(backtest.NewBinanceSource(binanceClient), func() strategy.Strategy {
+		return newVegasStrategy(&cfg.Trading, log)
+	}, cfg.Database.BacktestPath, log)
 
 	// 初始化Binance WebSocket客户端
-	binanceWSClient, err := binance.NewWebSocketClient(cfg.GetBinanceWSURL(), log)
+	binanceWSClient, err := binance.NewWebSocketClient(cfg.GetBinanceWSURL(), cfg.Binance.ProxyURL, log)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize binance websocket client: %w", err)
 	}
@@ -77,26 +111,152 @@ func New(cfg *config.Config, log logger.Logger) (*App, error) {
 
 	// 初始化策略管理器
 	strategyManager := strategy.NewStrategyManager(log)
+	strategyManager.SetSignalCooldown(time.Duration(cfg.Trading.SignalCooldownMinutes)*time.Minute, cfg.Trading.SignalMinMovePercent)
 	app.strategyManager = strategyManager
+	services.Strategies = strategyManager
 
 	// 初始化交易执行器
-	tradeExecutor := trading.NewTradeExecutor(log, binanceClient, db)
+	tradeExecutor := trading.NewTradeExecutor(&cfg.Trading, log, binanceClient, db)
+	tradeExecutor.SetEventBus(app.eventBus)
+	tradeExecutor.SetMode(cfg.Mode)
+	// 资金费结算后仅在4H趋势方向未变时重新开仓
+	tradeExecutor.SetReentryCheck(func(symbol string, isLong bool) bool {
+		trend, _, ok := strategyManager.Trend(symbol)
+		if !ok {
+			return false
+		}
+		if isLong {
+			return trend == strategy.TrendBullish
+		}
+		return trend == strategy.TrendBearish
+	})
+	// 15M K线收盘并经策略处理后检查持仓的EMA12移动止盈
+	tradeExecutor.SetExitCheck(strategyManager.ExitSignal)
+	strategyManager.SetCandleClosedHandler(tradeExecutor.CheckTrailingExits)
 	app.tradeExecutor = tradeExecutor
+	services.Executor = tradeExecutor
+
+	// 初始化交易日志
+	if cfg.Trading.JournalEnabled {
+		tradeExecutor.SetJournal(journal.New(cfg.Database.JournalPath, cfg.Trading.JournalCandles, log, db, strategyManager))
+	}
 
 	// 初始化通知管理器
 	notificationMgr := notification.New(cfg, log, telegramBot)
 	app.notificationMgr = notificationMgr
+	notificationMgr.SetPendingStore(db)
+	tradeExecutor.SetNotifier(notificationMgr)
+
+	// 交易所维护时暂停开仓并通知，恢复后自动继续
+	binanceClient.SetMaintenanceHandler(tradeExecutor.SetMaintenance)
+
+	// 实盘模式下由用户数据流推送订单和持仓变化，断开期间执行器回退到轮询
+	if cfg.Mode == config.ModeLive && cfg.Binance.UserDataStream {
+		userDataStream := binance.NewUserDataStream(binanceClient, cfg.GetBinanceWSURL(), log)
+		userDataStream.SetHandler(tradeExecutor)
+		userDataStream.SetStateHandler(tradeExecutor.SetUserStreamConnected)
+		app.userDataStream = userDataStream
+	}
+
+	// 初始化信号分发器：策略信号 → 通知 + 交易执行
+	app.dispatcher = NewSignalDispatcher(log, cfg.Mode, tradeExecutor, notificationMgr, db, app.eventBus)
+	strategyManager.SetSignalHandler(app.dispatcher.HandleStrategyResult)
+	app.eventBus.Subscribe(NewSignalRecorder(log, db).HandleEvent)
+
+	// 开启人工确认时开仓信号先发送确认按钮，确认后再执行
+	if cfg.Trading.ManualConfirm {
+		confirmations := NewSignalConfirmations(log, telegramBot,
+			time.Duration(cfg.Trading.ConfirmTimeoutSeconds)*time.Second, app.dispatcher.ExecuteConfirmed)
+		app.dispatcher.SetConfirmations(confirmations)
+		telegramBot.RegisterAdminCallbackHandler(signalCallbackPrefix, confirmations)
+	}
 
 	// 初始化流管理器
-	streamManager, err := stream.New(cfg, log, strategyManager)
+	streamManager, err := stream.New(cfg, log, binanceClient, strategyManager, app.eventBus)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize stream manager: %w", err)
 	}
 	app.streamManager = streamManager
+	services.Streams = streamManager
+	tradeExecutor.SetSymbolTradingCheck(streamManager.TradingEnabled)
+	tradeExecutor.SetPriceFeed(streamManager.PriceCache())
+	notificationMgr.SetTickerStats(streamManager.TickerStats)
+
+	// 持仓交易对停止交易时清理其行情订阅
+	tradeExecutor.SetSymbolHaltedHandler(streamManager.SuspendSymbol)
+	notificationMgr.SetHeartbeat(time.Duration(cfg.Telegram.HeartbeatMinutes)*time.Minute, app.heartbeatStatus)
+
+	// WebSocket持续重连失败时告警，可选进入维护模式暂停开仓
+	streamManager.SetReconnectHandler(cfg.Binance.MaxReconnectFailures, app.handleReconnectState)
+	streamManager.SetFlappingHandler(cfg.Binance.FlapReconnects,
+		time.Duration(cfg.Binance.FlapWindowMinutes)*time.Minute,
+		time.Duration(cfg.Binance.FlapStableMinutes)*time.Minute,
+		app.handleFlapping)
+
+	// 初始化失联保护
+	app.deadManSwitch = NewDeadManSwitch(&cfg.Trading, log, binanceClient, streamManager, tradeExecutor, notificationMgr)
 
 	return app, nil
 }
 
+// handleReconnectState 处理WebSocket连续重连失败和恢复
+func (a *App) handleReconnectState(exhausted bool, failures int, lastErr error) {
+	if exhausted {
+		message := fmt.Sprintf("WebSocket已连续重连失败 %d 次，最近错误：%v\n请检查网络、地区限制、API凭证或WS地址配置。", failures, lastErr)
+		if a.config.Binance.ReconnectMaintenance {
+			message += "\n已进入维护模式，暂停开仓，连接恢复后自动继续。"
+			a.tradeExecutor.SetMaintenance(true)
+		}
+		if err := a.notificationMgr.SendSystemNotification("critical", "🚨 行情连接持续失败", message); err != nil {
+			a.logger.Errorf("Failed to send reconnect alert: %v", err)
+		}
+		return
+	}
+
+	// REST接口仍在维护时由维护检测负责恢复
+	if a.config.Binance.ReconnectMaintenance && !a.binanceClient.InMaintenance() {
+		a.tradeExecutor.SetMaintenance(false)
+	}
+	message := fmt.Sprintf("WebSocket连接已恢复稳定（此前连续失败 %d 次）。", failures)
+	if err := a.notificationMgr.SendSystemNotification("info", "✅ 行情连接已恢复", message); err != nil {
+		a.logger.Errorf("Failed to send reconnect recovery notification: %v", err)
+	}
+}
+
+// handleFlapping 处理WebSocket重连抖动：抖动时暂停开仓，连接稳定后恢复
+func (a *App) handleFlapping(flapping bool, reconnects int, window time.Duration) {
+	a.tradeExecutor.SetFeedUnstable(flapping)
+
+	if flapping {
+		message := fmt.Sprintf("WebSocket在 %v 内重连 %d 次，行情数据不可靠。\n已暂停开仓，现有持仓的平仓和止损止盈不受影响。\n连接持续稳定 %d 分钟后自动恢复。",
+			window, reconnects, a.config.Binance.FlapStableMinutes)
+		if err := a.notificationMgr.SendSystemNotification("warning", "⚠️ 行情连接频繁重连", message); err != nil {
+			a.logger.Errorf("Failed to send flapping alert: %v", err)
+		}
+		return
+	}
+
+	message := fmt.Sprintf("WebSocket连接已持续稳定 %d 分钟，恢复开仓。", a.config.Binance.FlapStableMinutes)
+	if err := a.notificationMgr.SendSystemNotification("info", "✅ 行情连接已稳定", message); err != nil {
+		a.logger.Errorf("Failed to send flapping recovery notification: %v", err)
+	}
+}
+
+// heartbeatStatus 汇总心跳通知所需的运行状态
+func (a *App) heartbeatStatus() notification.HeartbeatStatus {
+	return notification.HeartbeatStatus{
+		Uptime:          time.Since(a.startedAt),
+		StreamConnected: a.streamManager.IsConnected(),
+		InMaintenance:   a.binanceClient.InMaintenance(),
+		OpenPositions:   len(a.tradeExecutor.GetPositions()),
+	}
+}
+
+// EventBus 获取数据流事件总线，可用于订阅各阶段事件（指标、测试等）
+func (a *App) EventBus() *pipeline.Bus {
+	return a.eventBus
+}
+
 // Run 运行应用
 func (a *App) Run(ctx context.Context) error {
 	a.mu.Lock()
@@ -107,7 +267,7 @@ func (a *App) Run(ctx context.Context) error {
 	a.isRunning = true
 	a.mu.Unlock()
 
-	a.logger.Info("Application starting...")
+	a.logger.Infof("Application starting in %s mode...", a.config.Mode)
 
 	// 启动Telegram机器人
 	if err := a.telegramBot.Start(ctx); err != nil {
@@ -127,6 +287,12 @@ func (a *App) Run(ctx context.Context) error {
 	}
 	a.logger.Info("Trade executor started")
 
+	// 启动用户数据流
+	if a.userDataStream != nil {
+		a.userDataStream.Start()
+		a.logger.Info("User data stream started")
+	}
+
 	// 启动通知管理器
 	if err := a.notificationMgr.Start(); err != nil {
 		return fmt.Errorf("failed to start notification manager: %w", err)
@@ -139,14 +305,28 @@ func (a *App) Run(ctx context.Context) error {
 	}
 	a.logger.Info("Stream manager started")
 
+	// 启动失联保护
+	a.deadManSwitch.Start()
+
+	// 启动数据库定期备份
+	a.db.StartBackupScheduler(ctx, a.config.Database.BackupPath,
+		time.Duration(a.config.Database.BackupInterval)*time.Hour,
+		time.Duration(a.config.Database.BackupRetention)*24*time.Hour)
+
+	// 启动Prometheus指标服务
+	a.startMetrics(ctx)
+
 	// 注册维加斯双隧道策略
-	vegasStrategy := strategy.NewVegasTunnelStrategy(a.logger)
+	vegasStrategy := newVegasStrategy(&a.config.Trading, a.logger)
 	if err := a.strategyManager.RegisterStrategy("vegas_tunnel", vegasStrategy); err != nil {
 		a.logger.Errorf("Failed to register vegas tunnel strategy: %v", err)
 	} else {
 		a.logger.Info("Vegas tunnel strategy registered")
 	}
 
+	// 订阅监控列表中的交易对
+	a.loadWatchlist()
+
 	a.logger.Info("Application started successfully")
 
 	// 等待上下文取消
@@ -154,28 +334,8 @@ func (a *App) Run(ctx context.Context) error {
 
 	a.logger.Info("Application shutting down...")
 
-	// 停止所有服务
-	a.streamManager.Stop()
-	a.logger.Info("Stream manager stopped")
-
-	a.notificationMgr.Stop()
-	a.logger.Info("Notification manager stopped")
-
-	a.tradeExecutor.Stop()
-	a.logger.Info("Trade executor stopped")
-
-	a.strategyManager.Stop()
-	a.logger.Info("Strategy manager stopped")
-
-	a.telegramBot.Stop()
-	a.logger.Info("Telegram bot stopped")
-
-	// 关闭数据库连接
-	if err := a.db.Close(); err != nil {
-		a.logger.Errorf("Failed to close database: %v", err)
-	} else {
-		a.logger.Info("Database closed")
-	}
+	// 按依赖顺序停止所有服务
+	a.shutdown()
 
 	a.mu.Lock()
 	a.isRunning = false
@@ -183,4 +343,19 @@ func (a *App) Run(ctx context.Context) error {
 
 	a.logger.Info("Application shutdown completed")
 	return nil
-}
\ No newline at end of file
+}
+
+// newVegasStrategy 按交易配置创建维加斯双隧道策略
+func newVegasStrategy(cfg *config.TradingConfig, log logger.Logger) *strategy.VegasTunnelStrategy {
+	vegasStrategy := strategy.NewVegasTunnelStrategy(log)
+	vegasStrategy.SetMinTunnelPeriod(cfg.MinTrendCandles)
+	vegasStrategy.SetADXThreshold(cfg.ADXThreshold)
+	vegasStrategy.SetEMA12Buffer(cfg.EMA12BufferPercent / 100)
+	vegasStrategy.SetHistoryMargin(cfg.HistoryMargin)
+	if source, err := strategy.ParsePriceSource(cfg.EMASource); err != nil {
+		log.Warnf("Invalid EMA source, using close: %v", err)
+	} else {
+		vegasStrategy.SetPriceSource(source)
+	}
+	return vegasStrategy
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/telegram"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// signalCallbackPrefix 开仓信号确认按钮的回调前缀
+const signalCallbackPrefix = "signal"
+
+// pendingSignal 等待人工确认的开仓信号
+type pendingSignal struct {
+	result *strategy.StrategyResult
+	timer  *time.Timer
+}
+
+// SignalConfirmations 待确认开仓信号存储：按回调ID索引，确认后执行，超时未确认自动作废
+type SignalConfirmations struct {
+	logger  logger.Logger
+	bot     *telegram.Bot
+	timeout time.Duration
+	execute func(result *strategy.StrategyResult)
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[string]*pendingSignal
+}
+
+// NewSignalConfirmations 创建待确认信号存储，execute 为确认后的执行回调
+func NewSignalConfirmations(log logger.Logger, bot *telegram.Bot, timeout time.Duration,
+	execute func(result *strategy.StrategyResult)) *SignalConfirmations {
+	return &SignalConfirmations{
+		logger:  log,
+		bot:     bot,
+		timeout: timeout,
+		execute: execute,
+		pending: make(map[string]*pendingSignal),
+	}
+}
+
+// Request 保存信号并发送带确认按钮的消息
+func (sc *SignalConfirmations) Request(result *strategy.StrategyResult) error {
+	sc.mu.Lock()
+	sc.nextID++
+	id := strconv.FormatUint(sc.nextID, 10)
+	sc.pending[id] = &pendingSignal{
+		result: result,
+		timer:  time.AfterFunc(sc.timeout, func() { sc.expire(id) }),
+	}
+	sc.mu.Unlock()
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ 执行", signalCallbackPrefix+":execute:"+id),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 跳过", signalCallbackPrefix+":skip:"+id),
+		),
+	)
+
+	if err := sc.bot.SendMarkdownWithKeyboard(formatConfirmMessage(result, sc.timeout), keyboard); err != nil {
+		sc.take(id)
+		return fmt.Errorf("failed to send signal confirmation: %w", err)
+	}
+	return nil
+}
+
+// HandleCallback 处理确认按钮：执行或跳过对应信号
+func (sc *SignalConfirmations) HandleCallback(ctx context.Context, bot *telegram.Bot, query *tgbotapi.CallbackQuery, data string) error {
+	action, id, ok := strings.Cut(data, ":")
+	if !ok || (action != "execute" && action != "skip") {
+		bot.AnswerCallback(query, "❌ 无效操作")
+		return nil
+	}
+
+	bot.ClearKeyboard(query.Message)
+
+	result, ok := sc.take(id)
+	if !ok {
+		bot.AnswerCallback(query, "⌛ 信号已过期或已处理")
+		return nil
+	}
+
+	if action == "skip" {
+		sc.logger.Infof("Signal for %s skipped by %s", result.Symbol, query.From.UserName)
+		bot.AnswerCallback(query, "已跳过")
+		return bot.SendMessage(fmt.Sprintf("❎ 已跳过 %s 信号", result.Symbol))
+	}
+
+	sc.logger.Infof("Signal for %s confirmed by %s", result.Symbol, query.From.UserName)
+	bot.AnswerCallback(query, "正在执行...")
+	sc.execute(result)
+	return nil
+}
+
+// take 取出并移除待确认信号，同时停止超时计时
+func (sc *SignalConfirmations) take(id string) (*strategy.StrategyResult, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	p, ok := sc.pending[id]
+	if !ok {
+		return nil, false
+	}
+	p.timer.Stop()
+	delete(sc.pending, id)
+	return p.result, true
+}
+
+// expire 超时未确认时作废信号
+func (sc *SignalConfirmations) expire(id string) {
+	result, ok := sc.take(id)
+	if !ok {
+		return
+	}
+
+	sc.logger.Infof("Signal for %s expired without confirmation", result.Symbol)
+	if err := sc.bot.SendMessage(fmt.Sprintf("⌛ %s 信号超过 %d 秒未确认，已作废", result.Symbol, int(sc.timeout.Seconds()))); err != nil {
+		sc.logger.Errorf("Failed to send signal expiry message: %v", err)
+	}
+}
+
+// Close 停止所有超时计时并丢弃未确认的信号
+func (sc *SignalConfirmations) Close() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	for id, p := range sc.pending {
+		p.timer.Stop()
+		delete(sc.pending, id)
+	}
+}
+
+// formatConfirmMessage 格式化开仓信号确认消息
+func formatConfirmMessage(result *strategy.StrategyResult, timeout time.Duration) string {
+	signal := result.Signal
+	side := "🟢 做多"
+	if signal.Type == strategy.SignalSell {
+		side = "🔴 做空"
+	}
+
+	return fmt.Sprintf("🔔 *开仓信号待确认*\n\n"+
+		"• 交易对: %s\n• 方向: %s\n• 价格: %s\n• 止损: %s\n• 止盈: %s\n• 置信度: %.2f\n• 策略: `%s`\n\n"+
+		"请在 %d 秒内确认，超时自动跳过",
+		result.Symbol, side, signal.Price.String(), signal.StopLoss.String(), signal.TakeProfit.String(),
+		signal.Confidence, result.StrategyName, int(timeout.Seconds()))
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/notification"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/stream"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// deadManCheckInterval 连接检查间隔
+const deadManCheckInterval = 30 * time.Second
+
+// DeadManSwitch 失联保护：REST与WebSocket长时间均不可用时，在连接恢复后立即平掉所有持仓
+type DeadManSwitch struct {
+	config          *config.TradingConfig
+	logger          logger.Logger
+	binanceClient   *binance.Client
+	streamManager   *stream.StreamManager
+	tradeExecutor   *trading.TradeExecutor
+	notificationMgr *notification.NotificationManager
+	lostSince       time.Time // 完全失联的开始时间，零值表示连接正常
+	tripped         bool      // 已触发，等待连接恢复后平仓
+	ctx             context.Context
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+}
+
+// NewDeadManSwitch 创建失联保护
+func NewDeadManSwitch(cfg *config.TradingConfig, log logger.Logger, client *binance.Client, streams *stream.StreamManager,
+	executor *trading.TradeExecutor, notificationMgr *notification.NotificationManager) *DeadManSwitch {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DeadManSwitch{
+		config:          cfg,
+		logger:          log,
+		binanceClient:   client,
+		streamManager:   streams,
+		tradeExecutor:   executor,
+		notificationMgr: notificationMgr,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Start 启动连接监控（未启用时为空操作）
+func (d *DeadManSwitch) Start() {
+	if !d.config.DeadManSwitchEnabled {
+		return
+	}
+
+	d.wg.Add(1)
+	go d.monitor()
+	d.logger.Infof("Dead man's switch armed (timeout: %d minutes)", d.config.DeadManSwitchMinutes)
+}
+
+// Stop 停止连接监控
+func (d *DeadManSwitch) Stop() {
+	if !d.config.DeadManSwitchEnabled {
+		return
+	}
+
+	d.cancel()
+	d.wg.Wait()
+}
+
+// monitor 定期检查连接状态
+func (d *DeadManSwitch) monitor() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(deadManCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.check()
+		}
+	}
+}
+
+// check 检查一次连接状态并推进失联保护状态
+func (d *DeadManSwitch) check() {
+	// 交易所维护期间接口仍可达，只是暂停服务，不视为失联（否则维护结束后会误平仓）
+	err := d.binanceClient.TestConnection(d.ctx)
+	restOK := err == nil || errors.Is(err, binance.ErrMaintenance)
+	wsOK := d.streamManager.IsConnected()
+
+	if d.tripped {
+		// 已触发：只要REST恢复就立即尝试平仓
+		if restOK {
+			d.flatten()
+		}
+		return
+	}
+
+	if restOK || wsOK {
+		if !d.lostSince.IsZero() {
+			d.logger.Infof("Connectivity restored after %v", time.Since(d.lostSince).Round(time.Second))
+			d.lostSince = time.Time{}
+		}
+		return
+	}
+
+	if d.lostSince.IsZero() {
+		d.lostSince = time.Now()
+		d.logger.Warn("Lost both REST and WebSocket connectivity")
+		return
+	}
+
+	timeout := time.Duration(d.config.DeadManSwitchMinutes) * time.Minute
+	if time.Since(d.lostSince) < timeout {
+		return
+	}
+
+	d.tripped = true
+	d.alert("critical", "失联保护已触发",
+		fmt.Sprintf("REST与WebSocket已连续中断 %v，持仓处于无人管理状态。连接恢复后将立即平掉所有持仓。",
+			time.Since(d.lostSince).Round(time.Second)))
+}
+
+// flatten 平掉所有持仓，失败时保持触发状态等待下次重试
+func (d *DeadManSwitch) flatten() {
+	results, err := d.tradeExecutor.FlattenAll(0, "dead man's switch")
+	if err != nil {
+		d.logger.Errorf("Dead man's switch flatten failed: %v", err)
+		return
+	}
+
+	var sb strings.Builder
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+			sb.WriteString(fmt.Sprintf("• %s: ❌ %v\n", result.Symbol, result.Error))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("• %s: %s %s，盈亏 %s USDT\n",
+			result.Symbol, result.Side, result.Quantity.String(), result.RealizedPnl.StringFixed(2)))
+	}
+
+	if failed > 0 {
+		d.alert("critical", "失联保护平仓未完成", fmt.Sprintf("%d 个持仓平仓失败，将在下次检查时重试：\n%s", failed, sb.String()))
+		return
+	}
+
+	message := "连接已恢复，没有需要平仓的持仓。"
+	if len(results) > 0 {
+		message = fmt.Sprintf("连接已恢复，已平掉 %d 个持仓：\n%s", len(results), sb.String())
+	}
+	d.alert("warning", "失联保护平仓完成", message)
+
+	d.tripped = false
+	d.lostSince = time.Time{}
+}
+
+// alert 通过所有可用渠道告警：日志始终记录，Telegram 可用时推送
+func (d *DeadManSwitch) alert(level, title, message string) {
+	d.logger.Errorf("[DEAD MAN'S SWITCH] %s: %s", title, message)
+
+	if err := d.notificationMgr.SendSystemNotification(level, title, message); err != nil {
+		d.logger.Errorf("Failed to send dead man's switch alert: %v", err)
+	}
+}
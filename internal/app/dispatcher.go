@@ -0,0 +1,189 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/notification"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/pipeline"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// SignalDispatcher 信号分发器：将策略信号推送通知并分发给各启用用户执行
+type SignalDispatcher struct {
+	logger          logger.Logger
+	mode            string
+	tradeExecutor   *trading.TradeExecutor
+	notificationMgr *notification.NotificationManager
+	userConfigRepo  *database.UserConfigRepository
+	eventBus        *pipeline.Bus
+	confirmations   *SignalConfirmations // 非空时开仓信号需人工确认后执行
+	wg              sync.WaitGroup
+	mu              sync.RWMutex
+	closed          bool
+}
+
+// NewSignalDispatcher 创建新的信号分发器
+func NewSignalDispatcher(log logger.Logger, mode string, executor *trading.TradeExecutor, notificationMgr *notification.NotificationManager,
+	db *database.Database, bus *pipeline.Bus) *SignalDispatcher {
+	return &SignalDispatcher{
+		logger:          log,
+		mode:            mode,
+		tradeExecutor:   executor,
+		notificationMgr: notificationMgr,
+		userConfigRepo:  database.NewUserConfigRepository(db.GetDB()),
+		eventBus:        bus,
+	}
+}
+
+// HandleStrategyResult 处理策略结果（作为 StrategyManager 的信号回调）
+func (d *SignalDispatcher) HandleStrategyResult(result *strategy.StrategyResult) {
+	if result == nil || result.Signal == nil {
+		return
+	}
+
+	d.eventBus.Publish(pipeline.Event{
+		Stage:    pipeline.StageSignalGenerated,
+		Symbol:   result.Symbol,
+		Strategy: result.StrategyName,
+		Signal:   result.Signal,
+	})
+
+	// 停止后不再分发新信号
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		d.logger.Infof("Dispatcher closed, dropping %s signal for %s", result.StrategyName, result.Symbol)
+		return
+	}
+
+	// 下单涉及网络请求，异步执行避免阻塞行情处理
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.dispatch(result)
+	}()
+}
+
+// SetConfirmations 启用开仓信号人工确认
+func (d *SignalDispatcher) SetConfirmations(confirmations *SignalConfirmations) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.confirmations = confirmations
+}
+
+// ExecuteConfirmed 执行已人工确认的信号，分发器停止后丢弃
+func (d *SignalDispatcher) ExecuteConfirmed(result *strategy.StrategyResult) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		d.logger.Infof("Dispatcher closed, dropping confirmed signal for %s", result.Symbol)
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.executeForUsers(result)
+	}()
+}
+
+// dispatch 推送信号通知，开仓信号需确认时发送确认按钮，否则为每个启用用户执行交易
+func (d *SignalDispatcher) dispatch(result *strategy.StrategyResult) {
+	if err := d.notificationMgr.SendSignalNotification(result.Signal); err != nil {
+		d.logger.Errorf("Failed to send signal notification: %v", err)
+	}
+
+	// 分析模式只推送信号，不分发给用户执行
+	if d.mode == config.ModeAnalysis {
+		d.logger.Debugf("Analysis mode, not executing %s signal for %s", result.StrategyName, result.Symbol)
+		return
+	}
+
+	d.mu.RLock()
+	confirmations := d.confirmations
+	d.mu.RUnlock()
+	if confirmations != nil && result.Signal.Type.IsEntry() {
+		if err := confirmations.Request(result); err != nil {
+			d.logger.Errorf("Failed to request confirmation for %s signal: %v", result.Symbol, err)
+		}
+		return
+	}
+
+	d.executeForUsers(result)
+}
+
+// executeForUsers 为每个启用用户执行交易
+func (d *SignalDispatcher) executeForUsers(result *strategy.StrategyResult) {
+	users, err := d.userConfigRepo.GetActiveUsers()
+	if err != nil {
+		d.logger.Errorf("Failed to load active users for signal dispatch: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		request := &trading.TradeRequest{
+			UserID:       user.UserID,
+			Symbol:       result.Symbol,
+			Signal:       result.Signal,
+			StrategyType: result.StrategyName,
+		}
+
+		d.eventBus.Publish(pipeline.Event{
+			Stage:    pipeline.StageSignalDispatched,
+			Symbol:   result.Symbol,
+			Strategy: result.StrategyName,
+			UserID:   user.UserID,
+			Signal:   result.Signal,
+		})
+
+		tradeResult := d.tradeExecutor.ExecuteTrade(request)
+		if tradeResult.Error != nil {
+			d.handleExecutionError(result.Symbol, user.UserID, tradeResult.Error)
+			continue
+		}
+
+		if err := d.notificationMgr.SendTradeNotification(tradeResult); err != nil {
+			d.logger.Errorf("Failed to send trade notification: %v", err)
+		}
+	}
+}
+
+// handleExecutionError 按执行失败原因处理：用户在加载活跃用户后被停用或删除、或执行器正在停止时静默跳过，
+// 读取配置失败时发送告警，其余（风控拒绝、下单失败等）记录警告
+func (d *SignalDispatcher) handleExecutionError(symbol string, userID int64, err error) {
+	switch {
+	case errors.Is(err, trading.ErrExecutionDisabled),
+		errors.Is(err, trading.ErrUserConfigNotFound),
+		errors.Is(err, trading.ErrUserInactive),
+		errors.Is(err, trading.ErrExecutorStopped):
+		d.logger.Debugf("Skipping %s signal for user %d: %v", symbol, userID, err)
+	case errors.Is(err, trading.ErrUserConfigUnavailable):
+		d.logger.Errorf("Signal for %s not executed for user %d: %v", symbol, userID, err)
+		if notifyErr := d.notificationMgr.SendSystemNotification("error", "⚠️ 用户配置读取失败",
+			fmt.Sprintf("用户 %d 的 %s 信号未执行：%v", userID, symbol, err)); notifyErr != nil {
+			d.logger.Errorf("Failed to send system notification: %v", notifyErr)
+		}
+	default:
+		d.logger.Warnf("Signal for %s not executed for user %d: %v", symbol, userID, err)
+	}
+}
+
+// Close 停止接收新信号并等待所有进行中的分发完成
+func (d *SignalDispatcher) Close() {
+	d.mu.Lock()
+	d.closed = true
+	confirmations := d.confirmations
+	d.mu.Unlock()
+
+	if confirmations != nil {
+		confirmations.Close()
+	}
+
+	d.wg.Wait()
+}
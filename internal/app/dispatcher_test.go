@@ -0,0 +1,87 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/notification"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// newWebhookNotifications 创建只通过Webhook发送的通知管理器，返回已启动的管理器和读取收到的通知标题的函数。
+// 读取前需先 Stop，保证队列中的通知已发送完毕
+func newWebhookNotifications(t *testing.T) (*notification.NotificationManager, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var titles []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Title string `json:"title"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		titles = append(titles, payload.Title)
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{Notification: config.NotificationConfig{
+		Channels: []string{config.ChannelWebhook},
+		Webhooks: []config.WebhookConfig{{URL: server.URL}},
+	}}
+	nm := notification.New(cfg, logger.NewLoggerWithLevel("error"), nil)
+	if err := nm.Start(); err != nil {
+		t.Fatalf("start notification manager: %v", err)
+	}
+	t.Cleanup(func() { nm.Stop() })
+
+	return nm, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), titles...)
+	}
+}
+
+func TestHandleExecutionErrorSkipsOrNotifies(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		notify bool
+	}{
+		{"analysis mode", trading.ErrExecutionDisabled, false},
+		{"missing user config", trading.ErrUserConfigNotFound, false},
+		{"inactive user", trading.ErrUserInactive, false},
+		{"executor stopped", trading.ErrExecutorStopped, false},
+		{"user config unreadable", fmt.Errorf("%w: %w", trading.ErrUserConfigUnavailable, errors.New("database is locked")), true},
+		{"order rejected", errors.New("insufficient margin"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nm, titles := newWebhookNotifications(t)
+			d := &SignalDispatcher{logger: logger.NewLoggerWithLevel("error"), notificationMgr: nm}
+
+			d.handleExecutionError("BTCUSDT", 1, tt.err)
+			nm.Stop()
+
+			got := titles()
+			if tt.notify && (len(got) != 1 || got[0] != "⚠️ 用户配置读取失败") {
+				t.Errorf("notifications = %v, want the config read failure alert", got)
+			}
+			if !tt.notify && len(got) != 0 {
+				t.Errorf("notifications = %v, want none for %v", got, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/metrics"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/pipeline"
+)
+
+// startMetrics 配置了指标端口时注册运行状态指标并启动指标服务，ctx 取消后关闭
+func (a *App) startMetrics(ctx context.Context) {
+	if a.config.MetricsPort <= 0 {
+		return
+	}
+
+	// 信号数按数据流事件统计，与写入数据库的信号一致
+	a.eventBus.Subscribe(func(event pipeline.Event) {
+		if event.Stage == pipeline.StageSignalGenerated && event.Signal != nil {
+			metrics.RecordSignal(event.Symbol, event.Signal.Type.String())
+		}
+	})
+
+	gauges := []struct {
+		name  string
+		help  string
+		value func() float64
+	}{
+		{"notification_queue_depth", "Notifications waiting to be sent.", func() float64 {
+			return float64(a.notificationMgr.GetQueueSize())
+		}},
+		{"open_positions", "Positions currently tracked by the trade executor.", func() float64 {
+			return float64(len(a.tradeExecutor.GetPositions()))
+		}},
+	}
+	for _, gauge := range gauges {
+		if err := metrics.RegisterGauge(gauge.name, gauge.help, gauge.value); err != nil {
+			a.logger.Warnf("Failed to register metric: %v", err)
+		}
+	}
+
+	metrics.NewServer(a.config.MetricsPort, a.logger).Start(ctx)
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// shutdownTimeout 所有服务停止共享的截止时间
+	shutdownTimeout = 30 * time.Second
+	// executorStopTimeout 交易执行器等待进行中操作的最长时间，为通知管理器发送积压消息留出时间
+	executorStopTimeout = 15 * time.Second
+)
+
+// shutdown 按依赖顺序停止服务：
+// 1. 失联保护与信号分发停止产生新交易；
+// 2. 交易执行器完成进行中的止损止盈设置并写回缓存记录（通知仍可送达）；
+// 3. 通知管理器和Telegram机器人发送完队列中的消息；
+// 4. 最后停止策略、行情流并关闭数据库
+func (a *App) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	a.stopWithin(ctx, "Dead man switch", a.deadManSwitch.Stop)
+	a.stopWithin(ctx, "Signal dispatcher", a.dispatcher.Close)
+	if a.userDataStream != nil {
+		a.stopWithin(ctx, "User data stream", a.userDataStream.Stop)
+	}
+	executorCtx, cancelExecutor := context.WithTimeout(ctx, executorStopTimeout)
+	a.stopWithin(ctx, "Trade executor", func() { a.tradeExecutor.Stop(executorCtx) })
+	cancelExecutor()
+	a.stopWithin(ctx, "Notification manager", func() { a.notificationMgr.Stop() })
+	a.stopWithin(ctx, "Telegram bot", a.telegramBot.Stop)
+	a.stopWithin(ctx, "Strategy manager", a.strategyManager.Stop)
+	a.stopWithin(ctx, "Stream manager", func() { a.streamManager.Stop() })
+
+	// 写完待镜像的日志后关闭数据库连接
+	a.stopLogMirror()
+	if err := a.db.Close(); err != nil {
+		a.logger.Errorf("Failed to close database: %v", err)
+	} else {
+		a.logger.Info("Database closed")
+	}
+}
+
+// stopWithin 在共享截止时间内执行停止步骤，截止时间已过时不再等待，继续后续步骤
+func (a *App) stopWithin(ctx context.Context, name string, stop func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stop()
+	}()
+
+	select {
+	case <-done:
+		a.logger.Infof("%s stopped", name)
+	case <-ctx.Done():
+		a.logger.Warnf("%s did not stop before shutdown deadline", name)
+	}
+}
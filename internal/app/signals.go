@@ -0,0 +1,73 @@
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/pipeline"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// signalMetadata 信号记录中以JSON保存的额外信息
+type signalMetadata struct {
+	Confidence   float64 `json:"confidence"`
+	Reason       string  `json:"reason"`
+	StopLoss     string  `json:"stop_loss,omitempty"`
+	TakeProfit   string  `json:"take_profit,omitempty"`
+	ATRPercent   float64 `json:"atr_percent,omitempty"`
+	RangePercent float64 `json:"range_percent,omitempty"`
+}
+
+// SignalRecorder 将策略生成的信号写入数据库，供 /signals 查询和事后复盘
+type SignalRecorder struct {
+	logger     logger.Logger
+	signalRepo *database.SignalRepository
+}
+
+// NewSignalRecorder 创建信号记录器
+func NewSignalRecorder(log logger.Logger, db *database.Database) *SignalRecorder {
+	return &SignalRecorder{
+		logger:     log,
+		signalRepo: database.NewSignalRepository(db.GetDB()),
+	}
+}
+
+// HandleEvent 记录信号生成事件，其他阶段的事件忽略。信号尚未分发给用户，用户ID记为0
+func (r *SignalRecorder) HandleEvent(event pipeline.Event) {
+	if event.Stage != pipeline.StageSignalGenerated || event.Signal == nil {
+		return
+	}
+	signal := event.Signal
+
+	metadata := signalMetadata{
+		Confidence:   signal.Confidence,
+		Reason:       signal.Reason,
+		ATRPercent:   signal.ATRPercent,
+		RangePercent: signal.RangePercent,
+	}
+	if !signal.StopLoss.IsZero() {
+		metadata.StopLoss = signal.StopLoss.String()
+	}
+	if !signal.TakeProfit.IsZero() {
+		metadata.TakeProfit = signal.TakeProfit.String()
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		r.logger.Warnf("Failed to encode signal metadata for %s: %v", event.Symbol, err)
+		return
+	}
+
+	record := &database.Signal{
+		Symbol:       event.Symbol,
+		Interval:     signal.Timeframe,
+		StrategyType: event.Strategy,
+		SignalType:   signal.Type.String(),
+		Price:        signal.Price.InexactFloat64(),
+		Confidence:   signal.Confidence,
+		Metadata:     string(data),
+	}
+	if err := r.signalRepo.Create(record); err != nil {
+		r.logger.Warnf("Failed to persist %s signal for %s: %v", event.Strategy, event.Symbol, err)
+	}
+}
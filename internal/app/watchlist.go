@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// loadWatchlist 启动时订阅所有用户启用的监控交易对。按添加时间先后计入每用户和全局上限，
+// 超出上限的监控项（如调低配置后）跳过并告警，不删除记录
+func (a *App) loadWatchlist() {
+	items, err := database.NewWatchlistRepository(a.db.GetDB()).GetAllActive()
+	if err != nil {
+		a.logger.Errorf("Failed to load watchlist: %v", err)
+		return
+	}
+
+	perUser := make(map[int64]int)
+	symbols := make(map[string]bool)
+	subscribed := make(map[string]bool)
+	var skipped []string
+
+	for _, item := range items {
+		if perUser[item.UserID] >= a.config.Trading.MaxWatchedSymbolsPerUser {
+			a.logger.Warnf("Skipping %s for user %d: per-user watchlist limit %d reached",
+				item.Symbol, item.UserID, a.config.Trading.MaxWatchedSymbolsPerUser)
+			skipped = append(skipped, fmt.Sprintf("%s（用户 %d）", item.Symbol, item.UserID))
+			continue
+		}
+		if !symbols[item.Symbol] && len(symbols) >= a.config.Trading.MaxWatchedSymbols {
+			a.logger.Warnf("Skipping %s for user %d: global watchlist limit %d reached",
+				item.Symbol, item.UserID, a.config.Trading.MaxWatchedSymbols)
+			skipped = append(skipped, fmt.Sprintf("%s（用户 %d）", item.Symbol, item.UserID))
+			continue
+		}
+		perUser[item.UserID]++
+		symbols[item.Symbol] = true
+
+		key := item.Symbol + "_" + item.Interval
+		if subscribed[key] {
+			continue
+		}
+		subscribed[key] = true
+		if err := a.streamManager.Subscribe(item.Symbol, item.Interval); err != nil {
+			a.logger.Warnf("Failed to subscribe watched symbol %s %s: %v", item.Symbol, item.Interval, err)
+		}
+	}
+
+	a.logger.Infof("Loaded watchlist: %d symbols, %d streams", len(symbols), len(subscribed))
+
+	if len(skipped) > 0 {
+		message := fmt.Sprintf("以下监控项超出数量上限（每用户 %d / 全局 %d），本次启动未订阅：\n%s",
+			a.config.Trading.MaxWatchedSymbolsPerUser, a.config.Trading.MaxWatchedSymbols, strings.Join(skipped, "\n"))
+		if err := a.notificationMgr.SendSystemNotification("warning", "👀 监控数量超出上限", message); err != nil {
+			a.logger.Errorf("Failed to send watchlist limit notification: %v", err)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+const (
+	// interval15M 回测逐根回放的K线周期
+	interval15M = 15 * time.Minute
+	// defaultPageSize 每次获取的K线数，与币安K线接口单次返回上限一致
+	defaultPageSize = 1500
+	// defaultWarmupLimit 策略未声明所需K线数时每个周期预热的K线数
+	defaultWarmupLimit = 500
+)
+
+// 平仓原因
+const (
+	ExitStopLoss   = "STOP_LOSS"
+	ExitTakeProfit = "TAKE_PROFIT"
+	ExitEnd        = "END" // 回测区间结束时按最后收盘价平仓
+)
+
+// KlineSource 历史K线来源
+type KlineSource interface {
+	// Klines 按开盘时间升序返回 [start, end] 内开盘的已收盘K线，最多 limit 根；
+	// start 为零值时返回截至 end 的最近 limit 根
+	Klines(ctx context.Context, symbol, interval string, start, end time.Time, limit int) ([]strategy.KlineData, error)
+}
+
+// Request 回测请求，区间按15M K线开盘时间计算
+type Request struct {
+	Symbol string
+	Start  time.Time
+	End    time.Time
+}
+
+// Position 回测中的模拟持仓
+type Position struct {
+	Side       string          `json:"side"` // "LONG" 或 "SHORT"
+	EntryPrice decimal.Decimal `json:"entry_price"`
+	StopLoss   decimal.Decimal `json:"stop_loss"`
+	TakeProfit decimal.Decimal `json:"take_profit"`
+	EntryTime  time.Time       `json:"entry_time"`
+}
+
+// Trade 回测中已平仓的模拟交易
+type Trade struct {
+	Position
+	ExitPrice     decimal.Decimal `json:"exit_price"`
+	ExitTime      time.Time       `json:"exit_time"`
+	ExitReason    string          `json:"exit_reason"`
+	ReturnPercent float64         `json:"return_percent"` // 不含杠杆和手续费的收益率
+}
+
+// Result 回测累计结果，同时作为检查点内容
+type Result struct {
+	Symbol    string    `json:"symbol"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Processed int       `json:"processed"` // 已处理的15M K线数
+	Through   time.Time `json:"through"`   // 最后处理的K线开盘时间
+	Trades    []Trade   `json:"trades"`
+	Open      *Position `json:"open,omitempty"`
+	Resumed   bool      `json:"-"` // 本次运行是否从检查点继续
+
+	LastClose     decimal.Decimal `json:"last_close"`      // 最后处理的K线收盘价，区间结束时按此价格平仓
+	LastCloseTime time.Time       `json:"last_close_time"` // 最后处理的K线收盘时间
+}
+
+// Wins 盈利交易数
+func (r *Result) Wins() int {
+	wins := 0
+	for _, trade := range r.Trades {
+		if trade.ReturnPercent > 0 {
+			wins++
+		}
+	}
+	return wins
+}
+
+// NetReturnPercent 各笔交易收益率之和
+func (r *Result) NetReturnPercent() float64 {
+	total := 0.0
+	for _, trade := range r.Trades {
+		total += trade.ReturnPercent
+	}
+	return total
+}
+
+// Progress 回测进度
+type Progress struct {
+	Symbol    string
+	Processed int       // 已处理的15M K线数
+	Total     int       // 区间内的15M K线总数
+	Through   time.Time // 最后处理的K线开盘时间
+	Trades    int
+	Resumed   bool
+}
+
+// Percent 完成百分比
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(100, float64(p.Processed)*100/float64(p.Total))
+}
+
+// ProgressFunc 进度回调，每处理完一页K线并写入检查点后调用
+type ProgressFunc func(Progress)
+
+// Backtester 回测器：逐根回放15M K线驱动策略并模拟止损止盈成交，
+// 每处理完一页K线将进度写入检查点，中断后再次运行同一请求时从检查点继续
+type Backtester struct {
+	logger        logger.Logger
+	source        KlineSource
+	newStrategy   func() strategy.Strategy // 每次运行创建独立的策略实例，避免污染实盘策略缓存
+	checkpointDir string
+	pageSize      int
+}
+
+// New 创建回测器，checkpointDir 为检查点存储目录
+func New(source KlineSource, newStrategy func() strategy.Strategy, checkpointDir string, log logger.Logger) *Backtester {
+	return &Backtester{
+		logger:        log,
+		source:        source,
+		newStrategy:   newStrategy,
+		checkpointDir: checkpointDir,
+		pageSize:      defaultPageSize,
+	}
+}
+
+// Run 执行回测。存在同一交易对、同一区间的检查点时从检查点之后的K线继续，
+// 否则从区间起点开始；运行中断时已写入的检查点保留，完成后删除
+func (b *Backtester) Run(ctx context.Context, req Request, progress ProgressFunc) (*Result, error) {
+	if !req.End.After(req.Start) {
+		return nil, fmt.Errorf("backtest end %s must be after start %s", req.End.Format(time.RFC3339), req.Start.Format(time.RFC3339))
+	}
+
+	result := &Result{Symbol: req.Symbol, Start: req.Start, End: req.End}
+	cursor := req.Start
+
+	checkpoint, err := b.LoadCheckpoint(req.Symbol)
+	if err != nil {
+		b.logger.Warnf("Ignoring unreadable backtest checkpoint for %s: %v", req.Symbol, err)
+	} else if checkpoint != nil && checkpoint.matches(req) {
+		result = &checkpoint.Result
+		result.Resumed = true
+		cursor = result.Through.Add(interval15M)
+		b.logger.Infof("Resuming %s backtest from checkpoint at %s (%d klines processed)",
+			req.Symbol, result.Through.Format(time.RFC3339), result.Processed)
+	}
+
+	strat := b.newStrategy()
+	if err := b.warmUp(ctx, strat, req.Symbol, cursor); err != nil {
+		return nil, err
+	}
+
+	total := int(req.End.Sub(req.Start)/interval15M) + 1
+	for !cursor.After(req.End) {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		klines, err := b.source.Klines(ctx, req.Symbol, "15m", cursor, req.End, b.pageSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch klines from %s: %w", cursor.Format(time.RFC3339), err)
+		}
+		if len(klines) == 0 {
+			break
+		}
+
+		for _, kline := range klines {
+			kline.Symbol = req.Symbol
+			b.step(strat, result, kline)
+		}
+
+		cursor = result.Through.Add(interval15M)
+		if err := b.saveCheckpoint(&Checkpoint{Result: *result, UpdatedAt: time.Now()}); err != nil {
+			b.logger.Warnf("Failed to save backtest checkpoint for %s: %v", req.Symbol, err)
+		}
+
+		if progress != nil {
+			progress(Progress{
+				Symbol:    req.Symbol,
+				Processed: result.Processed,
+				Total:     total,
+				Through:   result.Through,
+				Trades:    len(result.Trades),
+				Resumed:   result.Resumed,
+			})
+		}
+	}
+
+	b.closeAtEnd(result)
+	if err := b.DiscardCheckpoint(req.Symbol); err != nil {
+		b.logger.Warnf("Failed to remove backtest checkpoint for %s: %v", req.Symbol, err)
+	}
+
+	b.logger.Infof("Backtest for %s finished: %d klines, %d trades, net %.2f%%",
+		req.Symbol, result.Processed, len(result.Trades), result.NetReturnPercent())
+	return result, nil
+}
+
+// warmUp 用 before 之前的历史K线预热策略，使回测从起点（或检查点）开始即可生成信号
+func (b *Backtester) warmUp(ctx context.Context, strat strategy.Strategy, symbol string, before time.Time) error {
+	warmable, ok := strat.(strategy.WarmableStrategy)
+	if !ok {
+		return nil
+	}
+
+	limit := defaultWarmupLimit
+	if limiter, ok := strat.(strategy.HistoryLimiter); ok {
+		limit = min(limiter.HistoryLimit(), defaultPageSize)
+	}
+
+	end := before.Add(-time.Millisecond)
+	for _, interval := range []string{"4h", "15m"} {
+		klines, err := b.source.Klines(ctx, symbol, interval, time.Time{}, end, limit)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s warm-up klines: %w", interval, err)
+		}
+		warmable.WarmUp(symbol, interval, klines)
+	}
+	return nil
+}
+
+// step 回放一根已收盘的15M K线：先按K线高低点检查持仓的止损止盈，再交给策略生成信号，
+// 空仓时按信号价格开仓。同一根K线同时触及止损和止盈时按止损计，结果偏保守
+func (b *Backtester) step(strat strategy.Strategy, result *Result, kline strategy.KlineData) {
+	result.Processed++
+	result.Through = kline.OpenTime
+	result.LastClose = kline.Close
+	result.LastCloseTime = kline.CloseTime
+
+	if pos := result.Open; pos != nil {
+		isLong := pos.Side == "LONG"
+		switch {
+		case isLong && kline.Low.LessThanOrEqual(pos.StopLoss), !isLong && kline.High.GreaterThanOrEqual(pos.StopLoss):
+			b.close(result, pos.StopLoss, kline.CloseTime, ExitStopLoss)
+		case isLong && kline.High.GreaterThanOrEqual(pos.TakeProfit), !isLong && kline.Low.LessThanOrEqual(pos.TakeProfit):
+			b.close(result, pos.TakeProfit, kline.CloseTime, ExitTakeProfit)
+		}
+	}
+
+	signal := strat.GenerateSignal([]strategy.KlineData{kline})
+	if signal == nil || result.Open != nil {
+		return
+	}
+
+	var side string
+	switch signal.Type {
+	case strategy.SignalBuy:
+		side = "LONG"
+	case strategy.SignalSell:
+		side = "SHORT"
+	default:
+		return
+	}
+	if signal.StopLoss.IsZero() || signal.TakeProfit.IsZero() {
+		b.logger.Debugf("Skipping %s backtest signal at %s without protective levels", result.Symbol, kline.OpenTime.Format(time.RFC3339))
+		return
+	}
+
+	result.Open = &Position{
+		Side:       side,
+		EntryPrice: signal.Price,
+		StopLoss:   signal.StopLoss,
+		TakeProfit: signal.TakeProfit,
+		EntryTime:  kline.CloseTime,
+	}
+}
+
+// close 按指定价格平掉模拟持仓并记录交易
+func (b *Backtester) close(result *Result, price decimal.Decimal, at time.Time, reason string) {
+	pos := result.Open
+	change := price.Sub(pos.EntryPrice)
+	if pos.Side == "SHORT" {
+		change = change.Neg()
+	}
+
+	result.Trades = append(result.Trades, Trade{
+		Position:      *pos,
+		ExitPrice:     price,
+		ExitTime:      at,
+		ExitReason:    reason,
+		ReturnPercent: change.Div(pos.EntryPrice).Mul(decimal.NewFromInt(100)).Round(4).InexactFloat64(),
+	})
+	result.Open = nil
+}
+
+// closeAtEnd 区间结束时仍有持仓则按最后一根K线的收盘价平仓
+func (b *Backtester) closeAtEnd(result *Result) {
+	if result.Open == nil {
+		return
+	}
+	b.close(result, result.LastClose, result.LastCloseTime, ExitEnd)
+}
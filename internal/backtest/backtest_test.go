@@ -0,0 +1,252 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// fetch 记录的K线请求
+type fetch struct {
+	interval string
+	start    time.Time
+}
+
+// fakeSource 内存中的15M K线序列，记录每次请求
+type fakeSource struct {
+	klines []strategy.KlineData
+
+	mu      sync.Mutex
+	fetches []fetch
+}
+
+// newFakeSource 从 from 开始生成 n 根按正弦波动的15M K线
+func newFakeSource(from time.Time, n int) *fakeSource {
+	source := &fakeSource{}
+	prev := decimal.NewFromInt(100)
+	for i := 0; i < n; i++ {
+		price := decimal.NewFromFloat(100 + 10*math.Sin(float64(i)/7) + float64(i%5)*0.3).Round(2)
+		open := from.Add(time.Duration(i) * interval15M)
+		source.klines = append(source.klines, strategy.KlineData{
+			Open:      prev,
+			High:      decimal.Max(prev, price).Add(decimal.NewFromFloat(0.5)),
+			Low:       decimal.Min(prev, price).Sub(decimal.NewFromFloat(0.5)),
+			Close:     price,
+			Volume:    decimal.NewFromInt(1),
+			OpenTime:  open,
+			CloseTime: open.Add(interval15M - time.Millisecond),
+		})
+		prev = price
+	}
+	return source
+}
+
+func (s *fakeSource) Klines(ctx context.Context, symbol, interval string, start, end time.Time, limit int) ([]strategy.KlineData, error) {
+	s.mu.Lock()
+	s.fetches = append(s.fetches, fetch{interval, start})
+	s.mu.Unlock()
+
+	if interval != "15m" {
+		return nil, nil
+	}
+
+	var matched []strategy.KlineData
+	for _, kline := range s.klines {
+		if kline.OpenTime.Before(start) || kline.OpenTime.After(end) {
+			continue
+		}
+		matched = append(matched, kline)
+	}
+	if len(matched) <= limit {
+		return matched, nil
+	}
+	if start.IsZero() {
+		return matched[len(matched)-limit:], nil
+	}
+	return matched[:limit], nil
+}
+
+// pageStarts 回放K线（非预热）请求的起始时间
+func (s *fakeSource) pageStarts() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var starts []time.Time
+	for _, f := range s.fetches {
+		if f.interval == "15m" && !f.start.IsZero() {
+			starts = append(starts, f.start)
+		}
+	}
+	return starts
+}
+
+// momentumStrategy 只依赖上一根收盘价的测试策略：涨跌超过1%时顺势开仓，
+// 从检查点继续时经预热即可恢复到与不间断运行相同的状态
+type momentumStrategy struct {
+	last decimal.Decimal
+}
+
+func (m *momentumStrategy) GenerateSignal(klines []strategy.KlineData) *strategy.TradingSignal {
+	kline := klines[len(klines)-1]
+	last := m.last
+	m.last = kline.Close
+	if last.IsZero() {
+		return nil
+	}
+
+	change := kline.Close.Sub(last).Div(last).InexactFloat64()
+	signal := &strategy.TradingSignal{Symbol: kline.Symbol, Price: kline.Close, Timestamp: kline.CloseTime}
+	switch {
+	case change > 0.01:
+		signal.Type = strategy.SignalBuy
+		signal.StopLoss = kline.Close.Mul(decimal.NewFromFloat(0.98))
+		signal.TakeProfit = kline.Close.Mul(decimal.NewFromFloat(1.03))
+	case change < -0.01:
+		signal.Type = strategy.SignalSell
+		signal.StopLoss = kline.Close.Mul(decimal.NewFromFloat(1.02))
+		signal.TakeProfit = kline.Close.Mul(decimal.NewFromFloat(0.97))
+	default:
+		return nil
+	}
+	return signal
+}
+
+func (m *momentumStrategy) GetStrategyInfo() strategy.StrategyInfo { return strategy.StrategyInfo{} }
+
+func (m *momentumStrategy) ValidateParameters() error { return nil }
+
+func (m *momentumStrategy) ResetSymbol(symbol string) { m.last = decimal.Zero }
+
+func (m *momentumStrategy) WarmUp(symbol, timeframe string, klines []strategy.KlineData) {
+	if timeframe == "15m" && len(klines) > 0 {
+		m.last = klines[len(klines)-1].Close
+	}
+}
+
+func (m *momentumStrategy) GetWarmupStatus(symbol string) strategy.WarmupStatus {
+	return strategy.WarmupStatus{Symbol: symbol, Ready: !m.last.IsZero()}
+}
+
+func newTestBacktester(t *testing.T, source KlineSource, dir string) *Backtester {
+	t.Helper()
+	b := New(source, func() strategy.Strategy { return &momentumStrategy{} }, dir, logger.NewLoggerWithLevel("error"))
+	b.pageSize = 100
+	return b
+}
+
+func TestRunResumesFromCheckpoint(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := Request{
+		Symbol: "BTCUSDT",
+		Start:  from.Add(50 * interval15M),
+		End:    from.Add(549 * interval15M), // 500根
+	}
+
+	// 不间断运行的结果作为基准
+	baseline, err := newTestBacktester(t, newFakeSource(from, 600), t.TempDir()).Run(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("baseline Run: %v", err)
+	}
+	if baseline.Processed != 500 || len(baseline.Trades) == 0 {
+		t.Fatalf("baseline processed %d klines with %d trades, want 500 klines and some trades", baseline.Processed, len(baseline.Trades))
+	}
+
+	// 处理完两页后中断
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = newTestBacktester(t, newFakeSource(from, 600), dir).Run(ctx, req, func(p Progress) {
+		if p.Processed == 200 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("interrupted Run error = %v, want context.Canceled", err)
+	}
+
+	checkpoint, err := newTestBacktester(t, nil, dir).LoadCheckpoint(req.Symbol)
+	if err != nil || checkpoint == nil {
+		t.Fatalf("LoadCheckpoint = %v, %v; want the mid-run checkpoint", checkpoint, err)
+	}
+	if checkpoint.Processed != 200 || !checkpoint.Through.Equal(req.Start.Add(199*interval15M)) {
+		t.Fatalf("checkpoint at %d klines through %s, want 200 through %s",
+			checkpoint.Processed, checkpoint.Through, req.Start.Add(199*interval15M))
+	}
+
+	// 从检查点继续：不重新处理已完成的K线，结果与不间断运行一致
+	source := newFakeSource(from, 600)
+	var reported []Progress
+	resumed, err := newTestBacktester(t, source, dir).Run(context.Background(), req, func(p Progress) {
+		reported = append(reported, p)
+	})
+	if err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	if !resumed.Resumed {
+		t.Error("resumed run did not report resuming from the checkpoint")
+	}
+	if starts := source.pageStarts(); len(starts) == 0 || !starts[0].Equal(req.Start.Add(200*interval15M)) {
+		t.Errorf("resumed run fetched pages from %v, want the first page at %s", starts, req.Start.Add(200*interval15M))
+	}
+	if len(reported) == 0 || reported[0].Processed != 300 || reported[len(reported)-1].Percent() != 100 {
+		t.Errorf("resumed progress = %+v, want to continue from 200 of 500 klines to 100%%", reported)
+	}
+	if resumed.Processed != baseline.Processed {
+		t.Errorf("resumed processed %d klines, want %d", resumed.Processed, baseline.Processed)
+	}
+	if !reflect.DeepEqual(tradeSummary(resumed.Trades), tradeSummary(baseline.Trades)) {
+		t.Errorf("resumed trades differ from uninterrupted run:\n got %v\nwant %v",
+			tradeSummary(resumed.Trades), tradeSummary(baseline.Trades))
+	}
+
+	// 完成后删除检查点，下次从头开始
+	if checkpoint, err := newTestBacktester(t, nil, dir).LoadCheckpoint(req.Symbol); err != nil || checkpoint != nil {
+		t.Errorf("checkpoint after completion = %v, %v; want none", checkpoint, err)
+	}
+}
+
+func TestRunIgnoresCheckpointForOtherRange(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	b := newTestBacktester(t, newFakeSource(from, 300), dir)
+
+	stale := &Checkpoint{Result: Result{
+		Symbol:    "BTCUSDT",
+		Start:     from,
+		End:       from.Add(99 * interval15M),
+		Processed: 50,
+		Through:   from.Add(49 * interval15M),
+	}}
+	if err := b.saveCheckpoint(stale); err != nil {
+		t.Fatalf("saveCheckpoint: %v", err)
+	}
+
+	result, err := b.Run(context.Background(), Request{
+		Symbol: "BTCUSDT",
+		Start:  from.Add(10 * interval15M),
+		End:    from.Add(209 * interval15M),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Resumed || result.Processed != 200 {
+		t.Errorf("run resumed=%v processed=%d, want a fresh run over 200 klines", result.Resumed, result.Processed)
+	}
+}
+
+// tradeSummary 交易的可比较摘要
+func tradeSummary(trades []Trade) []string {
+	summary := make([]string, len(trades))
+	for i, trade := range trades {
+		summary[i] = trade.Side + " " + trade.EntryPrice.String() + "->" + trade.ExitPrice.String() + " " +
+			trade.ExitReason + " " + trade.ExitTime.UTC().Format(time.RFC3339)
+	}
+	return summary
+}
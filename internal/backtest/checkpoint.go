@@ -0,0 +1,74 @@
+package backtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Checkpoint 回测检查点：累计结果及最后处理的K线，每个交易对只保留一个
+type Checkpoint struct {
+	Result
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// matches 检查点是否属于同一交易对、同一区间的回测
+func (c *Checkpoint) matches(req Request) bool {
+	return c.Symbol == req.Symbol && c.Start.Equal(req.Start) && c.End.Equal(req.End)
+}
+
+// checkpointPath 交易对的检查点文件路径
+func (b *Backtester) checkpointPath(symbol string) string {
+	return filepath.Join(b.checkpointDir, fmt.Sprintf("backtest_%s.json", strings.ToUpper(symbol)))
+}
+
+// LoadCheckpoint 读取交易对未完成回测的检查点，没有检查点时返回 nil
+func (b *Backtester) LoadCheckpoint(symbol string) (*Checkpoint, error) {
+	data, err := os.ReadFile(b.checkpointPath(symbol))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// DiscardCheckpoint 删除交易对的检查点，下次回测从头开始
+func (b *Backtester) DiscardCheckpoint(symbol string) error {
+	if err := os.Remove(b.checkpointPath(symbol)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// saveCheckpoint 写入检查点：先写临时文件再重命名，写入中途中断不会留下损坏的检查点
+func (b *Backtester) saveCheckpoint(checkpoint *Checkpoint) error {
+	if err := os.MkdirAll(b.checkpointDir, 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	path := b.checkpointPath(checkpoint.Symbol)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint: %w", err)
+	}
+	return nil
+}
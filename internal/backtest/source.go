@@ -0,0 +1,61 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// BinanceSource 通过币安REST接口按时间范围获取历史K线
+type BinanceSource struct {
+	client *binance.Client
+}
+
+// NewBinanceSource 创建币安K线来源
+func NewBinanceSource(client *binance.Client) *BinanceSource {
+	return &BinanceSource{client: client}
+}
+
+// Klines 获取时间范围内的K线，丢弃仍在形成中的K线
+func (s *BinanceSource) Klines(ctx context.Context, symbol, interval string, start, end time.Time, limit int) ([]strategy.KlineData, error) {
+	var startMs, endMs int64
+	if !start.IsZero() {
+		startMs = start.UnixMilli()
+	}
+	if !end.IsZero() {
+		endMs = end.UnixMilli()
+	}
+
+	klines, err := s.client.GetKlinesRange(ctx, symbol, interval, startMs, endMs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	nowMs := time.Now().UnixMilli()
+	result := make([]strategy.KlineData, 0, len(klines))
+	for _, kline := range klines {
+		if kline.CloseTime > nowMs {
+			continue
+		}
+
+		parsed, err := kline.ToDecimal()
+		if err != nil {
+			return nil, fmt.Errorf("invalid kline at %d: %w", kline.OpenTime, err)
+		}
+		result = append(result, strategy.KlineData{
+			Symbol:    symbol,
+			Open:      parsed.Open,
+			High:      parsed.High,
+			Low:       parsed.Low,
+			Close:     parsed.Close,
+			Volume:    parsed.Volume,
+			OpenTime:  time.UnixMilli(parsed.OpenTime),
+			CloseTime: time.UnixMilli(parsed.CloseTime),
+		})
+	}
+
+	return result, nil
+}
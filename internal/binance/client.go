@@ -1,6 +1,7 @@
 package binance
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
@@ -13,16 +14,24 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
 )
 
+// defaultRequestTimeout 未配置 timeout 时单次REST请求的超时时间
+const defaultRequestTimeout = 30 * time.Second
+
 // Client Binance API客户端
 type Client struct {
 	config     *config.BinanceConfig
 	logger     logger.Logger
 	httpClient *http.Client
 	baseURL    string
+	maintenance maintenanceState
+	limiter     *rateLimiter
+	clock       clockState
+	dialer      *websocket.Dialer // 用户数据流使用的WebSocket拨号器，与REST请求共用代理
 }
 
 // New 创建新的Binance客户端
@@ -44,28 +53,48 @@ func New(cfg *config.BinanceConfig, log logger.Logger) (*Client, error) {
 		}
 	}
 
+	proxyURL, err := ParseProxyURL(cfg.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
 		config: cfg,
 		logger: log,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   requestTimeout(cfg.Timeout),
+			Transport: newTransport(proxyURL),
 		},
 		baseURL: baseURL,
+		limiter: newRateLimiter(cfg.RateLimit),
+		dialer:  newDialer(proxyURL),
+	}
+
+	if proxyURL != nil {
+		log.Infof("Binance client using proxy %s", proxyURL.Redacted())
 	}
 
 	log.Infof("Binance client initialized (testnet: %v)", cfg.Testnet)
 	return client, nil
 }
 
+// requestTimeout 单次REST请求的超时时间，未配置时使用默认值
+func requestTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultRequestTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // TestConnection 测试连接
-func (c *Client) TestConnection() error {
-	_, err := c.GetServerTime()
+func (c *Client) TestConnection(ctx context.Context) error {
+	_, err := c.GetServerTime(ctx)
 	return err
 }
 
 // GetServerTime 获取服务器时间
-func (c *Client) GetServerTime() (int64, error) {
-	resp, err := c.makeRequest("GET", "/fapi/v1/time", nil, false)
+func (c *Client) GetServerTime(ctx context.Context) (int64, error) {
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/time", nil, false)
 	if err != nil {
 		return 0, err
 	}
@@ -82,8 +111,8 @@ func (c *Client) GetServerTime() (int64, error) {
 }
 
 // GetAccountInfo 获取账户信息
-func (c *Client) GetAccountInfo() (*AccountInfo, error) {
-	resp, err := c.makeRequest("GET", "/fapi/v2/account", nil, true)
+func (c *Client) GetAccountInfo(ctx context.Context) (*AccountInfo, error) {
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v2/account", nil, true)
 	if err != nil {
 		return nil, err
 	}
@@ -96,9 +125,27 @@ func (c *Client) GetAccountInfo() (*AccountInfo, error) {
 	return &account, nil
 }
 
+// GetCommissionRate 获取交易对的挂单/吃单手续费率
+func (c *Client) GetCommissionRate(ctx context.Context, symbol string) (*CommissionRate, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/commissionRate", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var rate CommissionRate
+	if err := json.Unmarshal(resp, &rate); err != nil {
+		return nil, fmt.Errorf("failed to parse commission rate: %w", err)
+	}
+
+	return &rate, nil
+}
+
 // GetPositions 获取持仓信息
-func (c *Client) GetPositions() ([]Position, error) {
-	resp, err := c.makeRequest("GET", "/fapi/v2/positionRisk", nil, true)
+func (c *Client) GetPositions(ctx context.Context) ([]Position, error) {
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v2/positionRisk", nil, true)
 	if err != nil {
 		return nil, err
 	}
@@ -112,13 +159,25 @@ func (c *Client) GetPositions() ([]Position, error) {
 }
 
 // GetKlines 获取K线数据
-func (c *Client) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+func (c *Client) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	return c.GetKlinesRange(ctx, symbol, interval, 0, 0, limit)
+}
+
+// GetKlinesRange 获取指定时间范围内的K线数据（毫秒时间戳，为0时不限制），
+// 只指定 endTime 时返回截至该时间的最近 limit 根
+func (c *Client) GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error) {
 	params := url.Values{}
 	params.Set("symbol", symbol)
 	params.Set("interval", interval)
 	params.Set("limit", strconv.Itoa(limit))
+	if startTime > 0 {
+		params.Set("startTime", strconv.FormatInt(startTime, 10))
+	}
+	if endTime > 0 {
+		params.Set("endTime", strconv.FormatInt(endTime, 10))
+	}
 
-	resp, err := c.makeRequest("GET", "/fapi/v1/klines", params, false)
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/klines", params, false)
 	if err != nil {
 		return nil, err
 	}
@@ -140,13 +199,69 @@ func (c *Client) GetKlines(symbol, interval string, limit int) ([]Kline, error)
 	return klines, nil
 }
 
-// PlaceOrder 下单
-func (c *Client) PlaceOrder(order *OrderRequest) (*OrderResponse, error) {
+// GetTickerPrice 获取交易对最新价格
+func (c *Client) GetTickerPrice(ctx context.Context, symbol string) (*TickerPrice, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/ticker/price", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticker TickerPrice
+	if err := json.Unmarshal(resp, &ticker); err != nil {
+		return nil, fmt.Errorf("failed to parse ticker price: %w", err)
+	}
+
+	return &ticker, nil
+}
+
+// GetPremiumIndex 获取交易对标记价格与资金费率
+func (c *Client) GetPremiumIndex(ctx context.Context, symbol string) (*PremiumIndex, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/premiumIndex", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var index PremiumIndex
+	if err := json.Unmarshal(resp, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse premium index: %w", err)
+	}
+
+	return &index, nil
+}
+
+// GetExchangeInfo 获取交易规则信息
+func (c *Client) GetExchangeInfo(ctx context.Context) (*ExchangeInfo, error) {
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/exchangeInfo", nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var info ExchangeInfo
+	if err := json.Unmarshal(resp, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange info: %w", err)
+	}
+
+	return &info, nil
+}
+
+// submitOrder 提交一次下单请求，重试由 PlaceOrder 负责
+func (c *Client) submitOrder(ctx context.Context, order *OrderRequest) (*OrderResponse, error) {
 	params := url.Values{}
 	params.Set("symbol", order.Symbol)
 	params.Set("side", order.Side)
 	params.Set("type", order.Type)
-	params.Set("quantity", order.Quantity)
+	params.Set("newClientOrderId", order.NewClientOrderID)
+
+	// closePosition 平掉整个持仓，不指定数量
+	if order.Quantity != "" {
+		params.Set("quantity", order.Quantity)
+	}
 	
 	if order.Price != "" {
 		params.Set("price", order.Price)
@@ -160,7 +275,15 @@ func (c *Client) PlaceOrder(order *OrderRequest) (*OrderResponse, error) {
 		params.Set("stopPrice", order.StopPrice)
 	}
 
-	resp, err := c.makeRequest("POST", "/fapi/v1/order", params, true)
+	if order.ReduceOnly {
+		params.Set("reduceOnly", "true")
+	}
+
+	if order.ClosePosition {
+		params.Set("closePosition", "true")
+	}
+
+	resp, err := c.makeRequest(ctx, "POST", "/fapi/v1/order", params, true)
 	if err != nil {
 		return nil, err
 	}
@@ -173,31 +296,113 @@ func (c *Client) PlaceOrder(order *OrderRequest) (*OrderResponse, error) {
 	return &orderResp, nil
 }
 
+// GetOpenOrders 获取交易对的当前挂单
+func (c *Client) GetOpenOrders(ctx context.Context, symbol string) ([]OrderResponse, error) {
+	params := url.Values{}
+	if symbol != "" {
+		params.Set("symbol", symbol)
+	}
+
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/openOrders", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []OrderResponse
+	if err := json.Unmarshal(resp, &orders); err != nil {
+		return nil, fmt.Errorf("failed to parse open orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// GetOrder 查询订单状态
+func (c *Client) GetOrder(ctx context.Context, symbol string, orderID int64) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", strconv.FormatInt(orderID, 10))
+
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/order", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var order OrderResponse
+	if err := json.Unmarshal(resp, &order); err != nil {
+		return nil, fmt.Errorf("failed to parse order: %w", err)
+	}
+
+	return &order, nil
+}
+
 // CancelOrder 取消订单
-func (c *Client) CancelOrder(symbol string, orderID int64) error {
+func (c *Client) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
 	params := url.Values{}
 	params.Set("symbol", symbol)
 	params.Set("orderId", strconv.FormatInt(orderID, 10))
 
-	_, err := c.makeRequest("DELETE", "/fapi/v1/order", params, true)
+	_, err := c.makeRequest(ctx, "DELETE", "/fapi/v1/order", params, true)
+	return err
+}
+
+// CancelAllOrders 撤销交易对的全部挂单
+func (c *Client) CancelAllOrders(ctx context.Context, symbol string) error {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+
+	_, err := c.makeRequest(ctx, "DELETE", "/fapi/v1/allOpenOrders", params, true)
 	return err
 }
 
-// makeRequest 发送HTTP请求
-func (c *Client) makeRequest(method, endpoint string, params url.Values, signed bool) ([]byte, error) {
+// makeRequest 发送HTTP请求，ctx 取消时中止限流等待和进行中的请求。
+// 签名请求的时间戳超出接收窗口时立即重新同步服务器时间并重试一次
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, params url.Values, signed bool) ([]byte, error) {
 	if params == nil {
 		params = url.Values{}
 	}
 
-	// 添加时间戳
 	if signed {
-		params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
-		
+		c.ensureTimeSync(ctx)
+	}
+
+	body, err := c.doRequest(ctx, method, endpoint, params, signed)
+	if !signed || !hasCode(err, CodeTimestampOutOfSync) {
+		return body, err
+	}
+
+	c.logger.Warnf("Request to %s rejected for timestamp outside recvWindow, resyncing server time", endpoint)
+	if syncErr := c.resyncTime(ctx); syncErr != nil {
+		c.logger.Warnf("Failed to resync Binance server time: %v", syncErr)
+		return nil, err
+	}
+	return c.doRequest(ctx, method, endpoint, params, signed)
+}
+
+// doRequest 发送一次HTTP请求，签名请求按服务器时间重新生成时间戳和签名
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, params url.Values, signed bool) ([]byte, error) {
+	// 按接口权重限流，等待期间不占用签名时间戳的接收窗口
+	if err := c.limiter.acquire(ctx, requestWeight(endpoint, params)); err != nil {
+		return nil, fmt.Errorf("rate limiter: %w", err)
+	}
+
+	// 添加时间戳和接收窗口
+	if signed {
+		params.Del("signature")
+		params.Set("timestamp", strconv.FormatInt(c.serverTimestamp(), 10))
+		if c.config.RecvWindow > 0 {
+			params.Set("recvWindow", strconv.Itoa(c.config.RecvWindow))
+		}
+
 		// 生成签名
 		signature := c.generateSignature(params.Encode())
 		params.Set("signature", signature)
 	}
 
+	// 维护退避期间不发送请求
+	if err := c.checkMaintenance(); err != nil {
+		return nil, err
+	}
+
 	// 构建URL
 	var reqURL string
 	if method == "GET" || method == "DELETE" {
@@ -211,13 +416,13 @@ func (c *Client) makeRequest(method, endpoint string, params url.Values, signed
 	var err error
 	
 	if method == "POST" || method == "PUT" {
-		req, err = http.NewRequest(method, reqURL, strings.NewReader(params.Encode()))
+		req, err = http.NewRequestWithContext(ctx, method, reqURL, strings.NewReader(params.Encode()))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	} else {
-		req, err = http.NewRequest(method, reqURL, nil)
+		req, err = http.NewRequestWithContext(ctx, method, reqURL, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -232,6 +437,7 @@ func (c *Client) makeRequest(method, endpoint string, params url.Values, signed
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	c.limiter.observe(resp)
 
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
@@ -239,11 +445,17 @@ func (c *Client) makeRequest(method, endpoint string, params url.Values, signed
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	// 维护期间返回维护页面或特定错误码
+	if isMaintenanceResponse(resp.StatusCode, body) {
+		return nil, c.markMaintenance()
+	}
+	c.clearMaintenance()
+
 	// 检查HTTP状态码
 	if resp.StatusCode != http.StatusOK {
 		var apiErr APIError
 		if err := json.Unmarshal(body, &apiErr); err == nil {
-			return nil, fmt.Errorf("API error: %s (code: %d)", apiErr.Msg, apiErr.Code)
+			return nil, &BinanceError{StatusCode: resp.StatusCode, Code: apiErr.Code, Msg: apiErr.Msg, Endpoint: endpoint}
 		}
 		return nil, fmt.Errorf("HTTP error: %d - %s", resp.StatusCode, string(body))
 	}
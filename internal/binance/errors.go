@@ -0,0 +1,90 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// 常见的下单拒绝错误码
+const (
+	CodeDisconnected       = -1001 // 内部错误，无法处理请求
+	CodeTooManyRequests    = -1003 // 请求权重超限
+	CodeTimeout            = -1007 // 等待后端响应超时，结果未知
+	CodeTooManyOrders      = -1015 // 下单频率超限
+	CodeTimestampOutOfSync = -1021 // 时间戳超出接收窗口
+	CodeBadPrecision       = -1111 // 数量或价格精度超过交易对限制
+	CodeMarginInsufficient = -2019 // 保证金不足
+	CodeWouldTrigger       = -2021 // 条件单会立即触发
+	CodeReduceOnlyReject   = -2022 // 只减仓单被拒绝
+	CodePriceTickSize      = -4014 // 价格不是最小变动价位的整数倍
+	CodeQuantityStepSize   = -4023 // 数量不是最小变动数量的整数倍
+	CodeMinNotional        = -4164 // 名义价值低于下限
+)
+
+// BinanceError 币安接口返回的错误，携带错误码和HTTP状态码，调用方可用 errors.As 判断
+type BinanceError struct {
+	StatusCode int    // HTTP状态码
+	Code       int    // 币安错误码
+	Msg        string // 币安错误信息
+	Endpoint   string // 请求的接口路径
+}
+
+func (e *BinanceError) Error() string {
+	return fmt.Sprintf("API error on %s: %s (code: %d, HTTP %d)", e.Endpoint, e.Msg, e.Code, e.StatusCode)
+}
+
+// AsBinanceError 从错误链中取出币安错误
+func AsBinanceError(err error) (*BinanceError, bool) {
+	var binanceErr *BinanceError
+	if errors.As(err, &binanceErr) {
+		return binanceErr, true
+	}
+	return nil, false
+}
+
+// hasCode 错误链中是否包含指定错误码之一的币安错误
+func hasCode(err error, codes ...int) bool {
+	binanceErr, ok := AsBinanceError(err)
+	if !ok {
+		return false
+	}
+	for _, code := range codes {
+		if binanceErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInsufficientMargin 是否为保证金不足被拒绝
+func IsInsufficientMargin(err error) bool {
+	return hasCode(err, CodeMarginInsufficient)
+}
+
+// IsRateLimited 是否因请求或下单频率超限被拒绝（含 429/418 状态码）
+func IsRateLimited(err error) bool {
+	if binanceErr, ok := AsBinanceError(err); ok {
+		if binanceErr.StatusCode == http.StatusTooManyRequests || binanceErr.StatusCode == http.StatusTeapot {
+			return true
+		}
+	}
+	return hasCode(err, CodeTooManyRequests, CodeTooManyOrders)
+}
+
+// IsPrecisionError 是否因数量或价格不符合交易对精度规则被拒绝，需修正订单参数而非重试
+func IsPrecisionError(err error) bool {
+	return hasCode(err, CodeBadPrecision, CodePriceTickSize, CodeQuantityStepSize, CodeMinNotional)
+}
+
+// IsTransient 是否为可稍后重试的临时错误：频率超限、后端超时或时间戳不同步。
+// CodeTimeout 时订单可能已被受理，重试前应先按客户端订单号查询
+func IsTransient(err error) bool {
+	if IsRateLimited(err) {
+		return true
+	}
+	if binanceErr, ok := AsBinanceError(err); ok && binanceErr.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+	return hasCode(err, CodeDisconnected, CodeTimeout, CodeTimestampOutOfSync)
+}
@@ -0,0 +1,129 @@
+package binance
+
+import (
+	"time"
+)
+
+// FlappingHandler 重连抖动回调：flapping 为 true 表示窗口内重连次数达到阈值，false 表示连接已稳定足够时长
+type FlappingHandler func(flapping bool, reconnects int, window time.Duration)
+
+// flapState 重连抖动检测配置及汇总状态，重连记录按连接分别保存在 streamConn 上
+type flapState struct {
+	threshold    int
+	window       time.Duration
+	stablePeriod time.Duration
+	flapping     bool // 是否有连接处于抖动状态，全部连接稳定后清除
+	handler      FlappingHandler
+}
+
+// connFlapState 单个连接的重连频率跟踪状态
+type connFlapState struct {
+	reconnects []time.Time // 窗口内的重连时间
+	flapping   bool
+}
+
+// SetFlappingHandler 设置重连抖动检测：window 内重连达到 threshold 次时回调，连接稳定 stablePeriod 后回调恢复；threshold 为0时不检测
+func (ws *WebSocketClient) SetFlappingHandler(threshold int, window, stablePeriod time.Duration, handler FlappingHandler) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.flapState.threshold = threshold
+	ws.flapState.window = window
+	ws.flapState.stablePeriod = stablePeriod
+	ws.flapState.handler = handler
+}
+
+// Flapping 是否有连接当前处于重连抖动状态
+func (ws *WebSocketClient) Flapping() bool {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return ws.flapState.flapping
+}
+
+// anyFlappingLocked 是否仍有连接处于抖动状态，调用方需持有 ws.mu
+func (ws *WebSocketClient) anyFlappingLocked() bool {
+	for _, sc := range ws.conns {
+		if sc.flap.flapping {
+			return true
+		}
+	}
+	return false
+}
+
+// recordConnected 记录连接的一次成功连接并检测重连抖动，reconnect 为 false 表示连接的首次连接，不计为重连。
+// 返回稳定计时器（未启用检测时为nil），连接断开时需调用方停止
+func (ws *WebSocketClient) recordConnected(sc *streamConn, reconnect bool) *time.Timer {
+	now := time.Now()
+
+	ws.mu.Lock()
+	state := &ws.flapState
+	if state.threshold <= 0 {
+		ws.mu.Unlock()
+		return nil
+	}
+
+	if reconnect {
+		sc.flap.reconnects = append(sc.flap.reconnects, now)
+	}
+
+	// 丢弃窗口外的重连记录
+	cutoff := now.Add(-state.window)
+	kept := sc.flap.reconnects[:0]
+	for _, at := range sc.flap.reconnects {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	sc.flap.reconnects = kept
+
+	reconnects := len(sc.flap.reconnects)
+	notify := false
+	if reconnects >= state.threshold && !sc.flap.flapping {
+		sc.flap.flapping = true
+		notify = !state.flapping
+		state.flapping = true
+	}
+	window := state.window
+	stablePeriod := state.stablePeriod
+	handler := state.handler
+	ws.mu.Unlock()
+
+	if notify {
+		ws.logger.Warnf("WebSocket connection %d reconnect flapping: %d reconnects within %v", sc.id, reconnects, window)
+		if handler != nil {
+			handler(true, reconnects, window)
+		}
+	}
+
+	return time.AfterFunc(stablePeriod, func() { ws.recordFlapStable(sc) })
+}
+
+// recordFlapStable 连接稳定达到设定时长后清除该连接的抖动状态
+func (ws *WebSocketClient) recordFlapStable(sc *streamConn) {
+	ws.mu.Lock()
+	reconnects := len(sc.flap.reconnects)
+	sc.flap = connFlapState{}
+	ws.mu.Unlock()
+
+	ws.settleFlapState(reconnects)
+}
+
+// settleFlapState 所有连接都已稳定（或抖动中的连接已移出连接池）时清除抖动状态并回调恢复
+func (ws *WebSocketClient) settleFlapState(reconnects int) {
+	ws.mu.Lock()
+	state := &ws.flapState
+	recovered := state.flapping && !ws.anyFlappingLocked()
+	if recovered {
+		state.flapping = false
+	}
+	window := state.window
+	stablePeriod := state.stablePeriod
+	handler := state.handler
+	ws.mu.Unlock()
+
+	if recovered {
+		ws.logger.Infof("WebSocket connections stable for %v, flapping cleared", stablePeriod)
+		if handler != nil {
+			handler(false, reconnects, window)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package binance
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// pingInterval 客户端发送 ping 的间隔
+	pingInterval = 30 * time.Second
+	// pongWait 读取超时：期间既无消息也无 pong 时判定连接失效
+	pongWait = 60 * time.Second
+	// controlWriteWait 写入控制帧的超时
+	controlWriteWait = 10 * time.Second
+)
+
+// keepalive 单个连接的 ping/pong 保活状态
+type keepalive struct {
+	mu       sync.Mutex
+	pingSent time.Time // 最近一次未收到 pong 的 ping 发送时间，零值表示已响应
+	missed   int       // 连续未响应的 ping 数
+}
+
+// startKeepalive 为连接注册 ping/pong 处理器并定时发送 ping，返回停止函数
+func (ws *WebSocketClient) startKeepalive(conn *websocket.Conn) func() {
+	ka := &keepalive{}
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+
+	// 响应服务端 ping，并视为连接仍然存活
+	conn.SetPingHandler(func(appData string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		err := conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(controlWriteWait))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		ka.mu.Lock()
+		if ka.missed > 0 {
+			ws.logger.Infof("WebSocket pong received after %d missed", ka.missed)
+		}
+		ka.pingSent = time.Time{}
+		ka.missed = 0
+		ka.mu.Unlock()
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ws.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			ka.mu.Lock()
+			if !ka.pingSent.IsZero() {
+				ka.missed++
+				ws.logger.Warnf("WebSocket pong missed (%d consecutive, last ping %v ago)", ka.missed, time.Since(ka.pingSent).Round(time.Second))
+			} else {
+				ka.pingSent = time.Now()
+			}
+			ka.mu.Unlock()
+
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(controlWriteWait)); err != nil {
+				ws.logger.Warnf("Failed to send WebSocket ping: %v", err)
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
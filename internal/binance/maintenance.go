@@ -0,0 +1,131 @@
+package binance
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrMaintenance 币安接口维护中，调用方可用 errors.Is 判断
+var ErrMaintenance = errors.New("binance API under maintenance")
+
+// maintenanceCodes 维护或服务不可用期间返回的错误码
+var maintenanceCodes = map[int]bool{
+	-1001: true, // DISCONNECTED 内部错误，维护期间常见
+	-1008: true, // 服务器过载
+	-1016: true, // SERVICE_SHUTTING_DOWN
+}
+
+// maintenanceState 维护状态：检测到维护后按指数退避暂停请求，恢复后自动清除
+type maintenanceState struct {
+	mu      sync.Mutex
+	active  bool
+	since   time.Time
+	backoff time.Duration
+	retryAt time.Time
+	handler func(active bool)
+}
+
+// isMaintenanceResponse 判断响应是否表示交易所维护：503、非JSON响应体（维护页面）或维护错误码
+func isMaintenanceResponse(status int, body []byte) bool {
+	if status == http.StatusServiceUnavailable {
+		return true
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '<' {
+		return true
+	}
+
+	if status != http.StatusOK {
+		var apiErr APIError
+		if err := json.Unmarshal(body, &apiErr); err == nil {
+			return maintenanceCodes[apiErr.Code]
+		}
+		return status >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// SetMaintenanceHandler 设置维护状态变化回调，进入维护时 active 为 true，恢复时为 false
+func (c *Client) SetMaintenanceHandler(handler func(active bool)) {
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+	c.maintenance.handler = handler
+}
+
+// InMaintenance 是否处于维护状态
+func (c *Client) InMaintenance() bool {
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+	return c.maintenance.active
+}
+
+// checkMaintenance 维护退避期间直接返回错误，不向交易所发送请求
+func (c *Client) checkMaintenance() error {
+	c.maintenance.mu.Lock()
+	defer c.maintenance.mu.Unlock()
+
+	if c.maintenance.active && time.Now().Before(c.maintenance.retryAt) {
+		return fmt.Errorf("%w: retry at %s", ErrMaintenance, c.maintenance.retryAt.Format("15:04:05"))
+	}
+	return nil
+}
+
+// markMaintenance 记录一次维护响应并延长退避时间
+func (c *Client) markMaintenance() error {
+	base := time.Duration(c.config.MaintenanceBackoff) * time.Second
+	max := time.Duration(c.config.MaxMaintenanceBackoff) * time.Second
+
+	c.maintenance.mu.Lock()
+	entered := !c.maintenance.active
+	if entered {
+		c.maintenance.active = true
+		c.maintenance.since = time.Now()
+		c.maintenance.backoff = base
+	} else {
+		c.maintenance.backoff *= 2
+		if c.maintenance.backoff > max {
+			c.maintenance.backoff = max
+		}
+	}
+	c.maintenance.retryAt = time.Now().Add(c.maintenance.backoff)
+	backoff := c.maintenance.backoff
+	handler := c.maintenance.handler
+	c.maintenance.mu.Unlock()
+
+	if entered {
+		c.logger.Warnf("Binance API maintenance detected, pausing requests for %v", backoff)
+		if handler != nil {
+			handler(true)
+		}
+	} else {
+		c.logger.Debugf("Binance API still under maintenance, next retry in %v", backoff)
+	}
+
+	return fmt.Errorf("%w: retry in %v", ErrMaintenance, backoff)
+}
+
+// clearMaintenance 请求成功后退出维护状态
+func (c *Client) clearMaintenance() {
+	c.maintenance.mu.Lock()
+	if !c.maintenance.active {
+		c.maintenance.mu.Unlock()
+		return
+	}
+	duration := time.Since(c.maintenance.since)
+	c.maintenance.active = false
+	c.maintenance.backoff = 0
+	handler := c.maintenance.handler
+	c.maintenance.mu.Unlock()
+
+	c.logger.Infof("Binance API recovered after %v of maintenance", duration.Round(time.Second))
+	if handler != nil {
+		handler(false)
+	}
+}
@@ -0,0 +1,78 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// MarginType 保证金模式
+type MarginType string
+
+const (
+	MarginTypeIsolated MarginType = "ISOLATED"
+	MarginTypeCrossed  MarginType = "CROSSED"
+)
+
+const (
+	// codeNoNeedToChangeMarginType 保证金模式与当前一致，无需切换
+	codeNoNeedToChangeMarginType = -4046
+	// codeMarginTypeOpenOrders 存在挂单时无法切换保证金模式
+	codeMarginTypeOpenOrders = -4047
+	// codeMarginTypeOpenPosition 存在持仓时无法切换保证金模式
+	codeMarginTypeOpenPosition = -4048
+)
+
+// ErrMarginTypeLocked 交易对存在持仓或挂单，无法切换保证金模式
+var ErrMarginTypeLocked = errors.New("margin type cannot be changed while a position or open orders exist")
+
+// SetMarginType 设置交易对的保证金模式，已是目标模式时视为成功
+func (c *Client) SetMarginType(ctx context.Context, symbol string, marginType MarginType) error {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("marginType", string(marginType))
+
+	_, err := c.makeRequest(ctx, "POST", "/fapi/v1/marginType", params, true)
+	if err == nil {
+		return nil
+	}
+
+	if binanceErr, ok := AsBinanceError(err); ok {
+		switch binanceErr.Code {
+		case codeNoNeedToChangeMarginType:
+			return nil
+		case codeMarginTypeOpenOrders, codeMarginTypeOpenPosition:
+			return fmt.Errorf("%w: %w", ErrMarginTypeLocked, err)
+		}
+	}
+	return fmt.Errorf("failed to set margin type for %s: %w", symbol, err)
+}
+
+// LeverageResponse 调整杠杆的响应
+type LeverageResponse struct {
+	Symbol           string `json:"symbol"`
+	Leverage         int    `json:"leverage"`
+	MaxNotionalValue string `json:"maxNotionalValue"` // 当前杠杆下允许的最大名义价值
+}
+
+// SetLeverage 设置交易对的杠杆倍数
+func (c *Client) SetLeverage(ctx context.Context, symbol string, leverage int) (*LeverageResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("leverage", strconv.Itoa(leverage))
+
+	resp, err := c.makeRequest(ctx, "POST", "/fapi/v1/leverage", params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set leverage for %s: %w", symbol, err)
+	}
+
+	var result LeverageResponse
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse leverage response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,50 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/websocket"
+)
+
+// ParseProxyURL 解析代理地址，支持 http、https 和 socks5，为空时返回 nil 表示不使用代理
+func ParseProxyURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected http, https or socks5", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy URL %s has no host", proxyURL.Redacted())
+	}
+
+	return proxyURL, nil
+}
+
+// newTransport 创建REST请求使用的传输层，未配置代理时沿用环境变量中的代理设置
+func newTransport(proxyURL *url.URL) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return transport
+}
+
+// newDialer 创建WebSocket连接使用的拨号器，未配置代理时与默认拨号器一致
+func newDialer(proxyURL *url.URL) *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+	if proxyURL != nil {
+		dialer.Proxy = http.ProxyURL(proxyURL)
+	}
+	return &dialer
+}
@@ -0,0 +1,153 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitWindow 币安请求权重的统计窗口
+const rateLimitWindow = time.Minute
+
+// RateLimitUsage 请求权重使用情况
+type RateLimitUsage struct {
+	Limit      int           // 每分钟权重上限
+	Used       int           // 本地令牌桶已消耗的权重
+	ServerUsed int           // 最近一次响应头 X-MBX-USED-WEIGHT-1M 报告的已用权重
+	Waited     time.Duration // 因限流累计等待的时间
+}
+
+// rateLimiter 按请求权重计费的令牌桶，令牌按每分钟上限匀速补充
+type rateLimiter struct {
+	mu         sync.Mutex
+	limit      float64
+	tokens     float64
+	last       time.Time
+	serverUsed int
+	waited     time.Duration
+}
+
+// newRateLimiter 创建每分钟 limit 权重的令牌桶，初始为满
+func newRateLimiter(limit int) *rateLimiter {
+	return &rateLimiter{limit: float64(limit), tokens: float64(limit), last: time.Now()}
+}
+
+// refill 按流逝时间补充令牌，调用方需持有锁
+func (rl *rateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(rl.last)
+	rl.last = now
+	rl.tokens += rl.limit * elapsed.Seconds() / rateLimitWindow.Seconds()
+	if rl.tokens > rl.limit {
+		rl.tokens = rl.limit
+	}
+}
+
+// acquire 获取 weight 个令牌，不足时阻塞等待，ctx 取消时返回错误
+func (rl *rateLimiter) acquire(ctx context.Context, weight int) error {
+	need := float64(weight)
+	if need > rl.limit {
+		need = rl.limit
+	}
+
+	for {
+		rl.mu.Lock()
+		rl.refill(time.Now())
+		if rl.tokens >= need {
+			rl.tokens -= need
+			rl.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((need - rl.tokens) / rl.limit * float64(rateLimitWindow))
+		rl.waited += wait
+		rl.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// observe 按响应头同步服务端统计的已用权重：服务端用量高于本地估算时扣减令牌，
+// 收到 429/418 时清空令牌桶，等待一个完整窗口的补充
+func (rl *rateLimiter) observe(resp *http.Response) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill(time.Now())
+	if used, err := strconv.Atoi(resp.Header.Get("X-MBX-USED-WEIGHT-1M")); err == nil {
+		rl.serverUsed = used
+		if remaining := rl.limit - float64(used); remaining < rl.tokens {
+			rl.tokens = max(remaining, 0)
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		rl.tokens = 0
+	}
+}
+
+// usage 当前权重使用情况
+func (rl *rateLimiter) usage() RateLimitUsage {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill(time.Now())
+	return RateLimitUsage{
+		Limit:      int(rl.limit),
+		Used:       int(rl.limit - rl.tokens),
+		ServerUsed: rl.serverUsed,
+		Waited:     rl.waited,
+	}
+}
+
+// RateLimitUsage 获取REST请求权重的使用情况
+func (c *Client) RateLimitUsage() RateLimitUsage {
+	return c.limiter.usage()
+}
+
+// requestWeight 接口的请求权重，参考币安U本位合约文档
+func requestWeight(endpoint string, params url.Values) int {
+	_, hasSymbol := params["symbol"]
+	switch endpoint {
+	case "/fapi/v2/account", "/fapi/v2/positionRisk":
+		return 5
+	case "/fapi/v1/commissionRate":
+		return 20
+	case "/fapi/v1/openOrders":
+		if hasSymbol {
+			return 1
+		}
+		return 40
+	case "/fapi/v1/ticker/price":
+		if hasSymbol {
+			return 1
+		}
+		return 2
+	case "/fapi/v1/premiumIndex":
+		if hasSymbol {
+			return 1
+		}
+		return 10
+	case "/fapi/v1/klines":
+		limit, err := strconv.Atoi(params.Get("limit"))
+		if err != nil {
+			limit = 500
+		}
+		switch {
+		case limit < 100:
+			return 1
+		case limit < 500:
+			return 2
+		case limit <= 1000:
+			return 5
+		}
+		return 10
+	}
+	return 1
+}
@@ -0,0 +1,174 @@
+package binance
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ReconnectBackoff 重连退避参数
+type ReconnectBackoff struct {
+	Base        time.Duration // 首次重连前的等待时间，此后每次连续失败翻倍
+	Max         time.Duration // 等待时间上限
+	StableAfter time.Duration // 连接保持多久视为稳定，稳定后重置连续失败计数和退避时间
+}
+
+// DefaultReconnectBackoff 默认重连退避：1秒起按2倍增长至60秒，连接保持2分钟后重置
+func DefaultReconnectBackoff() ReconnectBackoff {
+	return ReconnectBackoff{
+		Base:        time.Second,
+		Max:         time.Minute,
+		StableAfter: 2 * time.Minute,
+	}
+}
+
+// delay 第 failures 次连续失败后的等待时间（failures 为0时为初始值），在 [d/2, d] 内随机抖动避免多实例同时重连
+func (b ReconnectBackoff) delay(failures int) time.Duration {
+	d := b.Base
+	for i := 1; i < failures && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// ReconnectHandler 重连状态回调：exhausted 为 true 表示连续失败达到上限，false 表示此后已恢复稳定连接
+type ReconnectHandler func(exhausted bool, failures int, lastErr error)
+
+// reconnectState 重连告警配置及汇总状态，连续失败计数按连接分别记录在 streamConn 上
+type reconnectState struct {
+	maxFailures int
+	exhausted   bool // 是否有连接连续失败达到上限，全部连接恢复后清除
+	handler     ReconnectHandler
+	backoff     ReconnectBackoff
+}
+
+// connReconnectState 单个连接的连续重连失败状态
+type connReconnectState struct {
+	failures  int
+	exhausted bool
+	lastErr   error
+}
+
+// SetReconnectHandler 设置最大连续重连失败次数及告警回调，maxFailures 为0时不告警
+func (ws *WebSocketClient) SetReconnectHandler(maxFailures int, handler ReconnectHandler) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.reconnectState.maxFailures = maxFailures
+	ws.reconnectState.handler = handler
+}
+
+// SetReconnectBackoff 设置重连退避参数，需在 Start 前调用
+func (ws *WebSocketClient) SetReconnectBackoff(backoff ReconnectBackoff) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.reconnectState.backoff = backoff
+}
+
+// stableAfter 连接保持多久视为稳定
+func (ws *WebSocketClient) stableAfter() time.Duration {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return ws.reconnectState.backoff.StableAfter
+}
+
+// reconnectDelay 稳定连接正常断开后的重连等待时间
+func (ws *WebSocketClient) reconnectDelay(sc *streamConn) time.Duration {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return ws.reconnectState.backoff.delay(sc.reconnect.failures)
+}
+
+// maxReconnectDelay 重连等待时间上限
+func (ws *WebSocketClient) maxReconnectDelay() time.Duration {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return ws.reconnectState.backoff.Max
+}
+
+// ReconnectFailures 连接池中各连接当前连续重连失败次数的最大值
+func (ws *WebSocketClient) ReconnectFailures() int {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	failures := 0
+	for _, sc := range ws.conns {
+		if sc.reconnect.failures > failures {
+			failures = sc.reconnect.failures
+		}
+	}
+	return failures
+}
+
+// anyExhaustedLocked 是否仍有连接处于连续失败告警状态，调用方需持有 ws.mu
+func (ws *WebSocketClient) anyExhaustedLocked() bool {
+	for _, sc := range ws.conns {
+		if sc.reconnect.exhausted {
+			return true
+		}
+	}
+	return false
+}
+
+// recordReconnectFailure 记录连接的一次失败（拨号失败或连接未达到稳定时长即断开），返回下次重连前的等待时间和该连接的连续失败次数。
+// 任一连接首次达到上限时告警，其他连接已在告警中时不重复告警
+func (ws *WebSocketClient) recordReconnectFailure(sc *streamConn, err error) (time.Duration, int) {
+	ws.mu.Lock()
+	state := &ws.reconnectState
+	sc.reconnect.failures++
+	sc.reconnect.lastErr = err
+	failures := sc.reconnect.failures
+	notify := false
+	if state.maxFailures > 0 && failures >= state.maxFailures && !sc.reconnect.exhausted {
+		sc.reconnect.exhausted = true
+		notify = !state.exhausted
+		state.exhausted = true
+	}
+	delay := state.backoff.delay(failures)
+	handler := state.handler
+	ws.mu.Unlock()
+
+	if notify {
+		ws.logger.Errorf("WebSocket connection %d reconnect failed %d consecutive times, last error: %v", sc.id, failures, err)
+		if handler != nil {
+			handler(true, failures, err)
+		}
+	}
+
+	return delay, failures
+}
+
+// recordStableConnection 连接保持稳定后重置该连接的失败计数
+func (ws *WebSocketClient) recordStableConnection(sc *streamConn) {
+	ws.mu.Lock()
+	failures := sc.reconnect.failures
+	sc.reconnect = connReconnectState{}
+	ws.mu.Unlock()
+
+	ws.settleReconnectState(failures)
+}
+
+// settleReconnectState 所有连接都已恢复（或告警中的连接已移出连接池）时清除告警并回调恢复
+func (ws *WebSocketClient) settleReconnectState(failures int) {
+	ws.mu.Lock()
+	state := &ws.reconnectState
+	recovered := state.exhausted && !ws.anyExhaustedLocked()
+	if recovered {
+		state.exhausted = false
+	}
+	handler := state.handler
+	ws.mu.Unlock()
+
+	if recovered {
+		ws.logger.Infof("WebSocket connections stable again after %d failed attempts", failures)
+		if handler != nil {
+			handler(false, failures, nil)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package binance
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+const (
+	// CodeDuplicateClientOrderID 客户端订单号重复，说明此前的提交已被受理
+	CodeDuplicateClientOrderID = -4116
+	// CodeOrderNotExist 订单不存在
+	CodeOrderNotExist = -2013
+
+	// clientOrderIDPrefix 本程序生成的客户端订单号前缀
+	clientOrderIDPrefix = "vdt-"
+	// orderRetryDelay 下单重试的基础等待时间，按尝试次数线性增加
+	orderRetryDelay = 500 * time.Millisecond
+)
+
+// newClientOrderID 由订单内容和首次提交时间生成客户端订单号，同一请求的重试使用相同订单号，
+// 交易所据此去重。币安限制为36个字符
+func newClientOrderID(order *OrderRequest, at time.Time) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%s|%s|%s|%s|%t|%t|%d",
+		order.Symbol, order.Side, order.Type, order.Quantity, order.Price, order.StopPrice,
+		order.ReduceOnly, order.ClosePosition, at.UnixNano())
+	return clientOrderIDPrefix + hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// isNetworkError 请求是否因网络错误或超时失败，此时请求可能已到达交易所
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
+
+// shouldRetryOrder 下单失败是否可以重试：网络错误、5xx、后端超时(-1007)等临时错误。
+// 频率超限时重试只会加重封禁，不重试
+func shouldRetryOrder(err error) bool {
+	if IsRateLimited(err) {
+		return false
+	}
+	return isNetworkError(err) || IsTransient(err)
+}
+
+// GetOrderByClientID 按客户端订单号查询订单
+func (c *Client) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("origClientOrderId", clientOrderID)
+
+	resp, err := c.makeRequest(ctx, "GET", "/fapi/v1/order", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var order OrderResponse
+	if err := json.Unmarshal(resp, &order); err != nil {
+		return nil, fmt.Errorf("failed to parse order: %w", err)
+	}
+
+	return &order, nil
+}
+
+// findSubmittedOrder 查询结果未知的下单是否已被交易所受理，未找到时返回 nil
+func (c *Client) findSubmittedOrder(ctx context.Context, symbol, clientOrderID string) (*OrderResponse, error) {
+	order, err := c.GetOrderByClientID(ctx, symbol, clientOrderID)
+	if hasCode(err, CodeOrderNotExist) {
+		return nil, nil
+	}
+	return order, err
+}
+
+// PlaceOrder 下单：未指定客户端订单号时自动生成，临时错误时以同一订单号重试，最多提交一次
+func (c *Client) PlaceOrder(ctx context.Context, order *OrderRequest) (*OrderResponse, error) {
+	if order.NewClientOrderID == "" {
+		order.NewClientOrderID = newClientOrderID(order, time.Now())
+	}
+
+	retries := max(c.config.OrderRetries, 0)
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("order %s cancelled before retry: %w (last error: %v)", order.NewClientOrderID, ctx.Err(), lastErr)
+			case <-time.After(time.Duration(attempt) * orderRetryDelay):
+			}
+
+			// 上次提交结果未知，先确认是否已被受理，避免重复下单
+			existing, err := c.findSubmittedOrder(ctx, order.Symbol, order.NewClientOrderID)
+			if err == nil && existing != nil {
+				c.logger.Infof("Order %s for %s was accepted despite error: %v", order.NewClientOrderID, order.Symbol, lastErr)
+				return existing, nil
+			}
+			if err != nil {
+				c.logger.Warnf("Failed to check order %s before retry: %v", order.NewClientOrderID, err)
+			}
+			c.logger.Warnf("Retrying order %s for %s (attempt %d/%d) after: %v", order.NewClientOrderID, order.Symbol, attempt, retries, lastErr)
+		}
+
+		resp, err := c.submitOrder(ctx, order)
+		if err == nil {
+			return resp, nil
+		}
+
+		// 订单号重复说明此前的提交已被受理，返回已存在的订单
+		if hasCode(err, CodeDuplicateClientOrderID) {
+			existing, queryErr := c.GetOrderByClientID(ctx, order.Symbol, order.NewClientOrderID)
+			if queryErr != nil {
+				return nil, fmt.Errorf("duplicate client order id %s but lookup failed: %w", order.NewClientOrderID, queryErr)
+			}
+			return existing, nil
+		}
+
+		lastErr = err
+		if !shouldRetryOrder(err) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("order %s failed after %d retries: %w", order.NewClientOrderID, retries, lastErr)
+}
@@ -0,0 +1,128 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxStreamsPerCombined 币安单个组合流连接允许的最大流数量
+	maxStreamsPerCombined = 1024
+	// streamsPerConnection 连接池中每个连接承载的流数量，远低于上限以控制URL长度和单连接消息量
+	streamsPerConnection = 200
+)
+
+var (
+	// errNoStreams 连接没有分配到任何流，不需要连接
+	errNoStreams = errors.New("no streams to subscribe")
+	// errStreamConfig 连接配置错误（流数量超限、地址无效），重连无法恢复，不计为重连失败
+	errStreamConfig = errors.New("invalid stream configuration")
+)
+
+// streamConn 连接池中的单个组合流连接，字段由 WebSocketClient.mu 保护
+type streamConn struct {
+	id      int
+	conn    *websocket.Conn
+	streams []string
+	dialed  bool       // 是否已成功连接过，之后的连接计为重连
+	writeMu sync.Mutex // 连接只允许单个写入方
+	// 重连失败和抖动状态按连接记录，单个连接反复失败不影响其他连接的计数
+	reconnect connReconnectState
+	flap      connFlapState
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// combinedStreamURL 构建组合流地址 <base>/stream?streams=a/b/c。
+// baseURL 已是 /stream 或 /ws 形式时先去掉路径和查询参数，流数量超过币安上限时返回错误
+func combinedStreamURL(baseURL string, streams []string) (string, error) {
+	if len(streams) == 0 {
+		return "", errNoStreams
+	}
+	if len(streams) > maxStreamsPerCombined {
+		return "", fmt.Errorf("%w: %d streams exceed the combined stream limit of %d", errStreamConfig, len(streams), maxStreamsPerCombined)
+	}
+
+	base := baseURL
+	if i := strings.IndexByte(base, '?'); i >= 0 {
+		base = base[:i]
+	}
+	base = strings.TrimRight(base, "/")
+	for _, suffix := range []string{"/stream", "/ws"} {
+		base = strings.TrimSuffix(base, suffix)
+	}
+
+	return fmt.Sprintf("%s/stream?streams=%s", base, strings.Join(streams, "/")), nil
+}
+
+// findStreamConnLocked 查找承载该流的连接，调用方需持有 ws.mu
+func (ws *WebSocketClient) findStreamConnLocked(stream string) (*streamConn, int) {
+	for _, sc := range ws.conns {
+		for i, s := range sc.streams {
+			if s == stream {
+				return sc, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+// assignStreamLocked 将新流分配到首个未满的连接，全部已满时新建连接。
+// 返回承载该流的连接，以及新建连接时为 true（需由调用方启动），调用方需持有 ws.mu
+func (ws *WebSocketClient) assignStreamLocked(stream string) (*streamConn, bool) {
+	for _, sc := range ws.conns {
+		if len(sc.streams) < ws.streamsPerConn {
+			sc.streams = append(sc.streams, stream)
+			return sc, false
+		}
+	}
+
+	sc := ws.newStreamConnLocked()
+	sc.streams = append(sc.streams, stream)
+	return sc, true
+}
+
+// newStreamConnLocked 向连接池添加一个连接，调用方需持有 ws.mu
+func (ws *WebSocketClient) newStreamConnLocked() *streamConn {
+	ctx, cancel := context.WithCancel(ws.ctx)
+	ws.nextConnID++
+	sc := &streamConn{id: ws.nextConnID, ctx: ctx, cancel: cancel}
+	ws.conns = append(ws.conns, sc)
+	return sc
+}
+
+// retireStreamConnLocked 移除已无订阅的连接并关闭，连接循环随之退出，调用方需持有 ws.mu
+func (ws *WebSocketClient) retireStreamConnLocked(sc *streamConn) {
+	if len(sc.streams) > 0 {
+		return
+	}
+
+	retired := false
+	for i, c := range ws.conns {
+		if c == sc {
+			ws.conns = append(ws.conns[:i], ws.conns[i+1:]...)
+			retired = true
+			break
+		}
+	}
+	if !retired {
+		return
+	}
+	sc.cancel()
+	if sc.conn != nil {
+		sc.conn.Close()
+	}
+	ws.logger.Infof("WebSocket connection %d has no streams left, closed", sc.id)
+}
+
+// ConnectionCount 连接池中的连接数
+func (ws *WebSocketClient) ConnectionCount() int {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return len(ws.conns)
+}
@@ -0,0 +1,111 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// controlRequest 组合流订阅控制消息
+type controlRequest struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int64    `json:"id"`
+}
+
+// controlResponse 控制消息的响应，成功时 result 为 null
+type controlResponse struct {
+	ID    *int64 `json:"id"`
+	Error *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+// pendingControl 已发送、等待响应的控制消息
+type pendingControl struct {
+	method string
+	params []string
+	sentAt time.Time
+	connID int // 发送该消息的连接
+}
+
+// sendSubscribe 在连接已连接时发送 SUBSCRIBE 控制消息，未连接时返回 false，由下次连接时的订阅列表生效
+func (ws *WebSocketClient) sendSubscribe(sc *streamConn, streams ...string) (bool, error) {
+	return ws.sendControl(sc, "SUBSCRIBE", streams)
+}
+
+// sendUnsubscribe 在连接已连接时发送 UNSUBSCRIBE 控制消息，未连接时返回 false
+func (ws *WebSocketClient) sendUnsubscribe(sc *streamConn, streams ...string) (bool, error) {
+	return ws.sendControl(sc, "UNSUBSCRIBE", streams)
+}
+
+// sendControl 向连接写入控制消息并记录请求ID，请求ID在连接池内唯一
+func (ws *WebSocketClient) sendControl(sc *streamConn, method string, streams []string) (bool, error) {
+	if len(streams) == 0 {
+		return false, nil
+	}
+
+	ws.mu.Lock()
+	conn := sc.conn
+	if conn == nil {
+		ws.mu.Unlock()
+		return false, nil
+	}
+	ws.nextRequestID++
+	id := ws.nextRequestID
+	ws.pendingControls[id] = pendingControl{method: method, params: streams, sentAt: time.Now(), connID: sc.id}
+	ws.mu.Unlock()
+
+	// 连接只允许单个写入方
+	sc.writeMu.Lock()
+	err := conn.WriteJSON(controlRequest{Method: method, Params: streams, ID: id})
+	sc.writeMu.Unlock()
+
+	if err != nil {
+		ws.mu.Lock()
+		delete(ws.pendingControls, id)
+		ws.mu.Unlock()
+		return false, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	ws.logger.Debugf("Sent %s %v (id %d)", method, streams, id)
+	return true, nil
+}
+
+// handleControlResponse 处理控制消息响应，message 不是控制响应时返回 false
+func (ws *WebSocketClient) handleControlResponse(message []byte) bool {
+	var resp controlResponse
+	if err := json.Unmarshal(message, &resp); err != nil || resp.ID == nil {
+		return false
+	}
+
+	ws.mu.Lock()
+	request, ok := ws.pendingControls[*resp.ID]
+	delete(ws.pendingControls, *resp.ID)
+	ws.mu.Unlock()
+
+	if !ok {
+		ws.logger.Debugf("Received response for unknown control request %d", *resp.ID)
+		return true
+	}
+
+	if resp.Error != nil {
+		ws.logger.Errorf("%s %v rejected: %s (code: %d)", request.method, request.params, resp.Error.Msg, resp.Error.Code)
+		return true
+	}
+
+	ws.logger.Infof("%s %v confirmed in %v", request.method, request.params, time.Since(request.sentAt).Round(time.Millisecond))
+	return true
+}
+
+// clearPendingControls 连接断开时丢弃该连接未响应的控制消息，重连时按完整订阅列表重新订阅
+func (ws *WebSocketClient) clearPendingControls(sc *streamConn) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for id, request := range ws.pendingControls {
+		if request.connID == sc.id {
+			delete(ws.pendingControls, id)
+		}
+	}
+}
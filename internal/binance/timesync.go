@@ -0,0 +1,91 @@
+package binance
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// timeSyncInterval 服务器时间同步间隔，签名请求发现同步过期时先重新同步
+	timeSyncInterval = 30 * time.Minute
+	// clockDriftWarning 本机时钟偏差超过该值时告警
+	clockDriftWarning = time.Second
+)
+
+// clockState 本机与服务器时间的偏差，用于修正签名请求的时间戳
+type clockState struct {
+	mu       sync.RWMutex
+	offset   time.Duration // 服务器时间减本机时间
+	syncedAt time.Time
+	syncMu   sync.Mutex // 串行化同步，避免并发请求同时同步
+}
+
+// SyncServerTime 查询服务器时间并更新本机时钟偏差，按请求往返的中点估算
+func (c *Client) SyncServerTime(ctx context.Context) (time.Duration, error) {
+	sent := time.Now()
+	serverTime, err := c.GetServerTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	offset := time.UnixMilli(serverTime).Sub(midpoint)
+
+	c.clock.mu.Lock()
+	c.clock.offset = offset
+	c.clock.syncedAt = received
+	c.clock.mu.Unlock()
+
+	drift := offset.Round(time.Millisecond)
+	if offset > clockDriftWarning || offset < -clockDriftWarning {
+		c.logger.Warnf("Local clock is off by %v from Binance server time (round trip %v), check the host clock sync",
+			drift, received.Sub(sent).Round(time.Millisecond))
+	} else {
+		c.logger.Infof("Synced with Binance server time, clock drift %v", drift)
+	}
+
+	return offset, nil
+}
+
+// ensureTimeSync 同步已过期时重新同步服务器时间，失败时沿用上次的偏差
+func (c *Client) ensureTimeSync(ctx context.Context) {
+	if !c.timeSyncStale() {
+		return
+	}
+
+	c.clock.syncMu.Lock()
+	defer c.clock.syncMu.Unlock()
+
+	// 等待期间其他请求可能已完成同步
+	if !c.timeSyncStale() {
+		return
+	}
+	if _, err := c.SyncServerTime(ctx); err != nil {
+		c.logger.Warnf("Failed to sync Binance server time, using last known offset: %v", err)
+	}
+}
+
+// resyncTime 时间戳被拒绝后立即重新同步服务器时间
+func (c *Client) resyncTime(ctx context.Context) error {
+	c.clock.syncMu.Lock()
+	defer c.clock.syncMu.Unlock()
+
+	_, err := c.SyncServerTime(ctx)
+	return err
+}
+
+// timeSyncStale 是否从未同步或距上次同步已超过同步间隔
+func (c *Client) timeSyncStale() bool {
+	c.clock.mu.RLock()
+	defer c.clock.mu.RUnlock()
+	return c.clock.syncedAt.IsZero() || time.Since(c.clock.syncedAt) >= timeSyncInterval
+}
+
+// serverTimestamp 按服务器时间修正后的当前毫秒时间戳
+func (c *Client) serverTimestamp() int64 {
+	c.clock.mu.RLock()
+	defer c.clock.mu.RUnlock()
+	return time.Now().Add(c.clock.offset).UnixMilli()
+}
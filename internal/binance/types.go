@@ -1,6 +1,10 @@
 package binance
 
-import "github.com/shopspring/decimal"
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
 
 // AccountInfo 账户信息
 type AccountInfo struct {
@@ -42,6 +46,16 @@ type AccountAsset struct {
 	UpdateTime             int64  `json:"updateTime"`
 }
 
+// FindAsset 查找指定资产，不存在时返回 nil
+func (a *AccountInfo) FindAsset(asset string) *AccountAsset {
+	for i := range a.Assets {
+		if a.Assets[i].Asset == asset {
+			return &a.Assets[i]
+		}
+	}
+	return nil
+}
+
 // AccountPosition 账户持仓
 type AccountPosition struct {
 	Symbol                 string `json:"symbol"`
@@ -59,6 +73,13 @@ type AccountPosition struct {
 	UpdateTime             int64  `json:"updateTime"`
 }
 
+// CommissionRate 交易对手续费率
+type CommissionRate struct {
+	Symbol              string `json:"symbol"`
+	MakerCommissionRate string `json:"makerCommissionRate"`
+	TakerCommissionRate string `json:"takerCommissionRate"`
+}
+
 // Position 持仓信息
 type Position struct {
 	Symbol           string          `json:"symbol"`
@@ -93,6 +114,54 @@ type Kline struct {
 	TakerBuyQuoteAssetVolume string `json:"takerBuyQuoteAssetVolume"`
 }
 
+// DecimalKline 价格和成交量已解析为 decimal 的K线数据
+type DecimalKline struct {
+	OpenTime  int64
+	Open      decimal.Decimal
+	High      decimal.Decimal
+	Low       decimal.Decimal
+	Close     decimal.Decimal
+	Volume    decimal.Decimal
+	CloseTime int64
+}
+
+// ToDecimal 解析K线的价格和成交量，任一字段无法解析或价格不为正时返回错误，避免异常数据被当作零值
+func (k Kline) ToDecimal() (DecimalKline, error) {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"open", k.Open},
+		{"high", k.High},
+		{"low", k.Low},
+		{"close", k.Close},
+		{"volume", k.Volume},
+	}
+
+	values := make([]decimal.Decimal, len(fields))
+	for i, field := range fields {
+		value, err := decimal.NewFromString(field.value)
+		if err != nil {
+			return DecimalKline{}, fmt.Errorf("invalid %s %q: %w", field.name, field.value, err)
+		}
+		// 价格必须为正，成交量允许为零
+		if field.name != "volume" && !value.IsPositive() {
+			return DecimalKline{}, fmt.Errorf("invalid %s %q: must be positive", field.name, field.value)
+		}
+		values[i] = value
+	}
+
+	return DecimalKline{
+		OpenTime:  k.OpenTime,
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+		Volume:    values[4],
+		CloseTime: k.CloseTime,
+	}, nil
+}
+
 // OrderRequest 下单请求
 type OrderRequest struct {
 	Symbol           string `json:"symbol"`
@@ -103,11 +172,13 @@ type OrderRequest struct {
 	Price            string `json:"price,omitempty"`
 	StopPrice        string `json:"stopPrice,omitempty"`
 	ClosePosition    bool   `json:"closePosition,omitempty"`
+	ReduceOnly       bool   `json:"reduceOnly,omitempty"`
 	ActivationPrice  string `json:"activationPrice,omitempty"`
 	CallbackRate     string `json:"callbackRate,omitempty"`
 	WorkingType      string `json:"workingType,omitempty"`
 	PriceProtect     bool   `json:"priceProtect,omitempty"`
 	NewOrderRespType string `json:"newOrderRespType,omitempty"`
+	NewClientOrderID string `json:"newClientOrderId,omitempty"` // 客户端订单号，为空时下单前自动生成
 }
 
 // OrderResponse 下单响应
@@ -132,15 +203,105 @@ type OrderResponse struct {
 	WorkingType   string `json:"workingType"`
 	PriceProtect  bool   `json:"priceProtect"`
 	OrigType      string `json:"origType"`
+	Time          int64  `json:"time"`
 	UpdateTime    int64  `json:"updateTime"`
 }
 
+// ExchangeInfo 交易规则信息
+type ExchangeInfo struct {
+	Timezone   string       `json:"timezone"`
+	ServerTime int64        `json:"serverTime"`
+	Symbols    []SymbolInfo `json:"symbols"`
+}
+
+// SymbolInfo 交易对规则
+type SymbolInfo struct {
+	Symbol            string                   `json:"symbol"`
+	Pair              string                   `json:"pair"`
+	ContractType      string                   `json:"contractType"`
+	Status            string                   `json:"status"`
+	BaseAsset         string                   `json:"baseAsset"`
+	QuoteAsset        string                   `json:"quoteAsset"`
+	MarginAsset       string                   `json:"marginAsset"`
+	PricePrecision    int                      `json:"pricePrecision"`
+	QuantityPrecision int                      `json:"quantityPrecision"`
+	ContractSize      float64                  `json:"contractSize,omitempty"` // 合约乘数（币本位合约，U本位合约无此字段）
+	Filters           []map[string]interface{} `json:"filters"`
+}
+
+// SymbolStatusTrading 交易对正常交易状态，其他如 PENDING_TRADING、BREAK 均不可下单
+const SymbolStatusTrading = "TRADING"
+
+// IsTrading 交易对当前是否可交易
+func (s *SymbolInfo) IsTrading() bool {
+	return s.Status == SymbolStatusTrading
+}
+
+// ContractMultiplier 获取合约乘数，未提供时按1:1处理
+func (s *SymbolInfo) ContractMultiplier() decimal.Decimal {
+	if s.ContractSize <= 0 {
+		return decimal.NewFromInt(1)
+	}
+	return decimal.NewFromFloat(s.ContractSize)
+}
+
+// SettlementAsset 获取保证金资产，未提供时使用报价资产
+func (s *SymbolInfo) SettlementAsset() string {
+	if s.MarginAsset != "" {
+		return s.MarginAsset
+	}
+	return s.QuoteAsset
+}
+
+// FilterValue 获取交易规则过滤器中的数值字段，不存在或无法解析时返回零
+func (s *SymbolInfo) FilterValue(filterType, field string) decimal.Decimal {
+	for _, filter := range s.Filters {
+		if filter["filterType"] != filterType {
+			continue
+		}
+		raw, ok := filter[field].(string)
+		if !ok {
+			return decimal.Zero
+		}
+		value, err := decimal.NewFromString(raw)
+		if err != nil {
+			return decimal.Zero
+		}
+		return value
+	}
+	return decimal.Zero
+}
+
+// TickSize 获取价格最小变动单位（PRICE_FILTER.tickSize）
+func (s *SymbolInfo) TickSize() decimal.Decimal {
+	return s.FilterValue("PRICE_FILTER", "tickSize")
+}
+
+// StepSize 获取数量最小变动单位（LOT_SIZE.stepSize）
+func (s *SymbolInfo) StepSize() decimal.Decimal {
+	return s.FilterValue("LOT_SIZE", "stepSize")
+}
+
+// MinQty 获取最小下单数量（LOT_SIZE.minQty）
+func (s *SymbolInfo) MinQty() decimal.Decimal {
+	return s.FilterValue("LOT_SIZE", "minQty")
+}
+
+// MinNotional 获取最小名义价值（MIN_NOTIONAL.notional）
+func (s *SymbolInfo) MinNotional() decimal.Decimal {
+	return s.FilterValue("MIN_NOTIONAL", "notional")
+}
+
 // APIError API错误响应
 type APIError struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
 }
 
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: %s (code: %d)", e.Msg, e.Code)
+}
+
 // TickerPrice 价格信息
 type TickerPrice struct {
 	Symbol string          `json:"symbol"`
@@ -148,6 +309,15 @@ type TickerPrice struct {
 	Time   int64           `json:"time"`
 }
 
+// PremiumIndex 标记价格与资金费率
+type PremiumIndex struct {
+	Symbol          string          `json:"symbol"`
+	MarkPrice       decimal.Decimal `json:"markPrice"`
+	LastFundingRate decimal.Decimal `json:"lastFundingRate"` // 下次结算适用的资金费率，正值为多头支付空头
+	NextFundingTime int64           `json:"nextFundingTime"` // 下次结算时间（毫秒）
+	Time            int64           `json:"time"`
+}
+
 // OrderSide 订单方向
 type OrderSide string
 
@@ -0,0 +1,348 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+const (
+	// listenKeyKeepalive listenKey 续期间隔，币安60分钟未续期即失效
+	listenKeyKeepalive = 30 * time.Minute
+	// userStreamReadWait 用户数据流读取超时：服务端每3分钟发送 ping，期间没有事件推送属于正常
+	userStreamReadWait = 10 * time.Minute
+)
+
+// 用户数据流事件类型
+const (
+	EventOrderTradeUpdate = "ORDER_TRADE_UPDATE"
+	EventAccountUpdate    = "ACCOUNT_UPDATE"
+	EventListenKeyExpired = "listenKeyExpired"
+)
+
+// OrderTradeUpdateEvent 订单状态或成交推送
+type OrderTradeUpdateEvent struct {
+	EventType       string `json:"e"`
+	EventTime       int64  `json:"E"`
+	TransactionTime int64  `json:"T"`
+	Order           struct {
+		Symbol          string `json:"s"`
+		ClientOrderID   string `json:"c"`
+		Side            string `json:"S"`
+		Type            string `json:"o"`
+		ExecutionType   string `json:"x"` // NEW / TRADE / CANCELED / EXPIRED 等
+		Status          string `json:"X"`
+		OrderID         int64  `json:"i"`
+		LastFilledQty   string `json:"l"`
+		LastFilledPrice string `json:"L"`
+		FilledQty       string `json:"z"` // 累计成交数量
+		AvgPrice        string `json:"ap"`
+		ActivationPrice string `json:"AP"` // 跟踪止损激活价，需单独声明以免按大小写不敏感匹配覆盖 ap
+		ReduceOnly      bool   `json:"R"`
+		PositionSide    string `json:"ps"`
+		RealizedPnl     string `json:"rp"`
+	} `json:"o"`
+}
+
+// AccountUpdateEvent 余额或持仓变化推送
+type AccountUpdateEvent struct {
+	EventType       string `json:"e"`
+	EventTime       int64  `json:"E"`
+	TransactionTime int64  `json:"T"`
+	Account         struct {
+		Reason   string `json:"m"` // ORDER / FUNDING_FEE / MARGIN_TYPE_CHANGE 等
+		Balances []struct {
+			Asset         string `json:"a"`
+			WalletBalance string `json:"wb"`
+			CrossWallet   string `json:"cw"`
+		} `json:"B"`
+		Positions []struct {
+			Symbol         string `json:"s"`
+			PositionAmount string `json:"pa"`
+			EntryPrice     string `json:"ep"`
+			UnrealizedPnl  string `json:"up"`
+			MarginType     string `json:"mt"`
+			PositionSide   string `json:"ps"`
+		} `json:"P"`
+	} `json:"a"`
+}
+
+// UserDataHandler 用户数据流事件处理器
+type UserDataHandler interface {
+	HandleOrderUpdate(event *OrderTradeUpdateEvent) error
+	HandleAccountUpdate(event *AccountUpdateEvent) error
+}
+
+// CreateListenKey 创建用户数据流 listenKey，已存在有效 listenKey 时返回同一个并延长有效期
+func (c *Client) CreateListenKey(ctx context.Context) (string, error) {
+	resp, err := c.makeRequest(ctx, "POST", "/fapi/v1/listenKey", nil, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to create listen key: %w", err)
+	}
+
+	var result struct {
+		ListenKey string `json:"listenKey"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", fmt.Errorf("failed to parse listen key: %w", err)
+	}
+	return result.ListenKey, nil
+}
+
+// KeepaliveListenKey 延长 listenKey 有效期60分钟
+func (c *Client) KeepaliveListenKey(ctx context.Context) error {
+	if _, err := c.makeRequest(ctx, "PUT", "/fapi/v1/listenKey", nil, false); err != nil {
+		return fmt.Errorf("failed to keep alive listen key: %w", err)
+	}
+	return nil
+}
+
+// CloseListenKey 关闭用户数据流
+func (c *Client) CloseListenKey(ctx context.Context) error {
+	if _, err := c.makeRequest(ctx, "DELETE", "/fapi/v1/listenKey", nil, false); err != nil {
+		return fmt.Errorf("failed to close listen key: %w", err)
+	}
+	return nil
+}
+
+// UserDataStream 用户数据流：维护 listenKey 并推送订单和账户事件，断开后按退避重连
+type UserDataStream struct {
+	client       *Client
+	baseURL      string
+	logger       logger.Logger
+	handler      UserDataHandler
+	stateHandler func(connected bool)
+	backoff      ReconnectBackoff
+
+	mu        sync.Mutex
+	conn      *websocket.Conn
+	connected bool
+	started   bool
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewUserDataStream 创建用户数据流，baseURL 为行情WebSocket地址
+func NewUserDataStream(client *Client, baseURL string, log logger.Logger) *UserDataStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &UserDataStream{
+		client:  client,
+		baseURL: baseURL,
+		logger:  log,
+		backoff: DefaultReconnectBackoff(),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+}
+
+// SetHandler 设置事件处理器，需在 Start 前调用
+func (us *UserDataStream) SetHandler(handler UserDataHandler) {
+	us.handler = handler
+}
+
+// SetStateHandler 设置连接状态回调，连接建立时 connected 为 true，断开时为 false
+func (us *UserDataStream) SetStateHandler(handler func(connected bool)) {
+	us.stateHandler = handler
+}
+
+// Connected 用户数据流当前是否连接
+func (us *UserDataStream) Connected() bool {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	return us.connected
+}
+
+// Start 启动用户数据流
+func (us *UserDataStream) Start() {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	if us.started {
+		return
+	}
+	us.started = true
+	go us.run()
+}
+
+// Stop 停止用户数据流并关闭 listenKey
+func (us *UserDataStream) Stop() {
+	us.cancel()
+	us.mu.Lock()
+	started := us.started
+	if us.conn != nil {
+		us.conn.Close()
+	}
+	us.mu.Unlock()
+	if !started {
+		return
+	}
+	<-us.done
+
+	// 流的上下文已取消，关闭 listenKey 只受客户端请求超时限制
+	if err := us.client.CloseListenKey(context.Background()); err != nil {
+		us.logger.Warnf("Failed to close user data stream: %v", err)
+	}
+}
+
+// run 连接循环：每次连接前获取 listenKey，断开后按退避等待重连
+func (us *UserDataStream) run() {
+	defer close(us.done)
+
+	failures := 0
+	for {
+		start := time.Now()
+		err := us.session()
+		us.setConnected(false)
+
+		if us.ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(start) >= us.backoff.StableAfter {
+			failures = 0
+		}
+		failures++
+		delay := us.backoff.delay(failures)
+		us.logger.Warnf("User data stream disconnected: %v, reconnecting in %v", err, delay)
+
+		select {
+		case <-us.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// session 建立一次用户数据流连接并处理消息，直到连接断开或 listenKey 失效
+func (us *UserDataStream) session() error {
+	listenKey, err := us.client.CreateListenKey(us.ctx)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(fmt.Sprintf("%s/ws/%s", us.baseURL, listenKey))
+	if err != nil {
+		return fmt.Errorf("failed to parse URL: %w", err)
+	}
+	conn, _, err := us.client.dialer.DialContext(us.ctx, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to dial user data stream: %w", err)
+	}
+	defer conn.Close()
+
+	us.mu.Lock()
+	us.conn = conn
+	us.mu.Unlock()
+	defer func() {
+		us.mu.Lock()
+		us.conn = nil
+		us.mu.Unlock()
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(userStreamReadWait))
+	conn.SetPingHandler(func(appData string) error {
+		conn.SetReadDeadline(time.Now().Add(userStreamReadWait))
+		err := conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(controlWriteWait))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	stopKeepalive := make(chan struct{})
+	defer close(stopKeepalive)
+	go us.keepalive(stopKeepalive)
+
+	us.logger.Info("User data stream connected")
+	us.setConnected(true)
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(userStreamReadWait))
+
+		expired, err := us.handleMessage(message)
+		if err != nil {
+			us.logger.Errorf("Failed to handle user data event: %v", err)
+		}
+		if expired {
+			return fmt.Errorf("listen key expired")
+		}
+	}
+}
+
+// keepalive 定时续期 listenKey
+func (us *UserDataStream) keepalive(stop <-chan struct{}) {
+	ticker := time.NewTicker(listenKeyKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-us.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := us.client.KeepaliveListenKey(us.ctx); err != nil {
+				us.logger.Warnf("Failed to keep alive user data stream: %v", err)
+			}
+		}
+	}
+}
+
+// handleMessage 解析并分发用户数据流事件，listenKey 失效时 expired 为 true
+func (us *UserDataStream) handleMessage(message []byte) (expired bool, err error) {
+	var base struct {
+		EventType string `json:"e"`
+	}
+	if err := json.Unmarshal(message, &base); err != nil {
+		return false, fmt.Errorf("failed to parse event: %w", err)
+	}
+
+	switch base.EventType {
+	case EventListenKeyExpired:
+		return true, nil
+	case EventOrderTradeUpdate:
+		var event OrderTradeUpdateEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			return false, fmt.Errorf("failed to parse order update: %w", err)
+		}
+		if us.handler != nil {
+			return false, us.handler.HandleOrderUpdate(&event)
+		}
+	case EventAccountUpdate:
+		var event AccountUpdateEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			return false, fmt.Errorf("failed to parse account update: %w", err)
+		}
+		if us.handler != nil {
+			return false, us.handler.HandleAccountUpdate(&event)
+		}
+	default:
+		us.logger.Debugf("Ignoring user data event %s", base.EventType)
+	}
+	return false, nil
+}
+
+// setConnected 更新连接状态，变化时回调
+func (us *UserDataStream) setConnected(connected bool) {
+	us.mu.Lock()
+	changed := us.connected != connected
+	us.connected = connected
+	handler := us.stateHandler
+	us.mu.Unlock()
+
+	if changed && handler != nil {
+		handler(connected)
+	}
+}
@@ -3,6 +3,7 @@ package binance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -10,19 +11,29 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/metrics"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
 )
 
 // WebSocketClient WebSocket客户端
 type WebSocketClient struct {
 	logger     logger.Logger
-	conn       *websocket.Conn
 	baseURL    string
-	streams    []string
+	dialer     *websocket.Dialer
+	// 连接池：订阅按 streamsPerConn 分配到多个组合流连接，处理器按流名称跨连接分发
+	conns          []*streamConn
+	nextConnID     int
+	streamsPerConn int
 	handlers   map[string]StreamHandler
 	mu         sync.RWMutex
 	isRunning  bool
 	reconnect  bool
+	reconnectState reconnectState
+	flapState  flapState
+	// 订阅控制：未指定处理器的流使用默认处理器，已连接时通过 SUBSCRIBE/UNSUBSCRIBE 即时生效
+	defaultHandler  StreamHandler
+	nextRequestID   int64
+	pendingControls map[int64]pendingControl
 	ctx        context.Context
 	cancel     context.CancelFunc
 }
@@ -62,6 +73,20 @@ type KlineStreamData struct {
 	} `json:"data"`
 }
 
+// ToDecimal 将K线数据流转换为已解析的K线数据
+func (d *KlineStreamData) ToDecimal() (DecimalKline, error) {
+	k := d.Data.Kline
+	return Kline{
+		OpenTime:  k.StartTime,
+		Open:      k.Open,
+		High:      k.High,
+		Low:       k.Low,
+		Close:     k.Close,
+		Volume:    k.Volume,
+		CloseTime: k.EndTime,
+	}.ToDecimal()
+}
+
 // TickerStreamData 价格数据流
 type TickerStreamData struct {
 	Stream string `json:"stream"`
@@ -88,41 +113,101 @@ type TickerStreamData struct {
 		CloseTime             int64  `json:"C"`
 		FirstID               int64  `json:"F"`
 		LastID                int64  `json:"L"`
-		Count                 int64  `json:"c"`
+		Count                 int64  `json:"n"`
 	} `json:"data"`
 }
 
-// NewWebSocketClient 创建新的WebSocket客户端
-func NewWebSocketClient(baseURL string, log logger.Logger) (*WebSocketClient, error) {
+// NewWebSocketClient 创建新的WebSocket客户端，proxyURL 为空时直连
+func NewWebSocketClient(baseURL, proxyURL string, log logger.Logger) (*WebSocketClient, error) {
 	if baseURL == "" {
 		return nil, fmt.Errorf("base URL cannot be empty")
 	}
 
+	proxy, err := ParseProxyURL(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		log.Infof("Binance websocket using proxy %s", proxy.Redacted())
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &WebSocketClient{
 		logger:    log,
 		baseURL:   baseURL,
-		streams:   make([]string, 0),
+		dialer:    newDialer(proxy),
+		streamsPerConn: streamsPerConnection,
 		handlers:  make(map[string]StreamHandler),
+		pendingControls: make(map[int64]pendingControl),
 		isRunning: false,
 		reconnect: true,
+		reconnectState: reconnectState{backoff: DefaultReconnectBackoff()},
 		ctx:       ctx,
 		cancel:    cancel,
 	}, nil
 }
 
-// Subscribe 订阅数据流
+// Subscribe 订阅数据流，handler 为空时使用默认处理器。新流分配到连接池中未满的连接，
+// 该连接已连接时立即发送 SUBSCRIBE，否则在下次连接时生效；全部连接已满时新建连接
 func (ws *WebSocketClient) Subscribe(stream string, handler StreamHandler) {
 	ws.mu.Lock()
-	defer ws.mu.Unlock()
-
-	ws.streams = append(ws.streams, stream)
+	if handler == nil {
+		handler = ws.defaultHandler
+	}
 	ws.handlers[stream] = handler
-	ws.logger.Infof("Subscribed to stream: %s", stream)
+	if sc, _ := ws.findStreamConnLocked(stream); sc != nil {
+		ws.mu.Unlock()
+		return
+	}
+	sc, created := ws.assignStreamLocked(stream)
+	start := created && ws.isRunning
+	ws.mu.Unlock()
+
+	if created {
+		ws.logger.Infof("WebSocket connection %d added to pool for stream %s", sc.id, stream)
+	}
+	if start {
+		go ws.connectionLoop(sc)
+		return
+	}
+
+	sent, err := ws.sendSubscribe(sc, stream)
+	if err != nil {
+		// 写入失败说明连接已不可用，重连时会按订阅列表重新订阅
+		ws.logger.Warnf("Live subscribe to %s failed, will apply on reconnect: %v", stream, err)
+	}
+	if sent {
+		ws.logger.Infof("Subscribed to stream: %s", stream)
+	} else {
+		ws.logger.Infof("Subscribed to stream: %s (applies on next connect)", stream)
+	}
 }
 
-// Start 启动WebSocket连接
+// unsubscribe 取消订阅数据流，已连接时立即发送 UNSUBSCRIBE，连接无订阅后关闭
+func (ws *WebSocketClient) unsubscribe(stream string) {
+	ws.mu.Lock()
+	delete(ws.handlers, stream)
+	sc, i := ws.findStreamConnLocked(stream)
+	if sc == nil {
+		ws.mu.Unlock()
+		return
+	}
+	sc.streams = append(sc.streams[:i], sc.streams[i+1:]...)
+	if len(sc.streams) == 0 {
+		ws.retireStreamConnLocked(sc)
+		ws.mu.Unlock()
+		return
+	}
+	ws.mu.Unlock()
+
+	if _, err := ws.sendUnsubscribe(sc, stream); err != nil {
+		ws.logger.Warnf("Live unsubscribe from %s failed, will apply on reconnect: %v", stream, err)
+	}
+}
+
+// Start 启动WebSocket连接，连接池中的每个连接独立连接和重连。
+// 尚无订阅时不建立连接，首次订阅时再按需创建
 func (ws *WebSocketClient) Start() error {
 	ws.mu.Lock()
 	if ws.isRunning {
@@ -130,12 +215,16 @@ func (ws *WebSocketClient) Start() error {
 		return fmt.Errorf("websocket client is already running")
 	}
 	ws.isRunning = true
+	conns := make([]*streamConn, len(ws.conns))
+	copy(conns, ws.conns)
 	ws.mu.Unlock()
 
-	ws.logger.Info("Starting WebSocket client...")
+	ws.logger.Infof("Starting WebSocket client with %d connection(s)...", len(conns))
 
 	// 启动连接和重连逻辑
-	go ws.connectionLoop()
+	for _, sc := range conns {
+		go ws.connectionLoop(sc)
+	}
 
 	return nil
 }
@@ -154,102 +243,173 @@ func (ws *WebSocketClient) Stop() {
 	ws.reconnect = false
 	ws.cancel()
 
-	if ws.conn != nil {
-		ws.conn.Close()
+	for _, sc := range ws.conns {
+		if sc.conn != nil {
+			sc.conn.Close()
+		}
 	}
 }
 
-// connectionLoop 连接循环
-func (ws *WebSocketClient) connectionLoop() {
+// connectionLoop 单个连接的连接循环，连接被移出连接池时退出
+func (ws *WebSocketClient) connectionLoop(sc *streamConn) {
+	defer ws.releaseStreamConn(sc)
+
 	for ws.reconnect {
 		select {
-		case <-ws.ctx.Done():
+		case <-sc.ctx.Done():
 			return
 		default:
 		}
 
-		if err := ws.connect(); err != nil {
-			ws.logger.Errorf("Failed to connect: %v", err)
-			time.Sleep(5 * time.Second)
+		if err := ws.connect(sc); err != nil {
+			// 订阅已全部取消，连接不再需要
+			if errors.Is(err, errNoStreams) {
+				ws.mu.Lock()
+				ws.retireStreamConnLocked(sc)
+				ws.mu.Unlock()
+				return
+			}
+			// 配置错误重试也无法恢复，不计为重连失败，按最大退避时间重试以便订阅变化后恢复
+			if errors.Is(err, errStreamConfig) {
+				ws.logger.Errorf("Cannot connect (connection %d): %v", sc.id, err)
+				ws.sleep(sc, ws.maxReconnectDelay())
+				continue
+			}
+			ws.logger.Errorf("Failed to connect (connection %d): %v", sc.id, err)
+			delay, failures := ws.recordReconnectFailure(sc, err)
+			ws.logger.Infof("Reconnecting in %v (attempt %d)...", delay, failures+1)
+			metrics.RecordReconnect()
+			ws.sleep(sc, delay)
 			continue
 		}
 
-		// 处理消息
-		ws.messageLoop()
+		// 处理消息，连接保持稳定后重置连续失败计数和退避时间
+		stableAfter := ws.stableAfter()
+		stable := time.AfterFunc(stableAfter, func() { ws.recordStableConnection(sc) })
+		ws.mu.Lock()
+		reconnected := sc.dialed
+		sc.dialed = true
+		conn := sc.conn
+		ws.mu.Unlock()
+		flapStable := ws.recordConnected(sc, reconnected)
+		stopKeepalive := ws.startKeepalive(conn)
+		ws.messageLoop(sc)
+		stopKeepalive()
+		ws.clearPendingControls(sc)
+		if flapStable != nil {
+			flapStable.Stop()
+		}
+		if sc.ctx.Err() != nil {
+			stable.Stop()
+			return
+		}
+		var delay time.Duration
+		var failures int
+		if stable.Stop() && ws.reconnect {
+			delay, failures = ws.recordReconnectFailure(sc, fmt.Errorf("connection dropped within %v", stableAfter))
+		} else {
+			delay = ws.reconnectDelay(sc)
+		}
 
-		// 如果需要重连，等待一段时间
+		// 如果需要重连，按退避时间等待
 		if ws.reconnect {
-			ws.logger.Info("Reconnecting in 5 seconds...")
-			time.Sleep(5 * time.Second)
+			ws.logger.Infof("Reconnecting in %v (attempt %d)...", delay, failures+1)
+			metrics.RecordReconnect()
+			ws.sleep(sc, delay)
 		}
 	}
 }
 
-// connect 建立WebSocket连接
-func (ws *WebSocketClient) connect() error {
-	ws.mu.RLock()
-	streams := make([]string, len(ws.streams))
-	copy(streams, ws.streams)
-	ws.mu.RUnlock()
+// releaseStreamConn 连接循环退出后清除该连接的告警状态，其余连接均正常时回调恢复
+func (ws *WebSocketClient) releaseStreamConn(sc *streamConn) {
+	ws.mu.Lock()
+	failures := sc.reconnect.failures
+	reconnects := len(sc.flap.reconnects)
+	sc.reconnect = connReconnectState{}
+	sc.flap = connFlapState{}
+	running := ws.isRunning
+	ws.mu.Unlock()
 
-	if len(streams) == 0 {
-		return fmt.Errorf("no streams to subscribe")
+	// 客户端停止时不发送恢复通知
+	if !running {
+		return
 	}
+	ws.settleReconnectState(failures)
+	ws.settleFlapState(reconnects)
+}
+
+// sleep 等待重连退避时间，连接被移出连接池或客户端停止时提前返回
+func (ws *WebSocketClient) sleep(sc *streamConn, delay time.Duration) {
+	select {
+	case <-sc.ctx.Done():
+	case <-time.After(delay):
+	}
+}
+
+// connect 建立组合流连接，订阅该连接分配到的全部流
+func (ws *WebSocketClient) connect(sc *streamConn) error {
+	ws.mu.RLock()
+	streams := make([]string, len(sc.streams))
+	copy(streams, sc.streams)
+	ws.mu.RUnlock()
 
 	// 构建WebSocket URL
-	streamParam := strings.Join(streams, "/")
-	u, err := url.Parse(fmt.Sprintf("%s/%s", ws.baseURL, streamParam))
+	streamURL, err := combinedStreamURL(ws.baseURL, streams)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(streamURL)
 	if err != nil {
-		return fmt.Errorf("failed to parse URL: %w", err)
+		return fmt.Errorf("%w: failed to parse URL: %v", errStreamConfig, err)
 	}
 
 	// 建立连接
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	conn, _, err := ws.dialer.Dial(u.String(), nil)
 	if err != nil {
 		return fmt.Errorf("failed to dial: %w", err)
 	}
 
 	ws.mu.Lock()
-	ws.conn = conn
+	sc.conn = conn
 	ws.mu.Unlock()
 
-	ws.logger.Infof("WebSocket connected to: %s", u.String())
+	ws.logger.Infof("WebSocket connection %d connected to %s with %d streams", sc.id, u.Host, len(streams))
 	return nil
 }
 
-// messageLoop 消息处理循环
-func (ws *WebSocketClient) messageLoop() {
+// messageLoop 单个连接的消息处理循环
+func (ws *WebSocketClient) messageLoop(sc *streamConn) {
 	defer func() {
 		ws.mu.Lock()
-		if ws.conn != nil {
-			ws.conn.Close()
-			ws.conn = nil
+		if sc.conn != nil {
+			sc.conn.Close()
+			sc.conn = nil
 		}
 		ws.mu.Unlock()
 	}()
 
 	for {
 		select {
-		case <-ws.ctx.Done():
+		case <-sc.ctx.Done():
 			return
 		default:
 		}
 
 		ws.mu.RLock()
-		conn := ws.conn
+		conn := sc.conn
 		ws.mu.RUnlock()
 
 		if conn == nil {
 			return
 		}
 
-		// 设置读取超时
-		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		// 设置读取超时，收到 ping/pong 时也会顺延
+		conn.SetReadDeadline(time.Now().Add(pongWait))
 
 		// 读取消息
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			ws.logger.Errorf("Failed to read message: %v", err)
+			ws.logger.Errorf("Failed to read message (connection %d): %v", sc.id, err)
 			return
 		}
 
@@ -262,6 +422,11 @@ func (ws *WebSocketClient) messageLoop() {
 
 // handleMessage 处理接收到的消息
 func (ws *WebSocketClient) handleMessage(message []byte) error {
+	// 订阅控制消息的响应不属于任何数据流
+	if ws.handleControlResponse(message) {
+		return nil
+	}
+
 	// 解析基础消息结构
 	var baseMsg struct {
 		Stream string `json:"stream"`
@@ -278,6 +443,7 @@ func (ws *WebSocketClient) handleMessage(message []byte) error {
 
 	if !exists {
 		// 尝试匹配部分流名称
+		ws.mu.RLock()
 		for stream, h := range ws.handlers {
 			if strings.Contains(baseMsg.Stream, stream) {
 				handler = h
@@ -285,9 +451,10 @@ func (ws *WebSocketClient) handleMessage(message []byte) error {
 				break
 			}
 		}
+		ws.mu.RUnlock()
 	}
 
-	if !exists {
+	if !exists || handler == nil {
 		ws.logger.Debugf("No handler for stream: %s", baseMsg.Stream)
 		return nil
 	}
@@ -310,11 +477,20 @@ func (ws *WebSocketClient) handleMessage(message []byte) error {
 	return nil
 }
 
-// IsConnected 检查连接状态
+// IsConnected 检查连接状态，连接池中的连接全部已连接时为 true，没有订阅（连接池为空）时运行中即为 true
 func (ws *WebSocketClient) IsConnected() bool {
 	ws.mu.RLock()
 	defer ws.mu.RUnlock()
-	return ws.conn != nil && ws.isRunning
+
+	if !ws.isRunning {
+		return false
+	}
+	for _, sc := range ws.conns {
+		if sc.conn == nil {
+			return false
+		}
+	}
+	return true
 }
 
 // GetStreams 获取已订阅的流
@@ -322,8 +498,10 @@ func (ws *WebSocketClient) GetStreams() []string {
 	ws.mu.RLock()
 	defer ws.mu.RUnlock()
 
-	streams := make([]string, len(ws.streams))
-	copy(streams, ws.streams)
+	var streams []string
+	for _, sc := range ws.conns {
+		streams = append(streams, sc.streams...)
+	}
 	return streams
 }
 
@@ -331,9 +509,12 @@ func (ws *WebSocketClient) GetStreams() []string {
 func (ws *WebSocketClient) SetStreamHandler(handler StreamHandler) {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
-	// 为所有流设置同一个处理器
-	for _, stream := range ws.streams {
-		ws.handlers[stream] = handler
+	// 为所有流设置同一个处理器，之后订阅的流也使用该处理器
+	ws.defaultHandler = handler
+	for _, sc := range ws.conns {
+		for _, stream := range sc.streams {
+			ws.handlers[stream] = handler
+		}
 	}
 }
 
@@ -346,20 +527,7 @@ func (ws *WebSocketClient) SubscribeKline(symbol, interval string) error {
 
 // UnsubscribeKline 取消订阅K线数据
 func (ws *WebSocketClient) UnsubscribeKline(symbol, interval string) error {
-	stream := fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), interval)
-	ws.mu.Lock()
-	defer ws.mu.Unlock()
-	
-	// 从streams中移除
-	for i, s := range ws.streams {
-		if s == stream {
-			ws.streams = append(ws.streams[:i], ws.streams[i+1:]...)
-			break
-		}
-	}
-	
-	// 从handlers中移除
-	delete(ws.handlers, stream)
+	ws.unsubscribe(fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), interval))
 	return nil
 }
 
@@ -372,19 +540,6 @@ func (ws *WebSocketClient) SubscribeTicker(symbol string) error {
 
 // UnsubscribeTicker 取消订阅价格数据
 func (ws *WebSocketClient) UnsubscribeTicker(symbol string) error {
-	stream := fmt.Sprintf("%s@ticker", strings.ToLower(symbol))
-	ws.mu.Lock()
-	defer ws.mu.Unlock()
-	
-	// 从streams中移除
-	for i, s := range ws.streams {
-		if s == stream {
-			ws.streams = append(ws.streams[:i], ws.streams[i+1:]...)
-			break
-		}
-	}
-	
-	// 从handlers中移除
-	delete(ws.handlers, stream)
+	ws.unsubscribe(fmt.Sprintf("%s@ticker", strings.ToLower(symbol)))
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,355 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// fakeStreamServer 模拟组合流服务：连接建立后为每个订阅的流推送一条K线，并响应订阅控制消息
+type fakeStreamServer struct {
+	t        *testing.T
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu     sync.Mutex
+	dials  [][]string                  // 每次连接订阅的流
+	reject func(streams []string) bool // 返回 true 时拒绝该次连接
+}
+
+func newFakeStreamServer(t *testing.T) *fakeStreamServer {
+	t.Helper()
+
+	fs := &fakeStreamServer{t: t}
+	fs.server = httptest.NewServer(http.HandlerFunc(fs.handle))
+	t.Cleanup(fs.server.Close)
+	return fs
+}
+
+// url 组合流地址（ws 协议）
+func (fs *fakeStreamServer) url() string {
+	return "ws" + strings.TrimPrefix(fs.server.URL, "http") + "/stream"
+}
+
+func (fs *fakeStreamServer) handle(w http.ResponseWriter, r *http.Request) {
+	streams := strings.Split(r.URL.Query().Get("streams"), "/")
+	fs.mu.Lock()
+	reject := fs.reject
+	fs.mu.Unlock()
+	if reject != nil && reject(streams) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := fs.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		fs.t.Errorf("upgrade: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	fs.mu.Lock()
+	fs.dials = append(fs.dials, streams)
+	fs.mu.Unlock()
+
+	for _, stream := range streams {
+		if err := conn.WriteJSON(klineMessage(stream)); err != nil {
+			return
+		}
+	}
+
+	for {
+		var req controlRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		if err := conn.WriteJSON(map[string]interface{}{"result": nil, "id": req.ID}); err != nil {
+			return
+		}
+	}
+}
+
+// dialed 返回每次连接订阅的流
+func (fs *fakeStreamServer) dialed() [][]string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return append([][]string(nil), fs.dials...)
+}
+
+// klineMessage 构建流的已收盘K线消息
+func klineMessage(stream string) map[string]interface{} {
+	symbol := strings.ToUpper(strings.SplitN(stream, "@", 2)[0])
+	return map[string]interface{}{
+		"stream": stream,
+		"data": map[string]interface{}{
+			"e": "kline",
+			"s": symbol,
+			"k": map[string]interface{}{
+				"t": 0, "T": 899999, "s": symbol, "i": "15m",
+				"o": "1", "c": "1", "h": "1", "l": "1", "v": "1", "x": true,
+			},
+		},
+	}
+}
+
+// recordingHandler 按流记录收到的K线
+type recordingHandler struct {
+	t        *testing.T
+	mu       sync.Mutex
+	received map[string]int
+}
+
+func (h *recordingHandler) HandleKlineData(data *KlineStreamData) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// 消息必须路由回其所属交易对的流
+	if want := strings.ToLower(data.Data.Symbol) + "@kline_15m"; data.Stream != want {
+		h.t.Errorf("kline for %s delivered on stream %s", data.Data.Symbol, data.Stream)
+	}
+	h.received[data.Stream]++
+	return nil
+}
+
+func (h *recordingHandler) HandleTickerData(data *TickerStreamData) error { return nil }
+
+func (h *recordingHandler) GetName() string { return "recording" }
+
+func (h *recordingHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.received)
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newTestWebSocketClient(t *testing.T, fs *fakeStreamServer, streamsPerConn int) *WebSocketClient {
+	t.Helper()
+
+	ws, err := NewWebSocketClient(fs.url(), "", logger.NewLoggerWithLevel("error"))
+	if err != nil {
+		t.Fatalf("NewWebSocketClient: %v", err)
+	}
+	ws.streamsPerConn = streamsPerConn
+	t.Cleanup(ws.Stop)
+	return ws
+}
+
+func TestStreamPoolFanOut(t *testing.T) {
+	fs := newFakeStreamServer(t)
+	ws := newTestWebSocketClient(t, fs, 20)
+	handler := &recordingHandler{t: t, received: make(map[string]int)}
+	ws.SetStreamHandler(handler)
+
+	var streams []string
+	for i := 0; i < 50; i++ {
+		symbol := fmt.Sprintf("SYM%02dUSDT", i)
+		if err := ws.SubscribeKline(symbol, "15m"); err != nil {
+			t.Fatalf("SubscribeKline(%s): %v", symbol, err)
+		}
+		streams = append(streams, strings.ToLower(symbol)+"@kline_15m")
+	}
+
+	if got := ws.ConnectionCount(); got != 3 {
+		t.Fatalf("ConnectionCount = %d, want 3 for 50 streams at 20 per connection", got)
+	}
+
+	if err := ws.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	waitFor(t, "klines on all 50 streams", func() bool { return handler.count() == 50 })
+	waitFor(t, "all connections up", ws.IsConnected)
+
+	// 每个流只分配到一个连接，各连接不超过上限
+	dials := fs.dialed()
+	var sizes []int
+	seen := make(map[string]int)
+	for _, dial := range dials {
+		sizes = append(sizes, len(dial))
+		for _, stream := range dial {
+			seen[stream]++
+		}
+	}
+	sort.Ints(sizes)
+	if fmt.Sprint(sizes) != "[10 20 20]" {
+		t.Errorf("streams per connection = %v, want [10 20 20]", sizes)
+	}
+	for _, stream := range streams {
+		if seen[stream] != 1 {
+			t.Errorf("stream %s subscribed on %d connections, want 1", stream, seen[stream])
+		}
+	}
+
+	handler.mu.Lock()
+	for stream, n := range handler.received {
+		if n != 1 {
+			t.Errorf("stream %s received %d klines, want 1", stream, n)
+		}
+	}
+	handler.mu.Unlock()
+}
+
+func TestStreamPoolEmptyConnectionsRetired(t *testing.T) {
+	fs := newFakeStreamServer(t)
+	ws := newTestWebSocketClient(t, fs, 20)
+	ws.SetStreamHandler(&recordingHandler{t: t, received: make(map[string]int)})
+
+	// 没有订阅时启动不建立连接
+	if err := ws.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if dials := fs.dialed(); len(dials) != 0 {
+		t.Fatalf("dialed %v with no subscriptions", dials)
+	}
+	if !ws.IsConnected() {
+		t.Error("IsConnected = false with nothing to connect")
+	}
+
+	// 首次订阅时按需连接
+	if err := ws.SubscribeKline("BTCUSDT", "15m"); err != nil {
+		t.Fatalf("SubscribeKline: %v", err)
+	}
+	waitFor(t, "connection for first subscription", func() bool { return len(fs.dialed()) == 1 })
+	waitFor(t, "connection up", ws.IsConnected)
+
+	// 取消全部订阅后连接关闭且不再重连
+	if err := ws.UnsubscribeKline("BTCUSDT", "15m"); err != nil {
+		t.Fatalf("UnsubscribeKline: %v", err)
+	}
+	if got := ws.ConnectionCount(); got != 0 {
+		t.Fatalf("ConnectionCount = %d after unsubscribing everything, want 0", got)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if dials := fs.dialed(); len(dials) != 1 {
+		t.Fatalf("redialed after the last stream was removed: %v", dials)
+	}
+	if failures := ws.ReconnectFailures(); failures != 0 {
+		t.Errorf("ReconnectFailures = %d after retiring the connection, want 0", failures)
+	}
+}
+
+// reconnectEvent 记录的重连告警回调
+type reconnectEvent struct {
+	exhausted bool
+	failures  int
+}
+
+// reconnectRecorder 记录重连告警回调
+type reconnectRecorder struct {
+	mu     sync.Mutex
+	events []reconnectEvent
+}
+
+func (r *reconnectRecorder) handle(exhausted bool, failures int, lastErr error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, reconnectEvent{exhausted, failures})
+}
+
+func (r *reconnectRecorder) recorded() []reconnectEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]reconnectEvent(nil), r.events...)
+}
+
+// fastBackoff 测试使用的短退避时间
+func fastBackoff() ReconnectBackoff {
+	return ReconnectBackoff{Base: time.Millisecond, Max: 5 * time.Millisecond, StableAfter: time.Minute}
+}
+
+func TestReconnectFailuresPerConnection(t *testing.T) {
+	fs := newFakeStreamServer(t)
+	fs.reject = func(streams []string) bool { return streams[0] == "badusdt@kline_15m" }
+	ws := newTestWebSocketClient(t, fs, 1)
+	ws.SetStreamHandler(&recordingHandler{t: t, received: make(map[string]int)})
+	ws.SetReconnectBackoff(fastBackoff())
+	recorder := &reconnectRecorder{}
+	ws.SetReconnectHandler(3, recorder.handle)
+
+	// 空订阅启动不计为失败，也不告警
+	if err := ws.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if events := recorder.recorded(); len(events) != 0 {
+		t.Fatalf("reconnect alerts %v with an empty watchlist", events)
+	}
+
+	if err := ws.SubscribeKline("GOODUSDT", "15m"); err != nil {
+		t.Fatalf("SubscribeKline: %v", err)
+	}
+	if err := ws.SubscribeKline("BADUSDT", "15m"); err != nil {
+		t.Fatalf("SubscribeKline: %v", err)
+	}
+	waitFor(t, "reconnect alert", func() bool { return len(recorder.recorded()) > 0 })
+	waitFor(t, "several more failures", func() bool { return ws.ReconnectFailures() >= 6 })
+
+	// 只有失败的连接累计失败次数，告警只发送一次
+	ws.mu.RLock()
+	for _, sc := range ws.conns {
+		if sc.streams[0] == "goodusdt@kline_15m" && (sc.reconnect.failures != 0 || sc.reconnect.exhausted) {
+			t.Errorf("healthy connection has failure state %+v", sc.reconnect)
+		}
+	}
+	ws.mu.RUnlock()
+	if events := recorder.recorded(); len(events) != 1 || !events[0].exhausted || events[0].failures != 3 {
+		t.Fatalf("reconnect alerts = %v, want one exhausted alert at 3 failures", events)
+	}
+
+	// 失败的连接移出连接池后告警恢复
+	if err := ws.UnsubscribeKline("BADUSDT", "15m"); err != nil {
+		t.Fatalf("UnsubscribeKline: %v", err)
+	}
+	waitFor(t, "recovery callback", func() bool { return len(recorder.recorded()) == 2 })
+	if events := recorder.recorded(); events[1].exhausted {
+		t.Errorf("second reconnect callback = %+v, want recovery", events[1])
+	}
+	if failures := ws.ReconnectFailures(); failures != 0 {
+		t.Errorf("ReconnectFailures = %d after retiring the failing connection, want 0", failures)
+	}
+}
+
+func TestStreamConfigErrorNotCountedAsFailure(t *testing.T) {
+	fs := newFakeStreamServer(t)
+	ws := newTestWebSocketClient(t, fs, 20)
+	ws.baseURL = "ws://bad%zzhost/stream"
+	ws.SetStreamHandler(&recordingHandler{t: t, received: make(map[string]int)})
+	ws.SetReconnectBackoff(fastBackoff())
+	recorder := &reconnectRecorder{}
+	ws.SetReconnectHandler(1, recorder.handle)
+
+	if err := ws.SubscribeKline("BTCUSDT", "15m"); err != nil {
+		t.Fatalf("SubscribeKline: %v", err)
+	}
+	if err := ws.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if failures := ws.ReconnectFailures(); failures != 0 {
+		t.Errorf("ReconnectFailures = %d for an invalid stream URL, want 0", failures)
+	}
+	if events := recorder.recorded(); len(events) != 0 {
+		t.Errorf("reconnect alerts %v for an invalid stream URL", events)
+	}
+}
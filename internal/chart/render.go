@@ -0,0 +1,249 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// 图表尺寸
+const (
+	defaultWidth  = 1200
+	defaultHeight = 700
+	padding       = 24
+)
+
+// 图表配色
+var (
+	colorBackground = color.RGBA{R: 19, G: 23, B: 34, A: 255}
+	colorGrid       = color.RGBA{R: 42, G: 46, B: 57, A: 255}
+	colorUp         = color.RGBA{R: 38, G: 166, B: 154, A: 255}
+	colorDown       = color.RGBA{R: 239, G: 83, B: 80, A: 255}
+	colorEMA12      = color.RGBA{R: 230, G: 230, B: 230, A: 255}
+	colorMidTunnel  = color.RGBA{R: 76, G: 175, B: 80, A: 255}
+	colorLongTunnel = color.RGBA{R: 255, G: 152, B: 0, A: 255}
+)
+
+// 价位线配色
+var (
+	ColorEntry      = color.RGBA{R: 41, G: 98, B: 255, A: 255}
+	ColorStopLoss   = color.RGBA{R: 239, G: 83, B: 80, A: 255}
+	ColorTakeProfit = color.RGBA{R: 38, G: 166, B: 154, A: 255}
+)
+
+// Level 图表上标注的水平价位线
+type Level struct {
+	Price decimal.Decimal
+	Color color.RGBA
+}
+
+// RenderTunnel 将K线与隧道指标渲染为PNG图片，tunnel 需与 klines 一一对应（未计算的位置为零值）
+func RenderTunnel(klines []strategy.KlineData, tunnel []strategy.TunnelData, levels []Level) ([]byte, error) {
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no kline data to render")
+	}
+	if len(tunnel) != 0 && len(tunnel) != len(klines) {
+		return nil, fmt.Errorf("tunnel length %d does not match kline length %d", len(tunnel), len(klines))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, defaultWidth, defaultHeight))
+	fillRect(img, 0, 0, defaultWidth, defaultHeight, colorBackground)
+
+	low, high := priceRange(klines, tunnel, levels)
+	scale := newScale(low, high, len(klines))
+
+	// 网格线
+	for i := 1; i < 5; i++ {
+		y := padding + (defaultHeight-2*padding)*i/5
+		drawLine(img, padding, y, defaultWidth-padding, y, colorGrid)
+	}
+
+	// 隧道与动能线
+	if len(tunnel) != 0 {
+		drawSeries(img, scale, tunnel, func(t strategy.TunnelData) decimal.Decimal { return t.LongTunnelUpper }, colorLongTunnel)
+		drawSeries(img, scale, tunnel, func(t strategy.TunnelData) decimal.Decimal { return t.LongTunnelLower }, colorLongTunnel)
+		drawSeries(img, scale, tunnel, func(t strategy.TunnelData) decimal.Decimal { return t.MidTunnelUpper }, colorMidTunnel)
+		drawSeries(img, scale, tunnel, func(t strategy.TunnelData) decimal.Decimal { return t.MidTunnelLower }, colorMidTunnel)
+		drawSeries(img, scale, tunnel, func(t strategy.TunnelData) decimal.Decimal { return t.EMA12 }, colorEMA12)
+	}
+
+	// K线
+	bodyWidth := scale.step * 2 / 3
+	if bodyWidth < 1 {
+		bodyWidth = 1
+	}
+	for i, k := range klines {
+		c := colorUp
+		if k.Close.LessThan(k.Open) {
+			c = colorDown
+		}
+		x := scale.x(i)
+		drawLine(img, x, scale.y(k.High), x, scale.y(k.Low), c)
+
+		top, bottom := scale.y(k.Open), scale.y(k.Close)
+		if top > bottom {
+			top, bottom = bottom, top
+		}
+		fillRect(img, x-bodyWidth/2, top, x-bodyWidth/2+bodyWidth, bottom+1, c)
+	}
+
+	// 入场、止损、止盈价位
+	for _, level := range levels {
+		if level.Price.IsZero() {
+			continue
+		}
+		drawDashedLine(img, padding, defaultWidth-padding, scale.y(level.Price), level.Color)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scale 价格与像素坐标的换算
+type scale struct {
+	low, high float64
+	count     int
+	step      int
+}
+
+// newScale 创建坐标换算
+func newScale(low, high float64, count int) *scale {
+	step := (defaultWidth - 2*padding) / count
+	if step < 1 {
+		step = 1
+	}
+	return &scale{low: low, high: high, count: count, step: step}
+}
+
+// x 第 i 根K线的中心横坐标
+func (s *scale) x(i int) int {
+	return padding + i*s.step + s.step/2
+}
+
+// y 价格对应的纵坐标
+func (s *scale) y(price decimal.Decimal) int {
+	p := price.InexactFloat64()
+	plotHeight := float64(defaultHeight - 2*padding)
+	return padding + int((s.high-p)/(s.high-s.low)*plotHeight)
+}
+
+// priceRange 计算图表需要覆盖的价格区间（含少量留白）
+func priceRange(klines []strategy.KlineData, tunnel []strategy.TunnelData, levels []Level) (float64, float64) {
+	low, high := klines[0].Low.InexactFloat64(), klines[0].High.InexactFloat64()
+	include := func(price decimal.Decimal) {
+		if price.IsZero() {
+			return
+		}
+		p := price.InexactFloat64()
+		if p < low {
+			low = p
+		}
+		if p > high {
+			high = p
+		}
+	}
+
+	for _, k := range klines {
+		include(k.Low)
+		include(k.High)
+	}
+	for _, t := range tunnel {
+		include(t.EMA12)
+		include(t.MidTunnelUpper)
+		include(t.MidTunnelLower)
+		include(t.LongTunnelUpper)
+		include(t.LongTunnelLower)
+	}
+	for _, level := range levels {
+		include(level.Price)
+	}
+
+	margin := (high - low) * 0.05
+	if margin == 0 {
+		margin = high * 0.01
+	}
+	return low - margin, high + margin
+}
+
+// drawSeries 绘制指标折线，跳过尚未计算的零值
+func drawSeries(img *image.RGBA, s *scale, tunnel []strategy.TunnelData, value func(strategy.TunnelData) decimal.Decimal, c color.RGBA) {
+	prevX, prevY, hasPrev := 0, 0, false
+	for i, t := range tunnel {
+		v := value(t)
+		if v.IsZero() {
+			hasPrev = false
+			continue
+		}
+		x, y := s.x(i), s.y(v)
+		if hasPrev {
+			drawLine(img, prevX, prevY, x, y, c)
+		}
+		prevX, prevY, hasPrev = x, y, true
+	}
+}
+
+// drawDashedLine 绘制水平虚线
+func drawDashedLine(img *image.RGBA, x0, x1, y int, c color.RGBA) {
+	for x := x0; x < x1; x += 12 {
+		end := x + 7
+		if end > x1 {
+			end = x1
+		}
+		drawLine(img, x, y, end, y, c)
+	}
+}
+
+// drawLine 使用 Bresenham 算法绘制线段
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// fillRect 填充矩形区域 [x0,x1) × [y0,y1)
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// abs 整数绝对值
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -11,11 +12,16 @@ import (
 
 // Config 应用程序配置
 type Config struct {
+	Mode     string         `json:"mode"` // 运行模式：analysis / paper / live
 	Telegram TelegramConfig `json:"telegram"`
 	Binance  BinanceConfig  `json:"binance"`
 	Database DatabaseConfig `json:"database"`
 	Trading  TradingConfig  `json:"trading"`
 	Logging  LoggingConfig  `json:"logging"`
+
+	Notification NotificationConfig `json:"notification"`
+
+	MetricsPort int `json:"metrics_port"` // Prometheus指标服务端口（/metrics），0表示不启用
 }
 
 // TelegramConfig Telegram机器人配置
@@ -25,6 +31,8 @@ type TelegramConfig struct {
 	AdminChatID int64    `json:"admin_chat_id"` // 管理员聊天ID
 	WebhookURL  string   `json:"webhook_url"`   // Webhook URL（可选）
 	Timeout     int      `json:"timeout"`       // 请求超时时间（秒）
+
+	HeartbeatMinutes int `json:"heartbeat_minutes"` // 心跳通知间隔（分钟），间隔内已有其他通知时跳过，0表示关闭
 }
 
 // BinanceConfig 币安API配置
@@ -37,6 +45,21 @@ type BinanceConfig struct {
 	Timeout     int    `json:"timeout"`      // 请求超时时间（秒）
 	RateLimit   int    `json:"rate_limit"`   // 请求频率限制（每分钟）
 	RecvWindow  int    `json:"recv_window"`  // 接收窗口时间（毫秒）
+	ProxyURL    string `json:"proxy_url"`    // REST和WebSocket代理地址（可选），支持 http、https、socks5
+
+	MaintenanceBackoff    int `json:"maintenance_backoff"`     // 检测到交易所维护后暂停请求的初始时间（秒），之后按指数退避
+	MaxMaintenanceBackoff int `json:"max_maintenance_backoff"` // 维护退避的最长时间（秒）
+
+	MaxReconnectFailures int  `json:"max_reconnect_failures"` // WebSocket连续重连失败多少次后发送严重告警
+	ReconnectMaintenance bool `json:"reconnect_maintenance"`  // 达到重连失败上限时进入维护模式暂停开仓，连接恢复后自动继续
+
+	FlapReconnects    int `json:"flap_reconnects"`     // 窗口内WebSocket重连达到多少次视为连接抖动，暂停开仓（保留平仓）
+	FlapWindowMinutes int `json:"flap_window_minutes"` // 连接抖动检测窗口（分钟）
+	FlapStableMinutes int `json:"flap_stable_minutes"` // 连接持续稳定多久（分钟）后解除抖动暂停
+
+	OrderRetries int `json:"order_retries"` // 下单遇到网络错误、5xx或后端超时时以同一客户端订单号重试的次数，0表示不重试
+
+	UserDataStream bool `json:"user_data_stream"` // 实盘模式下订阅用户数据流实时更新订单和持仓，断开期间回退到轮询
 }
 
 // DatabaseConfig 数据库配置
@@ -45,21 +68,119 @@ type DatabaseConfig struct {
 	MaxOpenConns    int    `json:"max_open_conns"`    // 最大打开连接数
 	MaxIdleConns    int    `json:"max_idle_conns"`    // 最大空闲连接数
 	ConnMaxLifetime int    `json:"conn_max_lifetime"` // 连接最大生存时间（秒）
-	BackupInterval  int    `json:"backup_interval"`   // 备份间隔（小时）
+	BackupInterval  int    `json:"backup_interval"`   // 备份间隔（小时），0表示不备份
 	BackupPath      string `json:"backup_path"`       // 备份路径
+	BackupRetention int    `json:"backup_retention"`  // 备份保留天数，0表示不清理
+	JournalPath     string `json:"journal_path"`      // 交易日志图表存储路径
+	BacktestPath    string `json:"backtest_path"`     // 回测检查点存储路径
 }
 
 // TradingConfig 交易配置
 type TradingConfig struct {
 	DefaultRiskPercent   float64 `json:"default_risk_percent"`   // 默认风险百分比
 	MaxPositions         int     `json:"max_positions"`          // 最大持仓数量
-	MinOrderValue        float64 `json:"min_order_value"`        // 最小订单价值（USDT）
-	MaxOrderValue        float64 `json:"max_order_value"`        // 最大订单价值（USDT）
+	MinOrderValue        float64 `json:"min_order_value"`        // 最小订单价值（按保证金资产计价）
+	MaxOrderValue        float64 `json:"max_order_value"`        // 最大订单价值（按保证金资产计价）
 	DefaultLeverage      int     `json:"default_leverage"`       // 默认杠杆倍数
+	DefaultMarginType    string  `json:"default_margin_type"`    // 默认保证金模式：ISOLATED 或 CROSSED，为空时沿用交易所当前设置
 	SlippageTolerance    float64 `json:"slippage_tolerance"`     // 滑点容忍度
 	OrderTimeout         int     `json:"order_timeout"`          // 订单超时时间（秒）
 	PriceCheckInterval   int     `json:"price_check_interval"`   // 价格检查间隔（秒）
 	EmergencyStopEnabled bool    `json:"emergency_stop_enabled"` // 紧急停止开关
+
+	MaxOpenOrdersPerSymbol int  `json:"max_open_orders_per_symbol"` // 单个交易对最大挂单数（含开仓/止损/止盈），只在开仓时检查，0表示不限制
+	CancelOldestOnLimit    bool `json:"cancel_oldest_on_limit"`     // 超过挂单上限时撤销最早的非保护挂单，否则拒绝开仓
+
+	RiskPresets map[string]RiskPreset `json:"risk_presets"` // 风险预设（名称 -> 参数组合）
+	MarginTypes map[string]string     `json:"margin_types"` // 按交易对设置保证金模式（交易对 -> ISOLATED/CROSSED），首次交易该交易对时应用
+
+	DeadManSwitchEnabled bool `json:"dead_man_switch_enabled"` // 连接长时间完全中断时自动平仓
+	DeadManSwitchMinutes int  `json:"dead_man_switch_minutes"` // REST与WebSocket均不可用多久后触发（分钟）
+
+	MaxDailyLossPercent float64 `json:"max_daily_loss_percent"` // 账户权益较当日（UTC）开盘下跌超过该百分比时平掉所有持仓并暂停开仓至次日，0表示不限制
+	MaxDrawdownPercent  float64 `json:"max_drawdown_percent"`   // 账户权益较当日（UTC）最高点回撤超过该百分比时同样熔断，0表示不限制
+
+	SizingMode       string `json:"sizing_mode"`        // 仓位计算模式：compounding 或 fixed_base
+	EquityResetHours int    `json:"equity_reset_hours"` // fixed_base 模式下权益基数的重置周期（小时，按UTC对齐）
+	RiskModel        string `json:"risk_model"`         // 风险比例的含义：position_value（仓位价值占比）或 stop_distance（止损亏损占比）
+
+	ProtectiveOrderRetries  int  `json:"protective_order_retries"`   // 止损止盈下单失败后的重试次数（指数退避）
+	CloseUnprotected        bool `json:"close_unprotected"`          // 止损最终下单失败时自动平仓
+	NudgeRoundedLevels      bool `json:"nudge_rounded_levels"`       // 止损止盈按最小变动单位取整后越过开仓价/标记价时，向安全方向移动一个单位
+	EntryFillTimeoutSeconds int  `json:"entry_fill_timeout_seconds"` // 等待开仓单成交的最长时间（秒），超时撤销未成交部分并告警，已成交部分照常设置止损止盈
+
+	CandleCloseDelayMs int  `json:"candle_close_delay_ms"` // 收到收盘K线后等待多久再用于信号（毫秒），0表示立即处理
+	VerifyCandleClose  bool `json:"verify_candle_close"`   // 等待后用REST最新K线核对收盘数据
+
+	MinRiskReward    float64 `json:"min_risk_reward"`    // 计入价格取整、滑点和手续费后的最低风险收益比，0表示不检查
+	EstimatedFeeRate float64 `json:"estimated_fee_rate"` // 估算单边手续费率（如0.0004表示0.04%），无法获取交易所实际费率时使用
+
+	JournalEnabled bool `json:"journal_enabled"` // 开仓时渲染图表并记入交易日志
+	JournalCandles int  `json:"journal_candles"` // 日志图表包含的15M K线数量
+
+	OrderPollInterval       int  `json:"order_poll_interval"`        // 订单状态轮询基础间隔（秒），用于仅有止损止盈挂单的交易对
+	ActiveOrderPollInterval int  `json:"active_order_poll_interval"` // 存在未成交开仓单的交易对的轮询间隔（秒）
+	AdaptiveOrderPolling    bool `json:"adaptive_order_polling"`     // 按交易对活跃度调整轮询频率，关闭时统一使用基础间隔
+	FillDedupMinutes        int  `json:"fill_dedup_minutes"`         // 已终结订单的成交记录保留时长（分钟），期间重复上报的成交不会再次处理
+
+	MinTrendCandles int     `json:"min_trend_candles"` // 4H趋势形成后至少保持多少根K线才允许开仓，0表示不限制
+	ADXThreshold    float64 `json:"adx_threshold"`     // 4H ADX低于此值视为震荡行情不开仓，0表示不过滤
+
+	SignalCooldownMinutes int     `json:"signal_cooldown_minutes"` // 同一交易对同方向信号的冷却时间（分钟），期间的重复信号不再分发，0表示不去重
+	SignalMinMovePercent  float64 `json:"signal_min_move_percent"` // 冷却期内价格相对上次信号变动达到此百分比时仍分发新信号，0表示冷却期内一律抑制
+
+	EMA12BufferPercent float64 `json:"ema12_buffer_percent"` // 入场和EMA12移动止盈要求收盘价越过EMA12的百分比，0表示单根K线穿越即触发
+	HistoryMargin      int     `json:"history_margin"`       // K线缓存在最长EMA周期之外额外保留的根数，缓存和回填数量均按此推算
+	EMASource          string  `json:"ema_source"`           // EMA价格来源：close / hl2 / hlc3 / ohlc4，复合价格会同时改变EMA12和隧道位置
+	EMA12Exit          bool    `json:"ema12_exit"`           // 15M收盘价反向越过EMA12时撤销止损止盈挂单并市价平仓（EMA12移动止盈）
+
+	MaxATRPercent         float64 `json:"max_atr_percent"`          // 15M ATR占价格的百分比超过该值时跳过开仓，0表示不检查
+	MaxCandleRangePercent float64 `json:"max_candle_range_percent"` // 信号K线振幅占价格的百分比超过该值时跳过开仓，0表示不检查
+
+	FundingCloseEnabled  bool    `json:"funding_close_enabled"`  // 资金费率不利时在结算前自动平仓
+	FundingCloseMinutes  int     `json:"funding_close_minutes"`  // 结算前多少分钟平仓
+	FundingRateThreshold float64 `json:"funding_rate_threshold"` // 持仓方需支付的资金费率超过该值才平仓（如0.0001表示0.01%）
+	FundingReopen        bool    `json:"funding_reopen"`         // 结算后若信号仍然成立则按原数量和止损止盈重新开仓
+
+	SymbolStatusCheckMinutes int `json:"symbol_status_check_minutes"` // 暂停交易（PENDING_TRADING、BREAK等）的交易对状态复查间隔（分钟）
+	TickerStatsMaxAgeSeconds int `json:"ticker_stats_max_age_seconds"` // 24h行情统计超过该时长未更新即视为过期，不再展示（秒）
+
+	CloseOnSymbolHalt bool `json:"close_on_symbol_halt"` // 持仓交易对被下架或暂停交易时尝试市价平仓，无论是否成功都会停止跟踪
+
+	MaxWatchedSymbolsPerUser int `json:"max_watched_symbols_per_user"` // 每个用户最多监控的交易对数
+	MaxWatchedSymbols        int `json:"max_watched_symbols"`          // 所有用户合计最多监控的不同交易对数（限制行情订阅和接口权重消耗）
+
+	DisabledSymbols []string `json:"disabled_symbols"` // 启动时只接收信号、不自动交易的交易对，运行中可用 /pause、/trade 切换
+
+	ManualConfirm         bool `json:"manual_confirm"`          // 开仓信号需在Telegram中点击确认后才执行，平仓信号不受影响
+	ConfirmTimeoutSeconds int  `json:"confirm_timeout_seconds"` // 开仓信号等待确认的时长（秒），超时未确认自动作废
+}
+
+// 运行模式
+const (
+	ModeAnalysis = "analysis" // 仅生成和推送信号，从不下单
+	ModePaper    = "paper"    // 完整执行交易流程，但订单只在本地模拟成交
+	ModeLive     = "live"     // 实盘下单
+)
+
+// 仓位计算模式
+const (
+	SizingModeCompounding = "compounding" // 按实时可用余额计算，风险随账户复利变化
+	SizingModeFixedBase   = "fixed_base"  // 按周期内固定的权益基数计算，周期内仓位稳定
+)
+
+// 风险模型
+const (
+	RiskModelPositionValue = "position_value" // 仓位价值 = 基数 × 风险比例，止损宽度不影响仓位
+	RiskModelStopDistance  = "stop_distance"  // 触发止损的亏损 = 基数 × 风险比例，止损越宽仓位越小
+)
+
+// RiskPreset 风险预设，将多个风险参数打包为一个可选方案
+type RiskPreset struct {
+	RiskPercent     float64 `json:"risk_percent"`     // 风险百分比
+	Leverage        int     `json:"leverage"`         // 杠杆倍数
+	MinConfidence   float64 `json:"min_confidence"`   // 最低信号置信度（0-1）
+	CooldownMinutes int     `json:"cooldown_minutes"` // 同一交易对开仓冷却时间（分钟）
 }
 
 // LoggingConfig 日志配置
@@ -71,6 +192,57 @@ type LoggingConfig struct {
 	MaxAge     int    `json:"max_age"`     // 最大保存天数
 	Compress   bool   `json:"compress"`    // 是否压缩
 	Console    bool   `json:"console"`     // 是否输出到控制台
+	Format     string `json:"format"`      // 日志格式：text 或 json
+}
+
+// 通知渠道
+const (
+	ChannelTelegram = "telegram" // Telegram机器人
+	ChannelWebhook  = "webhook"  // Discord/Slack等Webhook
+	ChannelEmail    = "email"    // SMTP邮件
+)
+
+// 通知Webhook格式
+const (
+	WebhookDiscord = "discord" // Discord: {"content": "..."}
+	WebhookSlack   = "slack"   // Slack: {"text": "..."}
+	WebhookGeneric = "generic" // 通用JSON：标题、正文、类型、优先级和时间
+)
+
+// NotificationConfig 通知渠道配置
+type NotificationConfig struct {
+	Channels []string        `json:"channels"` // 启用的通知渠道：telegram、webhook、email，未配置时仅使用 telegram
+	Webhooks []WebhookConfig `json:"webhooks"` // webhook 渠道的目标地址
+	Email    EmailConfig     `json:"email"`    // email 渠道的SMTP设置
+
+	DedupWindowSeconds int     `json:"dedup_window_seconds"` // 相同通知（类型、标题、交易对）的去重窗口（秒），窗口结束时合并发送一条汇总，0表示不去重
+	ChatRateLimit      float64 `json:"chat_rate_limit"`      // 每个Telegram聊天每秒最多发送的通知数，0表示不限制
+}
+
+// WebhookConfig 通知Webhook配置
+type WebhookConfig struct {
+	URL    string `json:"url"`
+	Format string `json:"format"` // 消息格式：discord、slack 或 generic，默认 generic
+}
+
+// EmailConfig 通知邮件配置
+type EmailConfig struct {
+	Host     string   `json:"host"`     // SMTP服务器
+	Port     int      `json:"port"`     // SMTP端口，默认587
+	Username string   `json:"username"` // SMTP用户名，为空时不认证
+	Password string   `json:"password"`
+	From     string   `json:"from"` // 发件人地址
+	To       []string `json:"to"`   // 收件人地址
+}
+
+// ChannelEnabled 通知渠道是否启用
+func (c NotificationConfig) ChannelEnabled(channel string) bool {
+	for _, enabled := range c.Channels {
+		if enabled == channel {
+			return true
+		}
+	}
+	return false
 }
 
 // Load 从文件加载配置
@@ -96,6 +268,173 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// 未配置运行模式时按实盘运行，与旧版本行为一致
+	if config.Mode == "" {
+		config.Mode = ModeLive
+	}
+
+	// 未配置维护退避时使用默认值
+	if config.Binance.MaintenanceBackoff == 0 {
+		config.Binance.MaintenanceBackoff = 30
+	}
+	if config.Binance.RateLimit == 0 {
+		config.Binance.RateLimit = 1200
+	}
+	if config.Binance.RecvWindow == 0 {
+		config.Binance.RecvWindow = 5000
+	}
+	if config.Binance.MaxMaintenanceBackoff == 0 {
+		config.Binance.MaxMaintenanceBackoff = 600
+	}
+	if config.Binance.MaxReconnectFailures == 0 {
+		config.Binance.MaxReconnectFailures = 10
+	}
+	if config.Binance.FlapReconnects == 0 {
+		config.Binance.FlapReconnects = 5
+	}
+	if config.Binance.FlapWindowMinutes == 0 {
+		config.Binance.FlapWindowMinutes = 10
+	}
+	if config.Binance.FlapStableMinutes == 0 {
+		config.Binance.FlapStableMinutes = 15
+	}
+
+	// 未配置资金费平仓提前时间时使用默认值
+	if config.Trading.FundingCloseMinutes == 0 {
+		config.Trading.FundingCloseMinutes = 5
+	}
+
+	// 未配置K线缓存余量时使用默认值
+	if config.Trading.HistoryMargin == 0 {
+		config.Trading.HistoryMargin = 160
+	}
+
+	// 未配置交易对状态复查间隔时使用默认值
+	if config.Trading.SymbolStatusCheckMinutes == 0 {
+		config.Trading.SymbolStatusCheckMinutes = 10
+	}
+
+	// 未配置监控数量上限时使用默认值
+	if config.Trading.MaxWatchedSymbolsPerUser == 0 {
+		config.Trading.MaxWatchedSymbolsPerUser = 10
+	}
+	if config.Trading.MaxWatchedSymbols == 0 {
+		config.Trading.MaxWatchedSymbols = 50
+	}
+
+	// 未配置24h行情统计有效期时使用默认值
+	if config.Trading.TickerStatsMaxAgeSeconds == 0 {
+		config.Trading.TickerStatsMaxAgeSeconds = 300
+	}
+
+	// 未配置仓位计算模式时按实时余额计算
+	if config.Trading.SizingMode == "" {
+		config.Trading.SizingMode = SizingModeCompounding
+	}
+	// 未配置风险模型时按仓位价值计算，与旧版本行为一致
+	if config.Trading.RiskModel == "" {
+		config.Trading.RiskModel = RiskModelPositionValue
+	}
+	if config.Trading.EquityResetHours == 0 {
+		config.Trading.EquityResetHours = 24
+	}
+
+	// 未配置备份路径时使用默认值
+	if config.Database.BackupPath == "" {
+		config.Database.BackupPath = "./data/backups"
+	}
+
+	// 未配置交易日志参数时使用默认值
+	if config.Database.JournalPath == "" {
+		config.Database.JournalPath = "./data/journal"
+	}
+	if config.Trading.JournalCandles == 0 {
+		config.Trading.JournalCandles = 120
+	}
+
+	// 未配置回测检查点路径时使用默认值
+	if config.Database.BacktestPath == "" {
+		config.Database.BacktestPath = "./data/backtest"
+	}
+
+	// 未配置订单轮询间隔时使用默认值
+	if config.Trading.OrderPollInterval == 0 {
+		config.Trading.OrderPollInterval = 30
+	}
+	if config.Trading.ActiveOrderPollInterval == 0 {
+		config.Trading.ActiveOrderPollInterval = 5
+	}
+	if config.Trading.FillDedupMinutes == 0 {
+		config.Trading.FillDedupMinutes = 60
+	}
+	if config.Trading.EntryFillTimeoutSeconds == 0 {
+		config.Trading.EntryFillTimeoutSeconds = 30
+	}
+	if config.Trading.ConfirmTimeoutSeconds == 0 {
+		config.Trading.ConfirmTimeoutSeconds = 120
+	}
+	if config.Trading.DefaultLeverage == 0 {
+		config.Trading.DefaultLeverage = 1
+	}
+
+	// 未配置风险预设时使用内置预设，预设名称统一为小写以便按名称不区分大小写查找
+	if len(config.Trading.RiskPresets) == 0 {
+		config.Trading.RiskPresets = defaultRiskPresets()
+	}
+	presets := make(map[string]RiskPreset, len(config.Trading.RiskPresets))
+	for name, preset := range config.Trading.RiskPresets {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, exists := presets[key]; exists {
+			return nil, fmt.Errorf("duplicate risk preset %q: preset names are case-insensitive", name)
+		}
+		presets[key] = preset
+	}
+	config.Trading.RiskPresets = presets
+
+	// EMA价格来源统一为小写，未配置时使用收盘价
+	config.Trading.EMASource = strings.ToLower(config.Trading.EMASource)
+	if config.Trading.EMASource == "" {
+		config.Trading.EMASource = "close"
+	}
+
+	// 日志级别和格式统一为小写，未配置时使用 info 级别的文本格式
+	config.Logging.Level = strings.ToLower(config.Logging.Level)
+	if config.Logging.Level == "" {
+		config.Logging.Level = "info"
+	}
+	config.Logging.Format = strings.ToLower(config.Logging.Format)
+	if config.Logging.Format == "" {
+		config.Logging.Format = "text"
+	}
+
+	// 通知渠道统一为小写，未配置时仅发送Telegram通知，与旧版本行为一致
+	for i, channel := range config.Notification.Channels {
+		config.Notification.Channels[i] = strings.ToLower(channel)
+	}
+	if len(config.Notification.Channels) == 0 {
+		config.Notification.Channels = []string{ChannelTelegram}
+	}
+	for i := range config.Notification.Webhooks {
+		webhook := &config.Notification.Webhooks[i]
+		webhook.Format = strings.ToLower(webhook.Format)
+		if webhook.Format == "" {
+			webhook.Format = WebhookGeneric
+		}
+	}
+	if config.Notification.Email.Port == 0 {
+		config.Notification.Email.Port = 587
+	}
+
+	// 保证金模式统一为大写，交易对名称统一为大写
+	config.Trading.DefaultMarginType = strings.ToUpper(config.Trading.DefaultMarginType)
+	if len(config.Trading.MarginTypes) > 0 {
+		marginTypes := make(map[string]string, len(config.Trading.MarginTypes))
+		for symbol, marginType := range config.Trading.MarginTypes {
+			marginTypes[strings.ToUpper(symbol)] = strings.ToUpper(marginType)
+		}
+		config.Trading.MarginTypes = marginTypes
+	}
+
 	// 从环境变量覆盖敏感配置
 	if err := loadFromEnv(&config); err != nil {
 		return nil, fmt.Errorf("failed to load environment variables: %w", err)
@@ -134,12 +473,15 @@ func Save(config *Config, configPath string) error {
 // getDefaultConfig 获取默认配置
 func getDefaultConfig() *Config {
 	return &Config{
+		Mode: ModeLive,
 		Telegram: TelegramConfig{
 			BotToken:    "", // 需要从环境变量设置
 			ChatIDs:     []int64{},
 			AdminChatID: 0,
 			WebhookURL:  "",
 			Timeout:     30,
+
+			HeartbeatMinutes: 0,
 		},
 		Binance: BinanceConfig{
 			APIKey:     "", // 需要从环境变量设置
@@ -150,6 +492,18 @@ func getDefaultConfig() *Config {
 			Timeout:    10,
 			RateLimit:  1200,
 			RecvWindow: 5000,
+
+			MaintenanceBackoff:    30,
+			MaxMaintenanceBackoff: 600,
+			MaxReconnectFailures:  10,
+
+			FlapReconnects:    5,
+			FlapWindowMinutes: 10,
+			FlapStableMinutes: 15,
+
+			OrderRetries: 3,
+
+			UserDataStream: true,
 		},
 		Database: DatabaseConfig{
 			Path:            "./data/trading.db",
@@ -158,6 +512,9 @@ func getDefaultConfig() *Config {
 			ConnMaxLifetime: 3600,
 			BackupInterval:  24,
 			BackupPath:      "./data/backups",
+			BackupRetention: 7,
+			JournalPath:     "./data/journal",
+			BacktestPath:    "./data/backtest",
 		},
 		Trading: TradingConfig{
 			DefaultRiskPercent:   2.0,
@@ -169,6 +526,69 @@ func getDefaultConfig() *Config {
 			OrderTimeout:         60,
 			PriceCheckInterval:   5,
 			EmergencyStopEnabled: false,
+			RiskPresets:          defaultRiskPresets(),
+
+			MaxOpenOrdersPerSymbol: 4,
+			CancelOldestOnLimit:    false,
+
+			DeadManSwitchEnabled: false,
+			DeadManSwitchMinutes: 10,
+
+			MaxDailyLossPercent: 0,
+			MaxDrawdownPercent:  0,
+
+			SizingMode:       SizingModeCompounding,
+			RiskModel:        RiskModelStopDistance,
+			EquityResetHours: 24,
+
+			ProtectiveOrderRetries:  3,
+			CloseUnprotected:        false,
+			NudgeRoundedLevels:      true,
+			EntryFillTimeoutSeconds: 30,
+
+			CandleCloseDelayMs: 300,
+			VerifyCandleClose:  false,
+
+			MinRiskReward:    1.2,
+			EstimatedFeeRate: 0.0004,
+
+			JournalEnabled: false,
+			JournalCandles: 120,
+
+			OrderPollInterval:       30,
+			ActiveOrderPollInterval: 5,
+			AdaptiveOrderPolling:    true,
+			FillDedupMinutes:        60,
+
+			MinTrendCandles: 3,
+			ADXThreshold:    20,
+
+			SignalCooldownMinutes: 60,
+			SignalMinMovePercent:  1.0,
+
+			EMA12BufferPercent: 0,
+			HistoryMargin:      160,
+			EMASource:          "close",
+			EMA12Exit:          true,
+
+			MaxATRPercent:         0,
+			MaxCandleRangePercent: 0,
+
+			FundingCloseEnabled:  false,
+			FundingCloseMinutes:  5,
+			FundingRateThreshold: 0.0001,
+			FundingReopen:        true,
+
+			SymbolStatusCheckMinutes: 10,
+			TickerStatsMaxAgeSeconds: 300,
+
+			CloseOnSymbolHalt: true,
+
+			MaxWatchedSymbolsPerUser: 10,
+			MaxWatchedSymbols:        50,
+
+			ManualConfirm:         false,
+			ConfirmTimeoutSeconds: 120,
 		},
 		Logging: LoggingConfig{
 			Level:      "info",
@@ -178,10 +598,28 @@ func getDefaultConfig() *Config {
 			MaxAge:     30,
 			Compress:   true,
 			Console:    true,
+			Format:     "text",
+		},
+		Notification: NotificationConfig{
+			Channels: []string{ChannelTelegram},
+			Email: EmailConfig{
+				Port: 587,
+			},
+			DedupWindowSeconds: 60,
+			ChatRateLimit:      1,
 		},
 	}
 }
 
+// defaultRiskPresets 获取内置风险预设
+func defaultRiskPresets() map[string]RiskPreset {
+	return map[string]RiskPreset{
+		"conservative": {RiskPercent: 1.0, Leverage: 2, MinConfidence: 0.85, CooldownMinutes: 240},
+		"balanced":     {RiskPercent: 2.0, Leverage: 5, MinConfidence: 0.75, CooldownMinutes: 60},
+		"aggressive":   {RiskPercent: 3.0, Leverage: 10, MinConfidence: 0.6, CooldownMinutes: 15},
+	}
+}
+
 // loadFromEnv 从环境变量加载敏感配置
 func loadFromEnv(config *Config) error {
 	// Telegram配置
@@ -206,6 +644,11 @@ func loadFromEnv(config *Config) error {
 		}
 	}
 
+	// 运行模式
+	if mode := os.Getenv("TRADING_MODE"); mode != "" {
+		config.Mode = strings.ToLower(mode)
+	}
+
 	// 币安配置
 	if apiKey := os.Getenv("BINANCE_API_KEY"); apiKey != "" {
 		config.Binance.APIKey = apiKey
@@ -233,6 +676,18 @@ func loadFromEnv(config *Config) error {
 
 // validate 验证配置
 func validate(config *Config) error {
+	// 验证运行模式
+	switch config.Mode {
+	case ModeAnalysis, ModePaper, ModeLive:
+	default:
+		return fmt.Errorf("mode must be %s, %s or %s", ModeAnalysis, ModePaper, ModeLive)
+	}
+
+	// 验证指标服务端口
+	if config.MetricsPort < 0 || config.MetricsPort > 65535 {
+		return fmt.Errorf("metrics port must be between 0 and 65535")
+	}
+
 	// 验证Telegram配置
 	if config.Telegram.BotToken == "" {
 		return fmt.Errorf("telegram bot token is required")
@@ -242,6 +697,10 @@ func validate(config *Config) error {
 		return fmt.Errorf("at least one telegram chat ID is required")
 	}
 
+	if config.Telegram.HeartbeatMinutes < 0 {
+		return fmt.Errorf("heartbeat minutes cannot be negative")
+	}
+
 	// 验证币安配置
 	if config.Binance.APIKey == "" {
 		return fmt.Errorf("binance API key is required")
@@ -251,6 +710,42 @@ func validate(config *Config) error {
 		return fmt.Errorf("binance secret key is required")
 	}
 
+	if config.Binance.MaintenanceBackoff <= 0 {
+		return fmt.Errorf("maintenance backoff must be greater than 0")
+	}
+
+	if config.Binance.RateLimit <= 0 {
+		return fmt.Errorf("rate limit must be greater than 0")
+	}
+
+	if err := validateProxyURL(config.Binance.ProxyURL); err != nil {
+		return err
+	}
+
+	// 币安允许的接收窗口最大为60000毫秒
+	if config.Binance.RecvWindow <= 0 || config.Binance.RecvWindow > 60000 {
+		return fmt.Errorf("recv window must be between 1 and 60000 milliseconds")
+	}
+
+	if config.Binance.MaxMaintenanceBackoff < config.Binance.MaintenanceBackoff {
+		return fmt.Errorf("max maintenance backoff cannot be less than maintenance backoff")
+	}
+
+	if config.Binance.MaxReconnectFailures <= 0 {
+		return fmt.Errorf("max reconnect failures must be greater than 0")
+	}
+
+	if config.Binance.FlapReconnects <= 0 {
+		return fmt.Errorf("flap reconnects must be greater than 0")
+	}
+	if config.Binance.FlapWindowMinutes <= 0 || config.Binance.FlapStableMinutes <= 0 {
+		return fmt.Errorf("flap window and stable minutes must be greater than 0")
+	}
+
+	if config.Binance.OrderRetries < 0 {
+		return fmt.Errorf("order retries cannot be negative")
+	}
+
 	// 验证交易配置
 	if config.Trading.DefaultRiskPercent <= 0 || config.Trading.DefaultRiskPercent > 100 {
 		return fmt.Errorf("default risk percent must be between 0 and 100")
@@ -260,6 +755,10 @@ func validate(config *Config) error {
 		return fmt.Errorf("max positions must be greater than 0")
 	}
 
+	if config.Trading.DefaultLeverage < 1 || config.Trading.DefaultLeverage > 125 {
+		return fmt.Errorf("default leverage must be between 1 and 125")
+	}
+
 	if config.Trading.MinOrderValue <= 0 {
 		return fmt.Errorf("min order value must be greater than 0")
 	}
@@ -268,6 +767,286 @@ func validate(config *Config) error {
 		return fmt.Errorf("max order value must be greater than min order value")
 	}
 
+	if config.Trading.MaxOpenOrdersPerSymbol < 0 {
+		return fmt.Errorf("max open orders per symbol cannot be negative")
+	}
+
+	if config.Trading.DeadManSwitchEnabled && config.Trading.DeadManSwitchMinutes <= 0 {
+		return fmt.Errorf("dead man switch minutes must be greater than 0")
+	}
+
+	if config.Trading.MaxDailyLossPercent < 0 || config.Trading.MaxDailyLossPercent >= 100 {
+		return fmt.Errorf("max daily loss percent must be between 0 and 100")
+	}
+
+	if config.Trading.MaxDrawdownPercent < 0 || config.Trading.MaxDrawdownPercent >= 100 {
+		return fmt.Errorf("max drawdown percent must be between 0 and 100")
+	}
+
+	switch config.Trading.SizingMode {
+	case SizingModeCompounding, SizingModeFixedBase:
+	default:
+		return fmt.Errorf("sizing mode must be %s or %s", SizingModeCompounding, SizingModeFixedBase)
+	}
+
+	switch config.Trading.RiskModel {
+	case RiskModelPositionValue, RiskModelStopDistance:
+	default:
+		return fmt.Errorf("risk model must be %s or %s", RiskModelPositionValue, RiskModelStopDistance)
+	}
+
+	if config.Trading.EquityResetHours < 0 {
+		return fmt.Errorf("equity reset hours cannot be negative")
+	}
+
+	if config.Trading.ProtectiveOrderRetries < 0 {
+		return fmt.Errorf("protective order retries cannot be negative")
+	}
+
+	if config.Trading.EntryFillTimeoutSeconds <= 0 {
+		return fmt.Errorf("entry fill timeout must be positive")
+	}
+
+	if config.Trading.CandleCloseDelayMs < 0 {
+		return fmt.Errorf("candle close delay cannot be negative")
+	}
+
+	if config.Trading.MinRiskReward < 0 {
+		return fmt.Errorf("min risk reward cannot be negative")
+	}
+
+	if config.Trading.EstimatedFeeRate < 0 || config.Trading.EstimatedFeeRate > 0.01 {
+		return fmt.Errorf("estimated fee rate must be between 0 and 0.01")
+	}
+
+	if config.Trading.JournalCandles < 0 {
+		return fmt.Errorf("journal candles cannot be negative")
+	}
+
+	if config.Trading.MinTrendCandles < 0 {
+		return fmt.Errorf("min trend candles cannot be negative")
+	}
+
+	if config.Trading.ADXThreshold < 0 || config.Trading.ADXThreshold >= 100 {
+		return fmt.Errorf("adx threshold must be between 0 and 100")
+	}
+
+	if config.Trading.SignalCooldownMinutes < 0 {
+		return fmt.Errorf("signal cooldown minutes cannot be negative")
+	}
+
+	if config.Trading.SignalMinMovePercent < 0 {
+		return fmt.Errorf("signal min move percent cannot be negative")
+	}
+
+	if config.Trading.HistoryMargin < 0 {
+		return fmt.Errorf("history margin cannot be negative")
+	}
+
+	if config.Trading.EMA12BufferPercent < 0 || config.Trading.EMA12BufferPercent >= 5 {
+		return fmt.Errorf("ema12 buffer percent must be between 0 and 5")
+	}
+
+	switch config.Trading.EMASource {
+	case "close", "hl2", "hlc3", "ohlc4":
+	default:
+		return fmt.Errorf("ema source must be one of close, hl2, hlc3, ohlc4")
+	}
+
+	if config.Trading.MaxATRPercent < 0 || config.Trading.MaxCandleRangePercent < 0 {
+		return fmt.Errorf("volatility thresholds cannot be negative")
+	}
+
+	if config.Trading.FundingCloseMinutes <= 0 || config.Trading.FundingCloseMinutes >= 480 {
+		return fmt.Errorf("funding close minutes must be between 1 and 479")
+	}
+
+	if config.Trading.FundingRateThreshold < 0 {
+		return fmt.Errorf("funding rate threshold cannot be negative")
+	}
+
+	if config.Trading.SymbolStatusCheckMinutes <= 0 {
+		return fmt.Errorf("symbol status check minutes must be greater than 0")
+	}
+
+	if config.Trading.MaxWatchedSymbolsPerUser <= 0 || config.Trading.MaxWatchedSymbols <= 0 {
+		return fmt.Errorf("watched symbol limits must be greater than 0")
+	}
+
+	if config.Trading.MaxWatchedSymbolsPerUser > config.Trading.MaxWatchedSymbols {
+		return fmt.Errorf("max watched symbols per user cannot exceed the global limit")
+	}
+
+	if config.Trading.TickerStatsMaxAgeSeconds <= 0 {
+		return fmt.Errorf("ticker stats max age must be greater than 0")
+	}
+
+	if config.Trading.OrderPollInterval <= 0 || config.Trading.ActiveOrderPollInterval <= 0 {
+		return fmt.Errorf("order poll intervals must be greater than 0")
+	}
+
+	if config.Trading.ActiveOrderPollInterval > config.Trading.OrderPollInterval {
+		return fmt.Errorf("active order poll interval cannot exceed order poll interval")
+	}
+
+	if config.Trading.FillDedupMinutes <= 0 {
+		return fmt.Errorf("fill dedup minutes must be greater than 0")
+	}
+
+	if config.Trading.ConfirmTimeoutSeconds <= 0 {
+		return fmt.Errorf("confirm timeout seconds must be greater than 0")
+	}
+
+	if !validMarginType(config.Trading.DefaultMarginType) {
+		return fmt.Errorf("default margin type must be ISOLATED or CROSSED")
+	}
+	for symbol, marginType := range config.Trading.MarginTypes {
+		if marginType == "" || !validMarginType(marginType) {
+			return fmt.Errorf("invalid margin type %q for %s: must be ISOLATED or CROSSED", marginType, symbol)
+		}
+	}
+
+	for name, preset := range config.Trading.RiskPresets {
+		if err := preset.Validate(); err != nil {
+			return fmt.Errorf("invalid risk preset %s: %w", name, err)
+		}
+	}
+
+	if config.Database.BackupInterval < 0 || config.Database.BackupRetention < 0 {
+		return fmt.Errorf("backup interval and retention cannot be negative")
+	}
+
+	// 验证日志配置
+	switch config.Logging.Level {
+	case "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic":
+	default:
+		return fmt.Errorf("log level must be one of trace, debug, info, warn, error, fatal, panic")
+	}
+
+	switch config.Logging.Format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("log format must be text or json")
+	}
+
+	if config.Logging.MaxSize < 0 || config.Logging.MaxBackups < 0 || config.Logging.MaxAge < 0 {
+		return fmt.Errorf("log rotation settings cannot be negative")
+	}
+
+	// 验证通知渠道
+	if err := config.Notification.validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validate 验证通知渠道配置
+func (c NotificationConfig) validate() error {
+	for _, channel := range c.Channels {
+		switch channel {
+		case ChannelTelegram:
+		case ChannelWebhook:
+			if len(c.Webhooks) == 0 {
+				return fmt.Errorf("webhook notification channel requires at least one webhook")
+			}
+		case ChannelEmail:
+			if c.Email.Host == "" || c.Email.From == "" || len(c.Email.To) == 0 {
+				return fmt.Errorf("email notification channel requires host, from and to")
+			}
+		default:
+			return fmt.Errorf("unknown notification channel %q", channel)
+		}
+	}
+
+	for _, webhook := range c.Webhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("notification webhook url is required")
+		}
+		switch webhook.Format {
+		case WebhookDiscord, WebhookSlack, WebhookGeneric:
+		default:
+			return fmt.Errorf("webhook format must be %s, %s or %s", WebhookDiscord, WebhookSlack, WebhookGeneric)
+		}
+	}
+
+	if c.Email.Port < 0 || c.Email.Port > 65535 {
+		return fmt.Errorf("email port must be between 0 and 65535")
+	}
+
+	if c.DedupWindowSeconds < 0 {
+		return fmt.Errorf("notification dedup window cannot be negative")
+	}
+
+	if c.ChatRateLimit < 0 {
+		return fmt.Errorf("notification chat rate limit cannot be negative")
+	}
+
+	return nil
+}
+
+// Validate 验证风险预设
+func (p RiskPreset) Validate() error {
+	if p.RiskPercent <= 0 || p.RiskPercent > 100 {
+		return fmt.Errorf("risk percent must be between 0 and 100")
+	}
+
+	if p.Leverage < 1 || p.Leverage > 125 {
+		return fmt.Errorf("leverage must be between 1 and 125")
+	}
+
+	if p.MinConfidence < 0 || p.MinConfidence > 1 {
+		return fmt.Errorf("min confidence must be between 0 and 1")
+	}
+
+	if p.CooldownMinutes < 0 {
+		return fmt.Errorf("cooldown minutes cannot be negative")
+	}
+
+	return nil
+}
+
+// GetRiskPreset 根据名称获取风险预设
+func (t *TradingConfig) GetRiskPreset(name string) (RiskPreset, bool) {
+	preset, ok := t.RiskPresets[strings.ToLower(name)]
+	return preset, ok
+}
+
+// validMarginType 保证金模式是否有效，空值表示沿用交易所当前设置
+func validMarginType(marginType string) bool {
+	switch marginType {
+	case "", "ISOLATED", "CROSSED":
+		return true
+	}
+	return false
+}
+
+// MarginTypeFor 获取交易对的保证金模式，未单独配置时使用默认值，为空表示不切换
+func (t *TradingConfig) MarginTypeFor(symbol string) string {
+	if marginType, ok := t.MarginTypes[strings.ToUpper(symbol)]; ok {
+		return marginType
+	}
+	return t.DefaultMarginType
+}
+
+// validateProxyURL 校验代理地址：为空表示不使用代理，否则需为带主机的 http、https 或 socks5 地址
+func validateProxyURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("proxy url scheme must be http, https or socks5")
+	}
+	if proxyURL.Host == "" {
+		return fmt.Errorf("proxy url must include a host")
+	}
 	return nil
 }
 
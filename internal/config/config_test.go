@@ -0,0 +1,64 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestConfig 将通过校验的默认配置经 mutate 修改后写入临时文件
+func writeTestConfig(t *testing.T, mutate func(cfg *Config)) string {
+	t.Helper()
+
+	cfg := getDefaultConfig()
+	cfg.Telegram.BotToken = "test-token"
+	cfg.Telegram.AdminChatID = 1
+	cfg.Telegram.ChatIDs = []int64{1}
+	cfg.Binance.APIKey = "test-key"
+	cfg.Binance.SecretKey = "test-secret"
+	mutate(cfg)
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("encode config: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadNormalizesRiskPresetNames(t *testing.T) {
+	path := writeTestConfig(t, func(cfg *Config) {
+		cfg.Trading.RiskPresets = map[string]RiskPreset{
+			"Aggressive": {RiskPercent: 3, Leverage: 10, MinConfidence: 0.6},
+			" Safe ":     {RiskPercent: 0.5, Leverage: 2, MinConfidence: 0.8},
+		}
+	})
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, name := range []string{"aggressive", "AGGRESSIVE", "Aggressive", "safe", "SAFE"} {
+		if _, ok := cfg.Trading.GetRiskPreset(name); !ok {
+			t.Errorf("GetRiskPreset(%q) not found", name)
+		}
+	}
+}
+
+func TestLoadRejectsDuplicateRiskPresetNames(t *testing.T) {
+	path := writeTestConfig(t, func(cfg *Config) {
+		cfg.Trading.RiskPresets = map[string]RiskPreset{
+			"safe": {RiskPercent: 0.5, Leverage: 2, MinConfidence: 0.8},
+			"SAFE": {RiskPercent: 1, Leverage: 3, MinConfidence: 0.7},
+		}
+	})
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "duplicate risk preset") {
+		t.Fatalf("Load error = %v, want duplicate risk preset", err)
+	}
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupPrefix 备份文件名前缀，清理时只处理带此前缀的文件
+const backupPrefix = "backup-"
+
+// backupTimeFormat 备份文件名中的时间格式
+const backupTimeFormat = "20060102-150405"
+
+// Backup 在线备份数据库到 dir 下带时间戳的文件，备份期间不阻塞读写。返回备份文件路径
+func (d *Database) Backup(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(dir, backupPrefix+time.Now().Format(backupTimeFormat)+".db")
+	if _, err := d.db.Exec("VACUUM INTO ?", path); err != nil {
+		return "", fmt.Errorf("failed to backup database: %w", err)
+	}
+
+	return path, nil
+}
+
+// listBackups 获取 dir 下的备份文件，按修改时间从旧到新排序
+func listBackups(dir string) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []os.FileInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, info)
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ModTime().Before(backups[j].ModTime()) })
+	return backups, nil
+}
+
+// PruneBackups 删除 dir 下早于保留期限的备份，返回删除的文件数；retention 为零表示不清理
+func PruneBackups(dir string, retention time.Duration) (int, error) {
+	if retention <= 0 {
+		return 0, nil
+	}
+
+	backups, err := listBackups(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-retention)
+	removed := 0
+	for _, backup := range backups {
+		if !backup.ModTime().Before(cutoff) {
+			break
+		}
+		if err := os.Remove(filepath.Join(dir, backup.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove backup %s: %w", backup.Name(), err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// StartBackupScheduler 按 interval 定期备份数据库到 dir 并清理超过 retention 的旧备份，ctx 取消后停止。
+// 以最近一次备份的时间计算下次备份，重启不会推迟已到期的备份；interval 为零表示不备份
+func (d *Database) StartBackupScheduler(ctx context.Context, dir string, interval, retention time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	next := time.Now()
+	if backups, err := listBackups(dir); err != nil {
+		d.logger.Warnf("Failed to list database backups: %v", err)
+	} else if len(backups) > 0 {
+		next = backups[len(backups)-1].ModTime().Add(interval)
+	}
+
+	go func() {
+		timer := time.NewTimer(time.Until(next))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			d.runBackup(dir, retention)
+			timer.Reset(interval)
+		}
+	}()
+
+	d.logger.Infof("Database backup scheduled every %v into %s, next at %s",
+		interval, dir, next.Format("2006-01-02 15:04"))
+}
+
+// runBackup 执行一次备份并清理旧备份
+func (d *Database) runBackup(dir string, retention time.Duration) {
+	start := time.Now()
+	path, err := d.Backup(dir)
+	if err != nil {
+		d.logger.Errorf("Database backup failed: %v", err)
+		return
+	}
+	d.logger.Infof("Database backed up to %s in %v", path, time.Since(start).Round(time.Millisecond))
+
+	removed, err := PruneBackups(dir, retention)
+	if err != nil {
+		d.logger.Warnf("Failed to prune old database backups: %v", err)
+		return
+	}
+	if removed > 0 {
+		d.logger.Infof("Pruned %d database backups older than %v", removed, retention)
+	}
+}
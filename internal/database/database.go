@@ -41,9 +41,9 @@ func New(dbPath string, log logger.Logger) (*Database, error) {
 		logger: log,
 	}
 
-	// 初始化数据库表
-	if err := database.initTables(); err != nil {
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
+	// 建表并执行未应用的迁移
+	if err := database.Migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	log.Infof("Database initialized: %s", absPath)
@@ -58,8 +58,8 @@ func (d *Database) Close() error {
 	return nil
 }
 
-// initTables 初始化数据库表
-func (d *Database) initTables() error {
+// baselineSchema 基线迁移：创建当前版本的全部表和索引，并为引入迁移前的旧库补充缺失的列
+func baselineSchema(tx *sql.Tx) error {
 	// 用户配置表
 	userConfigSQL := `
 	CREATE TABLE IF NOT EXISTS user_configs (
@@ -72,6 +72,13 @@ func (d *Database) initTables() error {
 		testnet BOOLEAN DEFAULT 1,
 		max_position_size REAL DEFAULT 100.0,
 		risk_percentage REAL DEFAULT 2.0,
+		leverage INTEGER DEFAULT 1,
+		min_confidence REAL DEFAULT 0,
+		cooldown_minutes INTEGER DEFAULT 0,
+		risk_preset TEXT DEFAULT '',
+		session_start TEXT DEFAULT '',
+		session_end TEXT DEFAULT '',
+		session_timezone TEXT DEFAULT '',
 		is_active BOOLEAN DEFAULT 1,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
@@ -173,6 +180,70 @@ func (d *Database) initTables() error {
 	);
 	`
 
+	// 权益基数表（固定权益基数仓位计算）
+	equityBaseSQL := `
+	CREATE TABLE IF NOT EXISTS equity_bases (
+		user_id INTEGER NOT NULL,
+		asset TEXT NOT NULL DEFAULT 'USDT',
+		equity REAL NOT NULL,
+		set_at DATETIME NOT NULL,
+		PRIMARY KEY (user_id, asset)
+	);
+	`
+
+	// 交易日志表（开仓时的图表快照）
+	tradeJournalSQL := `
+	CREATE TABLE IF NOT EXISTS trade_journal (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trade_id INTEGER,
+		user_id INTEGER NOT NULL,
+		symbol TEXT NOT NULL,
+		order_id TEXT,
+		side TEXT NOT NULL,
+		entry_price REAL,
+		stop_loss REAL,
+		take_profit REAL,
+		image_path TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES user_configs(user_id)
+	);
+	`
+
+	// 权益快照表（权益曲线）
+	equitySnapshotSQL := `
+	CREATE TABLE IF NOT EXISTS equity_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		asset TEXT NOT NULL DEFAULT 'USDT',
+		equity REAL NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	// 交易暂停状态表（手动暂停与风控熔断，重启后恢复）
+	tradingHaltSQL := `
+	CREATE TABLE IF NOT EXISTS trading_halts (
+		name TEXT PRIMARY KEY,
+		reason TEXT,
+		halted_until DATETIME,
+		created_at DATETIME NOT NULL
+	);
+	`
+
+	// 指令审计表（记录每条收到的指令及处理结果）
+	commandAuditSQL := `
+	CREATE TABLE IF NOT EXISTS command_audit (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		username TEXT,
+		chat_id INTEGER NOT NULL,
+		command TEXT NOT NULL,
+		args TEXT,
+		outcome TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
 	// 执行所有建表语句
 	tables := []string{
 		userConfigSQL,
@@ -181,14 +252,24 @@ func (d *Database) initTables() error {
 		signalsSQL,
 		positionsSQL,
 		logsSQL,
+		equityBaseSQL,
+		tradeJournalSQL,
+		equitySnapshotSQL,
+		tradingHaltSQL,
+		commandAuditSQL,
 	}
 
 	for _, tableSQL := range tables {
-		if _, err := d.db.Exec(tableSQL); err != nil {
+		if _, err := tx.Exec(tableSQL); err != nil {
 			return fmt.Errorf("failed to create table: %w", err)
 		}
 	}
 
+	// 为已存在的旧表补充新增列
+	if err := ensureColumns(tx); err != nil {
+		return err
+	}
+
 	// 创建索引
 	indexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_trades_user_symbol ON trades(user_id, symbol);",
@@ -197,18 +278,87 @@ func (d *Database) initTables() error {
 		"CREATE INDEX IF NOT EXISTS idx_signals_created_at ON signals(created_at);",
 		"CREATE INDEX IF NOT EXISTS idx_positions_user_symbol ON positions(user_id, symbol);",
 		"CREATE INDEX IF NOT EXISTS idx_logs_created_at ON system_logs(created_at);",
+		"CREATE INDEX IF NOT EXISTS idx_trade_journal_trade_id ON trade_journal(trade_id);",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_equity_bases_user_asset ON equity_bases(user_id, asset);",
+		"CREATE INDEX IF NOT EXISTS idx_equity_snapshots_user_created ON equity_snapshots(user_id, asset, created_at);",
+		"CREATE INDEX IF NOT EXISTS idx_command_audit_created_at ON command_audit(created_at);",
 	}
 
 	for _, indexSQL := range indexes {
-		if _, err := d.db.Exec(indexSQL); err != nil {
+		if _, err := tx.Exec(indexSQL); err != nil {
 			return fmt.Errorf("failed to create index: %w", err)
 		}
 	}
 
-	d.logger.Info("Database tables initialized successfully")
 	return nil
 }
 
+// columnDef 列定义
+type columnDef struct {
+	table      string
+	column     string
+	definition string
+}
+
+// ensureColumns 为旧版本数据库补充缺失的列（CREATE TABLE IF NOT EXISTS 不会修改已有表）
+func ensureColumns(tx *sql.Tx) error {
+	columns := []columnDef{
+		{"user_configs", "leverage", "INTEGER DEFAULT 1"},
+		{"user_configs", "min_confidence", "REAL DEFAULT 0"},
+		{"user_configs", "cooldown_minutes", "INTEGER DEFAULT 0"},
+		{"user_configs", "risk_preset", "TEXT DEFAULT ''"},
+		{"user_configs", "session_start", "TEXT DEFAULT ''"},
+		{"user_configs", "session_end", "TEXT DEFAULT ''"},
+		{"user_configs", "session_timezone", "TEXT DEFAULT ''"},
+		{"equity_bases", "asset", "TEXT NOT NULL DEFAULT 'USDT'"},
+	}
+
+	for _, col := range columns {
+		exists, err := columnExists(tx, col.table, col.column)
+		if err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", col.table, err)
+		}
+		if exists {
+			continue
+		}
+
+		alterSQL := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", col.table, col.column, col.definition)
+		if _, err := tx.Exec(alterSQL); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", col.table, col.column, err)
+		}
+	}
+
+	return nil
+}
+
+// columnExists 检查表中是否存在指定列
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
 // GetDB 获取数据库连接
 func (d *Database) GetDB() *sql.DB {
 	return d.db
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration 数据库结构迁移，up 在事务中执行
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// migrations 按版本号递增排列的迁移列表。新的表结构变更追加到末尾并使用更大的版本号，
+// 已发布的迁移不可修改，否则已应用该版本的数据库不会再执行
+var migrations = []migration{
+	{1, "baseline schema", baselineSchema},
+	{2, "encrypt stored api credentials", encryptStoredCredentials},
+	{3, "add pending notifications", createPendingNotifications},
+}
+
+// Migrate 创建迁移记录表并按版本顺序执行未应用的迁移，每个迁移及其版本记录在同一事务中提交
+func (d *Database) Migrate() error {
+	if _, err := d.db.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	last := 0
+	for _, m := range migrations {
+		if m.version <= last {
+			return fmt.Errorf("migration %d is out of order", m.version)
+		}
+		last = m.version
+
+		if applied[m.version] {
+			continue
+		}
+		if err := d.applyMigration(m); err != nil {
+			return err
+		}
+		d.logger.Infof("Applied database migration %d: %s", m.version, m.description)
+	}
+
+	d.logger.Infof("Database schema at version %d", last)
+	return nil
+}
+
+// appliedMigrations 获取已应用的迁移版本
+func (d *Database) appliedMigrations() (map[int]bool, error) {
+	rows, err := d.db.Query("SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan schema migration: %w", err)
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// applyMigration 在事务中执行单个迁移并记录版本，失败时回滚
+func (d *Database) applyMigration(m migration) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, description) VALUES (?, ?)",
+		m.version, m.description); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+	return nil
+}
+
+// createPendingNotifications 创建待重发通知表：重试后仍发送失败的紧急通知，下次启动时重发
+func createPendingNotifications(tx *sql.Tx) error {
+	statements := []string{`
+	CREATE TABLE IF NOT EXISTS pending_notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		channel TEXT NOT NULL,
+		type INTEGER NOT NULL,
+		priority INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		message TEXT NOT NULL,
+		symbol TEXT,
+		chat_ids TEXT,
+		attempts INTEGER DEFAULT 0,
+		last_error TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`,
+		"CREATE INDEX IF NOT EXISTS idx_pending_notifications_created_at ON pending_notifications(created_at);",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -17,6 +18,13 @@ type UserConfig struct {
 	Testnet          bool      `json:"testnet"`
 	MaxPositionSize  float64   `json:"max_position_size"`
 	RiskPercentage   float64   `json:"risk_percentage"`
+	Leverage         int       `json:"leverage"`
+	MinConfidence    float64   `json:"min_confidence"`
+	CooldownMinutes  int       `json:"cooldown_minutes"`
+	RiskPreset       string    `json:"risk_preset"`
+	SessionStart     string    `json:"session_start"`
+	SessionEnd       string    `json:"session_end"`
+	SessionTimezone  string    `json:"session_timezone"`
 	IsActive         bool      `json:"is_active"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
@@ -102,6 +110,110 @@ type SystemLog struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+// EquityBase 用于固定权益基数仓位计算的权益快照
+type EquityBase struct {
+	UserID int64     `json:"user_id"`
+	Asset  string    `json:"asset"` // 保证金资产，不同资产分别设置基数
+	Equity float64   `json:"equity"`
+	SetAt  time.Time `json:"set_at"`
+}
+
+// EquitySnapshot 定期记录的账户权益，用于权益曲线
+type EquitySnapshot struct {
+	ID        int       `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Asset     string    `json:"asset"`
+	Equity    float64   `json:"equity"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TradingHalt 生效中的开仓暂停（手动暂停或风控熔断）
+type TradingHalt struct {
+	Name        string     `json:"name"` // 暂停来源，同一来源只保留一条
+	Reason      string     `json:"reason"`
+	HaltedUntil *time.Time `json:"halted_until,omitempty"` // 自动解除时间，为空表示需手动恢复
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CommandAudit 指令审计记录
+type CommandAudit struct {
+	ID        int       `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	ChatID    int64     `json:"chat_id"`
+	Command   string    `json:"command"` // 指令名，按钮回调记为 callback:<前缀>
+	Args      string    `json:"args"`
+	Outcome   string    `json:"outcome"` // ok、error: <原因>、unknown、unauthorized
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PendingNotification 重试后仍发送失败、等待下次启动重发的通知
+type PendingNotification struct {
+	ID        int       `json:"id"`
+	Channel   string    `json:"channel"` // 发送失败的通知渠道名
+	Type      int       `json:"type"`
+	Priority  int       `json:"priority"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Symbol    string    `json:"symbol"`
+	ChatIDs   string    `json:"chat_ids"` // 指定发送的聊天ID（JSON数组），为空表示所有配置的聊天
+	Attempts  int       `json:"attempts"` // 已失败的发送轮次
+	LastError string    `json:"last_error"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TradeStats 一段时间内已实现盈亏的交易统计
+type TradeStats struct {
+	TotalTrades int     `json:"total_trades"` // 期间所有交易记录数（含开仓、止损止盈挂单）
+	Trades      int     `json:"trades"`       // 有已实现盈亏的交易数
+	Wins        int     `json:"wins"`
+	Losses      int     `json:"losses"`
+	RealizedPnl float64 `json:"realized_pnl"`
+	GrossProfit float64 `json:"gross_profit"`
+	GrossLoss   float64 `json:"gross_loss"` // 亏损交易的亏损合计（正数）
+	Commission  float64 `json:"commission"` // 期间所有交易的手续费合计
+	BestTrade   float64 `json:"best_trade"`  // 单笔最大已实现盈亏
+	WorstTrade  float64 `json:"worst_trade"` // 单笔最小已实现盈亏
+}
+
+// SymbolPnl 单个交易对的累计已实现盈亏，包含该交易对所有开平仓周期
+type SymbolPnl struct {
+	Symbol      string  `json:"symbol"`
+	RealizedPnl float64 `json:"realized_pnl"`
+	Trades      int     `json:"trades"` // 有已实现盈亏的交易数
+}
+
+// WinRate 胜率（0~1），没有交易时为0
+func (s *TradeStats) WinRate() float64 {
+	if s.Trades == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(s.Trades)
+}
+
+// ProfitFactor 盈亏因子（总盈利/总亏损），没有亏损时 ok 为 false
+func (s *TradeStats) ProfitFactor() (float64, bool) {
+	if s.GrossLoss == 0 {
+		return 0, false
+	}
+	return s.GrossProfit / s.GrossLoss, true
+}
+
+// TradeJournal 交易日志，记录开仓时的价位与图表快照
+type TradeJournal struct {
+	ID         int       `json:"id"`
+	TradeID    int       `json:"trade_id"`
+	UserID     int64     `json:"user_id"`
+	Symbol     string    `json:"symbol"`
+	OrderID    string    `json:"order_id"`
+	Side       string    `json:"side"`
+	EntryPrice float64   `json:"entry_price"`
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	ImagePath  string    `json:"image_path"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 // UserConfigRepository 用户配置仓库
 type UserConfigRepository struct {
 	db *sql.DB
@@ -112,22 +224,46 @@ func NewUserConfigRepository(db *sql.DB) *UserConfigRepository {
 	return &UserConfigRepository{db: db}
 }
 
-// GetByUserID 根据用户ID获取配置
-func (r *UserConfigRepository) GetByUserID(userID int64) (*UserConfig, error) {
-	query := `
-		SELECT id, user_id, username, chat_id, api_key, api_secret, testnet, 
-		       max_position_size, risk_percentage, is_active, created_at, updated_at
-		FROM user_configs WHERE user_id = ?
-	`
+// userConfigColumns 用户配置查询字段，顺序与 scanUserConfig 保持一致
+const userConfigColumns = `id, user_id, username, chat_id, api_key, api_secret, testnet,
+		       max_position_size, risk_percentage, leverage, min_confidence, cooldown_minutes,
+		       risk_preset, session_start, session_end, session_timezone, is_active,
+		       created_at, updated_at`
+
+// rowScanner 兼容 *sql.Row 与 *sql.Rows 的扫描接口
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanUserConfig 扫描一行用户配置并解密API凭证
+func scanUserConfig(row rowScanner) (*UserConfig, error) {
 	var config UserConfig
-	err := r.db.QueryRow(query, userID).Scan(
+	err := row.Scan(
 		&config.ID, &config.UserID, &config.Username, &config.ChatID,
 		&config.APIKey, &config.APISecret, &config.Testnet,
-		&config.MaxPositionSize, &config.RiskPercentage, &config.IsActive,
+		&config.MaxPositionSize, &config.RiskPercentage, &config.Leverage,
+		&config.MinConfidence, &config.CooldownMinutes, &config.RiskPreset,
+		&config.SessionStart, &config.SessionEnd, &config.SessionTimezone, &config.IsActive,
 		&config.CreatedAt, &config.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
 
+	if config.APIKey, err = decryptSecret(config.APIKey); err != nil {
+		return nil, fmt.Errorf("failed to decrypt api key of user %d: %w", config.UserID, err)
+	}
+	if config.APISecret, err = decryptSecret(config.APISecret); err != nil {
+		return nil, fmt.Errorf("failed to decrypt api secret of user %d: %w", config.UserID, err)
+	}
+	return &config, nil
+}
+
+// GetByUserID 根据用户ID获取配置
+func (r *UserConfigRepository) GetByUserID(userID int64) (*UserConfig, error) {
+	query := `SELECT ` + userConfigColumns + ` FROM user_configs WHERE user_id = ?`
+
+	config, err := scanUserConfig(r.db.QueryRow(query, userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -135,20 +271,51 @@ func (r *UserConfigRepository) GetByUserID(userID int64) (*UserConfig, error) {
 		return nil, fmt.Errorf("failed to get user config: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
-// Create 创建用户配置
+// GetActiveUsers 获取所有启用交易的用户配置
+func (r *UserConfigRepository) GetActiveUsers() ([]*UserConfig, error) {
+	query := `SELECT ` + userConfigColumns + ` FROM user_configs WHERE is_active = 1`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active users: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []*UserConfig
+	for rows.Next() {
+		config, err := scanUserConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user config: %w", err)
+		}
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
+
+// Create 创建用户配置，API凭证加密保存
 func (r *UserConfigRepository) Create(config *UserConfig) error {
+	apiKey, apiSecret, err := encryptCredentials(config.APIKey, config.APISecret)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO user_configs (user_id, username, chat_id, api_key, api_secret, testnet, 
-		                         max_position_size, risk_percentage, is_active)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                         max_position_size, risk_percentage, leverage, min_confidence,
+		                         cooldown_minutes, risk_preset, session_start, session_end,
+		                         session_timezone, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.Exec(query,
-		config.UserID, config.Username, config.ChatID, config.APIKey, config.APISecret,
-		config.Testnet, config.MaxPositionSize, config.RiskPercentage, config.IsActive,
+		config.UserID, config.Username, config.ChatID, apiKey, apiSecret,
+		config.Testnet, config.MaxPositionSize, config.RiskPercentage, config.Leverage,
+		config.MinConfidence, config.CooldownMinutes, config.RiskPreset, config.SessionStart,
+		config.SessionEnd, config.SessionTimezone, config.IsActive,
 	)
 
 	if err != nil {
@@ -164,18 +331,27 @@ func (r *UserConfigRepository) Create(config *UserConfig) error {
 	return nil
 }
 
-// Update 更新用户配置
+// Update 更新用户配置，API凭证加密保存
 func (r *UserConfigRepository) Update(config *UserConfig) error {
+	apiKey, apiSecret, err := encryptCredentials(config.APIKey, config.APISecret)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE user_configs 
 		SET username = ?, chat_id = ?, api_key = ?, api_secret = ?, testnet = ?,
-		    max_position_size = ?, risk_percentage = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+		    max_position_size = ?, risk_percentage = ?, leverage = ?, min_confidence = ?,
+		    cooldown_minutes = ?, risk_preset = ?, session_start = ?, session_end = ?,
+		    session_timezone = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE user_id = ?
 	`
 
-	_, err := r.db.Exec(query,
-		config.Username, config.ChatID, config.APIKey, config.APISecret, config.Testnet,
-		config.MaxPositionSize, config.RiskPercentage, config.IsActive, config.UserID,
+	_, err = r.db.Exec(query,
+		config.Username, config.ChatID, apiKey, apiSecret, config.Testnet,
+		config.MaxPositionSize, config.RiskPercentage, config.Leverage, config.MinConfidence,
+		config.CooldownMinutes, config.RiskPreset, config.SessionStart, config.SessionEnd,
+		config.SessionTimezone, config.IsActive, config.UserID,
 	)
 
 	if err != nil {
@@ -185,6 +361,148 @@ func (r *UserConfigRepository) Update(config *UserConfig) error {
 	return nil
 }
 
+// 监控列表数量上限错误，调用方可用 errors.Is 判断
+var (
+	// ErrUserWatchLimit 用户监控的交易对数已达上限
+	ErrUserWatchLimit = errors.New("user watchlist limit reached")
+	// ErrGlobalWatchLimit 所有用户监控的交易对总数已达上限
+	ErrGlobalWatchLimit = errors.New("global watchlist limit reached")
+)
+
+// WatchlistRepository 监控列表仓库
+type WatchlistRepository struct {
+	db *sql.DB
+}
+
+// NewWatchlistRepository 创建监控列表仓库
+func NewWatchlistRepository(db *sql.DB) *WatchlistRepository {
+	return &WatchlistRepository{db: db}
+}
+
+// GetActive 获取用户启用的监控项，按添加时间排序
+func (r *WatchlistRepository) GetActive(userID int64) ([]*WatchlistItem, error) {
+	return r.query(`
+		SELECT id, user_id, symbol, interval, is_active, created_at
+		FROM watchlist WHERE user_id = ? AND is_active = 1 ORDER BY created_at, id
+	`, userID)
+}
+
+// GetAllActive 获取所有用户启用的监控项，按添加时间排序
+func (r *WatchlistRepository) GetAllActive() ([]*WatchlistItem, error) {
+	return r.query(`
+		SELECT id, user_id, symbol, interval, is_active, created_at
+		FROM watchlist WHERE is_active = 1 ORDER BY created_at, id
+	`)
+}
+
+// query 查询监控项
+func (r *WatchlistRepository) query(query string, args ...interface{}) ([]*WatchlistItem, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*WatchlistItem
+	for rows.Next() {
+		var item WatchlistItem
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Symbol, &item.Interval, &item.IsActive, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist item: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
+// CountActiveSymbols 统计所有用户启用监控的不同交易对数
+func (r *WatchlistRepository) CountActiveSymbols() (int, error) {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(DISTINCT symbol) FROM watchlist WHERE is_active = 1`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count watched symbols: %w", err)
+	}
+	return count, nil
+}
+
+// Add 添加或重新启用监控项。用户监控数达到 perUserLimit，或新交易对会使全局监控数超过 globalLimit 时拒绝，
+// 上限为0表示不限制。计数与写入在同一事务内完成
+func (r *WatchlistRepository) Add(item *WatchlistItem, perUserLimit, globalLimit int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userCount, alreadyWatched int
+	err = tx.QueryRow(`
+		SELECT COUNT(*), COUNT(CASE WHEN symbol = ? THEN 1 END)
+		FROM watchlist WHERE user_id = ? AND is_active = 1
+	`, item.Symbol, item.UserID).Scan(&userCount, &alreadyWatched)
+	if err != nil {
+		return fmt.Errorf("failed to count user watchlist: %w", err)
+	}
+	if alreadyWatched == 0 && perUserLimit > 0 && userCount >= perUserLimit {
+		return fmt.Errorf("%w (%d)", ErrUserWatchLimit, perUserLimit)
+	}
+
+	var symbolCount, symbolWatched int
+	err = tx.QueryRow(`
+		SELECT COUNT(DISTINCT symbol), COUNT(CASE WHEN symbol = ? THEN 1 END)
+		FROM watchlist WHERE is_active = 1
+	`, item.Symbol).Scan(&symbolCount, &symbolWatched)
+	if err != nil {
+		return fmt.Errorf("failed to count watched symbols: %w", err)
+	}
+	if symbolWatched == 0 && globalLimit > 0 && symbolCount >= globalLimit {
+		return fmt.Errorf("%w (%d)", ErrGlobalWatchLimit, globalLimit)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO watchlist (user_id, symbol, interval, is_active)
+		VALUES (?, ?, ?, 1)
+		ON CONFLICT(user_id, symbol) DO UPDATE SET interval = excluded.interval, is_active = 1
+	`, item.UserID, item.Symbol, item.Interval)
+	if err != nil {
+		return fmt.Errorf("failed to add watchlist item: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit watchlist item: %w", err)
+	}
+	item.IsActive = true
+	return nil
+}
+
+// Remove 停用用户对交易对的监控，返回停用前的监控项；未在监控中时返回 nil
+func (r *WatchlistRepository) Remove(userID int64, symbol string) (*WatchlistItem, error) {
+	items, err := r.query(`
+		SELECT id, user_id, symbol, interval, is_active, created_at
+		FROM watchlist WHERE user_id = ? AND symbol = ? AND is_active = 1
+	`, userID, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	if _, err := r.db.Exec(`UPDATE watchlist SET is_active = 0 WHERE id = ?`, items[0].ID); err != nil {
+		return nil, fmt.Errorf("failed to remove watchlist item: %w", err)
+	}
+	items[0].IsActive = false
+	return items[0], nil
+}
+
+// CountWatchers 统计启用监控交易对指定周期的用户数
+func (r *WatchlistRepository) CountWatchers(symbol, interval string) (int, error) {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM watchlist WHERE symbol = ? AND interval = ? AND is_active = 1`,
+		symbol, interval).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count watchers: %w", err)
+	}
+	return count, nil
+}
+
 // TradeRepository 交易记录仓库
 type TradeRepository struct {
 	db *sql.DB
@@ -223,6 +541,26 @@ func (r *TradeRepository) Create(trade *Trade) error {
 	return nil
 }
 
+// UpdateFill 按订单号更新交易记录的状态、成交数量和成交均价，返回是否有记录被更新
+func (r *TradeRepository) UpdateFill(orderID, status string, filledQuantity, avgPrice float64) (bool, error) {
+	query := `
+		UPDATE trades SET status = ?, filled_quantity = ?, avg_price = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE order_id = ?
+	`
+
+	result, err := r.db.Exec(query, status, filledQuantity, avgPrice, orderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update trade fill: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
 // GetByUserID 获取用户的交易记录
 func (r *TradeRepository) GetByUserID(userID int64, limit int) ([]*Trade, error) {
 	query := `
@@ -257,6 +595,62 @@ func (r *TradeRepository) GetByUserID(userID int64, limit int) ([]*Trade, error)
 	return trades, nil
 }
 
+// GetStats 统计用户自 since 起的交易表现
+func (r *TradeRepository) GetStats(userID int64, since time.Time) (*TradeStats, error) {
+	query := `
+		SELECT COUNT(CASE WHEN realized_pnl != 0 THEN 1 END),
+		       COUNT(CASE WHEN realized_pnl > 0 THEN 1 END),
+		       COUNT(CASE WHEN realized_pnl < 0 THEN 1 END),
+		       COALESCE(SUM(realized_pnl), 0),
+		       COALESCE(SUM(CASE WHEN realized_pnl > 0 THEN realized_pnl END), 0),
+		       COALESCE(-SUM(CASE WHEN realized_pnl < 0 THEN realized_pnl END), 0),
+		       COALESCE(SUM(commission), 0),
+		       COUNT(*),
+		       COALESCE(MAX(CASE WHEN realized_pnl != 0 THEN realized_pnl END), 0),
+		       COALESCE(MIN(CASE WHEN realized_pnl != 0 THEN realized_pnl END), 0)
+		FROM trades WHERE user_id = ? AND created_at >= ?
+	`
+
+	var stats TradeStats
+	err := r.db.QueryRow(query, userID, since.UTC().Format(sqliteTimeFormat)).Scan(
+		&stats.Trades, &stats.Wins, &stats.Losses, &stats.RealizedPnl,
+		&stats.GrossProfit, &stats.GrossLoss, &stats.Commission,
+		&stats.TotalTrades, &stats.BestTrade, &stats.WorstTrade,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trade stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// GetRealizedPnlBySymbol 按交易对汇总用户全部交易的已实现盈亏，平仓后重新开仓的交易对累计所有交易
+func (r *TradeRepository) GetRealizedPnlBySymbol(userID int64) ([]*SymbolPnl, error) {
+	query := `
+		SELECT symbol, COALESCE(SUM(realized_pnl), 0), COUNT(CASE WHEN realized_pnl != 0 THEN 1 END)
+		FROM trades WHERE user_id = ?
+		GROUP BY symbol HAVING COUNT(CASE WHEN realized_pnl != 0 THEN 1 END) > 0
+		ORDER BY symbol
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query realized pnl by symbol: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*SymbolPnl
+	for rows.Next() {
+		var pnl SymbolPnl
+		if err := rows.Scan(&pnl.Symbol, &pnl.RealizedPnl, &pnl.Trades); err != nil {
+			return nil, fmt.Errorf("failed to scan realized pnl: %w", err)
+		}
+		result = append(result, &pnl)
+	}
+
+	return result, rows.Err()
+}
+
 // PositionRepository 持仓记录仓库
 type PositionRepository struct {
 	db *sql.DB
@@ -328,6 +722,29 @@ func (r *PositionRepository) Create(position *Position) error {
 	return nil
 }
 
+// Update 更新持仓记录，关闭持仓时记录平仓时间
+func (r *PositionRepository) Update(position *Position) error {
+	query := `
+		UPDATE positions 
+		SET size = ?, entry_price = ?, mark_price = ?, unrealized_pnl = ?, percentage = ?,
+		    stop_loss_price = ?, take_profit_price = ?, is_open = ?, updated_at = CURRENT_TIMESTAMP,
+		    closed_at = CASE WHEN ? THEN NULL ELSE COALESCE(closed_at, CURRENT_TIMESTAMP) END
+		WHERE id = ?
+	`
+
+	_, err := r.db.Exec(query,
+		position.Size, position.EntryPrice, position.MarkPrice, position.UnrealizedPnl,
+		position.Percentage, position.StopLossPrice, position.TakeProfitPrice, position.IsOpen,
+		position.IsOpen, position.ID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update position: %w", err)
+	}
+
+	return nil
+}
+
 // SignalRepository 信号仓库
 type SignalRepository struct {
 	db *sql.DB
@@ -403,4 +820,427 @@ func (r *SignalRepository) MarkProcessed(signalID int) error {
 		return fmt.Errorf("failed to mark signal as processed: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// GetRecent 获取最近生成的信号，按时间倒序
+func (r *SignalRepository) GetRecent(limit int) ([]*Signal, error) {
+	query := `
+		SELECT id, user_id, symbol, interval, strategy_type, signal_type, price,
+		       volume, confidence, metadata, is_processed, created_at
+		FROM signals ORDER BY id DESC LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signals: %w", err)
+	}
+	defer rows.Close()
+
+	var signals []*Signal
+	for rows.Next() {
+		var signal Signal
+		var volume, confidence sql.NullFloat64
+		var metadata sql.NullString
+		err := rows.Scan(
+			&signal.ID, &signal.UserID, &signal.Symbol, &signal.Interval, &signal.StrategyType,
+			&signal.SignalType, &signal.Price, &volume, &confidence,
+			&metadata, &signal.IsProcessed, &signal.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signal: %w", err)
+		}
+		signal.Volume = volume.Float64
+		signal.Confidence = confidence.Float64
+		signal.Metadata = metadata.String
+		signals = append(signals, &signal)
+	}
+
+	return signals, nil
+}
+
+// EquityBaseRepository 权益基数仓库
+type EquityBaseRepository struct {
+	db *sql.DB
+}
+
+// NewEquityBaseRepository 创建权益基数仓库
+func NewEquityBaseRepository(db *sql.DB) *EquityBaseRepository {
+	return &EquityBaseRepository{db: db}
+}
+
+// Get 获取用户指定保证金资产的权益基数，不存在时返回 nil
+func (r *EquityBaseRepository) Get(userID int64, asset string) (*EquityBase, error) {
+	query := "SELECT user_id, asset, equity, set_at FROM equity_bases WHERE user_id = ? AND asset = ?"
+
+	var base EquityBase
+	err := r.db.QueryRow(query, userID, asset).Scan(&base.UserID, &base.Asset, &base.Equity, &base.SetAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get equity base: %w", err)
+	}
+
+	return &base, nil
+}
+
+// Set 设置用户的权益基数
+func (r *EquityBaseRepository) Set(base *EquityBase) error {
+	query := `
+		INSERT INTO equity_bases (user_id, asset, equity, set_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id, asset) DO UPDATE SET equity = excluded.equity, set_at = excluded.set_at
+	`
+
+	if _, err := r.db.Exec(query, base.UserID, base.Asset, base.Equity, base.SetAt); err != nil {
+		return fmt.Errorf("failed to set equity base: %w", err)
+	}
+
+	return nil
+}
+
+// TradeJournalRepository 交易日志仓库
+type TradeJournalRepository struct {
+	db *sql.DB
+}
+
+// NewTradeJournalRepository 创建交易日志仓库
+func NewTradeJournalRepository(db *sql.DB) *TradeJournalRepository {
+	return &TradeJournalRepository{db: db}
+}
+
+// Create 创建交易日志
+func (r *TradeJournalRepository) Create(entry *TradeJournal) error {
+	query := `
+		INSERT INTO trade_journal (trade_id, user_id, symbol, order_id, side, entry_price,
+		                           stop_loss, take_profit, image_path)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query,
+		entry.TradeID, entry.UserID, entry.Symbol, entry.OrderID, entry.Side, entry.EntryPrice,
+		entry.StopLoss, entry.TakeProfit, entry.ImagePath,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create trade journal: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	entry.ID = int(id)
+	return nil
+}
+
+// GetByTradeID 根据交易记录ID获取用户的交易日志，不存在时返回 nil
+func (r *TradeJournalRepository) GetByTradeID(userID int64, tradeID int) (*TradeJournal, error) {
+	query := `
+		SELECT id, trade_id, user_id, symbol, order_id, side, entry_price, stop_loss,
+		       take_profit, image_path, created_at
+		FROM trade_journal WHERE user_id = ? AND trade_id = ?
+		ORDER BY created_at DESC LIMIT 1
+	`
+
+	var entry TradeJournal
+	err := r.db.QueryRow(query, userID, tradeID).Scan(
+		&entry.ID, &entry.TradeID, &entry.UserID, &entry.Symbol, &entry.OrderID, &entry.Side,
+		&entry.EntryPrice, &entry.StopLoss, &entry.TakeProfit, &entry.ImagePath, &entry.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get trade journal: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// sqliteTimeFormat 与 CURRENT_TIMESTAMP 默认值一致的时间格式（UTC），用于按时间范围查询
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// EquitySnapshotRepository 权益快照仓库
+type EquitySnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewEquitySnapshotRepository 创建权益快照仓库
+func NewEquitySnapshotRepository(db *sql.DB) *EquitySnapshotRepository {
+	return &EquitySnapshotRepository{db: db}
+}
+
+// Create 记录权益快照
+func (r *EquitySnapshotRepository) Create(snapshot *EquitySnapshot) error {
+	query := "INSERT INTO equity_snapshots (user_id, asset, equity) VALUES (?, ?, ?)"
+
+	result, err := r.db.Exec(query, snapshot.UserID, snapshot.Asset, snapshot.Equity)
+	if err != nil {
+		return fmt.Errorf("failed to create equity snapshot: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	snapshot.ID = int(id)
+	return nil
+}
+
+// GetFirstSince 获取用户自 since 起最早的权益快照，不存在时返回 nil
+func (r *EquitySnapshotRepository) GetFirstSince(userID int64, asset string, since time.Time) (*EquitySnapshot, error) {
+	query := `
+		SELECT id, user_id, asset, equity, created_at FROM equity_snapshots
+		WHERE user_id = ? AND asset = ? AND created_at >= ?
+		ORDER BY created_at ASC LIMIT 1
+	`
+
+	var snapshot EquitySnapshot
+	err := r.db.QueryRow(query, userID, asset, since.UTC().Format(sqliteTimeFormat)).Scan(
+		&snapshot.ID, &snapshot.UserID, &snapshot.Asset, &snapshot.Equity, &snapshot.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get equity snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// TradingHaltRepository 交易暂停状态仓库
+type TradingHaltRepository struct {
+	db *sql.DB
+}
+
+// NewTradingHaltRepository 创建交易暂停状态仓库
+func NewTradingHaltRepository(db *sql.DB) *TradingHaltRepository {
+	return &TradingHaltRepository{db: db}
+}
+
+// Set 保存暂停状态，同一来源覆盖旧记录
+func (r *TradingHaltRepository) Set(halt *TradingHalt) error {
+	query := `
+		INSERT INTO trading_halts (name, reason, halted_until, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET reason = excluded.reason, halted_until = excluded.halted_until,
+		                                created_at = excluded.created_at
+	`
+
+	if _, err := r.db.Exec(query, halt.Name, halt.Reason, halt.HaltedUntil, halt.CreatedAt); err != nil {
+		return fmt.Errorf("failed to set trading halt: %w", err)
+	}
+
+	return nil
+}
+
+// Delete 删除指定来源的暂停状态
+func (r *TradingHaltRepository) Delete(name string) error {
+	if _, err := r.db.Exec("DELETE FROM trading_halts WHERE name = ?", name); err != nil {
+		return fmt.Errorf("failed to delete trading halt: %w", err)
+	}
+	return nil
+}
+
+// GetAll 获取所有暂停状态
+func (r *TradingHaltRepository) GetAll() ([]*TradingHalt, error) {
+	rows, err := r.db.Query("SELECT name, reason, halted_until, created_at FROM trading_halts ORDER BY created_at")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trading halts: %w", err)
+	}
+	defer rows.Close()
+
+	var halts []*TradingHalt
+	for rows.Next() {
+		var halt TradingHalt
+		var reason sql.NullString
+		if err := rows.Scan(&halt.Name, &reason, &halt.HaltedUntil, &halt.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trading halt: %w", err)
+		}
+		halt.Reason = reason.String
+		halts = append(halts, &halt)
+	}
+
+	return halts, nil
+}
+
+// CommandAuditRepository 指令审计仓库
+type CommandAuditRepository struct {
+	db *sql.DB
+}
+
+// NewCommandAuditRepository 创建指令审计仓库
+func NewCommandAuditRepository(db *sql.DB) *CommandAuditRepository {
+	return &CommandAuditRepository{db: db}
+}
+
+// Create 记录一条指令
+func (r *CommandAuditRepository) Create(entry *CommandAudit) error {
+	query := `
+		INSERT INTO command_audit (user_id, username, chat_id, command, args, outcome)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query, entry.UserID, entry.Username, entry.ChatID, entry.Command, entry.Args, entry.Outcome)
+	if err != nil {
+		return fmt.Errorf("failed to create command audit: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	entry.ID = int(id)
+	return nil
+}
+
+// GetRecent 获取最近的指令记录，按时间倒序
+func (r *CommandAuditRepository) GetRecent(limit int) ([]*CommandAudit, error) {
+	query := `
+		SELECT id, user_id, username, chat_id, command, args, outcome, created_at
+		FROM command_audit ORDER BY id DESC LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query command audit: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*CommandAudit
+	for rows.Next() {
+		var entry CommandAudit
+		var username, args sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.UserID, &username, &entry.ChatID, &entry.Command,
+			&args, &entry.Outcome, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan command audit: %w", err)
+		}
+		entry.Username = username.String
+		entry.Args = args.String
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+// PendingNotificationRepository 待重发通知仓库
+type PendingNotificationRepository struct {
+	db *sql.DB
+}
+
+// NewPendingNotificationRepository 创建待重发通知仓库
+func NewPendingNotificationRepository(db *sql.DB) *PendingNotificationRepository {
+	return &PendingNotificationRepository{db: db}
+}
+
+// Create 保存一条发送失败的通知
+func (r *PendingNotificationRepository) Create(pending *PendingNotification) error {
+	query := `
+		INSERT INTO pending_notifications (channel, type, priority, title, message, symbol, chat_ids, attempts, last_error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	createdAt := pending.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	result, err := r.db.Exec(query, pending.Channel, pending.Type, pending.Priority, pending.Title, pending.Message,
+		pending.Symbol, pending.ChatIDs, pending.Attempts, pending.LastError, createdAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create pending notification: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	pending.ID = int(id)
+	return nil
+}
+
+// GetAll 获取待重发的通知，按保存时间正序
+func (r *PendingNotificationRepository) GetAll(limit int) ([]*PendingNotification, error) {
+	query := `
+		SELECT id, channel, type, priority, title, message, symbol, chat_ids, attempts, last_error, created_at
+		FROM pending_notifications ORDER BY id ASC LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var pendings []*PendingNotification
+	for rows.Next() {
+		var pending PendingNotification
+		var symbol, chatIDs, lastError sql.NullString
+		if err := rows.Scan(&pending.ID, &pending.Channel, &pending.Type, &pending.Priority, &pending.Title,
+			&pending.Message, &symbol, &chatIDs, &pending.Attempts, &lastError, &pending.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending notification: %w", err)
+		}
+		pending.Symbol = symbol.String
+		pending.ChatIDs = chatIDs.String
+		pending.LastError = lastError.String
+		pendings = append(pendings, &pending)
+	}
+
+	return pendings, rows.Err()
+}
+
+// RecordFailure 记录一次重发失败
+func (r *PendingNotificationRepository) RecordFailure(id int, lastError string) error {
+	_, err := r.db.Exec("UPDATE pending_notifications SET attempts = attempts + 1, last_error = ? WHERE id = ?", lastError, id)
+	if err != nil {
+		return fmt.Errorf("failed to update pending notification: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除已发送或放弃重发的通知
+func (r *PendingNotificationRepository) Delete(id int) error {
+	if _, err := r.db.Exec("DELETE FROM pending_notifications WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete pending notification: %w", err)
+	}
+	return nil
+}
+
+// SystemLogRepository 系统日志仓库
+type SystemLogRepository struct {
+	db *sql.DB
+}
+
+// NewSystemLogRepository 创建系统日志仓库
+func NewSystemLogRepository(db *sql.DB) *SystemLogRepository {
+	return &SystemLogRepository{db: db}
+}
+
+// Create 写入一条系统日志
+func (r *SystemLogRepository) Create(log *SystemLog) error {
+	query := `
+		INSERT INTO system_logs (level, message, module, user_id, error_details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	createdAt := log.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	result, err := r.db.Exec(query, log.Level, log.Message, log.Module, log.UserID, log.ErrorDetails, createdAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create system log: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	log.ID = int(id)
+	return nil
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// MasterKeyEnv 加密用户API凭证的主密钥环境变量，应为足够长的随机字符串
+const MasterKeyEnv = "CREDENTIALS_MASTER_KEY"
+
+// ErrMasterKeyMissing 未设置主密钥，拒绝明文保存或无法解密API凭证
+var ErrMasterKeyMissing = errors.New(MasterKeyEnv + " is not set, api credentials cannot be encrypted or decrypted")
+
+// secretPrefix 已加密字段的前缀，无此前缀的非空值为旧版本保存的明文
+const secretPrefix = "enc:"
+
+// secretVersion1 加密格式版本：AES-256-GCM，密钥为主密钥按 secretKeyInfoV1 派生。
+// 更换算法或密钥派生方式时新增版本号，旧版本的密文仍按其版本解密
+const secretVersion1 byte = 1
+
+// secretKeyInfoV1 版本1派生加密密钥所用的上下文
+const secretKeyInfoV1 = "vegas-bot/user-credentials/v1"
+
+// secretKey 按版本从主密钥派生加密密钥
+func secretKey(version byte) ([]byte, error) {
+	master := os.Getenv(MasterKeyEnv)
+	if master == "" {
+		return nil, ErrMasterKeyMissing
+	}
+
+	switch version {
+	case secretVersion1:
+		mac := hmac.New(sha256.New, []byte(master))
+		mac.Write([]byte(secretKeyInfoV1))
+		return mac.Sum(nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported secret version %d", version)
+	}
+}
+
+// secretAEAD 创建指定版本的AES-GCM加密器
+func secretAEAD(version byte) (cipher.AEAD, error) {
+	key, err := secretKey(version)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+	return aead, nil
+}
+
+// encryptSecret 加密敏感字段，格式为 前缀 + base64(版本号 | nonce | 密文)，空值保持为空。
+// 未设置主密钥时返回 ErrMasterKeyMissing，不会退回明文保存
+func encryptSecret(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	aead, err := secretAEAD(secretVersion1)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// 版本号作为附加数据参与认证，防止被篡改为其他版本解密
+	header := []byte{secretVersion1}
+	sealed := aead.Seal(append(header, nonce...), nonce, []byte(plaintext), header)
+	return secretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptSecret 解密敏感字段；无加密前缀的值为旧版本保存的明文，原样返回，下次更新时加密保存
+func decryptSecret(stored string) (string, error) {
+	if !strings.HasPrefix(stored, secretPrefix) {
+		return stored, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, secretPrefix))
+	if err != nil || len(data) == 0 {
+		return "", fmt.Errorf("malformed encrypted secret")
+	}
+
+	version := data[0]
+	aead, err := secretAEAD(version)
+	if err != nil {
+		return "", err
+	}
+
+	payload := data[1:]
+	if len(payload) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted secret")
+	}
+	nonce, ciphertext := payload[:aead.NonceSize()], payload[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, data[:1])
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// encryptCredentials 加密待保存的API密钥和私钥
+func encryptCredentials(apiKey, apiSecret string) (string, string, error) {
+	encryptedKey, err := encryptSecret(apiKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt api key: %w", err)
+	}
+	encryptedSecret, err := encryptSecret(apiSecret)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt api secret: %w", err)
+	}
+	return encryptedKey, encryptedSecret, nil
+}
+
+// encryptStoredCredentials 迁移：加密旧版本以明文保存的API凭证，存在明文凭证但未设置主密钥时迁移失败
+func encryptStoredCredentials(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, COALESCE(api_key, ''), COALESCE(api_secret, '') FROM user_configs
+		WHERE (api_key != '' AND api_key NOT LIKE ?) OR (api_secret != '' AND api_secret NOT LIKE ?)`,
+		secretPrefix+"%", secretPrefix+"%")
+	if err != nil {
+		return fmt.Errorf("failed to query plaintext credentials: %w", err)
+	}
+
+	type credentials struct {
+		id        int
+		apiKey    string
+		apiSecret string
+	}
+	var plaintext []credentials
+	for rows.Next() {
+		var c credentials
+		if err := rows.Scan(&c.id, &c.apiKey, &c.apiSecret); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan credentials: %w", err)
+		}
+		plaintext = append(plaintext, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range plaintext {
+		// 已加密的字段原样保留
+		apiKey, apiSecret := c.apiKey, c.apiSecret
+		if !strings.HasPrefix(apiKey, secretPrefix) {
+			if apiKey, err = encryptSecret(apiKey); err != nil {
+				return fmt.Errorf("failed to encrypt api key of user config %d: %w", c.id, err)
+			}
+		}
+		if !strings.HasPrefix(apiSecret, secretPrefix) {
+			if apiSecret, err = encryptSecret(apiSecret); err != nil {
+				return fmt.Errorf("failed to encrypt api secret of user config %d: %w", c.id, err)
+			}
+		}
+		if _, err := tx.Exec("UPDATE user_configs SET api_key = ?, api_secret = ? WHERE id = ?",
+			apiKey, apiSecret, c.id); err != nil {
+			return fmt.Errorf("failed to update credentials of user config %d: %w", c.id, err)
+		}
+	}
+	return nil
+}
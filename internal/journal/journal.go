@@ -0,0 +1,96 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/chart"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// Journal 交易日志记录器，开仓时渲染隧道图表并与交易记录关联
+type Journal struct {
+	dir        string
+	candles    int
+	logger     logger.Logger
+	repo       *database.TradeJournalRepository
+	strategies *strategy.StrategyManager
+}
+
+// New 创建交易日志记录器
+func New(dir string, candles int, log logger.Logger, db *database.Database, strategies *strategy.StrategyManager) *Journal {
+	return &Journal{
+		dir:        dir,
+		candles:    candles,
+		logger:     log,
+		repo:       database.NewTradeJournalRepository(db.GetDB()),
+		strategies: strategies,
+	}
+}
+
+// RecordEntry 记录开仓：渲染当前图表并保存日志，图表数据不可用时仍保存价位信息
+func (j *Journal) RecordEntry(trade *database.Trade, signal *strategy.TradingSignal) error {
+	entry := &database.TradeJournal{
+		TradeID: trade.ID,
+		UserID:  trade.UserID,
+		Symbol:  trade.Symbol,
+		OrderID: trade.OrderID,
+		Side:    trade.Side,
+	}
+	if signal != nil {
+		entry.EntryPrice = signal.Price.InexactFloat64()
+		entry.StopLoss = signal.StopLoss.InexactFloat64()
+		entry.TakeProfit = signal.TakeProfit.InexactFloat64()
+	}
+
+	path, err := j.renderChart(trade, signal)
+	if err != nil {
+		j.logger.Warnf("Failed to render journal chart for %s order %s: %v", trade.Symbol, trade.OrderID, err)
+	}
+	entry.ImagePath = path
+
+	if err := j.repo.Create(entry); err != nil {
+		return err
+	}
+
+	j.logger.Debugf("Trade journal recorded for %s order %s (trade %d)", trade.Symbol, trade.OrderID, trade.ID)
+	return nil
+}
+
+// renderChart 渲染开仓时的图表并写入文件，返回图片路径
+func (j *Journal) renderChart(trade *database.Trade, signal *strategy.TradingSignal) (string, error) {
+	klines, tunnel, ok := j.strategies.ChartData(trade.Symbol, j.candles)
+	if !ok {
+		return "", fmt.Errorf("no chart data for %s", trade.Symbol)
+	}
+
+	var levels []chart.Level
+	if signal != nil {
+		levels = []chart.Level{
+			{Price: signal.Price, Color: chart.ColorEntry},
+			{Price: signal.StopLoss, Color: chart.ColorStopLoss},
+			{Price: signal.TakeProfit, Color: chart.ColorTakeProfit},
+		}
+	}
+
+	image, err := chart.RenderTunnel(klines, tunnel, levels)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s_%s_%d.png", trade.Symbol, trade.OrderID, time.Now().Unix())
+	path := filepath.Join(j.dir, name)
+	if err := os.WriteFile(path, image, 0644); err != nil {
+		return "", fmt.Errorf("failed to write journal chart: %w", err)
+	}
+
+	return path, nil
+}
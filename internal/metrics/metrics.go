@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// namespace 指标名前缀
+const namespace = "vegas_bot"
+
+// shutdownTimeout 关闭指标服务时等待进行中请求的最长时间
+const shutdownTimeout = 5 * time.Second
+
+// registry 指标注册表，未启动指标服务时记录指标只是内存计数
+var registry = prometheus.NewRegistry()
+
+var (
+	signalsGenerated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "signals_generated_total",
+		Help:      "Trading signals generated by strategies.",
+	}, []string{"symbol", "type"})
+
+	ordersPlaced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_placed_total",
+		Help:      "Orders accepted by the exchange (or simulated in paper mode).",
+	}, []string{"symbol", "type"})
+
+	ordersFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_failed_total",
+		Help:      "Orders rejected or failed to submit.",
+	}, []string{"symbol", "type"})
+
+	websocketReconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "websocket_reconnects_total",
+		Help:      "Market data websocket reconnect attempts.",
+	})
+
+	notificationsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_dropped_total",
+		Help:      "Notifications that still failed on a channel after all retries and were not kept for redelivery.",
+	}, []string{"channel"})
+
+	strategyLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "strategy_evaluation_seconds",
+		Help:      "Time spent evaluating a strategy on a closed candle.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"strategy"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		signalsGenerated,
+		ordersPlaced,
+		ordersFailed,
+		websocketReconnects,
+		notificationsDropped,
+		strategyLatency,
+	)
+}
+
+// RecordSignal 记录一个策略生成的信号
+func RecordSignal(symbol, signalType string) {
+	signalsGenerated.WithLabelValues(symbol, signalType).Inc()
+}
+
+// RecordOrder 记录一次下单结果，err 不为空时计为失败
+func RecordOrder(symbol, orderType string, err error) {
+	if err != nil {
+		ordersFailed.WithLabelValues(symbol, orderType).Inc()
+		return
+	}
+	ordersPlaced.WithLabelValues(symbol, orderType).Inc()
+}
+
+// RecordReconnect 记录一次WebSocket重连
+func RecordReconnect() {
+	websocketReconnects.Inc()
+}
+
+// RecordNotificationDropped 记录一条重试后仍未送达而被丢弃的通知
+func RecordNotificationDropped(channel string) {
+	notificationsDropped.WithLabelValues(channel).Inc()
+}
+
+// ObserveStrategyLatency 记录一次策略计算耗时
+func ObserveStrategyLatency(strategy string, elapsed time.Duration) {
+	strategyLatency.WithLabelValues(strategy).Observe(elapsed.Seconds())
+}
+
+// RegisterGauge 注册在抓取时读取当前值的仪表指标（如通知队列长度、持仓数），name 不含前缀
+func RegisterGauge(name, help string, value func() float64) error {
+	gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, value)
+	if err := registry.Register(gauge); err != nil {
+		return fmt.Errorf("failed to register gauge %s: %w", name, err)
+	}
+	return nil
+}
+
+// Server Prometheus指标HTTP服务，在 /metrics 输出所有已注册指标
+type Server struct {
+	logger logger.Logger
+	server *http.Server
+}
+
+// NewServer 创建监听 port 端口的指标服务
+func NewServer(port int, log logger.Logger) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	return &Server{
+		logger: log,
+		server: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Start 在后台启动指标服务，ctx 取消后关闭
+func (s *Server) Start(ctx context.Context) {
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Errorf("Metrics server stopped: %v", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			s.logger.Warnf("Failed to shut down metrics server: %v", err)
+		}
+	}()
+
+	s.logger.Infof("Metrics server listening on %s/metrics", s.server.Addr)
+}
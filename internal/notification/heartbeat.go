@@ -0,0 +1,104 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+)
+
+// HeartbeatStatus 心跳通知展示的运行状态
+type HeartbeatStatus struct {
+	Uptime          time.Duration
+	StreamConnected bool // 行情WebSocket是否已连接
+	InMaintenance   bool // 交易所接口是否处于维护
+	OpenPositions   int
+}
+
+// HeartbeatStatusFunc 获取当前运行状态
+type HeartbeatStatusFunc func() HeartbeatStatus
+
+// SetHeartbeat 设置心跳间隔及状态来源，interval 为0时不发送心跳，需在 Start 前调用
+func (nm *NotificationManager) SetHeartbeat(interval time.Duration, status HeartbeatStatusFunc) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.heartbeatInterval = interval
+	nm.heartbeatStatus = status
+}
+
+// heartbeatLoop 定期发送心跳，间隔内已发送过其他通知时跳过
+func (nm *NotificationManager) heartbeatLoop(interval time.Duration) {
+	defer nm.loops.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-nm.loopCtx.Done():
+			return
+		case <-ticker.C:
+			nm.mu.RLock()
+			recent := time.Since(nm.lastSent) < interval
+			nm.mu.RUnlock()
+			if recent {
+				nm.logger.Debug("Recent notification sent, skipping heartbeat")
+				continue
+			}
+
+			if err := nm.sendHeartbeat(); err != nil {
+				nm.logger.Errorf("Failed to send heartbeat: %v", err)
+			}
+		}
+	}
+}
+
+// sendHeartbeat 汇总运行状态并发送心跳，发送后重置信号计数
+func (nm *NotificationManager) sendHeartbeat() error {
+	nm.mu.Lock()
+	statusFn := nm.heartbeatStatus
+	signals := nm.signalsSinceHeartbeat
+	nm.signalsSinceHeartbeat = 0
+	nm.mu.Unlock()
+
+	var status HeartbeatStatus
+	if statusFn != nil {
+		status = statusFn()
+	}
+
+	stream := "✅ 正常"
+	if !status.StreamConnected {
+		stream = "❌ 断开"
+	}
+	exchange := "✅ 正常"
+	if status.InMaintenance {
+		exchange = "🔧 维护中"
+	}
+
+	message := fmt.Sprintf("运行时长: %s\n", formatUptime(status.Uptime))
+	message += fmt.Sprintf("行情连接: %s\n", stream)
+	message += fmt.Sprintf("交易所接口: %s\n", exchange)
+	message += fmt.Sprintf("当前持仓: %d\n", status.OpenPositions)
+	message += fmt.Sprintf("期间信号: %d", signals)
+
+	return nm.SendNotification(&Notification{
+		Type:     NotificationHeartbeat,
+		Priority: PriorityLow,
+		Title:    "💓 运行心跳",
+		Message:  message,
+	})
+}
+
+// formatUptime 格式化运行时长
+func formatUptime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	if days > 0 {
+		return fmt.Sprintf("%d天%d小时%d分", days, hours, minutes)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%d小时%d分", hours, minutes)
+	}
+	return fmt.Sprintf("%d分", minutes)
+}
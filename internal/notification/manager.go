@@ -2,12 +2,15 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/stream"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/telegram"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
@@ -19,14 +22,44 @@ type NotificationManager struct {
 	config      *config.Config
 	logger      logger.Logger
 	telegramBot *telegram.Bot
+	notifiers   []Notifier // 启用的通知渠道，每条通知发送到所有渠道
 	mu          sync.RWMutex
 	running     bool
+	stopped     bool // 队列已关闭，通知管理器不能再次启动
 	ctx         context.Context
 	cancel      context.CancelFunc
+	loopCtx     context.Context // 心跳、去重汇总等后台生产者的上下文，停止时先于队列关闭取消
+	loopCancel  context.CancelFunc
+	loops       sync.WaitGroup
 	queue       chan *Notification
 	workers     int
+	wg          sync.WaitGroup
+	tickerStats TickerStatsFunc
+
+	// 心跳：间隔内已发送过其他通知时跳过
+	heartbeatInterval     time.Duration
+	heartbeatStatus       HeartbeatStatusFunc
+	lastSent              time.Time
+	signalsSinceHeartbeat int
+
+	// 去重：窗口内相同的通知只发送一条，窗口结束时汇总发送被抑制的数量
+	dedupWindow time.Duration
+	dedupMu     sync.Mutex
+	duplicates  map[string]*duplicateEntry
+
+	// 重试后仍发送失败的紧急通知写入数据库，为空时不保存
+	pendingRepo *database.PendingNotificationRepository
 }
 
+// drainTimeout 停止时等待队列中积压通知发送完毕的最长时间
+const drainTimeout = 10 * time.Second
+
+// errNotDrained 停止时仍在队列中未发送的通知
+var errNotDrained = errors.New("notification not sent before shutdown")
+
+// TickerStatsFunc 获取交易对最新的24h行情统计，ok 为 false 表示暂无数据
+type TickerStatsFunc func(symbol string) (stream.TickerStats, bool)
+
 // NotificationType 通知类型
 type NotificationType int
 
@@ -37,6 +70,7 @@ const (
 	NotificationTrade
 	NotificationSignal
 	NotificationSystem
+	NotificationHeartbeat
 )
 
 // NotificationPriority 通知优先级
@@ -57,7 +91,11 @@ type Notification struct {
 	Message   string
 	Data      interface{}
 	Timestamp time.Time
+	Symbol    string  // 相关交易对，参与去重判定
 	ChatIDs   []int64 // 指定发送的聊天ID，为空则发送给所有配置的聊天
+
+	channel   string // 只发送到该渠道，为空则发送到所有渠道（用于重发上次失败的通知）
+	pendingID int    // 待重发记录ID，发送成功后删除
 }
 
 // TradeNotificationData 交易通知数据
@@ -79,21 +117,36 @@ type SignalNotificationData struct {
 	Price      decimal.Decimal
 	Confidence float64
 	Reason     string
+	Stats      *stream.TickerStats // 24h行情统计，暂无数据时为空
 }
 
 // New 创建新的通知管理器
 func New(cfg *config.Config, log logger.Logger, bot *telegram.Bot) *NotificationManager {
 	ctx, cancel := context.WithCancel(context.Background())
+	loopCtx, loopCancel := context.WithCancel(ctx)
 
-	return &NotificationManager{
+	nm := &NotificationManager{
 		config:      cfg,
 		logger:      log,
 		telegramBot: bot,
 		ctx:         ctx,
 		cancel:      cancel,
+		loopCtx:     loopCtx,
+		loopCancel:  loopCancel,
 		queue:       make(chan *Notification, 1000), // 缓冲队列
 		workers:     3,                              // 工作协程数量
+		dedupWindow: time.Duration(cfg.Notification.DedupWindowSeconds) * time.Second,
+		duplicates:  make(map[string]*duplicateEntry),
 	}
+	nm.notifiers = newNotifiers(cfg, bot, nm.formatNotificationMessage)
+	return nm
+}
+
+// SetTickerStats 设置24h行情统计来源，信号通知中附带24h涨跌幅
+func (nm *NotificationManager) SetTickerStats(fn TickerStatsFunc) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.tickerStats = fn
 }
 
 // Start 启动通知管理器
@@ -104,50 +157,130 @@ func (nm *NotificationManager) Start() error {
 	if nm.running {
 		return fmt.Errorf("notification manager is already running")
 	}
+	if nm.stopped {
+		return fmt.Errorf("notification manager has been stopped")
+	}
 
 	// 启动工作协程
 	for i := 0; i < nm.workers; i++ {
+		nm.wg.Add(1)
 		go nm.worker(i)
 	}
 
+	if nm.heartbeatInterval > 0 {
+		nm.loops.Add(1)
+		go nm.heartbeatLoop(nm.heartbeatInterval)
+	}
+
+	if nm.dedupWindow > 0 {
+		nm.loops.Add(1)
+		go nm.dedupLoop()
+	}
+
 	nm.running = true
+	nm.replayPending()
 	nm.logger.Info("Notification manager started successfully")
 
 	return nil
 }
 
-// Stop 停止通知管理器
+// Stop 停止通知管理器：不再接收新通知，先停止心跳和去重汇总等后台生产者再关闭队列，
+// 工作协程在 drainTimeout 内发送完积压的通知；超时后中止重试，未发出的紧急通知保存待下次启动重发
 func (nm *NotificationManager) Stop() error {
 	nm.mu.Lock()
-	defer nm.mu.Unlock()
-
 	if !nm.running {
+		nm.mu.Unlock()
 		return nil
 	}
+	nm.running = false
+	nm.mu.Unlock()
 
-	// 取消上下文
-	nm.cancel()
+	nm.loopCancel()
+	nm.loops.Wait()
 
-	// 关闭队列
+	// 发送尚未结束的去重窗口汇总，然后关闭队列，工作协程处理完剩余通知后退出
+	nm.mu.Lock()
+	for _, summary := range nm.flushDuplicates(true) {
+		nm.enqueue(summary)
+	}
+	backlog := len(nm.queue)
+	nm.stopped = true
 	close(nm.queue)
+	nm.mu.Unlock()
+
+	if backlog > 0 {
+		nm.logger.Infof("Draining %d queued notifications before shutdown", backlog)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		nm.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(drainTimeout):
+		nm.logger.Warnf("Notification queue not drained within %v, aborting pending sends", drainTimeout)
+		nm.cancel()
+		<-done
+	}
+
+	// 取消上下文
+	nm.cancel()
+	nm.saveUndrained()
 
-	nm.running = false
 	nm.logger.Info("Notification manager stopped")
 
 	return nil
 }
 
-// SendNotification 发送通知
+// saveUndrained 处理停止时未能发出的通知：紧急通知保存待重发，其余计入丢弃指标
+func (nm *NotificationManager) saveUndrained() {
+	undrained := 0
+	for notification := range nm.queue {
+		undrained++
+		for _, notifier := range nm.notifiers {
+			if notification.channel != "" && notifier.Name() != notification.channel {
+				continue
+			}
+			nm.handleUndelivered(notifier.Name(), notification, errNotDrained)
+		}
+	}
+
+	if undrained > 0 {
+		nm.logger.Warnf("%d notifications were not sent before shutdown", undrained)
+	}
+}
+
+// SendNotification 发送通知。检查运行状态和入队都持有读锁，Stop 需取得写锁才能关闭队列，
+// 因此不会向已关闭的队列发送
 func (nm *NotificationManager) SendNotification(notification *Notification) error {
 	nm.mu.RLock()
-	running := nm.running
-	nm.mu.RUnlock()
+	defer nm.mu.RUnlock()
 
-	if !running {
+	if !nm.running {
 		return fmt.Errorf("notification manager is not running")
 	}
 
-	notification.Timestamp = time.Now()
+	admit, summary := nm.admitNotification(notification)
+	if summary != nil {
+		nm.enqueue(summary)
+	}
+	if !admit {
+		nm.logger.Debugf("Duplicate notification suppressed: %s", notification.Title)
+		return nil
+	}
+
+	return nm.enqueue(notification)
+}
+
+// enqueue 将通知放入发送队列，队列已满时丢弃。重发的通知保留原始时间。
+// 调用方需持有 nm.mu（读锁或写锁）并确认队列未关闭
+func (nm *NotificationManager) enqueue(notification *Notification) error {
+	if notification.Timestamp.IsZero() {
+		notification.Timestamp = time.Now()
+	}
 
 	select {
 	case nm.queue <- notification:
@@ -201,6 +334,7 @@ func (nm *NotificationManager) SendTradeNotification(trade *trading.TradeResult)
 		Title:    title,
 		Message:  message,
 		Data:     data,
+		Symbol:   trade.Symbol,
 	}
 
 	return nm.SendNotification(notification)
@@ -216,6 +350,15 @@ func (nm *NotificationManager) SendSignalNotification(signal *strategy.TradingSi
 		Reason:     signal.Reason,
 	}
 
+	nm.mu.RLock()
+	tickerStats := nm.tickerStats
+	nm.mu.RUnlock()
+	if tickerStats != nil {
+		if stats, ok := tickerStats(signal.Symbol); ok {
+			data.Stats = &stats
+		}
+	}
+
 	var title string
 	var priority NotificationPriority
 
@@ -239,12 +382,17 @@ func (nm *NotificationManager) SendSignalNotification(signal *strategy.TradingSi
 
 	message := nm.formatSignalMessage(data)
 
+	nm.mu.Lock()
+	nm.signalsSinceHeartbeat++
+	nm.mu.Unlock()
+
 	notification := &Notification{
 		Type:     NotificationSignal,
 		Priority: priority,
 		Title:    title,
 		Message:  message,
 		Data:     data,
+		Symbol:   signal.Symbol,
 	}
 
 	return nm.SendNotification(notification)
@@ -265,6 +413,9 @@ func (nm *NotificationManager) SendSystemNotification(level string, title, messa
 	case "error":
 		notificationType = NotificationError
 		priority = PriorityHigh
+	case "critical":
+		notificationType = NotificationError
+		priority = PriorityCritical
 	default:
 		notificationType = NotificationSystem
 		priority = PriorityNormal
@@ -282,6 +433,7 @@ func (nm *NotificationManager) SendSystemNotification(level string, title, messa
 
 // worker 工作协程
 func (nm *NotificationManager) worker(id int) {
+	defer nm.wg.Done()
 	nm.logger.Debugf("Notification worker %d started", id)
 
 	for {
@@ -302,23 +454,32 @@ func (nm *NotificationManager) worker(id int) {
 	}
 }
 
-// processNotification 处理通知
+// processNotification 处理通知：发送到所有启用的渠道，失败时重试，单个渠道失败不影响其他渠道，全部失败时返回错误
 func (nm *NotificationManager) processNotification(notification *Notification) error {
-	// 格式化消息
-	fullMessage := nm.formatNotificationMessage(notification)
-
-	// 确定发送目标
-	chatIDs := notification.ChatIDs
-	if len(chatIDs) == 0 {
-		chatIDs = nm.config.Telegram.ChatIDs
-	}
-
-	// 发送到所有目标聊天
-	for _, chatID := range chatIDs {
-		if err := nm.telegramBot.SendMessageToChat(chatID, fullMessage); err != nil {
-			nm.logger.Errorf("Failed to send notification to chat %d: %v", chatID, err)
+	targets, failed := 0, 0
+	for _, notifier := range nm.notifiers {
+		if notification.channel != "" && notifier.Name() != notification.channel {
 			continue
 		}
+		targets++
+		if err := nm.sendWithRetry(notifier, notification); err != nil {
+			nm.logger.Errorf("Failed to send notification via %s after %d attempts: %v", notifier.Name(), sendAttempts, err)
+			nm.handleUndelivered(notifier.Name(), notification, err)
+			failed++
+		}
+	}
+	if targets > 0 && failed == targets {
+		return fmt.Errorf("all %d notification channels failed for %q", failed, notification.Title)
+	}
+	if notification.pendingID != 0 {
+		nm.pendingDelivered(notification)
+	}
+
+	// 心跳本身不计为活动，避免抑制下一次心跳
+	if notification.Type != NotificationHeartbeat {
+		nm.mu.Lock()
+		nm.lastSent = time.Now()
+		nm.mu.Unlock()
 	}
 
 	nm.logger.Debugf("Notification sent: %s", notification.Title)
@@ -390,6 +551,14 @@ func (nm *NotificationManager) formatSignalMessage(data *SignalNotificationData)
 	message += fmt.Sprintf("信号类型: %s\n", data.SignalType)
 	message += fmt.Sprintf("价格: %s\n", data.Price.String())
 	message += fmt.Sprintf("置信度: %.2f%%\n", data.Confidence*100)
+	if data.Stats != nil {
+		sign := ""
+		if !data.Stats.PriceChangePercent.IsNegative() {
+			sign = "+"
+		}
+		message += fmt.Sprintf("24h涨跌: %s%s%% (高 %s / 低 %s)\n", sign,
+			data.Stats.PriceChangePercent.StringFixed(2), data.Stats.HighPrice.String(), data.Stats.LowPrice.String())
+	}
 	message += fmt.Sprintf("原因: %s", data.Reason)
 
 	return message
@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// countingNotifier 统计发送的通知数量
+type countingNotifier struct {
+	sent atomic.Int64
+}
+
+func (c *countingNotifier) Name() string { return "counting" }
+
+func (c *countingNotifier) Send(notification *Notification) error {
+	c.sent.Add(1)
+	return nil
+}
+
+// TestSendDuringStop 停止过程中并发发送通知：不得向已关闭的队列发送（否则 panic），
+// 被接受的通知都在停止前发送完毕，停止后的发送返回错误。需配合 -race 运行
+func TestSendDuringStop(t *testing.T) {
+	const senders = 8
+
+	for round := 0; round < 20; round++ {
+		nm := New(&config.Config{}, logger.NewLoggerWithLevel("error"), nil)
+		notifier := &countingNotifier{}
+		nm.notifiers = []Notifier{notifier}
+		if err := nm.Start(); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+
+		var accepted atomic.Int64
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := 0; i < senders; i++ {
+			wg.Add(1)
+			go func(sender int) {
+				defer wg.Done()
+				<-start
+				for n := 0; ; n++ {
+					if err := nm.SendSystemNotification("info", fmt.Sprintf("sender %d #%d", sender, n), "stress"); err != nil {
+						if !nm.IsRunning() {
+							return
+						}
+						continue // 队列已满
+					}
+					accepted.Add(1)
+				}
+			}(i)
+		}
+
+		close(start)
+		if err := nm.Stop(); err != nil {
+			t.Fatalf("Stop: %v", err)
+		}
+		wg.Wait()
+
+		if sent := notifier.sent.Load(); sent != accepted.Load() {
+			t.Fatalf("round %d: sent %d notifications, accepted %d", round, sent, accepted.Load())
+		}
+		if err := nm.SendSystemNotification("info", "after stop", "stress"); err == nil {
+			t.Fatalf("round %d: send after Stop accepted", round)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/telegram"
+)
+
+// webhookTimeout Webhook请求超时时间
+const webhookTimeout = 10 * time.Second
+
+// Notifier 通知发送渠道，由工作协程调用，需自行处理超时
+type Notifier interface {
+	Name() string
+	Send(notification *Notification) error
+}
+
+// MessageFormatter 将通知格式化为纯文本消息
+type MessageFormatter func(notification *Notification) string
+
+// newNotifiers 按配置创建启用的通知渠道
+func newNotifiers(cfg *config.Config, bot *telegram.Bot, format MessageFormatter) []Notifier {
+	var notifiers []Notifier
+	for _, channel := range cfg.Notification.Channels {
+		switch channel {
+		case config.ChannelTelegram:
+			notifiers = append(notifiers, NewTelegramNotifier(bot, cfg.Telegram.ChatIDs, cfg.Notification.ChatRateLimit, format))
+		case config.ChannelWebhook:
+			for _, webhook := range cfg.Notification.Webhooks {
+				notifiers = append(notifiers, NewWebhookNotifier(webhook, format))
+			}
+		case config.ChannelEmail:
+			notifiers = append(notifiers, NewEmailNotifier(cfg.Notification.Email, format))
+		}
+	}
+	return notifiers
+}
+
+// TelegramNotifier 通过Telegram机器人发送通知，未指定聊天时发送给所有配置的聊天
+type TelegramNotifier struct {
+	bot     *telegram.Bot
+	chatIDs []int64
+	limiter *chatLimiter // 每个聊天的发送频率限制，为空时不限制
+	format  MessageFormatter
+}
+
+// NewTelegramNotifier 创建Telegram通知渠道，每个聊天每秒最多发送 rateLimit 条通知（0表示不限制）
+func NewTelegramNotifier(bot *telegram.Bot, chatIDs []int64, rateLimit float64, format MessageFormatter) *TelegramNotifier {
+	return &TelegramNotifier{bot: bot, chatIDs: chatIDs, limiter: newChatLimiter(rateLimit), format: format}
+}
+
+// Name 渠道名称
+func (t *TelegramNotifier) Name() string {
+	return config.ChannelTelegram
+}
+
+// Send 发送到所有目标聊天，部分聊天失败时仍发送其余聊天并返回汇总错误
+func (t *TelegramNotifier) Send(notification *Notification) error {
+	chatIDs := notification.ChatIDs
+	if len(chatIDs) == 0 {
+		chatIDs = t.chatIDs
+	}
+
+	message := t.format(notification)
+	var errs []error
+	for _, chatID := range chatIDs {
+		t.limiter.wait(chatID)
+		if err := t.bot.SendMessageToChat(chatID, message); err != nil {
+			errs = append(errs, fmt.Errorf("chat %d: %w", chatID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WebhookNotifier 通过Webhook发送通知（Discord、Slack或通用JSON）
+type WebhookNotifier struct {
+	url    string
+	format string
+	text   MessageFormatter
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建Webhook通知渠道
+func NewWebhookNotifier(cfg config.WebhookConfig, format MessageFormatter) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    cfg.URL,
+		format: cfg.Format,
+		text:   format,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Name 渠道名称
+func (w *WebhookNotifier) Name() string {
+	return config.ChannelWebhook + ":" + w.format
+}
+
+// webhookPayload 通用格式的Webhook消息
+type webhookPayload struct {
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Text      string    `json:"text"`
+	Type      int       `json:"type"`
+	Priority  int       `json:"priority"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Send 按Webhook格式发送通知，非2xx响应视为失败
+func (w *WebhookNotifier) Send(notification *Notification) error {
+	text := w.text(notification)
+
+	var payload interface{}
+	switch w.format {
+	case config.WebhookDiscord:
+		payload = map[string]string{"content": text}
+	case config.WebhookSlack:
+		payload = map[string]string{"text": text}
+	default:
+		payload = webhookPayload{
+			Title:     notification.Title,
+			Message:   notification.Message,
+			Text:      text,
+			Type:      int(notification.Type),
+			Priority:  int(notification.Priority),
+			Timestamp: notification.Timestamp,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// EmailNotifier 通过SMTP邮件发送通知
+type EmailNotifier struct {
+	cfg    config.EmailConfig
+	format MessageFormatter
+}
+
+// NewEmailNotifier 创建邮件通知渠道
+func NewEmailNotifier(cfg config.EmailConfig, format MessageFormatter) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg, format: format}
+}
+
+// Name 渠道名称
+func (e *EmailNotifier) Name() string {
+	return config.ChannelEmail
+}
+
+// Send 以纯文本邮件发送通知，标题作为邮件主题
+func (e *EmailNotifier) Send(notification *Notification) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", notification.Timestamp.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(e.format(notification), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	if err := smtp.SendMail(addr, auth, e.cfg.From, e.cfg.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
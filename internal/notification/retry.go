@@ -0,0 +1,149 @@
+package notification
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/metrics"
+)
+
+const (
+	// sendAttempts 每个渠道发送一条通知的最多尝试次数
+	sendAttempts = 3
+	// sendRetryBackoff 首次重试前的等待时间，之后每次翻倍
+	sendRetryBackoff = 2 * time.Second
+	// pendingReplayLimit 启动时最多重发的待重发通知数，其余留待下次启动
+	pendingReplayLimit = 100
+)
+
+// SetPendingStore 设置待重发通知的存储：重试后仍失败的紧急通知写入数据库，启动时重发，需在 Start 前调用
+func (nm *NotificationManager) SetPendingStore(db *database.Database) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.pendingRepo = database.NewPendingNotificationRepository(db.GetDB())
+}
+
+// sendWithRetry 通过渠道发送通知，失败时按指数退避重试，通知管理器停止时不再等待
+func (nm *NotificationManager) sendWithRetry(notifier Notifier, notification *Notification) error {
+	backoff := sendRetryBackoff
+	var err error
+	for attempt := 1; attempt <= sendAttempts; attempt++ {
+		if err = notifier.Send(notification); err == nil {
+			return nil
+		}
+		if attempt == sendAttempts {
+			break
+		}
+
+		nm.logger.Warnf("Failed to send notification via %s (attempt %d/%d), retrying in %v: %v",
+			notifier.Name(), attempt, sendAttempts, backoff, err)
+		select {
+		case <-nm.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// handleUndelivered 处理重试后仍发送失败的通知：紧急通知保存到数据库待下次启动重发，其余丢弃并计入指标
+func (nm *NotificationManager) handleUndelivered(channel string, notification *Notification, sendErr error) {
+	// 重发的通知保留在数据库中，下次启动继续重发
+	if notification.pendingID != 0 {
+		if err := nm.pendingRepo.RecordFailure(notification.pendingID, sendErr.Error()); err != nil {
+			nm.logger.Errorf("Failed to record pending notification failure: %v", err)
+		}
+		return
+	}
+
+	if notification.Priority == PriorityCritical && nm.pendingRepo != nil {
+		chatIDs, err := json.Marshal(notification.ChatIDs)
+		if err == nil {
+			err = nm.pendingRepo.Create(&database.PendingNotification{
+				Channel:   channel,
+				Type:      int(notification.Type),
+				Priority:  int(notification.Priority),
+				Title:     notification.Title,
+				Message:   notification.Message,
+				Symbol:    notification.Symbol,
+				ChatIDs:   string(chatIDs),
+				Attempts:  1,
+				LastError: sendErr.Error(),
+				CreatedAt: notification.Timestamp,
+			})
+		}
+		if err == nil {
+			nm.logger.Warnf("Critical notification %q saved for redelivery via %s on next startup", notification.Title, channel)
+			return
+		}
+		nm.logger.Errorf("Failed to save critical notification for redelivery: %v", err)
+	}
+
+	metrics.RecordNotificationDropped(channel)
+}
+
+// replayPending 将上次运行未送达的通知重新放入队列，只发送到当时失败的渠道。
+// 渠道已不再启用的通知保留在数据库中
+func (nm *NotificationManager) replayPending() {
+	if nm.pendingRepo == nil {
+		return
+	}
+
+	pendings, err := nm.pendingRepo.GetAll(pendingReplayLimit)
+	if err != nil {
+		nm.logger.Errorf("Failed to load pending notifications: %v", err)
+		return
+	}
+
+	replayed := 0
+	for _, pending := range pendings {
+		if !nm.hasNotifier(pending.Channel) {
+			nm.logger.Warnf("Pending notification %d targets disabled channel %s, keeping it", pending.ID, pending.Channel)
+			continue
+		}
+
+		notification := &Notification{
+			Type:      NotificationType(pending.Type),
+			Priority:  NotificationPriority(pending.Priority),
+			Title:     pending.Title,
+			Message:   pending.Message,
+			Timestamp: pending.CreatedAt,
+			Symbol:    pending.Symbol,
+			channel:   pending.Channel,
+			pendingID: pending.ID,
+		}
+		if pending.ChatIDs != "" {
+			if err := json.Unmarshal([]byte(pending.ChatIDs), &notification.ChatIDs); err != nil {
+				nm.logger.Warnf("Invalid chat ids on pending notification %d: %v", pending.ID, err)
+			}
+		}
+
+		if err := nm.enqueue(notification); err != nil {
+			break
+		}
+		replayed++
+	}
+
+	if replayed > 0 {
+		nm.logger.Infof("Redelivering %d pending notifications", replayed)
+	}
+}
+
+// hasNotifier 检查渠道是否启用
+func (nm *NotificationManager) hasNotifier(channel string) bool {
+	for _, notifier := range nm.notifiers {
+		if notifier.Name() == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingDelivered 重发成功后删除待重发记录
+func (nm *NotificationManager) pendingDelivered(notification *Notification) {
+	if err := nm.pendingRepo.Delete(notification.pendingID); err != nil {
+		nm.logger.Errorf("Failed to delete delivered pending notification %d: %v", notification.pendingID, err)
+	}
+}
@@ -0,0 +1,152 @@
+package notification
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// dedupFlushInterval 检查去重窗口是否结束的间隔
+const dedupFlushInterval = time.Second
+
+// duplicateEntry 去重窗口内的一组相同通知
+type duplicateEntry struct {
+	firstSeen  time.Time     // 窗口开始时间，即首条通知发送时间
+	suppressed int           // 窗口内被抑制的重复通知数
+	latest     *Notification // 最近一条被抑制的通知，用于生成汇总
+}
+
+// dedupKey 相同通知的判定依据：类型、标题、交易对及发送目标
+func dedupKey(notification *Notification) string {
+	return fmt.Sprintf("%d|%s|%s|%v", notification.Type, notification.Title, notification.Symbol, notification.ChatIDs)
+}
+
+// admitNotification 判断通知是否应发送：去重窗口内的重复通知被抑制并计数。
+// 窗口已结束但尚未汇总的重复通知在 summary 中返回，需先于本条通知发送
+func (nm *NotificationManager) admitNotification(notification *Notification) (admit bool, summary *Notification) {
+	if nm.dedupWindow <= 0 || notification.Type == NotificationHeartbeat {
+		return true, nil
+	}
+
+	key := dedupKey(notification)
+	now := time.Now()
+
+	nm.dedupMu.Lock()
+	defer nm.dedupMu.Unlock()
+
+	if entry, exists := nm.duplicates[key]; exists {
+		if now.Sub(entry.firstSeen) < nm.dedupWindow {
+			entry.suppressed++
+			entry.latest = notification
+			return false, nil
+		}
+		summary = nm.duplicateSummary(entry)
+	}
+
+	nm.duplicates[key] = &duplicateEntry{firstSeen: now}
+	return true, summary
+}
+
+// flushDuplicates 移除已结束的去重窗口（all 为 true 时移除全部），返回有重复通知的窗口汇总
+func (nm *NotificationManager) flushDuplicates(all bool) []*Notification {
+	now := time.Now()
+
+	nm.dedupMu.Lock()
+	defer nm.dedupMu.Unlock()
+
+	var summaries []*Notification
+	for key, entry := range nm.duplicates {
+		if !all && now.Sub(entry.firstSeen) < nm.dedupWindow {
+			continue
+		}
+		delete(nm.duplicates, key)
+		if summary := nm.duplicateSummary(entry); summary != nil {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
+
+// duplicateSummary 将窗口内被抑制的重复通知合并为一条汇总，如 "📈 买入信号 (x5)"，没有重复时返回 nil
+func (nm *NotificationManager) duplicateSummary(entry *duplicateEntry) *Notification {
+	if entry.suppressed == 0 {
+		return nil
+	}
+
+	latest := entry.latest
+	return &Notification{
+		Type:     latest.Type,
+		Priority: latest.Priority,
+		Title:    fmt.Sprintf("%s (x%d)", latest.Title, entry.suppressed),
+		Message: fmt.Sprintf("%d秒内另有 %d 条相同通知已合并，最近一条：\n%s",
+			int(nm.dedupWindow.Seconds()), entry.suppressed, latest.Message),
+		Data:    latest.Data,
+		Symbol:  latest.Symbol,
+		ChatIDs: latest.ChatIDs,
+	}
+}
+
+// dedupLoop 定期发送已结束去重窗口的汇总，通知管理器停止后不再发送
+func (nm *NotificationManager) dedupLoop() {
+	defer nm.loops.Done()
+
+	ticker := time.NewTicker(dedupFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-nm.loopCtx.Done():
+			return
+		case <-ticker.C:
+			summaries := nm.flushDuplicates(false)
+			if len(summaries) == 0 {
+				continue
+			}
+
+			// 持有读锁发送，避免与 Stop 关闭队列并发
+			nm.mu.RLock()
+			if nm.running {
+				for _, summary := range summaries {
+					nm.enqueue(summary)
+				}
+			}
+			nm.mu.RUnlock()
+		}
+	}
+}
+
+// chatLimiter 按聊天限制消息发送频率，多个工作协程共享，同一聊天的消息按最小间隔依次放行
+type chatLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[int64]time.Time // 各聊天下一条消息最早可发送的时间
+}
+
+// newChatLimiter 创建每个聊天每秒最多发送 perSecond 条消息的限流器，perSecond 不大于0时返回 nil（不限流）
+func newChatLimiter(perSecond float64) *chatLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &chatLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		next:     make(map[int64]time.Time),
+	}
+}
+
+// wait 等待直到可以向聊天发送下一条消息
+func (l *chatLimiter) wait(chatID int64) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[chatID]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[chatID] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(slot))
+}
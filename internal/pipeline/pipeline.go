@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// Stage 数据流阶段（行情 → 策略 → 执行）
+type Stage int
+
+const (
+	StageCandleReceived   Stage = iota // 收到已收盘K线
+	StageSignalGenerated               // 策略生成信号
+	StageSignalDispatched              // 信号已分发给用户执行
+	StageOrderPlaced                   // 订单已提交（或提交失败）
+	StageFill                          // 订单成交
+)
+
+// String 获取阶段名称
+func (s Stage) String() string {
+	switch s {
+	case StageCandleReceived:
+		return "candle_received"
+	case StageSignalGenerated:
+		return "signal_generated"
+	case StageSignalDispatched:
+		return "signal_dispatched"
+	case StageOrderPlaced:
+		return "order_placed"
+	case StageFill:
+		return "fill"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 数据流事件
+type Event struct {
+	Stage     Stage
+	Symbol    string
+	Interval  string
+	Strategy  string
+	UserID    int64
+	Kline     *strategy.KlineData
+	Signal    *strategy.TradingSignal
+	OrderID   string
+	Err       error
+	Timestamp time.Time
+}
+
+// Subscriber 事件订阅函数，同步调用，需快速返回
+type Subscriber func(event Event)
+
+// Bus 事件总线
+type Bus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]Subscriber
+}
+
+// NewBus 创建新的事件总线
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[int]Subscriber),
+	}
+}
+
+// Subscribe 订阅所有阶段事件，返回取消订阅函数
+func (b *Bus) Subscribe(fn Subscriber) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = fn
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+// Publish 发布事件，nil 总线上发布为空操作
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	subscribers := make([]Subscriber, 0, len(b.subscribers))
+	for _, fn := range b.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	b.mu.RUnlock()
+
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
@@ -0,0 +1,74 @@
+package strategy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// emittedSignal 某交易对某方向最近一次放行的信号
+type emittedSignal struct {
+	price     decimal.Decimal
+	timestamp time.Time
+}
+
+// signalKey 信号去重键，同一交易对的多空信号分别计算冷却
+func signalKey(symbol string, signalType SignalType) string {
+	return fmt.Sprintf("%s:%d", symbol, signalType)
+}
+
+// SetSignalCooldown 设置重复信号冷却：同一交易对同方向的信号在 window 内且价格变动不足 minMovePercent 时不再分发。
+// window 为零表示不去重；minMovePercent 为零表示冷却期内一律抑制
+func (sm *StrategyManager) SetSignalCooldown(window time.Duration, minMovePercent float64) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.signalCooldown = window
+	sm.signalMinMove = minMovePercent
+}
+
+// allowSignal 判断信号是否可以分发，放行时记录为该交易对该方向的最近信号。
+// 以信号K线时间计算冷却，回填历史K线时同样生效
+func (sm *StrategyManager) allowSignal(signal *TradingSignal) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.signalCooldown <= 0 {
+		return true
+	}
+
+	key := signalKey(signal.Symbol, signal.Type)
+	if last, exists := sm.lastSignals[key]; exists {
+		elapsed := signal.Timestamp.Sub(last.timestamp)
+		if elapsed >= 0 && elapsed < sm.signalCooldown && !sm.movedEnough(last.price, signal.Price) {
+			sm.logger.Infof("Suppressed repeated %s signal for %s: last at %s (%.4f), cooldown %v",
+				signal.Type.String(), signal.Symbol, last.timestamp.Format("2006-01-02 15:04"),
+				last.price.InexactFloat64(), sm.signalCooldown)
+			return false
+		}
+	}
+
+	sm.lastSignals[key] = emittedSignal{price: signal.Price, timestamp: signal.Timestamp}
+	return true
+}
+
+// movedEnough 价格相对上次信号的变动是否达到最小幅度
+func (sm *StrategyManager) movedEnough(lastPrice, price decimal.Decimal) bool {
+	if sm.signalMinMove <= 0 || lastPrice.IsZero() {
+		return false
+	}
+	move := price.Sub(lastPrice).Abs().Div(lastPrice).InexactFloat64() * 100
+	return move >= sm.signalMinMove
+}
+
+// clearSignalHistory 清除该交易对的信号冷却记录
+func (sm *StrategyManager) clearSignalHistory(symbol string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	for key := range sm.lastSignals {
+		if strings.HasPrefix(key, symbol+":") {
+			delete(sm.lastSignals, key)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// repeatingStrategy 每根K线都以收盘价生成同方向信号的测试策略
+type repeatingStrategy struct{}
+
+func (repeatingStrategy) GenerateSignal(klines []KlineData) *TradingSignal {
+	kline := klines[len(klines)-1]
+	return &TradingSignal{Symbol: kline.Symbol, Type: SignalBuy, Price: kline.Close, Timestamp: kline.CloseTime}
+}
+
+func (repeatingStrategy) GetStrategyInfo() StrategyInfo { return StrategyInfo{} }
+
+func (repeatingStrategy) ValidateParameters() error { return nil }
+
+// testKline 指定收盘价和开盘时间的15M K线
+func testKline(close string, open time.Time) *KlineData {
+	price := decimal.RequireFromString(close)
+	return &KlineData{
+		Symbol:    "BTCUSDT",
+		Open:      price,
+		High:      price.Add(decimal.NewFromInt(10)),
+		Low:       price.Sub(decimal.NewFromInt(10)),
+		Close:     price,
+		Volume:    decimal.NewFromInt(1),
+		OpenTime:  open,
+		CloseTime: open.Add(15*time.Minute - time.Millisecond),
+	}
+}
+
+// countSignals 依次处理K线，返回分发的信号数
+func countSignals(t *testing.T, sm *StrategyManager, klines []*KlineData) int {
+	t.Helper()
+
+	dispatched := 0
+	sm.SetSignalHandler(func(result *StrategyResult) { dispatched++ })
+	for _, kline := range klines {
+		if _, err := sm.ProcessKlineData(kline); err != nil {
+			t.Fatalf("ProcessKlineData: %v", err)
+		}
+	}
+	return dispatched
+}
+
+func TestSignalCooldownSuppressesRepeatedCandles(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		minMove float64
+		klines  []*KlineData
+		want    int
+	}{
+		{
+			name:   "same candle delivered repeatedly",
+			klines: []*KlineData{testKline("30000", from), testKline("30000", from), testKline("30000", from), testKline("30000", from)},
+			want:   1,
+		},
+		{
+			name: "identical candles within cooldown",
+			klines: []*KlineData{
+				testKline("30000", from), testKline("30000", from.Add(15*time.Minute)),
+				testKline("30000", from.Add(30*time.Minute)), testKline("30000", from.Add(45*time.Minute)),
+			},
+			want: 1,
+		},
+		{
+			name:   "identical candle after cooldown",
+			klines: []*KlineData{testKline("30000", from), testKline("30000", from.Add(45*time.Minute)), testKline("30000", from.Add(time.Hour))},
+			want:   2,
+		},
+		{
+			name:    "price moved beyond minimum within cooldown",
+			minMove: 1,
+			klines:  []*KlineData{testKline("30000", from), testKline("30100", from.Add(15*time.Minute)), testKline("30400", from.Add(30*time.Minute))},
+			want:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewStrategyManager(logger.NewLoggerWithLevel("error"))
+			if err := sm.RegisterStrategy("repeating", repeatingStrategy{}); err != nil {
+				t.Fatalf("RegisterStrategy: %v", err)
+			}
+			sm.SetSignalCooldown(time.Hour, tt.minMove)
+
+			if got := countSignals(t, sm, tt.klines); got != tt.want {
+				t.Errorf("dispatched %d signals, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignalCooldownDisabled(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sm := NewStrategyManager(logger.NewLoggerWithLevel("error"))
+	if err := sm.RegisterStrategy("repeating", repeatingStrategy{}); err != nil {
+		t.Fatalf("RegisterStrategy: %v", err)
+	}
+
+	klines := []*KlineData{testKline("30000", from), testKline("30000", from.Add(15*time.Minute)), testKline("30000", from.Add(30*time.Minute))}
+	if got := countSignals(t, sm, klines); got != len(klines) {
+		t.Errorf("dispatched %d signals without cooldown, want %d", got, len(klines))
+	}
+}
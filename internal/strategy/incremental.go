@@ -0,0 +1,156 @@
+package strategy
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// emaPrecision EMA每次更新后保留的小数位数，与 decimal.DivisionPrecision 一致。
+// 不取整时乘法的精度随K线数无限增长，增量状态在整个进程生命周期内越算越慢
+const emaPrecision = 16
+
+// emaState 单条EMA的增量计算状态：前 period 个价格的SMA作为初值，之后每个新价格 O(1) 更新，
+// 对同一价格序列与 CalculateEMA 的结果一致
+type emaState struct {
+	period int
+	alpha  decimal.Decimal
+	count  int
+	sum    decimal.Decimal
+	value  decimal.Decimal
+}
+
+// newEMAState 创建EMA增量状态
+func newEMAState(period int) emaState {
+	return emaState{
+		period: period,
+		alpha:  decimal.NewFromFloat(2.0 / float64(period+1)),
+	}
+}
+
+// update 加入一个新价格，返回EMA是否已有值
+func (e *emaState) update(price decimal.Decimal) bool {
+	e.count++
+	switch {
+	case e.count < e.period:
+		e.sum = e.sum.Add(price)
+		return false
+	case e.count == e.period:
+		e.value = e.sum.Add(price).Div(decimal.NewFromInt(int64(e.period)))
+	default:
+		e.value = price.Mul(e.alpha).Add(e.value.Mul(decimal.NewFromInt(1).Sub(e.alpha))).Round(emaPrecision)
+	}
+	return true
+}
+
+// tunnelState 单个交易对单个时间周期的增量隧道状态：保存各EMA的最新值和趋势持续情况，
+// 新的已收盘K线到来时只做一次更新而不重算整个缓存。缓存裁剪掉的早期K线仍计入EMA，
+// 因此长周期EMA与对裁剪后缓存的批量计算略有差异
+type tunnelState struct {
+	source       PriceSource
+	emas         [5]emaState // EMA12、EMA144、EMA169、EMA288、EMA338
+	lastOpenTime time.Time   // 最近一根已计入的K线开盘时间
+	ready        bool        // 所有EMA均已有值
+	latest       TunnelData  // 最新一根K线的隧道数据
+	trendAge     int         // 最新趋势已连续保持的K线数
+	trendSince   time.Time   // 最新趋势开始的K线开盘时间
+}
+
+// newTunnelState 按当前策略参数创建空的隧道状态
+func (v *VegasTunnelStrategy) newTunnelState() *tunnelState {
+	state := &tunnelState{source: v.priceSource}
+	for i, period := range v.emaPeriods() {
+		state.emas[i] = newEMAState(period)
+	}
+	return state
+}
+
+// emaPeriods 隧道各EMA周期，顺序与 tunnelState.emas 一致
+func (v *VegasTunnelStrategy) emaPeriods() [5]int {
+	return [5]int{v.shortEMAPeriod, v.midTunnel1Period, v.midTunnel2Period, v.longTunnel1Period, v.longTunnel2Period}
+}
+
+// matches 状态是否按当前的EMA周期和价格来源计算，参数修改后需重建
+func (s *tunnelState) matches(v *VegasTunnelStrategy) bool {
+	if s.source != v.priceSource {
+		return false
+	}
+	for i, period := range v.emaPeriods() {
+		if s.emas[i].period != period {
+			return false
+		}
+	}
+	return true
+}
+
+// update 计入一根新的已收盘K线
+func (s *tunnelState) update(kline KlineData) {
+	s.lastOpenTime = kline.OpenTime
+
+	price := s.source.Price(kline)
+	ready := true
+	for i := range s.emas {
+		if !s.emas[i].update(price) {
+			ready = false
+		}
+	}
+	if !ready {
+		return
+	}
+
+	tunnel := newTunnelData(s.emas[0].value, s.emas[1].value, s.emas[2].value, s.emas[3].value, s.emas[4].value)
+	if s.ready && tunnel.TrendDirection == s.latest.TrendDirection {
+		s.trendAge++
+	} else {
+		s.trendAge = 1
+		s.trendSince = kline.OpenTime
+	}
+	s.latest = tunnel
+	s.ready = true
+}
+
+// syncTunnelState 将增量状态追到K线缓存的最新一根：只计入比状态更新的K线；
+// 状态缺失（如缓存中间插入或替换了K线）或参数已变化时按整个缓存重建
+func (v *VegasTunnelStrategy) syncTunnelState(state *tunnelState, klines []KlineData) *tunnelState {
+	if state == nil || !state.matches(v) {
+		state = v.newTunnelState()
+	}
+
+	start := len(klines)
+	for start > 0 && klines[start-1].OpenTime.After(state.lastOpenTime) {
+		start--
+	}
+	for _, kline := range klines[start:] {
+		state.update(kline)
+	}
+	return state
+}
+
+// appendsKline K线是否追加在缓存末尾；否则插入或替换了已计入增量状态的K线，状态需要重建
+func appendsKline(klines []KlineData, kline KlineData) bool {
+	return len(klines) == 0 || klines[len(klines)-1].OpenTime.Before(kline.OpenTime)
+}
+
+// liveTunnel 增量更新并返回交易对某时间周期（"15m" 或 "4h"）的隧道状态，数据不足以计算隧道时 ok 为 false
+func (v *VegasTunnelStrategy) liveTunnel(symbol, timeframe string) (tunnelState, bool) {
+	v.bufferMu.Lock()
+	defer v.bufferMu.Unlock()
+
+	buf, exists := v.buffers[symbol]
+	if !exists {
+		return tunnelState{}, false
+	}
+
+	var state *tunnelState
+	switch timeframe {
+	case "15m":
+		buf.state15M = v.syncTunnelState(buf.state15M, buf.kline15MData)
+		state = buf.state15M
+	case "4h":
+		buf.state4H = v.syncTunnelState(buf.state4H, buf.kline4HData)
+		state = buf.state4H
+	default:
+		return tunnelState{}, false
+	}
+	return *state, state.ready
+}
@@ -0,0 +1,80 @@
+package strategy
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// randomWalk 生成可复现的价格序列
+func randomWalk(n int) []decimal.Decimal {
+	rng := rand.New(rand.NewSource(42))
+	prices := make([]decimal.Decimal, n)
+	price := decimal.RequireFromString("30000")
+	for i := range prices {
+		change := decimal.NewFromFloat(rng.Float64()*2 - 1).Mul(decimal.NewFromInt(50)).Round(2)
+		price = price.Add(change)
+		prices[i] = price
+	}
+	return prices
+}
+
+func TestIncrementalEMAMatchesBatch(t *testing.T) {
+	prices := randomWalk(3000)
+	strategy := &VegasTunnelStrategy{}
+	tolerance := decimal.New(1, -10)
+
+	for _, period := range []int{12, 144, 169, 288, 338} {
+		batch := strategy.CalculateEMA(prices, period)
+		state := newEMAState(period)
+
+		for i, price := range prices {
+			ready := state.update(price)
+			if ready != (i >= period-1) {
+				t.Fatalf("EMA%d ready = %v at candle %d", period, ready, i)
+			}
+			if !ready {
+				continue
+			}
+			if diff := state.value.Sub(batch[i]).Abs(); diff.GreaterThan(tolerance) {
+				t.Fatalf("EMA%d at candle %d: incremental %s, batch %s", period, i, state.value, batch[i])
+			}
+		}
+	}
+}
+
+func TestIncrementalEMAPrecisionBounded(t *testing.T) {
+	state := newEMAState(12)
+	for _, price := range randomWalk(3000) {
+		state.update(price)
+	}
+
+	// 每次更新都取整，精度不随K线数增长
+	if digits := len(state.value.String()); digits > 32 {
+		t.Fatalf("EMA value has %d characters after 3000 updates: %s", digits, state.value)
+	}
+	if exp := state.value.Exponent(); exp < -emaPrecision {
+		t.Fatalf("EMA exponent = %d, want at least %d", exp, -emaPrecision)
+	}
+}
+
+func TestKlineSnapshotNotAliasedToBuffer(t *testing.T) {
+	v := NewVegasTunnelStrategy(logger.NewLoggerWithLevel("error"))
+	open := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	v.UpdateKlineData(*testKline("30000", open), "15m")
+
+	snapshot, _ := v.getKlineData("BTCUSDT")
+
+	// 同一开盘时间的K线原地替换缓存，已取得的快照不受影响
+	v.UpdateKlineData(*testKline("31000", open), "15m")
+	if got := snapshot[0].Close; !got.Equal(decimal.RequireFromString("30000")) {
+		t.Errorf("snapshot close = %s after the buffer was updated, want 30000", got)
+	}
+	if latest, _ := v.getKlineData("BTCUSDT"); !latest[0].Close.Equal(decimal.RequireFromString("31000")) {
+		t.Errorf("buffer close = %s, want 31000", latest[0].Close)
+	}
+}
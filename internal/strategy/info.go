@@ -0,0 +1,175 @@
+package strategy
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultInfoPrecision 策略信息中价格与指标的默认小数位数
+const DefaultInfoPrecision int32 = 8
+
+// StrategyInfo 策略信息：参数及各交易对的预热状态和指标快照，可直接序列化为JSON
+type StrategyInfo struct {
+	Name       string             `json:"name"`
+	Parameters StrategyParameters `json:"parameters"`
+	Symbols    []SymbolSnapshot   `json:"symbols"`
+}
+
+// StrategyParameters 策略参数
+type StrategyParameters struct {
+	ShortEMAPeriod    int     `json:"short_ema_period"`    // 动能线EMA周期（K线数）
+	MidTunnel1Period  int     `json:"mid_tunnel1_period"`  // 中期隧道EMA周期（K线数）
+	MidTunnel2Period  int     `json:"mid_tunnel2_period"`  // 中期隧道EMA周期（K线数）
+	LongTunnel1Period int     `json:"long_tunnel1_period"` // 长期隧道EMA周期（K线数）
+	LongTunnel2Period int     `json:"long_tunnel2_period"` // 长期隧道EMA周期（K线数）
+	MinTrendCandles   int     `json:"min_trend_candles"`   // 开仓前4H趋势需保持的K线数
+	ADXThreshold      float64 `json:"adx_threshold"`       // 开仓要求的4H ADX下限，0表示不过滤
+	EMA12Buffer       float64 `json:"ema12_buffer"`        // 收盘价需越过EMA12的比例（0.001表示0.1%）
+	PriceSource       string  `json:"price_source"`        // EMA价格来源：close / hl2 / hlc3 / ohlc4
+	VolumeFactor      float64 `json:"volume_factor"`       // 成交量确认倍数
+	VolumeLookback    int     `json:"volume_lookback"`     // 成交量均值的K线数
+	RiskRewardRatio   float64 `json:"risk_reward_ratio"`   // 目标风险收益比（倍）
+	StopLossPercent   float64 `json:"stop_loss_percent"`   // 止损百分比（%）
+	TakeProfitPercent float64 `json:"take_profit_percent"` // 止盈百分比（%）
+}
+
+// SymbolSnapshot 单个交易对的策略状态
+type SymbolSnapshot struct {
+	Symbol        string             `json:"symbol"`
+	Warmup        WarmupStatus       `json:"warmup"`
+	Trend4H       string             `json:"trend_4h"`                 // 4H趋势：bullish/bearish/sideways/none
+	TrendSince    *time.Time         `json:"trend_since,omitempty"`    // 当前4H趋势开始的K线开盘时间
+	Indicators4H  *IndicatorSnapshot `json:"indicators_4h,omitempty"`  // 数据不足时为空
+	Indicators15M *IndicatorSnapshot `json:"indicators_15m,omitempty"` // 数据不足时为空
+}
+
+// IndicatorSnapshot 最新一根K线的收盘价与隧道指标（价格单位为报价资产）
+type IndicatorSnapshot struct {
+	CloseTime       time.Time       `json:"close_time"`
+	Close           decimal.Decimal `json:"close"`
+	EMA12           decimal.Decimal `json:"ema12"`
+	MidTunnelUpper  decimal.Decimal `json:"mid_tunnel_upper"`
+	MidTunnelLower  decimal.Decimal `json:"mid_tunnel_lower"`
+	LongTunnelUpper decimal.Decimal `json:"long_tunnel_upper"`
+	LongTunnelLower decimal.Decimal `json:"long_tunnel_lower"`
+}
+
+// Round 返回价格与指标保留 places 位小数的副本
+func (i StrategyInfo) Round(places int32) StrategyInfo {
+	rounded := i
+	rounded.Symbols = make([]SymbolSnapshot, len(i.Symbols))
+	for n, s := range i.Symbols {
+		s.Indicators4H = s.Indicators4H.round(places)
+		s.Indicators15M = s.Indicators15M.round(places)
+		rounded.Symbols[n] = s
+	}
+	return rounded
+}
+
+// round 返回保留 places 位小数的副本
+func (s *IndicatorSnapshot) round(places int32) *IndicatorSnapshot {
+	if s == nil {
+		return nil
+	}
+	return &IndicatorSnapshot{
+		CloseTime:       s.CloseTime,
+		Close:           s.Close.Round(places),
+		EMA12:           s.EMA12.Round(places),
+		MidTunnelUpper:  s.MidTunnelUpper.Round(places),
+		MidTunnelLower:  s.MidTunnelLower.Round(places),
+		LongTunnelUpper: s.LongTunnelUpper.Round(places),
+		LongTunnelLower: s.LongTunnelLower.Round(places),
+	}
+}
+
+// String 趋势方向的英文标识
+func (t TrendDirection) String() string {
+	switch t {
+	case TrendBullish:
+		return "bullish"
+	case TrendBearish:
+		return "bearish"
+	case TrendSideways:
+		return "sideways"
+	default:
+		return "none"
+	}
+}
+
+// GetStrategyInfo 获取策略参数及各交易对的预热状态和最新指标
+func (v *VegasTunnelStrategy) GetStrategyInfo() StrategyInfo {
+	info := StrategyInfo{
+		Name: "Vegas Dual Tunnel Strategy",
+		Parameters: StrategyParameters{
+			ShortEMAPeriod:    v.shortEMAPeriod,
+			MidTunnel1Period:  v.midTunnel1Period,
+			MidTunnel2Period:  v.midTunnel2Period,
+			LongTunnel1Period: v.longTunnel1Period,
+			LongTunnel2Period: v.longTunnel2Period,
+			MinTrendCandles:   v.minTunnelPeriod,
+			ADXThreshold:      v.adxThreshold,
+			EMA12Buffer:       v.ema12Buffer,
+			PriceSource:       string(v.priceSource),
+			VolumeFactor:      v.volumeFactor,
+			VolumeLookback:    v.volumeLookback,
+			RiskRewardRatio:   v.riskRewardRatio,
+			StopLossPercent:   v.stopLossPercent,
+			TakeProfitPercent: v.takeProfitPercent,
+		},
+		Symbols: []SymbolSnapshot{},
+	}
+
+	for _, symbol := range v.symbols() {
+		kline15M, kline4H := v.getKlineData(symbol)
+		trend, since := v.TrendState(symbol)
+
+		snapshot := SymbolSnapshot{
+			Symbol:        symbol,
+			Warmup:        v.GetWarmupStatus(symbol),
+			Trend4H:       trend.String(),
+			Indicators4H:  v.indicatorSnapshot(symbol, "4h", kline4H),
+			Indicators15M: v.indicatorSnapshot(symbol, "15m", kline15M),
+		}
+		if !since.IsZero() {
+			snapshot.TrendSince = &since
+		}
+		info.Symbols = append(info.Symbols, snapshot)
+	}
+
+	return info
+}
+
+// symbols 获取已缓存数据的交易对（按名称排序）
+func (v *VegasTunnelStrategy) symbols() []string {
+	v.bufferMu.RLock()
+	defer v.bufferMu.RUnlock()
+
+	symbols := make([]string, 0, len(v.buffers))
+	for symbol := range v.buffers {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// indicatorSnapshot 获取某时间周期最新一根K线的指标快照，数据不足时返回 nil
+func (v *VegasTunnelStrategy) indicatorSnapshot(symbol, timeframe string, klines []KlineData) *IndicatorSnapshot {
+	state, ok := v.liveTunnel(symbol, timeframe)
+	if !ok || len(klines) == 0 {
+		return nil
+	}
+
+	last := state.latest
+	kline := klines[len(klines)-1]
+	return &IndicatorSnapshot{
+		CloseTime:       kline.CloseTime,
+		Close:           kline.Close,
+		EMA12:           last.EMA12,
+		MidTunnelUpper:  last.MidTunnelUpper,
+		MidTunnelLower:  last.MidTunnelLower,
+		LongTunnelUpper: last.LongTunnelUpper,
+		LongTunnelLower: last.LongTunnelLower,
+	}
+}
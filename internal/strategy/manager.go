@@ -4,23 +4,72 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sort"
 	"time"
 
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/metrics"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
 )
 
 // Strategy 策略接口
 type Strategy interface {
 	GenerateSignal(klines []KlineData) *TradingSignal
-	GetStrategyInfo() map[string]interface{}
+	GetStrategyInfo() StrategyInfo
 	ValidateParameters() error
 }
 
+// WarmableStrategy 支持按交易对重置和预热数据的策略
+type WarmableStrategy interface {
+	ResetSymbol(symbol string)
+	WarmUp(symbol, timeframe string, klines []KlineData)
+	GetWarmupStatus(symbol string) WarmupStatus
+}
+
+// ProtectiveLevelProvider 可为已有持仓给出止损止盈价位的策略
+type ProtectiveLevelProvider interface {
+	ProtectiveLevels(symbol string, isLong bool, entryPrice decimal.Decimal) (stopLoss, takeProfit decimal.Decimal)
+}
+
+// ChartDataProvider 可提供图表所需K线与隧道数据的策略
+type ChartDataProvider interface {
+	ChartData(symbol string, limit int) ([]KlineData, []TunnelData)
+}
+
+// TrendProvider 可给出交易对当前4H趋势的策略
+type TrendProvider interface {
+	TrendState(symbol string) (TrendDirection, time.Time)
+}
+
+// ExitSignalProvider 可为已有持仓给出EMA12移动止盈出场信号的策略
+type ExitSignalProvider interface {
+	CheckEMA12Exit(symbol string, isLong bool) *TradingSignal
+}
+
+// HistoryLimiter 按自身参数确定每个周期所需K线数的策略
+type HistoryLimiter interface {
+	HistoryLimit() int
+}
+
+// SignalHandler 信号回调，每个策略生成非空信号时调用
+type SignalHandler func(result *StrategyResult)
+
+// CandleClosedHandler 已收盘K线经所有策略处理后的回调
+type CandleClosedHandler func(symbol string)
+
 // StrategyManager 策略管理器
 type StrategyManager struct {
-	logger     logger.Logger
-	strategies map[string]Strategy
-	mu         sync.RWMutex
+	logger        logger.Logger
+	strategies    map[string]Strategy
+	signalHandler SignalHandler
+	candleClosed  CandleClosedHandler
+	mu            sync.RWMutex
+
+	signalCooldown time.Duration            // 同一交易对同方向信号的冷却时间，零表示不去重
+	signalMinMove  float64                  // 冷却期内价格变动达到此百分比时仍放行信号
+	lastSignals    map[string]emittedSignal // 各交易对各方向最近一次放行的信号
+
 	ctx        context.Context
 	cancel     context.CancelFunc
 	isRunning  bool
@@ -40,11 +89,12 @@ func NewStrategyManager(log logger.Logger) *StrategyManager {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &StrategyManager{
-		logger:     log,
-		strategies: make(map[string]Strategy),
-		ctx:        ctx,
-		cancel:     cancel,
-		isRunning:  false,
+		logger:      log,
+		strategies:  make(map[string]Strategy),
+		lastSignals: make(map[string]emittedSignal),
+		ctx:         ctx,
+		cancel:      cancel,
+		isRunning:   false,
 	}
 }
 
@@ -122,7 +172,9 @@ func (sm *StrategyManager) ExecuteStrategy(strategyName string, symbol string, k
 	}
 
 	// 执行策略
+	start := time.Now()
 	signal := strategy.GenerateSignal(klines)
+	metrics.ObserveStrategyLatency(strategyName, time.Since(start))
 	result.Signal = signal
 
 	if signal != nil {
@@ -187,45 +239,207 @@ func (sm *StrategyManager) ExecuteStrategiesAsync(symbol string, klines []KlineD
 }
 
 // GetStrategyInfo 获取策略信息
-func (sm *StrategyManager) GetStrategyInfo(name string) (map[string]interface{}, error) {
+func (sm *StrategyManager) GetStrategyInfo(name string) (*StrategyInfo, error) {
 	strategy, err := sm.GetStrategy(name)
 	if err != nil {
 		return nil, err
 	}
 
-	return strategy.GetStrategyInfo(), nil
+	info := strategy.GetStrategyInfo()
+	return &info, nil
 }
 
-// ProcessKlineData 处理K线数据
-func (sm *StrategyManager) ProcessKlineData(klineData *KlineData) error {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
+// SetSignalHandler 设置信号回调
+func (sm *StrategyManager) SetSignalHandler(handler SignalHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.signalHandler = handler
+}
 
+// SetCandleClosedHandler 设置K线处理完成回调，回调时策略已包含该K线，可据此检查持仓出场条件
+func (sm *StrategyManager) SetCandleClosedHandler(handler CandleClosedHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.candleClosed = handler
+}
+
+// ProcessKlineData 处理K线数据，同步执行所有策略并返回结果，生成的信号交给信号回调
+func (sm *StrategyManager) ProcessKlineData(klineData *KlineData) ([]*StrategyResult, error) {
 	// 验证K线数据
 	if err := sm.ValidateKlineData([]KlineData{*klineData}); err != nil {
-		return fmt.Errorf("invalid kline data: %w", err)
+		return nil, fmt.Errorf("invalid kline data: %w", err)
 	}
 
-	// 对所有注册的策略执行分析
-	for name, strategy := range sm.strategies {
-		go func(strategyName string, s Strategy, data *KlineData) {
-			if signal := s.GenerateSignal([]KlineData{*data}); signal != nil {
-				sm.logger.Infof("Strategy %s generated signal: %s for %s", 
-					strategyName, sm.signalTypeToString(signal.Type), data.Symbol)
-				// 这里可以添加信号处理逻辑，比如发送到交易执行器
-			}
-		}(name, strategy, klineData)
+	sm.mu.RLock()
+	handler := sm.signalHandler
+	candleClosed := sm.candleClosed
+	sm.mu.RUnlock()
+
+	// 对所有注册的策略执行分析，冷却期内的重复信号不返回也不分发
+	results := sm.ExecuteAllStrategies(klineData.Symbol, []KlineData{*klineData})
+	for _, result := range results {
+		if result.Signal == nil {
+			continue
+		}
+		if !sm.allowSignal(result.Signal) {
+			result.Signal = nil
+			continue
+		}
+		if handler != nil {
+			handler(result)
+		}
+	}
+
+	if candleClosed != nil {
+		candleClosed(klineData.Symbol)
+	}
+
+	return results, nil
+}
+
+// InjectSignal 将外部构造的信号（如测试信号）交给信号回调，与策略生成的信号走相同的分发流程
+func (sm *StrategyManager) InjectSignal(strategyName string, signal *TradingSignal) error {
+	sm.mu.RLock()
+	handler := sm.signalHandler
+	sm.mu.RUnlock()
+
+	if handler == nil {
+		return fmt.Errorf("no signal handler registered")
 	}
 
+	sm.logger.Infof("Injecting %s signal for %s: %s at %s",
+		strategyName, signal.Symbol, sm.signalTypeToString(signal.Type), signal.Price.String())
+	handler(&StrategyResult{
+		StrategyName: strategyName,
+		Symbol:       signal.Symbol,
+		Signal:       signal,
+		Timestamp:    time.Now(),
+	})
 	return nil
 }
 
+// warmableStrategies 获取所有支持数据预热的策略
+func (sm *StrategyManager) warmableStrategies() map[string]WarmableStrategy {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	warmable := make(map[string]WarmableStrategy)
+	for name, strategy := range sm.strategies {
+		if w, ok := strategy.(WarmableStrategy); ok {
+			warmable[name] = w
+		}
+	}
+
+	return warmable
+}
+
+// ResetSymbol 清空所有策略中该交易对的缓存数据
+func (sm *StrategyManager) ResetSymbol(symbol string) {
+	sm.clearSignalHistory(symbol)
+	for name, strategy := range sm.warmableStrategies() {
+		strategy.ResetSymbol(symbol)
+		sm.logger.Infof("Strategy %s: reset data for %s", name, symbol)
+	}
+}
+
+// WarmUp 使用历史K线预热所有策略中该交易对的数据
+func (sm *StrategyManager) WarmUp(symbol, timeframe string, klines []KlineData) {
+	for _, strategy := range sm.warmableStrategies() {
+		strategy.WarmUp(symbol, timeframe, klines)
+	}
+}
+
+// GetWarmupStatus 获取各策略中该交易对的数据预热状态
+func (sm *StrategyManager) GetWarmupStatus(symbol string) map[string]WarmupStatus {
+	status := make(map[string]WarmupStatus)
+	for name, strategy := range sm.warmableStrategies() {
+		status[name] = strategy.GetWarmupStatus(symbol)
+	}
+	return status
+}
+
+// firstProvider 获取首个（按名称排序）实现了接口 T 的策略，结果不随注册顺序变化
+func firstProvider[T any](sm *StrategyManager) (T, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	names := make([]string, 0, len(sm.strategies))
+	for name := range sm.strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if provider, ok := sm.strategies[name].(T); ok {
+			return provider, true
+		}
+	}
+	var none T
+	return none, false
+}
+
+// ProtectiveLevels 使用首个（按名称排序）支持的策略计算已有持仓的止损止盈价位
+func (sm *StrategyManager) ProtectiveLevels(symbol string, isLong bool, entryPrice decimal.Decimal) (decimal.Decimal, decimal.Decimal, bool) {
+	provider, ok := firstProvider[ProtectiveLevelProvider](sm)
+	if !ok {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	stopLoss, takeProfit := provider.ProtectiveLevels(symbol, isLong, entryPrice)
+	return stopLoss, takeProfit, true
+}
+
+// ChartData 使用首个（按名称排序）支持的策略获取交易对最近 limit 根K线及对应隧道数据
+func (sm *StrategyManager) ChartData(symbol string, limit int) ([]KlineData, []TunnelData, bool) {
+	provider, ok := firstProvider[ChartDataProvider](sm)
+	if !ok {
+		return nil, nil, false
+	}
+
+	klines, tunnel := provider.ChartData(symbol, limit)
+	return klines, tunnel, len(klines) > 0
+}
+
+// ExitSignal 使用首个（按名称排序）支持的策略检查持仓的EMA12移动止盈出场信号，无信号时返回 nil
+func (sm *StrategyManager) ExitSignal(symbol string, isLong bool) *TradingSignal {
+	provider, ok := firstProvider[ExitSignalProvider](sm)
+	if !ok {
+		return nil
+	}
+	return provider.CheckEMA12Exit(symbol, isLong)
+}
+
+// Trend 使用首个（按名称排序）支持的策略获取交易对当前4H趋势及其开始时间
+func (sm *StrategyManager) Trend(symbol string) (TrendDirection, time.Time, bool) {
+	provider, ok := firstProvider[TrendProvider](sm)
+	if !ok {
+		return TrendNone, time.Time{}, false
+	}
+
+	trend, since := provider.TrendState(symbol)
+	return trend, since, trend != TrendNone
+}
+
+// HistoryLimit 获取所有策略中每个周期所需的最多K线数，没有策略声明时 ok 为 false
+func (sm *StrategyManager) HistoryLimit() (limit int, ok bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	for _, strategy := range sm.strategies {
+		if l, isLimiter := strategy.(HistoryLimiter); isLimiter {
+			limit = max(limit, l.HistoryLimit())
+			ok = true
+		}
+	}
+	return limit, ok
+}
+
 // GetAllStrategyInfo 获取所有策略信息
-func (sm *StrategyManager) GetAllStrategyInfo() map[string]map[string]interface{} {
+func (sm *StrategyManager) GetAllStrategyInfo() map[string]StrategyInfo {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	info := make(map[string]map[string]interface{})
+	info := make(map[string]StrategyInfo)
 	for name, strategy := range sm.strategies {
 		info[name] = strategy.GetStrategyInfo()
 	}
@@ -270,18 +484,7 @@ func (sm *StrategyManager) IsRunning() bool {
 
 // signalTypeToString 将信号类型转换为字符串
 func (sm *StrategyManager) signalTypeToString(signalType SignalType) string {
-	switch signalType {
-	case SignalBuy:
-		return "BUY"
-	case SignalSell:
-		return "SELL"
-	case SignalStopLoss:
-		return "STOP_LOSS"
-	case SignalTakeProfit:
-		return "TAKE_PROFIT"
-	default:
-		return "NONE"
-	}
+	return signalType.String()
 }
 
 // FilterSignalsByConfidence 根据置信度过滤信号
@@ -0,0 +1,42 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// trendingStrategy 固定返回指定趋势的测试策略
+type trendingStrategy struct {
+	repeatingStrategy
+	trend TrendDirection
+}
+
+func (s trendingStrategy) TrendState(symbol string) (TrendDirection, time.Time) {
+	return s.trend, time.Time{}
+}
+
+func TestFirstProviderIgnoresRegistrationOrder(t *testing.T) {
+	for _, order := range [][]string{{"alpha", "beta"}, {"beta", "alpha"}} {
+		sm := NewStrategyManager(logger.NewLoggerWithLevel("error"))
+		strategies := map[string]Strategy{
+			"alpha": trendingStrategy{trend: TrendBullish},
+			"beta":  trendingStrategy{trend: TrendBearish},
+			"gamma": repeatingStrategy{},
+		}
+		for _, name := range append(order, "gamma") {
+			if err := sm.RegisterStrategy(name, strategies[name]); err != nil {
+				t.Fatalf("RegisterStrategy(%s): %v", name, err)
+			}
+		}
+
+		// 按名称排序取首个实现接口的策略，未实现的策略被跳过
+		if trend, _, ok := sm.Trend("BTCUSDT"); !ok || trend != TrendBullish {
+			t.Errorf("registered %v: Trend = %v (ok %v), want alpha's bullish trend", order, trend, ok)
+		}
+		if _, ok := firstProvider[ChartDataProvider](sm); ok {
+			t.Errorf("registered %v: found a ChartDataProvider among strategies that implement none", order)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package strategy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceSource EMA计算所用的K线价格
+type PriceSource string
+
+const (
+	SourceClose PriceSource = "close" // 收盘价
+	SourceHL2   PriceSource = "hl2"   // (最高+最低)/2，K线中点
+	SourceHLC3  PriceSource = "hlc3"  // (最高+最低+收盘)/3，典型价格
+	SourceOHLC4 PriceSource = "ohlc4" // (开盘+最高+最低+收盘)/4，平均价格
+)
+
+var (
+	two   = decimal.NewFromInt(2)
+	three = decimal.NewFromInt(3)
+	four  = decimal.NewFromInt(4)
+)
+
+// ParsePriceSource 解析价格来源，空字符串视为收盘价
+func ParsePriceSource(s string) (PriceSource, error) {
+	switch source := PriceSource(strings.ToLower(strings.TrimSpace(s))); source {
+	case "":
+		return SourceClose, nil
+	case SourceClose, SourceHL2, SourceHLC3, SourceOHLC4:
+		return source, nil
+	}
+	return "", fmt.Errorf("unknown price source %q (expected close, hl2, hlc3 or ohlc4)", s)
+}
+
+// Price 按价格来源计算单根K线的价格
+func (s PriceSource) Price(k KlineData) decimal.Decimal {
+	switch s {
+	case SourceHL2:
+		return k.High.Add(k.Low).Div(two)
+	case SourceHLC3:
+		return k.High.Add(k.Low).Add(k.Close).Div(three)
+	case SourceOHLC4:
+		return k.Open.Add(k.High).Add(k.Low).Add(k.Close).Div(four)
+	}
+	return k.Close
+}
+
+// SetPriceSource 设置EMA的价格来源。复合价格对影线更敏感、比收盘价更平滑，
+// 会同时改变EMA12和各隧道的位置；入场和移动止盈仍以收盘价与EMA12比较
+func (v *VegasTunnelStrategy) SetPriceSource(source PriceSource) {
+	v.priceSource = source
+}
@@ -3,6 +3,8 @@ package strategy
 import (
 	"fmt"
 	"math"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -19,14 +21,44 @@ type VegasTunnelStrategy struct {
 	longTunnel1Period int    // 长期隧道1 EMA，默认288
 	longTunnel2Period int    // 长期隧道2 EMA，默认338
 	// 策略参数
-	minTunnelPeriod  int     // 最小隧道持续周期，默认3
+	minTunnelPeriod  int     // 最小隧道持续周期：4H趋势需连续保持的K线数，默认3
+	adxThreshold     float64 // 4H ADX趋势强度门槛，低于此值视为震荡不开仓，默认20，0表示不过滤
+	ema12Buffer      float64 // EMA12触发缓冲：收盘价需越过EMA12的比例，默认0（单根穿越即触发）
+	historyMargin    int     // K线缓存在最长指标周期之外额外保留的根数，默认160
+	priceSource      PriceSource // EMA价格来源，默认收盘价
 	volumeFactor     float64 // 成交量确认因子，默认1.5
+	volumeLookback   int     // 成交量均值的K线数，默认20
 	riskRewardRatio  float64 // 风险收益比，默认2:1
 	stopLossPercent  float64 // 止损百分比，默认2%
 	takeProfitPercent float64 // 止盈百分比，默认4%
-	// 多时间周期数据缓存
-	kline15MData     []KlineData // 15分钟K线数据
-	kline4HData      []KlineData // 4小时K线数据
+	// 按交易对划分的多时间周期数据缓存
+	buffers          map[string]*klineBuffer
+	bufferMu         sync.RWMutex
+}
+
+// 由15M K线合成4H K线：每根4H由同一4H区间内连续16根已收盘的15M K线组成
+const (
+	interval4H  = 4 * time.Hour
+	klinesPer4H = 16
+)
+
+// klineBuffer 单个交易对的多时间周期K线缓存
+type klineBuffer struct {
+	kline15MData   []KlineData    // 15分钟K线数据
+	kline4HData    []KlineData    // 4小时K线数据
+	trend4H        TrendDirection // 最近一次判断的4H趋势
+	trendChangedAt time.Time      // 4H趋势最近一次变化的K线开盘时间
+	state15M       *tunnelState   // 15M隧道增量状态，为空时按缓存重建
+	state4H        *tunnelState   // 4H隧道增量状态，为空时按缓存重建
+}
+
+// WarmupStatus 交易对的数据预热状态
+type WarmupStatus struct {
+	Symbol   string `json:"symbol"`
+	Count15M int    `json:"count_15m"` // 已缓存的15M K线数
+	Count4H  int    `json:"count_4h"`  // 已缓存的4H K线数
+	Required int    `json:"required"`  // 每个周期所需的K线数
+	Ready    bool   `json:"ready"`
 }
 
 // KlineData K线数据结构
@@ -37,7 +69,8 @@ type KlineData struct {
 	Low       decimal.Decimal
 	Close     decimal.Decimal
 	Volume    decimal.Decimal
-	Timestamp time.Time
+	OpenTime  time.Time // 开盘时间（毫秒精度）
+	CloseTime time.Time // 收盘时间（毫秒精度），与币安一致为下一根开盘前1毫秒；基于K线的时间判断均以此为准
 }
 
 // TunnelData 隧道数据
@@ -75,6 +108,27 @@ const (
 	SignalTakeProfit
 )
 
+// String 获取信号类型名称
+func (t SignalType) String() string {
+	switch t {
+	case SignalBuy:
+		return "BUY"
+	case SignalSell:
+		return "SELL"
+	case SignalStopLoss:
+		return "STOP_LOSS"
+	case SignalTakeProfit:
+		return "TAKE_PROFIT"
+	default:
+		return "NONE"
+	}
+}
+
+// IsEntry 是否为开仓信号（买入或卖出）
+func (t SignalType) IsEntry() bool {
+	return t == SignalBuy || t == SignalSell
+}
+
 // TradingSignal 交易信号
 type TradingSignal struct {
 	Symbol      string
@@ -86,8 +140,23 @@ type TradingSignal struct {
 	Reason      string
 	Timestamp   time.Time
 	Timeframe   string // "15M" 或 "4H"
+	ATRPercent   float64 // 信号周期ATR占价格的百分比
+	RangePercent float64 // 信号K线振幅（最高-最低）占收盘价的百分比
 }
 
+// atrPeriod ATR计算周期
+const atrPeriod = 14
+
+// adxPeriod ADX计算周期
+const adxPeriod = 14
+
+// defaultADXThreshold 默认的4H ADX开仓门槛，低于20通常视为无明显趋势
+const defaultADXThreshold = 20.0
+
+// defaultHistoryMargin 默认的K线缓存余量。EMA以SMA起算，余量越长初始值的影响越小：
+// 以EMA338为例，160根后初始值残留权重约39%，与原先4H缓存500根时的精度相当，需要更高精度时可调大余量
+const defaultHistoryMargin = 160
+
 // NewVegasTunnelStrategy 创建新的维加斯隧道策略实例
 func NewVegasTunnelStrategy(log logger.Logger) *VegasTunnelStrategy {
 	return &VegasTunnelStrategy{
@@ -98,17 +167,20 @@ func NewVegasTunnelStrategy(log logger.Logger) *VegasTunnelStrategy {
 		longTunnel1Period: 288,
 		longTunnel2Period: 338,
 		minTunnelPeriod:   3,
+		adxThreshold:      defaultADXThreshold,
+		historyMargin:     defaultHistoryMargin,
+		priceSource:       SourceClose,
 		volumeFactor:      1.5,
+		volumeLookback:    20,
 		riskRewardRatio:   2.0,
 		stopLossPercent:   0.02, // 2%
 		takeProfitPercent: 0.04, // 4%
-		kline15MData:      make([]KlineData, 0),
-		kline4HData:       make([]KlineData, 0),
+		buffers:           make(map[string]*klineBuffer),
 	}
 }
 
-// SetParameters 设置策略参数
-func (v *VegasTunnelStrategy) SetParameters(shortEMA, midTunnel1, midTunnel2, longTunnel1, longTunnel2 int, stopLoss, takeProfit float64) {
+// SetParameters 设置策略参数，volumeLookback 为成交量确认所用均值的K线数，adxThreshold 为4H ADX开仓门槛
+func (v *VegasTunnelStrategy) SetParameters(shortEMA, midTunnel1, midTunnel2, longTunnel1, longTunnel2 int, stopLoss, takeProfit float64, volumeLookback int, adxThreshold float64) {
 	v.shortEMAPeriod = shortEMA
 	v.midTunnel1Period = midTunnel1
 	v.midTunnel2Period = midTunnel2
@@ -116,27 +188,226 @@ func (v *VegasTunnelStrategy) SetParameters(shortEMA, midTunnel1, midTunnel2, lo
 	v.longTunnel2Period = longTunnel2
 	v.stopLossPercent = stopLoss
 	v.takeProfitPercent = takeProfit
+	v.volumeLookback = volumeLookback
+	v.adxThreshold = adxThreshold
+}
+
+// SetADXThreshold 设置4H ADX开仓门槛，ADX低于门槛时视为震荡行情不开仓，0表示不过滤
+func (v *VegasTunnelStrategy) SetADXThreshold(threshold float64) {
+	v.adxThreshold = threshold
+}
+
+// SetMinTunnelPeriod 设置开仓前4H趋势需连续保持的最少K线数，0表示不限制
+func (v *VegasTunnelStrategy) SetMinTunnelPeriod(candles int) {
+	v.minTunnelPeriod = candles
+}
+
+// SetEMA12Buffer 设置入场和EMA12移动止盈要求收盘价越过EMA12的比例（0.001表示0.1%），减少在EMA12附近反复进出
+func (v *VegasTunnelStrategy) SetEMA12Buffer(buffer float64) {
+	v.ema12Buffer = buffer
+}
+
+// ema12Clearance 收盘价沿指定方向越过EMA12的比例，负值表示未越过
+func ema12Clearance(close, ema12 decimal.Decimal, above bool) float64 {
+	if ema12.IsZero() {
+		return 0
+	}
+	clearance := close.Sub(ema12).Div(ema12).InexactFloat64()
+	if !above {
+		clearance = -clearance
+	}
+	return clearance
+}
+
+// clearsEMA12 收盘价是否沿指定方向越过EMA12且超出缓冲
+func (v *VegasTunnelStrategy) clearsEMA12(close, ema12 decimal.Decimal, above bool) bool {
+	clearance := ema12Clearance(close, ema12, above)
+	if v.ema12Buffer <= 0 {
+		return clearance > 0
+	}
+	return clearance >= v.ema12Buffer
+}
+
+// ema12ClearanceBonus 越过缓冲越多置信度越高：超出缓冲一倍及以上加满0.1，未启用缓冲时不加分
+func (v *VegasTunnelStrategy) ema12ClearanceBonus(clearance float64) float64 {
+	if v.ema12Buffer <= 0 || clearance <= v.ema12Buffer {
+		return 0
+	}
+	return 0.1 * math.Min((clearance-v.ema12Buffer)/v.ema12Buffer, 1)
+}
+
+// SetHistoryMargin 设置K线缓存在最长指标周期之外额外保留的根数
+func (v *VegasTunnelStrategy) SetHistoryMargin(candles int) {
+	v.historyMargin = candles
+}
+
+// HistoryLimit 每个周期需缓存的K线数：最长指标周期加余量。默认参数下为 338+160=498 根，
+// 相比固定的 1000/500 根，每个交易对少缓存约500根15M K线（按每根约400字节计约200KB）
+func (v *VegasTunnelStrategy) HistoryLimit() int {
+	longest := atrPeriod + 1
+	for _, period := range []int{v.shortEMAPeriod, v.midTunnel1Period, v.midTunnel2Period, v.longTunnel1Period, v.longTunnel2Period} {
+		longest = max(longest, period)
+	}
+	return longest + v.historyMargin
+}
+
+// trimKlines 只保留最近 limit 根K线，底层数组明显大于所需时复制，避免被裁掉的K线仍占用内存
+func trimKlines(klines []KlineData, limit int) []KlineData {
+	if len(klines) <= limit {
+		return klines
+	}
+	klines = klines[len(klines)-limit:]
+	if cap(klines) > 2*limit {
+		klines = append(make([]KlineData, 0, limit+limit/4), klines...)
+	}
+	return klines
 }
 
-// UpdateKlineData 更新K线数据
+// UpdateKlineData 更新K线数据（按 kline.Symbol 写入对应交易对的缓存）
 func (v *VegasTunnelStrategy) UpdateKlineData(kline KlineData, timeframe string) {
+	v.bufferMu.Lock()
+	defer v.bufferMu.Unlock()
+
+	buf, exists := v.buffers[kline.Symbol]
+	if !exists {
+		buf = &klineBuffer{}
+		v.buffers[kline.Symbol] = buf
+	}
+
+	// 只保留指标计算所需的K线
+	limit := v.HistoryLimit()
 	switch timeframe {
 	case "15m":
-		v.kline15MData = append(v.kline15MData, kline)
-		// 保持最近1000根K线
-		if len(v.kline15MData) > 1000 {
-			v.kline15MData = v.kline15MData[1:]
+		if !appendsKline(buf.kline15MData, kline) {
+			buf.state15M = nil
+		}
+		buf.kline15MData = trimKlines(insertKline(buf.kline15MData, kline), limit)
+		// 4H区间的最后一根15M收盘时合成该4H K线
+		if kline4H, ok := v.completed4H(buf.kline15MData); ok {
+			if !appendsKline(buf.kline4HData, kline4H) {
+				buf.state4H = nil
+			}
+			buf.kline4HData = trimKlines(insertKline(buf.kline4HData, kline4H), limit)
 		}
 	case "4h":
-		v.kline4HData = append(v.kline4HData, kline)
-		// 保持最近500根K线
-		if len(v.kline4HData) > 500 {
-			v.kline4HData = v.kline4HData[1:]
+		if !appendsKline(buf.kline4HData, kline) {
+			buf.state4H = nil
+		}
+		buf.kline4HData = trimKlines(insertKline(buf.kline4HData, kline), limit)
+	}
+}
+
+// AggregateKlines 将连续K线按 factor 根一组合成更大周期的K线：开盘取首根、收盘取末根、
+// 最高最低取极值、成交量求和。末尾不足一组的K线被忽略
+func (v *VegasTunnelStrategy) AggregateKlines(src []KlineData, factor int) []KlineData {
+	if factor <= 0 {
+		return nil
+	}
+
+	result := make([]KlineData, 0, len(src)/factor)
+	for start := 0; start+factor <= len(src); start += factor {
+		group := src[start : start+factor]
+		aggregated := KlineData{
+			Symbol:    group[0].Symbol,
+			Open:      group[0].Open,
+			High:      group[0].High,
+			Low:       group[0].Low,
+			Close:     group[factor-1].Close,
+			OpenTime:  group[0].OpenTime,
+			CloseTime: group[factor-1].CloseTime,
+		}
+		for _, kline := range group {
+			aggregated.High = decimal.Max(aggregated.High, kline.High)
+			aggregated.Low = decimal.Min(aggregated.Low, kline.Low)
+			aggregated.Volume = aggregated.Volume.Add(kline.Volume)
+		}
+		result = append(result, aggregated)
+	}
+	return result
+}
+
+// completed4H 最新的15M K线恰好收在4H边界、且该4H区间的16根15M连续无缺口时，返回合成的4H K线
+func (v *VegasTunnelStrategy) completed4H(kline15M []KlineData) (KlineData, bool) {
+	if len(kline15M) < klinesPer4H {
+		return KlineData{}, false
+	}
+
+	group := kline15M[len(kline15M)-klinesPer4H:]
+	periodMs := interval4H.Milliseconds()
+	if (group[klinesPer4H-1].CloseTime.UnixMilli()+1)%periodMs != 0 || group[0].OpenTime.UnixMilli()%periodMs != 0 {
+		return KlineData{}, false
+	}
+	// 收盘时间与开盘时间之差应恰好为一个4H区间，否则中间有缺失的15M K线
+	if group[klinesPer4H-1].CloseTime.UnixMilli()+1-group[0].OpenTime.UnixMilli() != periodMs {
+		return KlineData{}, false
+	}
+	for i := 1; i < len(group); i++ {
+		if !group[i].OpenTime.After(group[i-1].OpenTime) {
+			return KlineData{}, false
 		}
 	}
+
+	return v.AggregateKlines(group, klinesPer4H)[0], true
+}
+
+// insertKline 按开盘时间有序插入K线，开盘时间相同时替换已有K线，避免回填与实时推送的K线重复或乱序
+func insertKline(klines []KlineData, kline KlineData) []KlineData {
+	n := len(klines)
+	if n == 0 || klines[n-1].OpenTime.Before(kline.OpenTime) {
+		return append(klines, kline)
+	}
+
+	i := sort.Search(n, func(i int) bool { return !klines[i].OpenTime.Before(kline.OpenTime) })
+	if klines[i].OpenTime.Equal(kline.OpenTime) {
+		klines[i] = kline
+		return klines
+	}
+	klines = append(klines, KlineData{})
+	copy(klines[i+1:], klines[i:])
+	klines[i] = kline
+	return klines
+}
+
+// getKlineData 获取交易对K线缓存的快照。缓存会被原地更新，返回副本避免调用方读取时与更新竞争
+func (v *VegasTunnelStrategy) getKlineData(symbol string) (kline15M, kline4H []KlineData) {
+	v.bufferMu.RLock()
+	defer v.bufferMu.RUnlock()
+
+	buf, exists := v.buffers[symbol]
+	if !exists {
+		return nil, nil
+	}
+	return append([]KlineData(nil), buf.kline15MData...), append([]KlineData(nil), buf.kline4HData...)
+}
+
+// ResetSymbol 清空交易对的K线缓存
+func (v *VegasTunnelStrategy) ResetSymbol(symbol string) {
+	v.bufferMu.Lock()
+	defer v.bufferMu.Unlock()
+	delete(v.buffers, symbol)
+}
+
+// WarmUp 使用历史K线预热交易对数据，timeframe 为 "15m" 或 "4h"
+func (v *VegasTunnelStrategy) WarmUp(symbol, timeframe string, klines []KlineData) {
+	for _, kline := range klines {
+		kline.Symbol = symbol
+		v.UpdateKlineData(kline, timeframe)
+	}
+}
+
+// GetWarmupStatus 获取交易对的数据预热状态
+func (v *VegasTunnelStrategy) GetWarmupStatus(symbol string) WarmupStatus {
+	kline15M, kline4H := v.getKlineData(symbol)
+	return WarmupStatus{
+		Symbol:   symbol,
+		Count15M: len(kline15M),
+		Count4H:  len(kline4H),
+		Required: v.longTunnel2Period,
+		Ready:    len(kline15M) >= v.longTunnel2Period && len(kline4H) >= v.longTunnel2Period,
+	}
 }
 
-// CalculateEMA 计算指数移动平均线
+// CalculateEMA 计算指数移动平均线（批量计算整个序列，用于回测和图表；实时信号使用增量状态）
 func (v *VegasTunnelStrategy) CalculateEMA(prices []decimal.Decimal, period int) []decimal.Decimal {
 	if len(prices) < period {
 		return nil
@@ -155,22 +426,147 @@ func (v *VegasTunnelStrategy) CalculateEMA(prices []decimal.Decimal, period int)
 
 	// 后续EMA值
 	for i := period; i < len(prices); i++ {
-		result[i] = prices[i].Mul(alpha).Add(result[i-1].Mul(one.Sub(alpha)))
+		result[i] = prices[i].Mul(alpha).Add(result[i-1].Mul(one.Sub(alpha))).Round(emaPrecision)
 	}
 
 	return result
 }
 
+// CalculateATR 计算平均真实波幅（Wilder平滑），数据不足时返回零
+func (v *VegasTunnelStrategy) CalculateATR(klines []KlineData, period int) decimal.Decimal {
+	if period <= 0 || len(klines) <= period {
+		return decimal.Zero
+	}
+
+	trueRange := func(i int) decimal.Decimal {
+		tr := klines[i].High.Sub(klines[i].Low)
+		prevClose := klines[i-1].Close
+		if d := klines[i].High.Sub(prevClose).Abs(); d.GreaterThan(tr) {
+			tr = d
+		}
+		if d := klines[i].Low.Sub(prevClose).Abs(); d.GreaterThan(tr) {
+			tr = d
+		}
+		return tr
+	}
+
+	// 第一个ATR值使用真实波幅的简单平均
+	n := decimal.NewFromInt(int64(period))
+	atr := decimal.Zero
+	for i := 1; i <= period; i++ {
+		atr = atr.Add(trueRange(i))
+	}
+	atr = atr.Div(n)
+
+	// 后续ATR值：ATR = (前值 × (n-1) + TR) / n
+	for i := period + 1; i < len(klines); i++ {
+		atr = atr.Mul(n.Sub(decimal.NewFromInt(1))).Add(trueRange(i)).Div(n)
+	}
+
+	return atr
+}
+
+// CalculateADX 计算平均趋向指数（Wilder平滑，0-100），衡量趋势强度而不区分方向；数据不足 2×period+1 根时返回零
+func (v *VegasTunnelStrategy) CalculateADX(klines []KlineData, period int) float64 {
+	if period <= 0 || len(klines) < 2*period+1 {
+		return 0
+	}
+
+	n := float64(period)
+	var trSum, plusSum, minusSum, dxSum, adx float64
+	for i := 1; i < len(klines); i++ {
+		high := klines[i].High.InexactFloat64()
+		low := klines[i].Low.InexactFloat64()
+		prevHigh := klines[i-1].High.InexactFloat64()
+		prevLow := klines[i-1].Low.InexactFloat64()
+		prevClose := klines[i-1].Close.InexactFloat64()
+
+		tr := math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
+		upMove, downMove := high-prevHigh, prevLow-low
+		var plusDM, minusDM float64
+		if upMove > downMove && upMove > 0 {
+			plusDM = upMove
+		}
+		if downMove > upMove && downMove > 0 {
+			minusDM = downMove
+		}
+
+		// 前 period 个值求和作为初始值，之后按 S = S - S/n + 当前值 平滑
+		if i <= period {
+			trSum += tr
+			plusSum += plusDM
+			minusSum += minusDM
+			if i < period {
+				continue
+			}
+		} else {
+			trSum = trSum - trSum/n + tr
+			plusSum = plusSum - plusSum/n + plusDM
+			minusSum = minusSum - minusSum/n + minusDM
+		}
+
+		dx := 0.0
+		if trSum > 0 {
+			plusDI := 100 * plusSum / trSum
+			minusDI := 100 * minusSum / trSum
+			if sum := plusDI + minusDI; sum > 0 {
+				dx = 100 * math.Abs(plusDI-minusDI) / sum
+			}
+		}
+
+		// 第一个ADX为前 period 个DX的平均，之后 ADX = (前值 × (n-1) + DX) / n
+		switch count := i - period + 1; {
+		case count < period:
+			dxSum += dx
+		case count == period:
+			adx = (dxSum + dx) / n
+		default:
+			adx = (adx*(n-1) + dx) / n
+		}
+	}
+
+	return adx
+}
+
+// strongTrend 4H ADX是否达到开仓门槛
+func (v *VegasTunnelStrategy) strongTrend(adx float64) bool {
+	return v.adxThreshold <= 0 || adx >= v.adxThreshold
+}
+
+// adxBonus ADX超出门槛越多置信度越高，超出一倍门槛时加满0.1；未设门槛时按默认门槛计算
+func (v *VegasTunnelStrategy) adxBonus(adx float64) float64 {
+	threshold := v.adxThreshold
+	if threshold <= 0 {
+		threshold = defaultADXThreshold
+	}
+	if adx <= threshold {
+		return 0
+	}
+	return 0.1 * math.Min((adx-threshold)/threshold, 1)
+}
+
+// annotateVolatility 在信号上标注ATR百分比和信号K线振幅百分比，供执行层过滤极端波动
+func (v *VegasTunnelStrategy) annotateVolatility(signal *TradingSignal, klines []KlineData) {
+	last := klines[len(klines)-1]
+	if !last.Close.IsPositive() {
+		return
+	}
+
+	hundred := decimal.NewFromInt(100)
+	signal.ATRPercent = v.CalculateATR(klines, atrPeriod).Div(last.Close).Mul(hundred).InexactFloat64()
+	signal.RangePercent = last.High.Sub(last.Low).Div(last.Close).Mul(hundred).InexactFloat64()
+}
+
 // CalculateTunnelData 计算隧道数据
 func (v *VegasTunnelStrategy) CalculateTunnelData(klines []KlineData) []TunnelData {
 	if len(klines) < v.longTunnel2Period {
 		return nil
 	}
 
-	// 提取收盘价
+	// 按价格来源提取EMA输入价格
 	prices := make([]decimal.Decimal, len(klines))
 	for i, kline := range klines {
-		prices[i] = kline.Close
+		prices[i] = v.priceSource.Price(kline)
 	}
 
 	// 计算所有EMA
@@ -187,39 +583,46 @@ func (v *VegasTunnelStrategy) CalculateTunnelData(klines []KlineData) []TunnelDa
 	tunnelData := make([]TunnelData, len(klines))
 
 	for i := v.longTunnel2Period - 1; i < len(klines); i++ {
-		tunnel := &tunnelData[i]
-		tunnel.EMA12 = ema12[i]
-		tunnel.EMA144 = ema144[i]
-		tunnel.EMA169 = ema169[i]
-		tunnel.EMA288 = ema288[i]
-		tunnel.EMA338 = ema338[i]
-
-		// 计算隧道边界
-		if tunnel.EMA144.GreaterThan(tunnel.EMA169) {
-			tunnel.MidTunnelUpper = tunnel.EMA144
-			tunnel.MidTunnelLower = tunnel.EMA169
-		} else {
-			tunnel.MidTunnelUpper = tunnel.EMA169
-			tunnel.MidTunnelLower = tunnel.EMA144
-		}
+		tunnelData[i] = newTunnelData(ema12[i], ema144[i], ema169[i], ema288[i], ema338[i])
+	}
 
-		if tunnel.EMA288.GreaterThan(tunnel.EMA338) {
-			tunnel.LongTunnelUpper = tunnel.EMA288
-			tunnel.LongTunnelLower = tunnel.EMA338
-		} else {
-			tunnel.LongTunnelUpper = tunnel.EMA338
-			tunnel.LongTunnelLower = tunnel.EMA288
-		}
+	return tunnelData
+}
 
-		// 判断趋势方向
-		tunnel.TrendDirection = v.determineTrendDirection(*tunnel)
+// newTunnelData 由各EMA值计算隧道边界和趋势方向
+func newTunnelData(ema12, ema144, ema169, ema288, ema338 decimal.Decimal) TunnelData {
+	tunnel := TunnelData{
+		EMA12:  ema12,
+		EMA144: ema144,
+		EMA169: ema169,
+		EMA288: ema288,
+		EMA338: ema338,
 	}
 
-	return tunnelData
+	// 计算隧道边界
+	if tunnel.EMA144.GreaterThan(tunnel.EMA169) {
+		tunnel.MidTunnelUpper = tunnel.EMA144
+		tunnel.MidTunnelLower = tunnel.EMA169
+	} else {
+		tunnel.MidTunnelUpper = tunnel.EMA169
+		tunnel.MidTunnelLower = tunnel.EMA144
+	}
+
+	if tunnel.EMA288.GreaterThan(tunnel.EMA338) {
+		tunnel.LongTunnelUpper = tunnel.EMA288
+		tunnel.LongTunnelLower = tunnel.EMA338
+	} else {
+		tunnel.LongTunnelUpper = tunnel.EMA338
+		tunnel.LongTunnelLower = tunnel.EMA288
+	}
+
+	// 判断趋势方向
+	tunnel.TrendDirection = determineTrendDirection(tunnel)
+	return tunnel
 }
 
 // determineTrendDirection 判断趋势方向
-func (v *VegasTunnelStrategy) determineTrendDirection(tunnel TunnelData) TrendDirection {
+func determineTrendDirection(tunnel TunnelData) TrendDirection {
 	// 多头排列：价格 > 中期隧道 > 长期隧道
 	if tunnel.MidTunnelLower.GreaterThan(tunnel.LongTunnelUpper) {
 		return TrendBullish
@@ -232,6 +635,50 @@ func (v *VegasTunnelStrategy) determineTrendDirection(tunnel TunnelData) TrendDi
 	return TrendSideways
 }
 
+// calculateAverageVolume 计算最近 n 根K线的平均成交量，K线不足 n 根时返回零
+func calculateAverageVolume(klines []KlineData, n int) decimal.Decimal {
+	if n <= 0 || len(klines) < n {
+		return decimal.Zero
+	}
+
+	sum := decimal.Zero
+	for _, kline := range klines[len(klines)-n:] {
+		sum = sum.Add(kline.Volume)
+	}
+	return sum.Div(decimal.NewFromInt(int64(n)))
+}
+
+// volumeConfirmation 触发K线的成交量相对均量的倍数，known 为 false 表示数据不足或均量为零
+type volumeConfirmation struct {
+	ratio float64
+	known bool
+}
+
+// volumeRatio 计算最新K线成交量相对此前 volumeLookback 根均量的倍数
+func (v *VegasTunnelStrategy) volumeRatio(klines []KlineData) volumeConfirmation {
+	if len(klines) < 2 {
+		return volumeConfirmation{}
+	}
+	average := calculateAverageVolume(klines[:len(klines)-1], v.volumeLookback)
+	if !average.IsPositive() {
+		return volumeConfirmation{}
+	}
+	return volumeConfirmation{ratio: klines[len(klines)-1].Volume.Div(average).InexactFloat64(), known: true}
+}
+
+// confirmsVolume 触发K线成交量是否达到均量的 volumeFactor 倍，未启用或无法计算均量时不拦截
+func (v *VegasTunnelStrategy) confirmsVolume(volume volumeConfirmation) bool {
+	return v.volumeFactor <= 0 || !volume.known || volume.ratio >= v.volumeFactor
+}
+
+// volumeBonus 放量越明显置信度越高：达到确认倍数的两倍及以上加满0.1
+func (v *VegasTunnelStrategy) volumeBonus(volume volumeConfirmation) float64 {
+	if v.volumeFactor <= 0 || !volume.known || volume.ratio <= v.volumeFactor {
+		return 0
+	}
+	return 0.1 * math.Min((volume.ratio-v.volumeFactor)/v.volumeFactor, 1)
+}
+
 // GenerateSignal 生成交易信号
 func (v *VegasTunnelStrategy) GenerateSignal(klines []KlineData) *TradingSignal {
 	if len(klines) == 0 {
@@ -241,58 +688,75 @@ func (v *VegasTunnelStrategy) GenerateSignal(klines []KlineData) *TradingSignal
 	// 获取symbol
 	symbol := klines[0].Symbol
 	
-	// 更新K线数据（假设输入的是15M数据）
+	// 更新K线数据（假设输入的是15M数据），4H区间完成时自动合成4H K线
 	for _, kline := range klines {
-		v.UpdateKlineData(kline, "15M")
+		v.UpdateKlineData(kline, "15m")
 	}
+	kline15MData, kline4HData := v.getKlineData(symbol)
+
 	// 检查数据充足性
-	if len(v.kline15MData) < v.longTunnel2Period {
-		v.logger.Debugf("Insufficient 15M data for signal generation: %d", len(v.kline15MData))
+	if len(kline15MData) < v.longTunnel2Period {
+		v.logger.Debugf("Insufficient 15M data for signal generation: %d", len(kline15MData))
 		return nil
 	}
 	
-	// 如果没有4H数据，使用15M数据模拟
-	if len(v.kline4HData) < v.longTunnel2Period {
-		v.logger.Debugf("Insufficient 4H data, using 15M data: %d", len(v.kline4HData))
-		// 可以考虑从15M数据中提取4H数据或使用其他逻辑
+	// 4H数据来自回填，并在每个4H区间收盘时由15M K线合成
+	if len(kline4HData) < v.longTunnel2Period {
+		v.logger.Debugf("Insufficient 4H data for signal generation: %d", len(kline4HData))
 		return nil
 	}
 
-	// 计算4H隧道数据（宏观趋势确认）
-	tunnel4H := v.CalculateTunnelData(v.kline4HData)
-	if tunnel4H == nil {
+	// 增量更新4H隧道数据（宏观趋势确认）
+	state4H, ok := v.liveTunnel(symbol, "4h")
+	if !ok {
 		return nil
 	}
-	current4H := tunnel4H[len(tunnel4H)-1]
+	current4H := state4H.latest
+	trendAge := v.trackTrend(symbol, state4H)
+	adx4H := v.CalculateADX(kline4HData, adxPeriod)
 
-	// 计算15M隧道数据（战术入场点）
-	tunnel15M := v.CalculateTunnelData(v.kline15MData)
-	if tunnel15M == nil {
+	// 增量更新15M隧道数据（战术入场点）
+	state15M, ok := v.liveTunnel(symbol, "15m")
+	if !ok {
 		return nil
 	}
-	current15M := tunnel15M[len(tunnel15M)-1]
-	currentKline := v.kline15MData[len(v.kline15MData)-1]
+	current15M := state15M.latest
+	currentKline := kline15MData[len(kline15MData)-1]
+	volume := v.volumeRatio(kline15MData)
 
 	// 检查多头入场信号
-	if signal := v.checkLongSignal(current4H, current15M, currentKline, symbol); signal != nil {
-		return signal
-	}
+	signal := v.checkLongSignal(current4H, current15M, currentKline, symbol, trendAge, adx4H, volume)
 
 	// 检查空头入场信号
-	if signal := v.checkShortSignal(current4H, current15M, currentKline, symbol); signal != nil {
-		return signal
+	if signal == nil {
+		signal = v.checkShortSignal(current4H, current15M, currentKline, symbol, trendAge, adx4H, volume)
 	}
 
-	return nil
+	if signal != nil {
+		v.annotateVolatility(signal, kline15MData)
+	}
+	return signal
 }
 
 // checkLongSignal 检查多头入场信号
-func (v *VegasTunnelStrategy) checkLongSignal(tunnel4H, tunnel15M TunnelData, kline KlineData, symbol string) *TradingSignal {
+func (v *VegasTunnelStrategy) checkLongSignal(tunnel4H, tunnel15M TunnelData, kline KlineData, symbol string, trendAge int, adx float64, volume volumeConfirmation) *TradingSignal {
 	// 1. 4H宏观确认：多头排列
 	if tunnel4H.TrendDirection != TrendBullish {
 		return nil
 	}
 
+	// 趋势刚形成时容易反复，需连续保持足够的4H K线
+	if trendAge < v.minTunnelPeriod {
+		v.logger.Debugf("4H trend for %s only %d candles old, need %d", symbol, trendAge, v.minTunnelPeriod)
+		return nil
+	}
+
+	// 趋势强度不足时多空排列多为震荡中的假信号
+	if !v.strongTrend(adx) {
+		v.logger.Debugf("4H trend for %s too weak: ADX %.1f below %.1f", symbol, adx, v.adxThreshold)
+		return nil
+	}
+
 	// 2. 4H价格位置确认：现价 > EMA144/169隧道
 	if kline.Close.LessThanOrEqual(tunnel4H.MidTunnelLower) {
 		return nil
@@ -303,8 +767,14 @@ func (v *VegasTunnelStrategy) checkLongSignal(tunnel4H, tunnel15M TunnelData, kl
 		return nil
 	}
 
-	// 4. 15M动能触发：收盘价站上EMA12
-	if kline.Close.LessThanOrEqual(tunnel15M.EMA12) {
+	// 4. 15M动能触发：收盘价站上EMA12（且超出缓冲）
+	if !v.clearsEMA12(kline.Close, tunnel15M.EMA12, true) {
+		return nil
+	}
+
+	// 5. 成交量确认：触发K线需放量
+	if !v.confirmsVolume(volume) {
+		v.logger.Debugf("Signal for %s suppressed: volume %.2fx average, need %.2fx", symbol, volume.ratio, v.volumeFactor)
 		return nil
 	}
 
@@ -313,12 +783,16 @@ func (v *VegasTunnelStrategy) checkLongSignal(tunnel4H, tunnel15M TunnelData, kl
 		Symbol:    symbol,
 		Type:      SignalBuy,
 		Price:     kline.Close,
-		Confidence: v.calculateSignalConfidence(tunnel4H, tunnel15M, true),
-		Reason:    "4H多头排列，15M回调至隧道获支撑后站上EMA12",
-		Timestamp: kline.Timestamp,
+		Confidence: v.calculateSignalConfidence(tunnel4H, tunnel15M, kline.Close, true, trendAge, adx, volume),
+		Reason:    fmt.Sprintf("4H多头排列（已持续%d根，ADX %.1f），15M回调至隧道获支撑后站上EMA12", trendAge, adx),
+		Timestamp: kline.CloseTime,
 		Timeframe: "15M",
 	}
 
+	if volume.known {
+		signal.Reason += fmt.Sprintf("，成交量为均量的%.1f倍", volume.ratio)
+	}
+
 	// 计算止损止盈
 	v.calculateStopLossAndTakeProfit(signal, tunnel15M, true)
 
@@ -326,12 +800,24 @@ func (v *VegasTunnelStrategy) checkLongSignal(tunnel4H, tunnel15M TunnelData, kl
 }
 
 // checkShortSignal 检查空头入场信号
-func (v *VegasTunnelStrategy) checkShortSignal(tunnel4H, tunnel15M TunnelData, kline KlineData, symbol string) *TradingSignal {
+func (v *VegasTunnelStrategy) checkShortSignal(tunnel4H, tunnel15M TunnelData, kline KlineData, symbol string, trendAge int, adx float64, volume volumeConfirmation) *TradingSignal {
 	// 1. 4H宏观确认：空头排列
 	if tunnel4H.TrendDirection != TrendBearish {
 		return nil
 	}
 
+	// 趋势刚形成时容易反复，需连续保持足够的4H K线
+	if trendAge < v.minTunnelPeriod {
+		v.logger.Debugf("4H trend for %s only %d candles old, need %d", symbol, trendAge, v.minTunnelPeriod)
+		return nil
+	}
+
+	// 趋势强度不足时多空排列多为震荡中的假信号
+	if !v.strongTrend(adx) {
+		v.logger.Debugf("4H trend for %s too weak: ADX %.1f below %.1f", symbol, adx, v.adxThreshold)
+		return nil
+	}
+
 	// 2. 4H价格位置确认：现价 < EMA144/169隧道
 	if kline.Close.GreaterThanOrEqual(tunnel4H.MidTunnelUpper) {
 		return nil
@@ -342,8 +828,14 @@ func (v *VegasTunnelStrategy) checkShortSignal(tunnel4H, tunnel15M TunnelData, k
 		return nil
 	}
 
-	// 4. 15M动能触发：收盘价跌破EMA12
-	if kline.Close.GreaterThanOrEqual(tunnel15M.EMA12) {
+	// 4. 15M动能触发：收盘价跌破EMA12（且超出缓冲）
+	if !v.clearsEMA12(kline.Close, tunnel15M.EMA12, false) {
+		return nil
+	}
+
+	// 5. 成交量确认：触发K线需放量
+	if !v.confirmsVolume(volume) {
+		v.logger.Debugf("Signal for %s suppressed: volume %.2fx average, need %.2fx", symbol, volume.ratio, v.volumeFactor)
 		return nil
 	}
 
@@ -352,18 +844,58 @@ func (v *VegasTunnelStrategy) checkShortSignal(tunnel4H, tunnel15M TunnelData, k
 		Symbol:    symbol,
 		Type:      SignalSell,
 		Price:     kline.Close,
-		Confidence: v.calculateSignalConfidence(tunnel4H, tunnel15M, false),
-		Reason:    "4H空头排列，15M反弹至隧道受压制后跌破EMA12",
-		Timestamp: kline.Timestamp,
+		Confidence: v.calculateSignalConfidence(tunnel4H, tunnel15M, kline.Close, false, trendAge, adx, volume),
+		Reason:    fmt.Sprintf("4H空头排列（已持续%d根，ADX %.1f），15M反弹至隧道受压制后跌破EMA12", trendAge, adx),
+		Timestamp: kline.CloseTime,
 		Timeframe: "15M",
 	}
 
+	if volume.known {
+		signal.Reason += fmt.Sprintf("，成交量为均量的%.1f倍", volume.ratio)
+	}
+
 	// 计算止损止盈
 	v.calculateStopLossAndTakeProfit(signal, tunnel15M, false)
 
 	return signal
 }
 
+// trackTrend 获取最新4H趋势已连续保持的K线数，并在趋势变化时记录变化时间
+func (v *VegasTunnelStrategy) trackTrend(symbol string, state tunnelState) int {
+	current := state.latest.TrendDirection
+	age := state.trendAge
+	changedAt := state.trendSince
+
+	v.bufferMu.Lock()
+	defer v.bufferMu.Unlock()
+
+	buf, exists := v.buffers[symbol]
+	if !exists {
+		return age
+	}
+	if buf.trend4H != current {
+		if buf.trend4H != TrendNone {
+			v.logger.Infof("4H trend for %s changed: %d -> %d", symbol, buf.trend4H, current)
+		}
+		buf.trend4H = current
+	}
+	buf.trendChangedAt = changedAt
+
+	return age
+}
+
+// TrendState 获取交易对最近一次判断的4H趋势及其形成时间
+func (v *VegasTunnelStrategy) TrendState(symbol string) (TrendDirection, time.Time) {
+	v.bufferMu.RLock()
+	defer v.bufferMu.RUnlock()
+
+	buf, exists := v.buffers[symbol]
+	if !exists {
+		return TrendNone, time.Time{}
+	}
+	return buf.trend4H, buf.trendChangedAt
+}
+
 // isPriceNearTunnel 判断价格是否接近隧道区域
 func (v *VegasTunnelStrategy) isPriceNearTunnel(price decimal.Decimal, tunnel TunnelData, isLong bool) bool {
 	tolerance := decimal.NewFromFloat(0.002) // 0.2%的容差
@@ -400,7 +932,7 @@ func (v *VegasTunnelStrategy) isPriceNearTunnel(price decimal.Decimal, tunnel Tu
 }
 
 // calculateSignalConfidence 计算信号置信度
-func (v *VegasTunnelStrategy) calculateSignalConfidence(tunnel4H, tunnel15M TunnelData, isLong bool) float64 {
+func (v *VegasTunnelStrategy) calculateSignalConfidence(tunnel4H, tunnel15M TunnelData, close decimal.Decimal, isLong bool, trendAge int, adx float64, volume volumeConfirmation) float64 {
 	confidence := 0.6 // 基础置信度
 
 	// 4H趋势强度加分
@@ -425,6 +957,20 @@ func (v *VegasTunnelStrategy) calculateSignalConfidence(tunnel4H, tunnel15M Tunn
 		}
 	}
 
+	// 趋势持续时间加分：达到最低要求两倍以上视为成熟趋势
+	if trendAge >= 2*v.minTunnelPeriod && trendAge > 0 {
+		confidence += 0.1
+	}
+
+	// 4H趋势强度（ADX）加分
+	confidence += v.adxBonus(adx)
+
+	// 收盘价越过EMA12缓冲的幅度加分
+	confidence += v.ema12ClearanceBonus(ema12Clearance(close, tunnel15M.EMA12, isLong))
+
+	// 放量突破加分
+	confidence += v.volumeBonus(volume)
+
 	return math.Min(confidence, 1.0)
 }
 
@@ -455,23 +1001,44 @@ func (v *VegasTunnelStrategy) calculateStopLossAndTakeProfit(signal *TradingSign
 	}
 }
 
-// GetStrategyInfo 获取策略信息
-func (v *VegasTunnelStrategy) GetStrategyInfo() map[string]interface{} {
-	return map[string]interface{}{
-		"name":                "Vegas Dual Tunnel Strategy",
-		"short_ema_period":    v.shortEMAPeriod,
-		"mid_tunnel1_period":  v.midTunnel1Period,
-		"mid_tunnel2_period":  v.midTunnel2Period,
-		"long_tunnel1_period": v.longTunnel1Period,
-		"long_tunnel2_period": v.longTunnel2Period,
-		"min_tunnel_period":   v.minTunnelPeriod,
-		"volume_factor":       v.volumeFactor,
-		"risk_reward_ratio":   v.riskRewardRatio,
-		"stop_loss_percent":   v.stopLossPercent,
-		"take_profit_percent":  v.takeProfitPercent,
-		"15m_data_count":      len(v.kline15MData),
-		"4h_data_count":       len(v.kline4HData),
+// ProtectiveLevels 根据最新15M隧道为已有持仓计算止损止盈，隧道数据不足或不适用时按止损/止盈百分比计算
+func (v *VegasTunnelStrategy) ProtectiveLevels(symbol string, isLong bool, entryPrice decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	if state, ok := v.liveTunnel(symbol, "15m"); ok {
+		signal := &TradingSignal{Symbol: symbol, Price: entryPrice}
+		v.calculateStopLossAndTakeProfit(signal, state.latest, isLong)
+
+		// 仅当隧道止损位于入场价的正确一侧时采用
+		if (isLong && signal.StopLoss.LessThan(entryPrice)) || (!isLong && signal.StopLoss.GreaterThan(entryPrice)) {
+			return signal.StopLoss, signal.TakeProfit
+		}
+	}
+
+	one := decimal.NewFromInt(1)
+	stopLossPct := decimal.NewFromFloat(v.stopLossPercent)
+	takeProfitPct := decimal.NewFromFloat(v.takeProfitPercent)
+	if isLong {
+		return entryPrice.Mul(one.Sub(stopLossPct)), entryPrice.Mul(one.Add(takeProfitPct))
+	}
+	return entryPrice.Mul(one.Add(stopLossPct)), entryPrice.Mul(one.Sub(takeProfitPct))
+}
+
+// ChartData 获取交易对最近 limit 根15M K线及对应隧道数据（数据不足以计算隧道时隧道为空）
+func (v *VegasTunnelStrategy) ChartData(symbol string, limit int) ([]KlineData, []TunnelData) {
+	kline15MData, _ := v.getKlineData(symbol)
+	if len(kline15MData) == 0 {
+		return nil, nil
 	}
+
+	tunnel := v.CalculateTunnelData(kline15MData)
+
+	start := 0
+	if limit > 0 && len(kline15MData) > limit {
+		start = len(kline15MData) - limit
+	}
+	if tunnel != nil {
+		tunnel = tunnel[start:]
+	}
+	return kline15MData[start:], tunnel
 }
 
 // ValidateParameters 验证策略参数
@@ -500,47 +1067,64 @@ func (v *VegasTunnelStrategy) ValidateParameters() error {
 		return fmt.Errorf("risk reward ratio must be greater than 1.0")
 	}
 
+	if v.minTunnelPeriod < 0 {
+		return fmt.Errorf("min tunnel period cannot be negative")
+	}
+
+	if v.adxThreshold < 0 || v.adxThreshold >= 100 {
+		return fmt.Errorf("adx threshold must be between 0 and 100")
+	}
+
+	if v.historyMargin < 0 {
+		return fmt.Errorf("history margin cannot be negative")
+	}
+
+	if v.ema12Buffer < 0 || v.ema12Buffer >= 0.05 {
+		return fmt.Errorf("ema12 buffer must be between 0 and 0.05 (5%%)")
+	}
+
 	return nil
 }
 
 // CheckEMA12Exit 检查EMA12移动止盈出场信号
 func (v *VegasTunnelStrategy) CheckEMA12Exit(symbol string, isLong bool) *TradingSignal {
-	if len(v.kline15MData) < 2 {
+	kline15MData, _ := v.getKlineData(symbol)
+	if len(kline15MData) < 2 {
 		return nil
 	}
 
-	tunnel15M := v.CalculateTunnelData(v.kline15MData)
-	if tunnel15M == nil {
+	state15M, ok := v.liveTunnel(symbol, "15m")
+	if !ok {
 		return nil
 	}
 
-	currentKline := v.kline15MData[len(v.kline15MData)-1]
-	currentTunnel := tunnel15M[len(tunnel15M)-1]
+	currentKline := kline15MData[len(kline15MData)-1]
+	currentTunnel := state15M.latest
 
-	var shouldExit bool
 	var reason string
 
+	// 多单在收盘价跌破EMA12、空单在收盘价突破EMA12（且超出缓冲）时出场
 	if isLong {
-		// 多单：收盘价跌破EMA12
-		shouldExit = currentKline.Close.LessThan(currentTunnel.EMA12)
 		reason = "15M收盘价跌破EMA12移动止盈线"
 	} else {
-		// 空单：收盘价突破EMA12
-		shouldExit = currentKline.Close.GreaterThan(currentTunnel.EMA12)
 		reason = "15M收盘价突破EMA12移动止盈线"
 	}
 
-	if !shouldExit {
+	if !v.clearsEMA12(currentKline.Close, currentTunnel.EMA12, !isLong) {
 		return nil
 	}
 
+	// 越过缓冲越多越确定趋势已反转
+	clearance := ema12Clearance(currentKline.Close, currentTunnel.EMA12, !isLong)
+	confidence := math.Min(0.9+v.ema12ClearanceBonus(clearance), 1.0)
+
 	return &TradingSignal{
 		Symbol:    symbol,
 		Type:      SignalTakeProfit,
 		Price:     currentKline.Close,
-		Confidence: 0.9,
+		Confidence: confidence,
 		Reason:    reason,
-		Timestamp: currentKline.Timestamp,
+		Timestamp: currentKline.CloseTime,
 		Timeframe: "15M",
 	}
 }
\ No newline at end of file
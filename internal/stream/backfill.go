@@ -0,0 +1,137 @@
+package stream
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+const (
+	backfill15MLimit = 1000 // 15分钟K线回填数量（策略未声明所需K线数时使用）
+	backfill4HLimit  = 500  // 4小时K线回填数量（策略未声明所需K线数时使用）
+	maxBackfillLimit = 1500 // 币安K线接口单次返回上限
+)
+
+// Backfill 通过REST接口回填交易对的历史K线并预热策略数据
+func (sm *StreamManager) Backfill(symbol string) error {
+	if sm.binanceClient == nil {
+		return fmt.Errorf("binance client not configured")
+	}
+
+	timeframes := []struct {
+		interval string
+		limit    int
+	}{
+		{"15m", backfill15MLimit},
+		{"4h", backfill4HLimit},
+	}
+
+	// 只回填策略实际需要的K线，多取一根补偿被丢弃的未收盘K线
+	if limit, ok := sm.strategyManager.HistoryLimit(); ok {
+		limit = min(limit+1, maxBackfillLimit)
+		for i := range timeframes {
+			timeframes[i].limit = limit
+		}
+	}
+
+	for _, tf := range timeframes {
+		klines, err := sm.fetchClosedKlines(symbol, tf.interval, tf.limit)
+		if err != nil {
+			return fmt.Errorf("failed to backfill %s %s: %w", symbol, tf.interval, err)
+		}
+
+		sm.strategyManager.WarmUp(symbol, tf.interval, klines)
+		sm.logger.Infof("Backfilled %d %s klines for %s", len(klines), tf.interval, symbol)
+	}
+
+	return nil
+}
+
+// warmUpOnSubscribe 订阅后回填尚未预热的交易对，避免等待数百根实时K线才能生成通道。
+// 先订阅再回填，期间收盘的K线按开盘时间去重，回填失败不影响订阅
+func (sm *StreamManager) warmUpOnSubscribe(symbol string) {
+	if sm.binanceClient == nil || sm.strategyManager == nil {
+		return
+	}
+
+	warmed := true
+	for _, status := range sm.strategyManager.GetWarmupStatus(symbol) {
+		if !status.Ready {
+			warmed = false
+			break
+		}
+	}
+	if warmed {
+		return
+	}
+
+	if err := sm.Backfill(symbol); err != nil {
+		sm.logger.Warnf("Failed to backfill %s on subscribe, waiting for live klines: %v", symbol, err)
+	}
+}
+
+// Rewarm 清空交易对的策略数据并重新回填，完成后恢复实时处理
+func (sm *StreamManager) Rewarm(symbol string) (map[string]strategy.WarmupStatus, error) {
+	symbol = strings.ToUpper(symbol)
+
+	sm.strategyHandler.pause(symbol)
+	defer sm.strategyHandler.resume(symbol)
+
+	sm.strategyManager.ResetSymbol(symbol)
+	if err := sm.Backfill(symbol); err != nil {
+		return sm.strategyManager.GetWarmupStatus(symbol), err
+	}
+
+	sm.logger.Infof("Rewarmed strategy data for %s", symbol)
+	return sm.strategyManager.GetWarmupStatus(symbol), nil
+}
+
+// fetchClosedKlines 获取已收盘的历史K线，丢弃仍在形成中的最后一根
+func (sm *StreamManager) fetchClosedKlines(symbol, interval string, limit int) ([]strategy.KlineData, error) {
+	klines, err := sm.binanceClient.GetKlines(sm.ctx, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	nowMs := time.Now().UnixMilli()
+	result := make([]strategy.KlineData, 0, len(klines))
+	for _, kline := range klines {
+		if kline.CloseTime > nowMs {
+			continue
+		}
+
+		data, err := toKlineData(symbol, kline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kline at %d: %w", kline.OpenTime, err)
+		}
+		result = append(result, data)
+	}
+
+	return result, nil
+}
+
+// toKlineData 将REST K线转换为策略K线数据
+func toKlineData(symbol string, kline binance.Kline) (strategy.KlineData, error) {
+	parsed, err := kline.ToDecimal()
+	if err != nil {
+		return strategy.KlineData{}, err
+	}
+	return fromDecimalKline(symbol, parsed), nil
+}
+
+// fromDecimalKline 将已解析的K线转换为策略K线数据
+func fromDecimalKline(symbol string, kline binance.DecimalKline) strategy.KlineData {
+	return strategy.KlineData{
+		Symbol:    symbol,
+		Open:      kline.Open,
+		High:      kline.High,
+		Low:       kline.Low,
+		Close:     kline.Close,
+		Volume:    kline.Volume,
+		OpenTime:  time.UnixMilli(kline.OpenTime),
+		CloseTime: time.UnixMilli(kline.CloseTime),
+	}
+}
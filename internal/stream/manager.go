@@ -3,12 +3,14 @@ package stream
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/shopspring/decimal"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/pipeline"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
 )
@@ -18,8 +20,12 @@ type StreamManager struct {
 	config          *config.Config
 	logger          logger.Logger
 	binanceWS       *binance.WebSocketClient
+	binanceClient   *binance.Client
 	strategyManager *strategy.StrategyManager
+	strategyHandler *StrategyHandler
+	eventBus        *pipeline.Bus
 	subscriptions   map[string]*Subscription
+	tradingDisabled map[string]bool // 只接收信号、不自动交易的交易对
 	mu              sync.RWMutex
 	ctx             context.Context
 	cancel          context.CancelFunc
@@ -35,6 +41,10 @@ type Subscription struct {
 	CreatedAt time.Time
 	LastData  time.Time
 	Handlers  []DataHandler
+	// PendingStatus 交易对暂停交易时的交易所状态，非空表示等待恢复后自动订阅
+	PendingStatus string
+	// TradingEnabled 是否按该交易对的信号自动交易，关闭时仍接收行情和信号
+	TradingEnabled bool
 }
 
 // DataHandler 数据处理器接口
@@ -47,29 +57,73 @@ type DataHandler interface {
 // StrategyHandler 策略数据处理器
 type StrategyHandler struct {
 	strategyManager *strategy.StrategyManager
+	binanceClient   *binance.Client
+	eventBus        *pipeline.Bus
 	logger          logger.Logger
+	paused          map[string]bool // 正在预热、暂停实时处理的交易对
+	mu              sync.RWMutex
+	// 24h行情统计缓存，超过有效期未更新的不再对外提供
+	tickerStats       map[string]*TickerStats
+	tickerStatsMaxAge time.Duration
+	// 最新价格缓存，供交易执行读取
+	prices *PriceCache
+	// 收盘缓冲：延迟确认收盘K线，避免使用提前推送的非最终价格
+	closeDelay  time.Duration
+	verifyClose bool
+	ctx         context.Context
+	wg          sync.WaitGroup
+	// 收到K线数据（含未收盘）时的回调
+	onKline func(symbol, interval string)
 }
 
 // New 创建新的流管理器
-func New(cfg *config.Config, log logger.Logger, strategyMgr *strategy.StrategyManager) (*StreamManager, error) {
+func New(cfg *config.Config, log logger.Logger, client *binance.Client, strategyMgr *strategy.StrategyManager, bus *pipeline.Bus) (*StreamManager, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	tradingDisabled := make(map[string]bool, len(cfg.Trading.DisabledSymbols))
+	for _, symbol := range cfg.Trading.DisabledSymbols {
+		tradingDisabled[strings.ToUpper(symbol)] = true
+	}
+
 	// 创建币安WebSocket客户端
-	binanceWS, err := binance.NewWebSocketClient(cfg.GetBinanceWSURL(), log)
+	binanceWS, err := binance.NewWebSocketClient(cfg.GetBinanceWSURL(), cfg.Binance.ProxyURL, log)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to create binance websocket client: %w", err)
 	}
 
-	return &StreamManager{
+	sm := &StreamManager{
 		config:          cfg,
 		logger:          log,
 		binanceWS:       binanceWS,
+		binanceClient:   client,
 		strategyManager: strategyMgr,
+		strategyHandler: &StrategyHandler{
+			strategyManager: strategyMgr,
+			binanceClient:   client,
+			eventBus:        bus,
+			logger:          log,
+			paused:          make(map[string]bool),
+			closeDelay:      time.Duration(cfg.Trading.CandleCloseDelayMs) * time.Millisecond,
+			verifyClose:     cfg.Trading.VerifyCandleClose,
+			ctx:             ctx,
+
+			tickerStats:       make(map[string]*TickerStats),
+			tickerStatsMaxAge: time.Duration(cfg.Trading.TickerStatsMaxAgeSeconds) * time.Second,
+
+			prices: NewPriceCache(),
+		},
+		eventBus:        bus,
 		subscriptions:   make(map[string]*Subscription),
+		tradingDisabled: tradingDisabled,
 		ctx:             ctx,
 		cancel:          cancel,
 		running:         false,
-	}, nil
+	}
+	// 收到K线即更新订阅的最后数据时间，供订阅健康检查判断数据流是否中断
+	sm.strategyHandler.onKline = sm.updateLastDataTime
+
+	return sm, nil
 }
 
 // Start 启动流管理器
@@ -82,11 +136,7 @@ func (sm *StreamManager) Start() error {
 	}
 
 	// 设置数据处理器
-	strategyHandler := &StrategyHandler{
-		strategyManager: sm.strategyManager,
-		logger:          sm.logger,
-	}
-	sm.binanceWS.SetStreamHandler(strategyHandler)
+	sm.binanceWS.SetStreamHandler(sm.strategyHandler)
 
 	// 启动WebSocket客户端
 	if err := sm.binanceWS.Start(); err != nil {
@@ -118,6 +168,9 @@ func (sm *StreamManager) Stop() error {
 	// 停止WebSocket客户端
 	sm.binanceWS.Stop()
 
+	// 等待收盘缓冲中的K线处理结束
+	sm.strategyHandler.wg.Wait()
+
 	// 等待所有协程结束
 	sm.wg.Wait()
 
@@ -127,20 +180,71 @@ func (sm *StreamManager) Stop() error {
 	return nil
 }
 
-// Subscribe 订阅数据流
+// Subscribe 订阅数据流并回填历史K线，使策略通道立即可用。交易对不在 TRADING 状态时不订阅，记录为待恢复并定期复查
 func (sm *StreamManager) Subscribe(symbol, interval string) error {
+	if err := sm.subscribeStreams(symbol, interval); err != nil {
+		return err
+	}
+
+	sm.warmUpOnSubscribe(symbol)
+	return nil
+}
+
+// subscribeStreams 向交易所订阅交易对的K线和价格数据流
+func (sm *StreamManager) subscribeStreams(symbol, interval string) error {
+	status, err := sm.symbolStatus(symbol)
+	if err != nil {
+		return err
+	}
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
 	key := fmt.Sprintf("%s_%s", symbol, interval)
 
+	if status != binance.SymbolStatusTrading {
+		if sub, exists := sm.subscriptions[key]; exists {
+			if sub.Active {
+				return fmt.Errorf("already subscribed to %s %s", symbol, interval)
+			}
+			sub.PendingStatus = status
+		} else {
+			sm.subscriptions[key] = &Subscription{
+				Symbol:        symbol,
+				Interval:      interval,
+				CreatedAt:     time.Now(),
+				Handlers:      []DataHandler{},
+				PendingStatus: status,
+			}
+		}
+		sm.logger.Warnf("Symbol %s is not trading (status %s), subscription deferred", symbol, status)
+		return fmt.Errorf("symbol %s is not trading (status %s), will subscribe when it resumes", symbol, status)
+	}
+
 	// 检查是否已经订阅
-	if sub, exists := sm.subscriptions[key]; exists {
-		if sub.Active {
-			return fmt.Errorf("already subscribed to %s %s", symbol, interval)
+	sub, exists := sm.subscriptions[key]
+	if exists && sub.Active {
+		return fmt.Errorf("already subscribed to %s %s", symbol, interval)
+	}
+
+	// 订阅K线数据
+	if err := sm.binanceWS.SubscribeKline(symbol, interval); err != nil {
+		return fmt.Errorf("failed to subscribe kline: %w", err)
+	}
+
+	// 订阅价格数据，失败时撤回K线订阅，订阅状态保持不变
+	if err := sm.binanceWS.SubscribeTicker(symbol); err != nil {
+		if unsubErr := sm.binanceWS.UnsubscribeKline(symbol, interval); unsubErr != nil {
+			sm.logger.Errorf("Failed to unsubscribe kline: %v", unsubErr)
 		}
+		return fmt.Errorf("failed to subscribe ticker: %w", err)
+	}
+
+	// 数据流订阅成功后才标记为有效
+	if exists {
 		sub.Active = true
 		sub.LastData = time.Now()
+		sub.PendingStatus = ""
 	} else {
 		// 创建新订阅
 		sm.subscriptions[key] = &Subscription{
@@ -153,16 +257,6 @@ func (sm *StreamManager) Subscribe(symbol, interval string) error {
 		}
 	}
 
-	// 订阅K线数据
-	if err := sm.binanceWS.SubscribeKline(symbol, interval); err != nil {
-		return fmt.Errorf("failed to subscribe kline: %w", err)
-	}
-
-	// 订阅价格数据
-	if err := sm.binanceWS.SubscribeTicker(symbol); err != nil {
-		return fmt.Errorf("failed to subscribe ticker: %w", err)
-	}
-
 	sm.logger.Infof("Subscribed to %s %s", symbol, interval)
 	return nil
 }
@@ -175,14 +269,23 @@ func (sm *StreamManager) Unsubscribe(symbol, interval string) error {
 	key := fmt.Sprintf("%s_%s", symbol, interval)
 
 	if sub, exists := sm.subscriptions[key]; exists {
+		if sub.PendingStatus != "" {
+			// 仍在等待恢复，尚未向交易所订阅
+			delete(sm.subscriptions, key)
+			sm.logger.Infof("Cancelled pending subscription %s %s", symbol, interval)
+			return nil
+		}
 		sub.Active = false
 		// 取消订阅K线数据
 		if err := sm.binanceWS.UnsubscribeKline(symbol, interval); err != nil {
 			sm.logger.Errorf("Failed to unsubscribe kline: %v", err)
 		}
-		// 取消订阅价格数据
-		if err := sm.binanceWS.UnsubscribeTicker(symbol); err != nil {
-			sm.logger.Errorf("Failed to unsubscribe ticker: %v", err)
+		// 价格数据按交易对订阅，同一交易对的其他周期仍在订阅时保留
+		if !sm.hasActiveSubscriptionLocked(symbol) {
+			if err := sm.binanceWS.UnsubscribeTicker(symbol); err != nil {
+				sm.logger.Errorf("Failed to unsubscribe ticker: %v", err)
+			}
+			sm.strategyHandler.prices.Remove(symbol)
 		}
 		sm.logger.Infof("Unsubscribed from %s %s", symbol, interval)
 	}
@@ -190,6 +293,38 @@ func (sm *StreamManager) Unsubscribe(symbol, interval string) error {
 	return nil
 }
 
+// SuspendSymbol 交易对停止交易时取消其全部行情订阅：仍在交易所规则中的记录为待恢复，恢复 TRADING 后自动重新订阅；
+// status 为空表示已下架，直接移除订阅
+func (sm *StreamManager) SuspendSymbol(symbol, status string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for key, sub := range sm.subscriptions {
+		if sub.Symbol != symbol {
+			continue
+		}
+
+		if sub.Active {
+			if err := sm.binanceWS.UnsubscribeKline(symbol, sub.Interval); err != nil {
+				sm.logger.Errorf("Failed to unsubscribe kline: %v", err)
+			}
+			if err := sm.binanceWS.UnsubscribeTicker(symbol); err != nil {
+				sm.logger.Errorf("Failed to unsubscribe ticker: %v", err)
+			}
+			sm.strategyHandler.prices.Remove(symbol)
+			sub.Active = false
+		}
+
+		if status == "" {
+			delete(sm.subscriptions, key)
+			sm.logger.Warnf("Symbol %s delisted, removed subscription %s", symbol, sub.Interval)
+			continue
+		}
+		sub.PendingStatus = status
+		sm.logger.Warnf("Symbol %s not trading (status %s), subscription %s suspended", symbol, status, sub.Interval)
+	}
+}
+
 // GetSubscriptions 获取所有订阅
 func (sm *StreamManager) GetSubscriptions() map[string]*Subscription {
 	sm.mu.RLock()
@@ -204,12 +339,73 @@ func (sm *StreamManager) GetSubscriptions() map[string]*Subscription {
 			Active:    v.Active,
 			CreatedAt: v.CreatedAt,
 			LastData:  v.LastData,
+
+			PendingStatus:  v.PendingStatus,
+			TradingEnabled: !sm.tradingDisabled[v.Symbol],
 		}
 	}
 
 	return result
 }
 
+// SetTradingEnabled 开启或暂停交易对的自动交易（不影响行情订阅和信号推送），返回状态是否发生变化
+func (sm *StreamManager) SetTradingEnabled(symbol string, enabled bool) bool {
+	symbol = strings.ToUpper(symbol)
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if enabled == !sm.tradingDisabled[symbol] {
+		return false
+	}
+	if enabled {
+		delete(sm.tradingDisabled, symbol)
+		sm.logger.Infof("Trading for %s enabled", symbol)
+	} else {
+		sm.tradingDisabled[symbol] = true
+		sm.logger.Infof("Trading for %s paused, still streaming signals", symbol)
+	}
+	return true
+}
+
+// IsSubscribed 检查交易对和周期是否已在订阅中
+func (sm *StreamManager) IsSubscribed(symbol, interval string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	sub, exists := sm.subscriptions[fmt.Sprintf("%s_%s", symbol, interval)]
+	return exists && sub.Active
+}
+
+// hasActiveSubscriptionLocked 交易对是否还有任一周期的有效订阅。调用方需持有 sm.mu
+func (sm *StreamManager) hasActiveSubscriptionLocked(symbol string) bool {
+	for _, sub := range sm.subscriptions {
+		if sub.Symbol == symbol && sub.Active {
+			return true
+		}
+	}
+	return false
+}
+
+// TradingEnabled 交易对是否自动交易
+func (sm *StreamManager) TradingEnabled(symbol string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return !sm.tradingDisabled[strings.ToUpper(symbol)]
+}
+
+// TradingDisabledSymbols 获取暂停自动交易的交易对
+func (sm *StreamManager) TradingDisabledSymbols() []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	symbols := make([]string, 0, len(sm.tradingDisabled))
+	for symbol := range sm.tradingDisabled {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
 // IsRunning 检查是否正在运行
 func (sm *StreamManager) IsRunning() bool {
 	sm.mu.RLock()
@@ -217,6 +413,21 @@ func (sm *StreamManager) IsRunning() bool {
 	return sm.running
 }
 
+// SetReconnectHandler 设置WebSocket连续重连失败上限及告警回调
+func (sm *StreamManager) SetReconnectHandler(maxFailures int, handler binance.ReconnectHandler) {
+	sm.binanceWS.SetReconnectHandler(maxFailures, handler)
+}
+
+// SetFlappingHandler 设置WebSocket重连抖动检测阈值及回调
+func (sm *StreamManager) SetFlappingHandler(threshold int, window, stablePeriod time.Duration, handler binance.FlappingHandler) {
+	sm.binanceWS.SetFlappingHandler(threshold, window, stablePeriod, handler)
+}
+
+// IsConnected 检查WebSocket是否已连接
+func (sm *StreamManager) IsConnected() bool {
+	return sm.binanceWS.IsConnected()
+}
+
 // monitorConnections 监控连接状态
 func (sm *StreamManager) monitorConnections() {
 	defer sm.wg.Done()
@@ -224,12 +435,78 @@ func (sm *StreamManager) monitorConnections() {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
+	statusTicker := time.NewTicker(sm.symbolStatusInterval())
+	defer statusTicker.Stop()
+
 	for {
 		select {
 		case <-sm.ctx.Done():
 			return
 		case <-ticker.C:
 			sm.checkSubscriptionHealth()
+		case <-statusTicker.C:
+			sm.checkPendingSymbols()
+		}
+	}
+}
+
+// symbolStatusInterval 暂停交易的交易对状态复查间隔
+func (sm *StreamManager) symbolStatusInterval() time.Duration {
+	minutes := sm.config.Trading.SymbolStatusCheckMinutes
+	if minutes <= 0 {
+		minutes = 10
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// symbolStatus 从交易所规则获取交易对状态
+func (sm *StreamManager) symbolStatus(symbol string) (string, error) {
+	info, err := sm.binanceClient.GetExchangeInfo(sm.ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get exchange info: %w", err)
+	}
+	for _, s := range info.Symbols {
+		if s.Symbol == symbol {
+			return s.Status, nil
+		}
+	}
+	return "", fmt.Errorf("symbol %s not found in exchange info", symbol)
+}
+
+// checkPendingSymbols 复查等待恢复的订阅，交易对恢复 TRADING 后自动订阅
+func (sm *StreamManager) checkPendingSymbols() {
+	sm.mu.RLock()
+	var pending []*Subscription
+	for _, sub := range sm.subscriptions {
+		if sub.PendingStatus != "" {
+			pending = append(pending, &Subscription{Symbol: sub.Symbol, Interval: sub.Interval})
+		}
+	}
+	sm.mu.RUnlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	info, err := sm.binanceClient.GetExchangeInfo(sm.ctx)
+	if err != nil {
+		sm.logger.Warnf("Failed to re-check pending symbols: %v", err)
+		return
+	}
+	statuses := make(map[string]string, len(info.Symbols))
+	for _, s := range info.Symbols {
+		statuses[s.Symbol] = s.Status
+	}
+
+	for _, p := range pending {
+		status := statuses[p.Symbol]
+		if status != binance.SymbolStatusTrading {
+			sm.logger.Debugf("Symbol %s still not trading (status %s)", p.Symbol, status)
+			continue
+		}
+		sm.logger.Infof("Symbol %s is trading again, resuming subscription %s", p.Symbol, p.Interval)
+		if err := sm.Subscribe(p.Symbol, p.Interval); err != nil {
+			sm.logger.Errorf("Failed to resume subscription %s %s: %v", p.Symbol, p.Interval, err)
 		}
 	}
 }
@@ -275,22 +552,9 @@ func (sh *StrategyHandler) HandleKlineData(data *binance.KlineStreamData) error
 		return fmt.Errorf("received nil kline data")
 	}
 
-	// 转换价格字符串为 decimal.Decimal
-	open, _ := decimal.NewFromString(data.Data.Kline.Open)
-	high, _ := decimal.NewFromString(data.Data.Kline.High)
-	low, _ := decimal.NewFromString(data.Data.Kline.Low)
-	close, _ := decimal.NewFromString(data.Data.Kline.Close)
-	volume, _ := decimal.NewFromString(data.Data.Kline.Volume)
-
-	// 转换为策略所需的K线数据格式
-	klineData := &strategy.KlineData{
-		Symbol:    data.Data.Symbol,
-		Open:      open,
-		High:      high,
-		Low:       low,
-		Close:     close,
-		Volume:    volume,
-		Timestamp: time.Unix(data.Data.Kline.StartTime/1000, 0),
+	// 未收盘的K线也说明数据流正常
+	if sh.onKline != nil {
+		sh.onKline(data.Data.Symbol, data.Data.Kline.Interval)
 	}
 
 	// 只处理已关闭的K线
@@ -298,16 +562,108 @@ func (sh *StrategyHandler) HandleKlineData(data *binance.KlineStreamData) error
 		return nil
 	}
 
+	// 转换为策略所需的K线数据格式，异常数值直接报错而不是当作零值
+	parsed, err := data.ToDecimal()
+	if err != nil {
+		return fmt.Errorf("invalid kline for %s: %w", data.Data.Symbol, err)
+	}
+	kline := fromDecimalKline(data.Data.Symbol, parsed)
+	klineData := &kline
+
+	interval := data.Data.Kline.Interval
+	if sh.closeDelay <= 0 && !sh.verifyClose {
+		return sh.processClosedKline(klineData, interval)
+	}
+
+	// 收盘缓冲：延迟后（可选地核对REST数据）再交给策略，不阻塞WebSocket读取
+	openTime := data.Data.Kline.StartTime
+	sh.wg.Add(1)
+	go func() {
+		defer sh.wg.Done()
+
+		select {
+		case <-sh.ctx.Done():
+			return
+		case <-time.After(sh.closeDelay):
+		}
+
+		if sh.verifyClose {
+			sh.confirmClose(klineData, interval, openTime)
+		}
+
+		if err := sh.processClosedKline(klineData, interval); err != nil {
+			sh.logger.Errorf("Failed to process buffered kline for %s: %v", klineData.Symbol, err)
+		}
+	}()
+
+	return nil
+}
+
+// processClosedKline 发布收盘事件并执行策略分析
+func (sh *StrategyHandler) processClosedKline(klineData *strategy.KlineData, interval string) error {
+	// 策略只按15M K线计算，其他周期的K线交给策略会被当作15M数据
+	if interval != string(binance.Interval15m) {
+		sh.logger.Debugf("Skipping %s kline for %s, strategy only uses %s", interval, klineData.Symbol, binance.Interval15m)
+		return nil
+	}
+
+	// 预热期间跳过实时K线，避免与回填数据交错
+	if sh.isPaused(klineData.Symbol) {
+		sh.logger.Debugf("Skipping kline for %s during warm-up", klineData.Symbol)
+		return nil
+	}
+
+	sh.eventBus.Publish(pipeline.Event{
+		Stage:    pipeline.StageCandleReceived,
+		Symbol:   klineData.Symbol,
+		Interval: interval,
+		Kline:    klineData,
+	})
+
 	// 执行策略分析
-	if err := sh.strategyManager.ProcessKlineData(klineData); err != nil {
-		sh.logger.Errorf("Failed to process kline data for %s: %v", data.Data.Symbol, err)
+	if _, err := sh.strategyManager.ProcessKlineData(klineData); err != nil {
+		sh.logger.Errorf("Failed to process kline data for %s: %v", klineData.Symbol, err)
 		return err
 	}
 
-	sh.logger.Debugf("Processed kline data for %s: %s", data.Data.Symbol, klineData.Close.String())
+	sh.logger.Debugf("Processed kline data for %s: %s", klineData.Symbol, klineData.Close.String())
 	return nil
 }
 
+// confirmClose 用REST接口的同一根K线核对收盘数据，不一致时以REST为准
+func (sh *StrategyHandler) confirmClose(klineData *strategy.KlineData, interval string, openTime int64) {
+	if sh.binanceClient == nil {
+		return
+	}
+
+	klines, err := sh.binanceClient.GetKlines(sh.ctx, klineData.Symbol, interval, 2)
+	if err != nil {
+		sh.logger.Warnf("Failed to verify candle close for %s, using stream data: %v", klineData.Symbol, err)
+		return
+	}
+
+	for _, kline := range klines {
+		if kline.OpenTime != openTime {
+			continue
+		}
+
+		confirmed, err := toKlineData(klineData.Symbol, kline)
+		if err != nil {
+			sh.logger.Warnf("Invalid REST kline for %s, using stream data: %v", klineData.Symbol, err)
+			return
+		}
+
+		if !confirmed.Close.Equal(klineData.Close) {
+			sh.logger.Infof("Candle close for %s revised from %s to %s",
+				klineData.Symbol, klineData.Close.String(), confirmed.Close.String())
+		}
+		*klineData = confirmed
+		return
+	}
+
+	sh.logger.Warnf("Candle %d for %s not found via REST, using stream data", openTime, klineData.Symbol)
+}
+
 // HandleTickerData 处理价格数据
 func (sh *StrategyHandler) HandleTickerData(data *binance.TickerStreamData) error {
 	if data == nil {
@@ -315,10 +671,38 @@ func (sh *StrategyHandler) HandleTickerData(data *binance.TickerStreamData) erro
 	}
 
 	sh.logger.Debugf("Received ticker data for %s: %s", data.Data.Symbol, data.Data.LastPrice)
+
+	stats, err := tickerStatsFrom(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse ticker stats for %s: %w", data.Data.Symbol, err)
+	}
+	sh.updateTickerStats(stats)
+	sh.prices.Update(stats.Symbol, stats.LastPrice, stats.UpdatedAt)
 	return nil
 }
 
 // GetName 获取处理器名称
 func (sh *StrategyHandler) GetName() string {
 	return "StrategyHandler"
-}
\ No newline at end of file
+}
+
+// pause 暂停交易对的实时K线处理
+func (sh *StrategyHandler) pause(symbol string) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.paused[symbol] = true
+}
+
+// resume 恢复交易对的实时K线处理
+func (sh *StrategyHandler) resume(symbol string) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	delete(sh.paused, symbol)
+}
+
+// isPaused 检查交易对是否暂停实时处理
+func (sh *StrategyHandler) isPaused(symbol string) bool {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.paused[symbol]
+}
@@ -0,0 +1,147 @@
+package stream
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// newTestStrategyHandler 创建注册了维加斯隧道策略、不缓冲收盘K线的策略数据处理器
+func newTestStrategyHandler(t *testing.T) (*StrategyHandler, *strategy.StrategyManager) {
+	t.Helper()
+
+	log := logger.NewLoggerWithLevel("error")
+	strategyMgr := strategy.NewStrategyManager(log)
+	if err := strategyMgr.RegisterStrategy("vegas", strategy.NewVegasTunnelStrategy(log)); err != nil {
+		t.Fatalf("register strategy: %v", err)
+	}
+
+	return &StrategyHandler{
+		strategyManager: strategyMgr,
+		logger:          log,
+		paused:          make(map[string]bool),
+		tickerStats:     make(map[string]*TickerStats),
+		prices:          NewPriceCache(),
+	}, strategyMgr
+}
+
+// newTestStreamManager 创建未启动的流管理器，不连接交易所
+func newTestStreamManager(t *testing.T) *StreamManager {
+	t.Helper()
+
+	log := logger.NewLoggerWithLevel("error")
+	strategyMgr := strategy.NewStrategyManager(log)
+	sm, err := New(&config.Config{}, log, nil, strategyMgr, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(sm.cancel)
+	return sm
+}
+
+// addSubscription 直接记录一个有效订阅并订阅其K线和价格数据流，跳过交易所状态检查
+func (sm *StreamManager) addSubscription(symbol, interval string) {
+	sm.subscriptions[symbol+"_"+interval] = &Subscription{Symbol: symbol, Interval: interval, Active: true, CreatedAt: time.Now(), LastData: time.Now()}
+	sm.binanceWS.SubscribeKline(symbol, interval)
+	sm.binanceWS.SubscribeTicker(symbol)
+}
+
+// streams 返回WebSocket客户端已订阅的流（排序后）
+func (sm *StreamManager) streams() []string {
+	streams := sm.binanceWS.GetStreams()
+	sort.Strings(streams)
+	return streams
+}
+
+// closedKline 构建指定周期的已收盘K线推送
+func closedKline(symbol, interval string, open time.Time) *binance.KlineStreamData {
+	data := &binance.KlineStreamData{}
+	data.Data.Symbol = symbol
+	data.Data.Kline.Symbol = symbol
+	data.Data.Kline.Interval = interval
+	data.Data.Kline.StartTime = open.UnixMilli()
+	data.Data.Kline.EndTime = open.Add(15*time.Minute).UnixMilli() - 1
+	data.Data.Kline.Open = "30000"
+	data.Data.Kline.High = "30100"
+	data.Data.Kline.Low = "29900"
+	data.Data.Kline.Close = "30050"
+	data.Data.Kline.Volume = "10"
+	data.Data.Kline.IsClosed = true
+	return data
+}
+
+func TestNonStrategyIntervalKlineSkipped(t *testing.T) {
+	sh, strategyMgr := newTestStrategyHandler(t)
+	open := time.Now().Truncate(time.Hour).Add(-time.Hour)
+
+	for _, interval := range []string{"1m", "1h", "4h"} {
+		if err := sh.HandleKlineData(closedKline("BTCUSDT", interval, open)); err != nil {
+			t.Fatalf("HandleKlineData(%s): %v", interval, err)
+		}
+	}
+	if klines, _, ok := strategyMgr.ChartData("BTCUSDT", 0); ok {
+		t.Fatalf("strategy buffer has %d klines after non-15m klines, want none", len(klines))
+	}
+
+	if err := sh.HandleKlineData(closedKline("BTCUSDT", "15m", open)); err != nil {
+		t.Fatalf("HandleKlineData(15m): %v", err)
+	}
+	if klines, _, _ := strategyMgr.ChartData("BTCUSDT", 0); len(klines) != 1 {
+		t.Errorf("strategy buffer has %d klines after one 15m kline, want 1", len(klines))
+	}
+}
+
+func TestUnsubscribeKeepsTickerWhileSymbolSubscribed(t *testing.T) {
+	sm := newTestStreamManager(t)
+	sm.addSubscription("BTCUSDT", "15m")
+	sm.addSubscription("BTCUSDT", "1h")
+	sm.strategyHandler.prices.Update("BTCUSDT", decimal.RequireFromString("30000"), time.Now())
+
+	// 同一交易对仍有其他周期订阅时保留价格数据
+	if err := sm.Unsubscribe("BTCUSDT", "1h"); err != nil {
+		t.Fatalf("Unsubscribe(1h): %v", err)
+	}
+	if got := strings.Join(sm.streams(), ","); got != "btcusdt@kline_15m,btcusdt@ticker" {
+		t.Errorf("streams after unsubscribing 1h = %s, want the 15m kline and the ticker", got)
+	}
+	if _, _, ok := sm.strategyHandler.prices.GetPrice("BTCUSDT"); !ok {
+		t.Error("price dropped while BTCUSDT 15m is still subscribed")
+	}
+
+	// 最后一个周期取消后价格数据随之取消
+	if err := sm.Unsubscribe("BTCUSDT", "15m"); err != nil {
+		t.Fatalf("Unsubscribe(15m): %v", err)
+	}
+	if streams := sm.streams(); len(streams) != 0 {
+		t.Errorf("streams after unsubscribing every interval = %v, want none", streams)
+	}
+	if _, _, ok := sm.strategyHandler.prices.GetPrice("BTCUSDT"); ok {
+		t.Error("price kept after the last BTCUSDT subscription was removed")
+	}
+}
+
+func TestKlineRefreshesSubscriptionLastData(t *testing.T) {
+	sm := newTestStreamManager(t)
+	sm.addSubscription("BTCUSDT", "15m")
+	stale := time.Now().Add(-5 * time.Minute)
+	sm.subscriptions["BTCUSDT_15m"].LastData = stale
+
+	// 未收盘的K线同样说明数据流正常
+	kline := closedKline("BTCUSDT", "15m", time.Now().Truncate(15*time.Minute))
+	kline.Data.Kline.IsClosed = false
+	if err := sm.strategyHandler.HandleKlineData(kline); err != nil {
+		t.Fatalf("HandleKlineData: %v", err)
+	}
+
+	if last := sm.GetSubscriptions()["BTCUSDT_15m"].LastData; !last.After(stale) {
+		t.Errorf("LastData = %v after receiving a kline, want it refreshed from %v", last, stale)
+	}
+}
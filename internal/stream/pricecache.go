@@ -0,0 +1,61 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CachedPrice 交易对的最新成交价
+type CachedPrice struct {
+	Price     decimal.Decimal
+	UpdatedAt time.Time // 对应ticker推送的事件时间
+}
+
+// PriceCache 由ticker推送维护的最新价格缓存，可并发读写
+type PriceCache struct {
+	mu     sync.RWMutex
+	prices map[string]CachedPrice
+}
+
+// NewPriceCache 创建价格缓存
+func NewPriceCache() *PriceCache {
+	return &PriceCache{prices: make(map[string]CachedPrice)}
+}
+
+// Update 更新交易对的最新价格，事件时间早于已缓存价格时忽略（乱序推送）
+func (pc *PriceCache) Update(symbol string, price decimal.Decimal, at time.Time) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if cached, ok := pc.prices[symbol]; ok && at.Before(cached.UpdatedAt) {
+		return
+	}
+	pc.prices[symbol] = CachedPrice{Price: price, UpdatedAt: at}
+}
+
+// GetPrice 获取交易对的最新价格及其更新时间，从未收到推送时 ok 为 false。
+// 调用方应根据 updatedAt 判断价格是否过期
+func (pc *PriceCache) GetPrice(symbol string) (price decimal.Decimal, updatedAt time.Time, ok bool) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	cached, ok := pc.prices[symbol]
+	if !ok {
+		return decimal.Zero, time.Time{}, false
+	}
+	return cached.Price, cached.UpdatedAt, true
+}
+
+// Remove 删除交易对的缓存价格，取消订阅后不再提供过期价格
+func (pc *PriceCache) Remove(symbol string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	delete(pc.prices, symbol)
+}
+
+// PriceCache 获取由ticker推送维护的价格缓存
+func (sm *StreamManager) PriceCache() *PriceCache {
+	return sm.strategyHandler.prices
+}
@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// TickerStats 交易对的24h滚动行情统计
+type TickerStats struct {
+	Symbol             string
+	LastPrice          decimal.Decimal
+	PriceChange        decimal.Decimal
+	PriceChangePercent decimal.Decimal // 24h涨跌幅（百分比，如2.35表示+2.35%）
+	HighPrice          decimal.Decimal
+	LowPrice           decimal.Decimal
+	Volume             decimal.Decimal // 基础资产成交量
+	QuoteVolume        decimal.Decimal // 报价资产成交额
+	UpdatedAt          time.Time
+}
+
+// tickerStatsFrom 解析ticker推送中的24h统计
+func tickerStatsFrom(data *binance.TickerStreamData) (*TickerStats, error) {
+	fields := []struct {
+		raw  string
+		name string
+	}{
+		{data.Data.LastPrice, "last price"},
+		{data.Data.PriceChange, "price change"},
+		{data.Data.PriceChangePercent, "price change percent"},
+		{data.Data.HighPrice, "high price"},
+		{data.Data.LowPrice, "low price"},
+		{data.Data.Volume, "volume"},
+		{data.Data.QuoteVolume, "quote volume"},
+	}
+
+	values := make([]decimal.Decimal, len(fields))
+	for i, field := range fields {
+		value, err := decimal.NewFromString(field.raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", field.name, field.raw, err)
+		}
+		values[i] = value
+	}
+
+	updatedAt := time.Now()
+	if data.Data.EventTime > 0 {
+		updatedAt = time.UnixMilli(data.Data.EventTime)
+	}
+
+	return &TickerStats{
+		Symbol:             data.Data.Symbol,
+		LastPrice:          values[0],
+		PriceChange:        values[1],
+		PriceChangePercent: values[2],
+		HighPrice:          values[3],
+		LowPrice:           values[4],
+		Volume:             values[5],
+		QuoteVolume:        values[6],
+		UpdatedAt:          updatedAt,
+	}, nil
+}
+
+// updateTickerStats 更新交易对的24h统计缓存
+func (sh *StrategyHandler) updateTickerStats(stats *TickerStats) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.tickerStats[stats.Symbol] = stats
+}
+
+// freshTickerStats 获取未过期的24h统计副本
+func (sh *StrategyHandler) freshTickerStats(symbol string) (TickerStats, bool) {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	stats, ok := sh.tickerStats[symbol]
+	if !ok || time.Since(stats.UpdatedAt) > sh.tickerStatsMaxAge {
+		return TickerStats{}, false
+	}
+	return *stats, true
+}
+
+// TickerStats 获取交易对最新的24h行情统计，未收到或已过期时 ok 为 false
+func (sm *StreamManager) TickerStats(symbol string) (TickerStats, bool) {
+	return sm.strategyHandler.freshTickerStats(symbol)
+}
+
+// AllTickerStats 获取所有已订阅交易对未过期的24h行情统计，按交易对排序
+func (sm *StreamManager) AllTickerStats() []TickerStats {
+	sm.mu.RLock()
+	seen := make(map[string]bool, len(sm.subscriptions))
+	symbols := make([]string, 0, len(sm.subscriptions))
+	for _, sub := range sm.subscriptions {
+		if sub.Active && !seen[sub.Symbol] {
+			seen[sub.Symbol] = true
+			symbols = append(symbols, sub.Symbol)
+		}
+	}
+	sm.mu.RUnlock()
+	sort.Strings(symbols)
+
+	result := make([]TickerStats, 0, len(symbols))
+	for _, symbol := range symbols {
+		if stats, ok := sm.strategyHandler.freshTickerStats(symbol); ok {
+			result = append(result, stats)
+		}
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// 指令审计结果
+const (
+	auditOK           = "ok"
+	auditUnknown      = "unknown"
+	auditUnauthorized = "unauthorized"
+
+	callbackAuditPrefix = "callback:"
+
+	defaultAuditEntries = 20
+	maxAuditEntries     = 100
+)
+
+// auditOutcome 将处理结果转换为审计结果
+func auditOutcome(err error) string {
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	return auditOK
+}
+
+// audit 记录一条指令，写入失败只记录日志不影响指令处理
+func (b *Bot) audit(from *tgbotapi.User, chatID int64, command, args, outcome string) {
+	entry := &database.CommandAudit{
+		ChatID:  chatID,
+		Command: command,
+		Args:    args,
+		Outcome: outcome,
+	}
+	if from != nil {
+		entry.UserID = from.ID
+		entry.Username = from.UserName
+	}
+
+	if err := b.auditRepo.Create(entry); err != nil {
+		b.logger.Errorf("Failed to record command audit for /%s: %v", command, err)
+	}
+}
+
+// AuditHandler 指令审计查询处理器
+type AuditHandler struct{}
+
+func (h *AuditHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	limit := defaultAuditEntries
+	if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return bot.SendMessage("❌ 条数必须是正整数\n\n用法: /audit [条数]")
+		}
+		limit = min(n, maxAuditEntries)
+	}
+
+	entries, err := bot.auditRepo.GetRecent(limit)
+	if err != nil {
+		bot.logger.Errorf("Failed to get command audit: %v", err)
+		return bot.SendMessage(fmt.Sprintf("❌ 获取审计记录失败: %v", err))
+	}
+
+	if len(entries) == 0 {
+		return bot.SendMessage("📋 暂无指令审计记录")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 *指令审计（最近%d条）*\n\n", len(entries))
+	for _, entry := range entries {
+		icon := "✅"
+		switch {
+		case entry.Outcome == auditUnauthorized:
+			icon = "🚫"
+		case entry.Outcome != auditOK:
+			icon = "⚠️"
+		}
+
+		user := entry.Username
+		if user == "" {
+			user = strconv.FormatInt(entry.UserID, 10)
+		}
+
+		command := entry.Command
+		if !strings.HasPrefix(command, callbackAuditPrefix) {
+			command = "/" + command
+		}
+		if entry.Args != "" {
+			command += " " + entry.Args
+		}
+
+		fmt.Fprintf(&b, "%s `%s` %s\n", icon, entry.CreatedAt.Format("01-02 15:04:05"),
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, user))
+		fmt.Fprintf(&b, "    %s", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, command))
+		if entry.Outcome != auditOK {
+			fmt.Fprintf(&b, " → %s", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, entry.Outcome))
+		}
+		b.WriteString("\n")
+	}
+
+	return bot.SendLongMarkdownMessage(b.String())
+}
+
+func (h *AuditHandler) Description() string {
+	return "查看最近的指令审计记录"
+}
@@ -0,0 +1,186 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/backtest"
+)
+
+const (
+	// defaultBacktestDays 未指定天数时的回测区间
+	defaultBacktestDays = 30
+	// maxBacktestDays 回测区间上限
+	maxBacktestDays = 365
+	// backtestProgressInterval 编辑进度消息的最小间隔，避免触发Telegram频率限制
+	backtestProgressInterval = 5 * time.Second
+)
+
+// BacktestHandler 策略回测处理器：后台运行回测并编辑同一条消息汇报进度，
+// 中断后以相同参数再次执行时从检查点继续
+type BacktestHandler struct {
+	mu      sync.Mutex
+	running map[string]bool // 正在回测的交易对
+}
+
+func (h *BacktestHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return bot.SendMessage(fmt.Sprintf("❌ 请指定交易对\n\n用法: /backtest BTCUSDT [天数] [restart]\n天数默认%d，最多%d", defaultBacktestDays, maxBacktestDays))
+	}
+
+	symbol := strings.ToUpper(args[0])
+	days := 0
+	restart := false
+	for _, arg := range args[1:] {
+		if strings.EqualFold(arg, "restart") {
+			restart = true
+			continue
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 || n > maxBacktestDays {
+			return bot.SendMessage(fmt.Sprintf("❌ 无效的天数: %s（1-%d）", arg, maxBacktestDays))
+		}
+		days = n
+	}
+
+	backtester := bot.services.Backtester
+	if backtester == nil {
+		return bot.SendMessage("❌ 回测服务不可用")
+	}
+
+	if !h.start(symbol) {
+		return bot.SendMessage(fmt.Sprintf("⏳ %s 的回测正在进行中", symbol))
+	}
+
+	if restart {
+		if err := backtester.DiscardCheckpoint(symbol); err != nil {
+			h.finish(symbol)
+			return bot.SendMessage(fmt.Sprintf("❌ 删除 %s 的回测检查点失败: %v", symbol, err))
+		}
+	}
+
+	req, resuming := h.request(bot, backtester, symbol, days)
+
+	status := fmt.Sprintf("🧪 %s 回测开始：%s ~ %s", symbol, req.Start.Format("2006-01-02 15:04"), req.End.Format("2006-01-02 15:04"))
+	if resuming {
+		status = fmt.Sprintf("🧪 %s 回测从检查点继续：%s ~ %s", symbol, req.Start.Format("2006-01-02 15:04"), req.End.Format("2006-01-02 15:04"))
+	}
+	messageID, err := bot.SendTrackedMessage(status)
+	if err != nil {
+		h.finish(symbol)
+		return fmt.Errorf("failed to send backtest status: %w", err)
+	}
+
+	// 回测可能持续数分钟，在后台运行以免阻塞其他指令；机器人停止时中断并保留检查点
+	go func() {
+		defer h.finish(symbol)
+		h.run(ctx, bot, backtester, req, messageID)
+	}()
+	return nil
+}
+
+// request 确定回测区间：存在检查点且天数一致（或未指定天数）时沿用检查点的区间继续，
+// 否则以最近一根已收盘的15M K线为终点
+func (h *BacktestHandler) request(bot *Bot, backtester *backtest.Backtester, symbol string, days int) (backtest.Request, bool) {
+	checkpoint, err := backtester.LoadCheckpoint(symbol)
+	if err != nil {
+		bot.logger.Warnf("Ignoring unreadable backtest checkpoint for %s: %v", symbol, err)
+	}
+	if checkpoint != nil && (days == 0 || checkpointDays(checkpoint) == days) {
+		return backtest.Request{Symbol: symbol, Start: checkpoint.Start, End: checkpoint.End}, true
+	}
+
+	if days == 0 {
+		days = defaultBacktestDays
+	}
+	end := time.Now().UTC().Truncate(15 * time.Minute).Add(-15 * time.Minute)
+	start := end.Add(-time.Duration(days) * 24 * time.Hour).Add(15 * time.Minute)
+	return backtest.Request{Symbol: symbol, Start: start, End: end}, false
+}
+
+// checkpointDays 检查点对应的回测天数
+func checkpointDays(checkpoint *backtest.Checkpoint) int {
+	return int((checkpoint.End.Sub(checkpoint.Start) + 15*time.Minute) / (24 * time.Hour))
+}
+
+// run 执行回测，定期编辑进度消息，结束后以结果替换进度
+func (h *BacktestHandler) run(ctx context.Context, bot *Bot, backtester *backtest.Backtester, req backtest.Request, messageID int) {
+	var lastEdit time.Time
+	result, err := backtester.Run(ctx, req, func(p backtest.Progress) {
+		if time.Since(lastEdit) < backtestProgressInterval {
+			return
+		}
+		lastEdit = time.Now()
+		if err := bot.EditMessage(messageID, formatBacktestProgress(p)); err != nil {
+			bot.logger.Warnf("Failed to edit backtest progress for %s: %v", req.Symbol, err)
+		}
+	})
+
+	text := formatBacktestResult(result)
+	if err != nil {
+		bot.logger.Errorf("Backtest for %s interrupted: %v", req.Symbol, err)
+		text = fmt.Sprintf("⚠️ %s 回测中断: %v\n\n已保存检查点，再次执行 /backtest %s 从中断处继续", req.Symbol, err, req.Symbol)
+		if result != nil {
+			text += fmt.Sprintf("\n已处理 %d 根K线，%d 笔交易", result.Processed, len(result.Trades))
+		}
+	}
+	if err := bot.EditMessage(messageID, text); err != nil {
+		bot.logger.Warnf("Failed to edit backtest result for %s: %v", req.Symbol, err)
+		bot.SendMessage(text)
+	}
+}
+
+// start 标记交易对开始回测，已在回测中时返回 false
+func (h *BacktestHandler) start(symbol string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.running[symbol] {
+		return false
+	}
+	h.running[symbol] = true
+	return true
+}
+
+// finish 标记交易对回测结束
+func (h *BacktestHandler) finish(symbol string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.running, symbol)
+}
+
+func (h *BacktestHandler) Description() string {
+	return "回测策略历史表现，支持断点续跑"
+}
+
+// formatBacktestProgress 格式化回测进度
+func formatBacktestProgress(p backtest.Progress) string {
+	resumed := ""
+	if p.Resumed {
+		resumed = "（从检查点继续）"
+	}
+	return fmt.Sprintf("🧪 %s 回测中%s\n\n进度: %.1f%%（%d/%d 根15M K线）\n已处理至: %s\n交易: %d 笔",
+		p.Symbol, resumed, p.Percent(), p.Processed, p.Total, p.Through.UTC().Format("2006-01-02 15:04"), p.Trades)
+}
+
+// formatBacktestResult 格式化回测结果
+func formatBacktestResult(result *backtest.Result) string {
+	if result == nil {
+		return ""
+	}
+
+	trades := len(result.Trades)
+	winRate := 0.0
+	if trades > 0 {
+		winRate = float64(result.Wins()) * 100 / float64(trades)
+	}
+
+	return fmt.Sprintf("✅ %s 回测完成\n\n区间: %s ~ %s（UTC）\nK线: %d 根15M\n交易: %d 笔，盈利 %d 笔，胜率 %.1f%%\n累计收益: %+.2f%%（不含杠杆和手续费）",
+		result.Symbol, result.Start.UTC().Format("2006-01-02 15:04"), result.End.UTC().Format("2006-01-02 15:04"),
+		result.Processed, trades, result.Wins(), winRate, result.NetReturnPercent())
+}
@@ -3,9 +3,16 @@ package telegram
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/backtest"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/stream"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
 )
 
@@ -14,29 +21,65 @@ type Bot struct {
 	api    *tgbotapi.BotAPI
 	config *config.TelegramConfig
 	logger logger.Logger
-	chatID int64
+	chatID int64 // 回复目标聊天：默认为管理员聊天，处理指令时为发起指令的聊天
+
+	// 授权聊天：ChatIDs 中的聊天可使用普通指令，管理员聊天可使用全部指令
+	adminChatID  int64
+	allowedChats map[int64]bool
+
+	// 仅管理员聊天可用的指令和回调前缀
+	adminCommands  map[string]bool
+	adminCallbacks map[string]bool
+
+	// 指令处理器依赖的服务
+	services *Services
 
 	// 指令处理器
 	commandHandlers map[string]CommandHandler
+
+	// 按钮回调处理器（按回调数据前缀路由）
+	callbackHandlers map[string]CallbackHandler
+
+	// 指令审计记录
+	auditRepo *database.CommandAuditRepository
 	
 	// 消息队列
 	messageQueue chan Message
+	senderDone   chan struct{} // 消息发送协程退出时关闭
 	
 	// 状态管理
 	isRunning bool
 }
 
+// Services 指令处理器可访问的服务依赖
+type Services struct {
+	AppConfig  *config.Config
+	DB         *database.Database
+	Binance    *binance.Client           // 在币安客户端创建后注入
+	Streams    *stream.StreamManager     // 在流管理器创建后注入
+	Executor   *trading.TradeExecutor    // 在交易执行器创建后注入
+	Strategies *strategy.StrategyManager // 在策略管理器创建后注入
+	Backtester *backtest.Backtester      // 在币安客户端创建后注入
+}
+
 // CommandHandler 指令处理器接口
 type CommandHandler interface {
 	Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error
 	Description() string
 }
 
+// CallbackHandler 内联按钮回调处理器接口，data 为去掉前缀后的回调数据
+type CallbackHandler interface {
+	HandleCallback(ctx context.Context, bot *Bot, query *tgbotapi.CallbackQuery, data string) error
+}
+
 // Message 消息结构
 type Message struct {
-	ChatID int64
-	Text   string
-	Type   MessageType
+	ChatID   int64
+	Text     string
+	Type     MessageType
+	Keyboard *tgbotapi.InlineKeyboardMarkup
+	Photo    string // 图片文件路径，Type 为 MessageTypePhoto 时 Text 作为图片说明
 }
 
 // MessageType 消息类型
@@ -46,10 +89,15 @@ const (
 	MessageTypeText MessageType = iota
 	MessageTypeMarkdown
 	MessageTypeHTML
+	MessageTypePhoto
 )
 
 // New 创建新的Telegram机器人实例
-func New(cfg *config.TelegramConfig, log logger.Logger) (*Bot, error) {
+func New(cfg *config.TelegramConfig, log logger.Logger, services *Services) (*Bot, error) {
+	if services == nil {
+		return nil, fmt.Errorf("services cannot be nil")
+	}
+
 	if cfg.BotToken == "" {
 		return nil, fmt.Errorf("bot token is required")
 	}
@@ -63,20 +111,33 @@ func New(cfg *config.TelegramConfig, log logger.Logger) (*Bot, error) {
 		return nil, fmt.Errorf("failed to create bot API: %w", err)
 	}
 
+	allowedChats := map[int64]bool{cfg.AdminChatID: true}
+	for _, chatID := range cfg.ChatIDs {
+		allowedChats[chatID] = true
+	}
+
 	bot := &Bot{
-		api:             api,
-		config:          cfg,
-		logger:          log,
-		chatID:          cfg.AdminChatID,
-		commandHandlers: make(map[string]CommandHandler),
-		messageQueue:    make(chan Message, 100),
-		isRunning:       false,
+		api:              api,
+		config:           cfg,
+		logger:           log,
+		chatID:           cfg.AdminChatID,
+		adminChatID:      cfg.AdminChatID,
+		allowedChats:     allowedChats,
+		adminCommands:    make(map[string]bool),
+		adminCallbacks:   make(map[string]bool),
+		services:         services,
+		commandHandlers:  make(map[string]CommandHandler),
+		callbackHandlers: make(map[string]CallbackHandler),
+		auditRepo:        database.NewCommandAuditRepository(services.DB.GetDB()),
+		messageQueue:     make(chan Message, 100),
+		senderDone:       make(chan struct{}),
+		isRunning:        false,
 	}
 
 	// 注册默认指令处理器
 	bot.registerDefaultHandlers()
 
-	log.Infof("Telegram bot initialized for admin chat ID: %d", cfg.AdminChatID)
+	log.Infof("Telegram bot initialized for admin chat ID: %d (%d authorized chats)", cfg.AdminChatID, len(allowedChats))
 	return bot, nil
 }
 
@@ -97,8 +158,8 @@ func (b *Bot) Start(ctx context.Context) error {
 
 	b.logger.Infof("Bot started: @%s", me.UserName)
 
-	// 启动消息发送协程
-	go b.messageProcessor(ctx)
+	// 启动消息发送协程（不随上下文退出，停止时发送完队列中的消息）
+	go b.messageProcessor()
 
 	// 启动更新处理协程
 	go b.updateProcessor(ctx)
@@ -109,7 +170,7 @@ func (b *Bot) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop 停止机器人
+// Stop 停止机器人，等待队列中的消息发送完毕
 func (b *Bot) Stop() {
 	if !b.isRunning {
 		return
@@ -121,8 +182,9 @@ func (b *Bot) Stop() {
 	// 发送停止消息
 	b.SendMessage("🛑 Vegas Dual Tunnel Trading Bot 已停止")
 	
-	// 关闭消息队列
+	// 关闭消息队列并等待剩余消息发送
 	close(b.messageQueue)
+	<-b.senderDone
 }
 
 // SendMessage 发送文本消息
@@ -158,32 +220,149 @@ func (b *Bot) SendMarkdownMessage(text string) error {
 	}
 }
 
+// maxMessageLength 单条消息的最大长度（Telegram 上限为4096字符，留出余量）
+const maxMessageLength = 4000
+
+// SendLongMarkdownMessage 发送可能超出长度上限的Markdown消息，按行拆分为多条依次发送
+func (b *Bot) SendLongMarkdownMessage(text string) error {
+	for _, chunk := range splitMessage(text, maxMessageLength) {
+		if err := b.SendMarkdownMessage(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitMessage 按行将文本拆分为不超过 limit 个字符的片段，单行过长时按字符截断
+func splitMessage(text string, limit int) []string {
+	var chunks []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.TrimRight(string(current), "\n"))
+			current = current[:0]
+		}
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		runes := []rune(line)
+		if len(current)+len(runes) > limit {
+			flush()
+		}
+		for len(runes) > limit {
+			chunks = append(chunks, string(runes[:limit]))
+			runes = runes[limit:]
+		}
+		current = append(current, runes...)
+	}
+	flush()
+
+	return chunks
+}
+
+// SendMarkdownWithKeyboard 发送带内联按钮的Markdown格式消息
+func (b *Bot) SendMarkdownWithKeyboard(text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	select {
+	case b.messageQueue <- Message{
+		ChatID:   b.chatID,
+		Text:     text,
+		Type:     MessageTypeMarkdown,
+		Keyboard: &keyboard,
+	}:
+		return nil
+	default:
+		return fmt.Errorf("message queue is full")
+	}
+}
+
+// SendPhoto 发送本地图片，caption 为Markdown格式说明
+func (b *Bot) SendPhoto(path, caption string) error {
+	select {
+	case b.messageQueue <- Message{
+		ChatID: b.chatID,
+		Text:   caption,
+		Type:   MessageTypePhoto,
+		Photo:  path,
+	}:
+		return nil
+	default:
+		return fmt.Errorf("message queue is full")
+	}
+}
+
+// SendTrackedMessage 立即发送文本消息（不经消息队列）并返回消息ID，用于之后编辑同一条消息汇报进度
+func (b *Bot) SendTrackedMessage(text string) (int, error) {
+	sent, err := b.api.Send(tgbotapi.NewMessage(b.chatID, text))
+	if err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
+
+// EditMessage 编辑已发送的文本消息
+func (b *Bot) EditMessage(messageID int, text string) error {
+	_, err := b.api.Request(tgbotapi.NewEditMessageText(b.chatID, messageID, text))
+	return err
+}
+
+// RegisterCallbackHandler 注册按钮回调处理器，回调数据格式为 "<prefix>:<data>"
+func (b *Bot) RegisterCallbackHandler(prefix string, handler CallbackHandler) {
+	b.callbackHandlers[prefix] = handler
+	b.logger.Debugf("Registered callback handler: %s", prefix)
+}
+
 // RegisterCommandHandler 注册指令处理器
 func (b *Bot) RegisterCommandHandler(command string, handler CommandHandler) {
 	b.commandHandlers[command] = handler
 	b.logger.Debugf("Registered command handler: %s", command)
 }
 
-// messageProcessor 消息发送处理器
-func (b *Bot) messageProcessor(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case msg, ok := <-b.messageQueue:
-			if !ok {
-				return
-			}
-			
-			if err := b.sendMessage(msg); err != nil {
-				b.logger.Errorf("Failed to send message: %v", err)
-			}
+// RegisterAdminCommandHandler 注册仅管理员聊天可用的指令处理器
+func (b *Bot) RegisterAdminCommandHandler(command string, handler CommandHandler) {
+	b.adminCommands[command] = true
+	b.RegisterCommandHandler(command, handler)
+}
+
+// RegisterAdminCallbackHandler 注册仅管理员聊天可用的回调处理器
+func (b *Bot) RegisterAdminCallbackHandler(prefix string, handler CallbackHandler) {
+	b.adminCallbacks[prefix] = true
+	b.RegisterCallbackHandler(prefix, handler)
+}
+
+// IsAdminChat 当前回复目标是否为管理员聊天
+func (b *Bot) IsAdminChat() bool {
+	return b.chatID == b.adminChatID
+}
+
+// forChat 获取回复到指定聊天的机器人副本，副本与原实例共享消息队列和处理器
+func (b *Bot) forChat(chatID int64) *Bot {
+	reply := *b
+	reply.chatID = chatID
+	return &reply
+}
+
+// messageProcessor 消息发送处理器，队列关闭且剩余消息发送完后退出
+func (b *Bot) messageProcessor() {
+	defer close(b.senderDone)
+
+	for msg := range b.messageQueue {
+		if err := b.sendMessage(msg); err != nil {
+			b.logger.Errorf("Failed to send message: %v", err)
 		}
 	}
 }
 
 // sendMessage 实际发送消息
 func (b *Bot) sendMessage(msg Message) error {
+	if msg.Type == MessageTypePhoto {
+		photo := tgbotapi.NewPhoto(msg.ChatID, tgbotapi.FilePath(msg.Photo))
+		photo.Caption = msg.Text
+		photo.ParseMode = "Markdown"
+		_, err := b.api.Send(photo)
+		return err
+	}
+
 	msgConfig := tgbotapi.NewMessage(msg.ChatID, msg.Text)
 	
 	switch msg.Type {
@@ -193,6 +372,10 @@ func (b *Bot) sendMessage(msg Message) error {
 		msgConfig.ParseMode = "HTML"
 	}
 
+	if msg.Keyboard != nil {
+		msgConfig.ReplyMarkup = *msg.Keyboard
+	}
+
 	_, err := b.api.Send(msgConfig)
 	return err
 }
@@ -218,13 +401,21 @@ func (b *Bot) updateProcessor(ctx context.Context) {
 
 // handleUpdate 处理更新
 func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) error {
+	if update.CallbackQuery != nil {
+		return b.handleCallback(ctx, update.CallbackQuery)
+	}
+
 	// 只处理来自指定聊天的消息
 	if update.Message == nil {
 		return nil
 	}
 
-	if update.Message.Chat.ID != b.chatID {
+	if !b.allowedChats[update.Message.Chat.ID] {
 		b.logger.Warnf("Received message from unauthorized chat: %d", update.Message.Chat.ID)
+		if update.Message.IsCommand() {
+			b.audit(update.Message.From, update.Message.Chat.ID, update.Message.Command(),
+				update.Message.CommandArguments(), auditUnauthorized)
+		}
 		return nil
 	}
 
@@ -241,21 +432,145 @@ func (b *Bot) handleCommand(ctx context.Context, update tgbotapi.Update) error {
 	command := update.Message.Command()
 	handler, exists := b.commandHandlers[command]
 	
+	args := update.Message.CommandArguments()
+	reply := b.forChat(update.Message.Chat.ID)
+
 	if !exists {
-		return b.SendMessage(fmt.Sprintf("❌ 未知指令: /%s\n\n使用 /help 查看可用指令", command))
+		b.audit(update.Message.From, update.Message.Chat.ID, command, args, auditUnknown)
+		return reply.SendMessage(fmt.Sprintf("❌ 未知指令: /%s\n\n使用 /help 查看可用指令", command))
+	}
+
+	if b.adminCommands[command] && !reply.IsAdminChat() {
+		b.logger.Warnf("Admin command /%s denied for chat %d", command, update.Message.Chat.ID)
+		b.audit(update.Message.From, update.Message.Chat.ID, command, args, auditUnauthorized)
+		return reply.SendMessage(fmt.Sprintf("⛔ /%s 仅限管理员聊天使用", command))
 	}
 
 	b.logger.Infof("Handling command: /%s from user: %s", command, update.Message.From.UserName)
-	return handler.Handle(ctx, b, update)
+	err := handler.Handle(ctx, reply, update)
+	b.audit(update.Message.From, update.Message.Chat.ID, command, args, auditOutcome(err))
+	return err
+}
+
+// handleCallback 处理内联按钮回调
+func (b *Bot) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	prefix, data, _ := strings.Cut(query.Data, ":")
+	command := callbackAuditPrefix + prefix
+
+	if query.Message == nil || !b.allowedChats[query.Message.Chat.ID] {
+		b.logger.Warnf("Received callback from unauthorized chat")
+		var chatID int64
+		if query.Message != nil {
+			chatID = query.Message.Chat.ID
+		}
+		b.audit(query.From, chatID, command, data, auditUnauthorized)
+		return nil
+	}
+
+	handler, exists := b.callbackHandlers[prefix]
+	if !exists {
+		b.audit(query.From, query.Message.Chat.ID, command, data, auditUnknown)
+		b.AnswerCallback(query, "❌ 未知操作")
+		return nil
+	}
+
+	reply := b.forChat(query.Message.Chat.ID)
+	if b.adminCallbacks[prefix] && !reply.IsAdminChat() {
+		b.logger.Warnf("Admin callback %s denied for chat %d", prefix, query.Message.Chat.ID)
+		b.audit(query.From, query.Message.Chat.ID, command, data, auditUnauthorized)
+		b.AnswerCallback(query, "⛔ 仅限管理员聊天操作")
+		return nil
+	}
+
+	b.logger.Infof("Handling callback: %s from user: %s", prefix, query.From.UserName)
+	err := handler.HandleCallback(ctx, reply, query, data)
+	b.audit(query.From, query.Message.Chat.ID, command, data, auditOutcome(err))
+	return err
+}
+
+// AnswerCallback 应答按钮回调（消除按钮上的加载状态）
+func (b *Bot) AnswerCallback(query *tgbotapi.CallbackQuery, text string) {
+	if _, err := b.api.Request(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		b.logger.Errorf("Failed to answer callback: %v", err)
+	}
+}
+
+// ClearKeyboard 移除消息上的内联按钮，防止重复点击
+func (b *Bot) ClearKeyboard(message *tgbotapi.Message) {
+	edit := tgbotapi.NewEditMessageReplyMarkup(message.Chat.ID, message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+	if _, err := b.api.Request(edit); err != nil {
+		b.logger.Errorf("Failed to clear keyboard: %v", err)
+	}
 }
 
 // registerDefaultHandlers 注册默认指令处理器
 func (b *Bot) registerDefaultHandlers() {
 	b.RegisterCommandHandler("start", &StartHandler{})
 	b.RegisterCommandHandler("help", &HelpHandler{})
-	b.RegisterCommandHandler("status", &StatusHandler{})
-	b.RegisterCommandHandler("stop", &StopHandler{})
-	b.RegisterCommandHandler("resume", &ResumeHandler{})
+	b.RegisterCommandHandler("status", &StatusHandler{
+		userConfigRepo: database.NewUserConfigRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterAdminCommandHandler("stop", &StopHandler{})
+	b.RegisterAdminCommandHandler("resume", &ResumeHandler{})
 	b.RegisterCommandHandler("positions", &PositionsHandler{})
 	b.RegisterCommandHandler("balance", &BalanceHandler{})
+	b.RegisterCommandHandler("plan", &PlanHandler{})
+	b.RegisterCommandHandler("config", &ConfigHandler{
+		userConfigRepo: database.NewUserConfigRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterAdminCommandHandler("setlever", &SetLeverageHandler{
+		userConfigRepo: database.NewUserConfigRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterAdminCommandHandler("setsize", &SetSizeHandler{
+		userConfigRepo: database.NewUserConfigRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterAdminCommandHandler("preset", &PresetHandler{
+		userConfigRepo: database.NewUserConfigRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterAdminCommandHandler("session", &SessionHandler{
+		userConfigRepo: database.NewUserConfigRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterCommandHandler("scan", &ScanHandler{})
+	b.RegisterCommandHandler("watch", &WatchHandler{
+		userConfigRepo: database.NewUserConfigRepository(b.services.DB.GetDB()),
+		watchlistRepo:  database.NewWatchlistRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterCommandHandler("unwatch", &UnwatchHandler{
+		watchlistRepo: database.NewWatchlistRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterCommandHandler("watchlist", &WatchlistHandler{
+		watchlistRepo: database.NewWatchlistRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterAdminCommandHandler("rewarm", &RewarmHandler{})
+	b.RegisterAdminCommandHandler("backtest", &BacktestHandler{running: make(map[string]bool)})
+
+	flattenHandler := &FlattenHandler{}
+	b.RegisterAdminCommandHandler("flatten", flattenHandler)
+	b.RegisterAdminCallbackHandler("flatten", flattenHandler)
+	b.RegisterAdminCommandHandler("adopt", &AdoptHandler{})
+	b.RegisterAdminCommandHandler("panic", &PanicHandler{})
+	b.RegisterCommandHandler("fees", &FeesHandler{})
+	b.RegisterCommandHandler("size", &SizeHandler{})
+	b.RegisterCommandHandler("stats", &StatsHandler{
+		tradeRepo: database.NewTradeRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterCommandHandler("pnl", &PnlHandler{
+		tradeRepo: database.NewTradeRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterCommandHandler("report", &ReportHandler{})
+	b.RegisterCommandHandler("history", &HistoryHandler{
+		tradeRepo: database.NewTradeRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterCommandHandler("signals", &SignalsHandler{
+		signalRepo: database.NewSignalRepository(b.services.DB.GetDB()),
+	})
+	testSignalHandler := &TestSignalHandler{}
+	b.RegisterAdminCommandHandler("testsignal", testSignalHandler)
+	b.RegisterAdminCallbackHandler("testsignal", testSignalHandler)
+	b.RegisterCommandHandler("trade", &TradeJournalHandler{
+		journalRepo: database.NewTradeJournalRepository(b.services.DB.GetDB()),
+	})
+	b.RegisterAdminCommandHandler("pause", &PauseSymbolHandler{})
+	b.RegisterAdminCommandHandler("audit", &AuditHandler{})
 }
\ No newline at end of file
@@ -2,8 +2,16 @@ package telegram
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
+	"github.com/shopspring/decimal"
 )
 
 // StartHandler 启动指令处理器
@@ -25,6 +33,7 @@ func (h *StartHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Upd
 /status - 查看运行状态
 /positions - 查看当前仓位
 /balance - 查看账户余额
+/plan <交易对> - 查看持仓的止损、止盈和移动止盈计划
 /stop - 停止交易
 /resume - 恢复交易
 
@@ -48,22 +57,46 @@ func (h *HelpHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Upda
 /start - 启动机器人
 /help - 显示此帮助信息
 /status - 查看机器人运行状态
+/scan - 查看已订阅交易对的24h行情
+/watch <交易对> [周期] - 添加监控交易对
+/unwatch <交易对> - 移除监控交易对
+/watchlist - 查看监控列表和数量上限
 
 💹 *交易指令：*
 /positions - 查看当前持仓
 /balance - 查看账户余额
-/stop - 停止自动交易
-/resume - 恢复自动交易
+/stop - 停止自动交易 🔒
+/resume - 恢复自动交易 🔒
+/pause <交易对> - 暂停交易对的自动交易（继续接收信号） 🔒
+/trade <交易对> - 恢复交易对的自动交易 🔒
 
 📊 *查询指令：*
-/stats - 查看交易统计
-/history - 查看交易历史
-/signals - 查看最近信号
+/stats [天数] - 查看交易统计
+/pnl - 查看各交易对的已实现和未实现盈亏
+/report [天数] - 查看综合报告（统计、权益曲线、持仓风险）
+/history [条数] - 查看交易历史
+/trade <交易ID> - 查看开仓日志与图表
+/signals [条数] - 查看最近信号
 
 ⚙️ *设置指令：*
 /config - 查看当前配置
-/setlever <倍数> - 设置杠杆倍数
-/setsize <金额> - 设置仓位大小
+/setlever <倍数> - 设置杠杆倍数 🔒
+/setsize <金额> - 设置最大仓位金额 🔒
+/preset <名称> - 应用风险预设 🔒
+/session <开始-结束> [时区] - 设置开仓时段 🔒
+
+🛠 *运维指令：*
+/rewarm <交易对> - 重新回填并预热策略数据 🔒
+/backtest <交易对> [天数] [restart] - 回测策略，中断后再次执行从检查点继续 🔒
+/flatten <交易对> - 撤销挂单并市价平仓该交易对 🔒
+/panic - 紧急停止：撤销全部挂单并平掉所有持仓 🔒
+/adopt <交易对> [nostop] - 接管手动开立的持仓 🔒
+/fees [交易对] - 查看手续费等级和费率
+/size <交易对> [long|short] - 预览仓位计算
+/testsignal <交易对> <buy|sell> [置信度] - 注入测试信号验证执行流程 🔒
+/audit [条数] - 查看最近的指令审计记录 🔒
+
+🔒 标记的指令仅限管理员聊天使用
 
 ❓ *使用说明：*
 • 机器人会自动监控市场并发送交易信号
@@ -84,7 +117,9 @@ func (h *HelpHandler) Description() string {
 }
 
 // StatusHandler 状态查询处理器
-type StatusHandler struct{}
+type StatusHandler struct {
+	userConfigRepo *database.UserConfigRepository
+}
 
 func (h *StatusHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
 	// TODO: 从应用状态获取实际数据
@@ -97,11 +132,6 @@ func (h *StatusHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Up
   • WebSocket: ✅ 已连接
   • 数据库: ✅ 正常
 
-📈 *监控信息：*
-  • 交易对: BTCUSDT
-  • 当前价格: $43,250.50
-  • 24h涨跌: +2.35%
-
 ⚡ *策略状态：*
   • EMA338: 多头趋势
   • 信号强度: 中等
@@ -114,6 +144,30 @@ func (h *StatusHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Up
 
 ⏰ *运行时间：* 2小时35分钟`
 
+	message += "\n\n🧭 *运行模式：* " + formatMode(bot.services.AppConfig.Mode)
+
+	if bot.services.Executor != nil {
+		message += "\n\n⏸ *开仓暂停：*\n" + formatHalts(bot.services.Executor.ActiveHalts())
+	}
+
+	if bot.services.Streams != nil {
+		if stats := bot.services.Streams.AllTickerStats(); len(stats) > 0 {
+			message += "\n\n📈 *24h行情：*"
+			for _, s := range stats {
+				message += "\n" + formatTickerStats(s)
+			}
+		}
+		if symbols := bot.services.Streams.TradingDisabledSymbols(); len(symbols) > 0 {
+			message += "\n\n🔕 *仅接收信号的交易对：* " + strings.Join(symbols, ", ")
+		}
+	}
+
+	if userConfig, err := loadUserConfig(bot, h.userConfigRepo, update); err != nil {
+		bot.logger.Errorf("Failed to load user config: %v", err)
+	} else {
+		message += "\n\n🕒 *交易时段：*\n" + formatSessionState(userConfig)
+	}
+
 	return bot.SendMarkdownMessage(message)
 }
 
@@ -121,11 +175,67 @@ func (h *StatusHandler) Description() string {
 	return "查看机器人运行状态"
 }
 
+// formatMode 格式化运行模式
+func formatMode(mode string) string {
+	switch mode {
+	case config.ModeAnalysis:
+		return "分析模式（仅推送信号，不下单）"
+	case config.ModePaper:
+		return "模拟盘（本地模拟成交，不向交易所下单）"
+	default:
+		return "实盘"
+	}
+}
+
+// formatHalts 格式化生效中的开仓暂停及原因
+func formatHalts(halts []*trading.Halt) string {
+	if len(halts) == 0 {
+		return "  • 无，正常开仓"
+	}
+
+	lines := make([]string, 0, len(halts))
+	for _, halt := range halts {
+		line := fmt.Sprintf("  • %s: %s（自 %s）", formatHaltName(halt.Name), halt.Reason, halt.Since.Format("01-02 15:04"))
+		if !halt.Until.IsZero() {
+			line += fmt.Sprintf("，%s 自动解除", halt.Until.Local().Format("01-02 15:04"))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatHaltName 格式化开仓暂停来源
+func formatHaltName(name string) string {
+	switch name {
+	case trading.HaltManual:
+		return "手动暂停"
+	case trading.HaltDailyLoss:
+		return "单日亏损熔断"
+	case trading.HaltDrawdown:
+		return "单日回撤熔断"
+	case trading.HaltLossStreak:
+		return "连续亏损熔断"
+	case trading.HaltEmergency:
+		return "紧急停止"
+	default:
+		return name
+	}
+}
+
 // StopHandler 停止交易处理器
 type StopHandler struct{}
 
 func (h *StopHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
-	// TODO: 实现停止交易逻辑
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	if err := executor.SetTradingEnabled(false); err != nil {
+		bot.logger.Errorf("Failed to stop trading: %v", err)
+		return bot.SendMessage(fmt.Sprintf("❌ 停止交易失败: %v", err))
+	}
+
 	message := `🛑 *停止交易*
 
 自动交易已停止。
@@ -136,6 +246,9 @@ func (h *StopHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Upda
 • 止损止盈仍然有效
 • 监控功能继续运行
 
+⏸ *开仓暂停：*
+` + formatHalts(executor.ActiveHalts()) + `
+
 💡 *提示：*
 使用 /resume 可以重新启动自动交易
 使用 /positions 查看当前持仓`
@@ -151,16 +264,31 @@ func (h *StopHandler) Description() string {
 type ResumeHandler struct{}
 
 func (h *ResumeHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
-	// TODO: 实现恢复交易逻辑
-	message := `▶️ *恢复交易*
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	// 同时解除紧急停止，紧急停止只能通过 /resume 显式解除
+	if err := executor.SetTradingEnabled(true); err != nil {
+		bot.logger.Errorf("Failed to resume trading: %v", err)
+		return bot.SendMessage(fmt.Sprintf("❌ 恢复交易失败: %v", err))
+	}
+
+	// 熔断等其他暂停仍可能生效，如实展示
+	halts := executor.ActiveHalts()
+	state := "• 自动交易已启用\n• 新信号将自动执行"
+	if len(halts) > 0 {
+		state = "• 手动暂停已解除\n• 以下暂停仍在生效，解除前不会开仓"
+	}
 
-自动交易已恢复。
+	message := `▶️ *恢复交易*
 
 📋 *当前状态：*
-• 自动交易已启用
-• 新信号将自动执行
-• 风险管理已激活
-• 监控功能正常运行
+` + state + `
+
+⏸ *开仓暂停：*
+` + formatHalts(halts) + `
 
 ⚠️ *风险提醒：*
 请确保账户余额充足，并关注市场变化。`
@@ -176,27 +304,72 @@ func (h *ResumeHandler) Description() string {
 type PositionsHandler struct{}
 
 func (h *PositionsHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
-	// TODO: 从交易系统获取实际仓位数据
-	message := `📊 *当前仓位*
-
-💼 *持仓概览：*
-当前无持仓
-
-📈 *账户信息：*
-• 可用余额: 1,000.00 USDT
-• 已用保证金: 0.00 USDT
-• 未实现盈亏: 0.00 USDT
-
-📋 *风险参数：*
-• 杠杆倍数: 10x
-• 仓位大小: 100.00 USDT
-• 止损比例: 2.0%
-• 止盈比例: 4.0%
-
-💡 *提示：*
-使用 /balance 查看详细账户信息`
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	positions := executor.GetPositions()
+	if len(positions) == 0 {
+		return bot.SendMarkdownMessage("📊 *当前仓位*\n\n当前无持仓\n\n💡 使用 /balance 查看账户余额")
+	}
+
+	// 交易所持仓中的保证金模式为实际生效值，获取失败时显示配置值
+	marginTypes := make(map[string]string)
+	if client := bot.services.Binance; client != nil {
+		exchangePositions, err := client.GetPositions(ctx)
+		if err != nil {
+			bot.logger.Warnf("Failed to fetch exchange margin types: %v", err)
+		}
+		for _, p := range exchangePositions {
+			marginTypes[p.Symbol] = p.MarginType
+		}
+	}
+
+	symbols := make([]string, 0, len(positions))
+	for symbol := range positions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var b strings.Builder
+	b.WriteString("📊 *当前仓位*\n")
+	for _, symbol := range symbols {
+		p := positions[symbol]
+		side := "🟢 多"
+		if p.Side == "SHORT" {
+			side = "🔴 空"
+		}
+		pnlSign := ""
+		if p.UnrealizedPnl.IsPositive() {
+			pnlSign = "+"
+		}
+
+		b.WriteString(fmt.Sprintf("\n• *%s* %s %s @ %s\n", p.Symbol, side, p.Size.String(), p.EntryPrice.String()))
+		b.WriteString(fmt.Sprintf("  标记价 %s · 未实现盈亏 %s%s USDT\n", p.MarkPrice.String(), pnlSign, p.UnrealizedPnl.StringFixed(2)))
+		b.WriteString(fmt.Sprintf("  止损 %s / 止盈 %s\n", p.StopLossPrice.String(), p.TakeProfitPrice.String()))
+		b.WriteString(fmt.Sprintf("  保证金模式: %s\n", formatMarginType(marginTypes[symbol], bot.services.AppConfig.Trading.MarginTypeFor(symbol))))
+	}
+
+	return bot.SendMarkdownMessage(b.String())
+}
 
-	return bot.SendMarkdownMessage(message)
+// formatMarginType 格式化保证金模式，effective 为交易所返回的实际模式，为空时显示配置值
+func formatMarginType(effective, configured string) string {
+	switch strings.ToUpper(effective) {
+	case "ISOLATED":
+		return "逐仓"
+	case "CROSS", "CROSSED":
+		return "全仓"
+	}
+
+	switch configured {
+	case "ISOLATED":
+		return "逐仓（配置）"
+	case "CROSSED":
+		return "全仓（配置）"
+	}
+	return "未知"
 }
 
 func (h *PositionsHandler) Description() string {
@@ -207,32 +380,83 @@ func (h *PositionsHandler) Description() string {
 type BalanceHandler struct{}
 
 func (h *BalanceHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
-	// TODO: 从Binance API获取实际余额数据
-	message := `💰 *账户余额*
+	client := bot.services.Binance
+	if client == nil {
+		return bot.SendMessage("❌ 币安客户端不可用")
+	}
+
+	account, err := client.GetAccountInfo(ctx)
+	if err != nil {
+		bot.logger.Errorf("Failed to fetch account balance: %v", err)
+		return bot.SendMessage(fmt.Sprintf("❌ 获取账户余额失败: %v", err))
+	}
+
+	var usdt *binance.AccountAsset
+	for i := range account.Assets {
+		if account.Assets[i].Asset == "USDT" {
+			usdt = &account.Assets[i]
+			break
+		}
+	}
+	if usdt == nil {
+		return bot.SendMessage("❌ 获取账户余额失败: 账户中没有USDT资产")
+	}
+
+	values, err := parseBalanceFields(
+		usdt.WalletBalance, usdt.AvailableBalance, account.TotalUnrealizedProfit,
+		account.TotalMarginBalance, account.TotalInitialMargin, account.TotalMaintMargin,
+	)
+	if err != nil {
+		bot.logger.Errorf("Failed to parse account balance: %v", err)
+		return bot.SendMessage(fmt.Sprintf("❌ 获取账户余额失败: %v", err))
+	}
+	wallet, available, unrealized := values[0], values[1], values[2]
+	marginBalance, initialMargin, maintMargin := values[3], values[4], values[5]
+
+	marginRatio := decimal.Zero
+	if marginBalance.IsPositive() {
+		marginRatio = maintMargin.Div(marginBalance).Mul(decimal.NewFromInt(100))
+	}
+
+	unrealizedSign := ""
+	if unrealized.IsPositive() {
+		unrealizedSign = "+"
+	}
+
+	message := fmt.Sprintf(`💰 *账户余额*
 
 💵 *USDT余额：*
-• 总余额: 1,000.00 USDT
-• 可用余额: 1,000.00 USDT
-• 冻结余额: 0.00 USDT
+• 钱包余额: %s USDT
+• 可用余额: %s USDT
 
 📊 *保证金信息：*
-• 已用保证金: 0.00 USDT
-• 维持保证金: 0.00 USDT
-• 保证金率: 0.00%
-
-📈 *盈亏统计：*
-• 未实现盈亏: 0.00 USDT
-• 今日盈亏: +25.50 USDT
-• 总盈亏: +125.75 USDT
-
-⚡ *风险等级：* 低风险
+• 保证金余额: %s USDT
+• 已用保证金: %s USDT
+• 维持保证金: %s USDT
+• 保证金率: %s%%
 
-💡 *提示：*
-建议保持充足的可用余额以应对市场波动`
+📈 *未实现盈亏：* %s%s USDT`,
+		wallet.StringFixed(2), available.StringFixed(2),
+		marginBalance.StringFixed(2), initialMargin.StringFixed(2), maintMargin.StringFixed(2),
+		marginRatio.StringFixed(2),
+		unrealizedSign, unrealized.StringFixed(2))
 
 	return bot.SendMarkdownMessage(message)
 }
 
+// parseBalanceFields 按顺序解析账户接口返回的金额字段
+func parseBalanceFields(raws ...string) ([]decimal.Decimal, error) {
+	values := make([]decimal.Decimal, len(raws))
+	for i, raw := range raws {
+		value, err := decimal.NewFromString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance value %q: %w", raw, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
 func (h *BalanceHandler) Description() string {
 	return "查看账户余额信息"
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/stream"
+)
+
+// ScanHandler 已订阅交易对24h行情概览处理器
+type ScanHandler struct{}
+
+func (h *ScanHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	streams := bot.services.Streams
+	if streams == nil {
+		return bot.SendMessage("❌ 数据流服务不可用")
+	}
+
+	stats := streams.AllTickerStats()
+	if len(stats) == 0 {
+		return bot.SendMessage("ℹ️ 暂无24h行情数据，请等待行情推送")
+	}
+
+	// 按24h涨跌幅从高到低排列
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].PriceChangePercent.GreaterThan(stats[j].PriceChangePercent)
+	})
+
+	var b strings.Builder
+	b.WriteString("🔎 *24h行情概览*\n")
+	for _, s := range stats {
+		b.WriteString("\n")
+		b.WriteString(formatTickerStats(s))
+	}
+
+	return bot.SendMarkdownMessage(b.String())
+}
+
+func (h *ScanHandler) Description() string {
+	return "查看已订阅交易对的24h行情"
+}
+
+// formatTickerStats 格式化单个交易对的24h行情
+func formatTickerStats(s stream.TickerStats) string {
+	return fmt.Sprintf("• *%s* %s (%s)\n  高 %s / 低 %s · 成交额 %s",
+		s.Symbol, s.LastPrice.String(), formatChangePercent(s),
+		s.HighPrice.String(), s.LowPrice.String(), s.QuoteVolume.StringFixed(0))
+}
+
+// formatChangePercent 格式化24h涨跌幅，带正负号和涨跌图标
+func formatChangePercent(s stream.TickerStats) string {
+	icon := "🔴"
+	sign := ""
+	if !s.PriceChangePercent.IsNegative() {
+		icon = "🟢"
+		sign = "+"
+	}
+	return fmt.Sprintf("%s %s%s%%", icon, sign, s.PriceChangePercent.StringFixed(2))
+}
@@ -0,0 +1,667 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
+)
+
+// flattenConfirmTimeout 平仓确认按钮的有效期
+const flattenConfirmTimeout = 60 * time.Second
+
+// RewarmHandler 重新预热交易对策略数据处理器
+type RewarmHandler struct{}
+
+func (h *RewarmHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	symbol := strings.ToUpper(strings.TrimSpace(update.Message.CommandArguments()))
+	if symbol == "" {
+		return bot.SendMessage("❌ 请指定交易对\n\n用法: /rewarm BTCUSDT")
+	}
+
+	streams := bot.services.Streams
+	if streams == nil {
+		return bot.SendMessage("❌ 数据流服务不可用")
+	}
+
+	if err := bot.SendMessage(fmt.Sprintf("🔄 正在重新回填 %s 的K线数据...", symbol)); err != nil {
+		bot.logger.Errorf("Failed to send rewarm progress: %v", err)
+	}
+
+	status, err := streams.Rewarm(symbol)
+	if err != nil {
+		bot.logger.Errorf("Failed to rewarm %s: %v", symbol, err)
+		return bot.SendMarkdownMessage(fmt.Sprintf("❌ *%s 重新预热失败*\n\n%v\n\n%s",
+			symbol, err, formatWarmupStatus(status)))
+	}
+
+	return bot.SendMarkdownMessage(fmt.Sprintf("✅ *%s 数据已重新预热*\n\n%s\n\n实时K线处理已恢复",
+		symbol, formatWarmupStatus(status)))
+}
+
+func (h *RewarmHandler) Description() string {
+	return "清空并重新回填交易对的策略数据"
+}
+
+// formatWarmupStatus 格式化各策略的数据预热状态
+func formatWarmupStatus(status map[string]strategy.WarmupStatus) string {
+	if len(status) == 0 {
+		return "⚠️ 没有支持预热的策略"
+	}
+
+	names := make([]string, 0, len(status))
+	for name := range status {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		s := status[name]
+		state := "🟡 预热中"
+		if s.Ready {
+			state = "🟢 已就绪"
+		}
+		sb.WriteString(fmt.Sprintf("*%s* %s\n• 15M: %d/%d\n• 4H: %d/%d\n",
+			strings.ReplaceAll(name, "_", "\\_"), state, s.Count15M, s.Required, s.Count4H, s.Required))
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// FlattenHandler 单个交易对紧急平仓处理器（需按钮确认）
+type FlattenHandler struct{}
+
+func (h *FlattenHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	symbol := strings.ToUpper(strings.TrimSpace(update.Message.CommandArguments()))
+	if symbol == "" {
+		return bot.SendMessage("❌ 请指定交易对\n\n用法: /flatten BTCUSDT")
+	}
+
+	if bot.services.Executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	// 回调数据携带发起人和时间，只有发起人可在有效期内确认
+	payload := fmt.Sprintf("%s:%d:%d", symbol, update.Message.From.ID, time.Now().Unix())
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ 确认平仓", "flatten:confirm:"+payload),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", "flatten:cancel:"+payload),
+		),
+	)
+
+	message := fmt.Sprintf(`⚠️ *确认紧急平仓 %s？*
+
+• 撤销该交易对的全部挂单（含止损止盈）
+• 以市价只减仓单平掉当前持仓
+• 其他交易对不受影响
+
+请在 %d 秒内确认`, symbol, int(flattenConfirmTimeout.Seconds()))
+
+	return bot.SendMarkdownWithKeyboard(message, keyboard)
+}
+
+func (h *FlattenHandler) Description() string {
+	return "撤销挂单并市价平仓指定交易对"
+}
+
+// HandleCallback 处理平仓确认按钮
+func (h *FlattenHandler) HandleCallback(ctx context.Context, bot *Bot, query *tgbotapi.CallbackQuery, data string) error {
+	parts := strings.Split(data, ":")
+	if len(parts) != 4 {
+		bot.AnswerCallback(query, "❌ 无效操作")
+		return nil
+	}
+
+	action, symbol := parts[0], parts[1]
+	userID, err1 := strconv.ParseInt(parts[2], 10, 64)
+	issuedAt, err2 := strconv.ParseInt(parts[3], 10, 64)
+	if err1 != nil || err2 != nil {
+		bot.AnswerCallback(query, "❌ 无效操作")
+		return nil
+	}
+
+	if query.From.ID != userID {
+		bot.AnswerCallback(query, "⛔ 只有发起人可以确认")
+		return nil
+	}
+
+	bot.ClearKeyboard(query.Message)
+
+	if action == "cancel" {
+		bot.AnswerCallback(query, "已取消")
+		return bot.SendMessage(fmt.Sprintf("❎ 已取消 %s 的紧急平仓", symbol))
+	}
+
+	if time.Since(time.Unix(issuedAt, 0)) > flattenConfirmTimeout {
+		bot.AnswerCallback(query, "⌛ 确认已过期")
+		return bot.SendMessage(fmt.Sprintf("⌛ %s 平仓确认已过期，请重新执行 /flatten %s", symbol, symbol))
+	}
+
+	bot.AnswerCallback(query, "正在平仓...")
+
+	result, err := bot.services.Executor.FlattenSymbol(userID, symbol, "telegram /flatten")
+	if err != nil {
+		bot.logger.Errorf("Failed to flatten %s: %v", symbol, err)
+		return bot.SendMessage(fmt.Sprintf("❌ %s 平仓失败: %v", symbol, err))
+	}
+
+	if result == nil {
+		return bot.SendMarkdownMessage(fmt.Sprintf("✅ *%s 已处理*\n\n已撤销全部挂单，当前无持仓", symbol))
+	}
+
+	if result.Error != nil {
+		bot.logger.Errorf("Failed to flatten %s: %v", symbol, result.Error)
+		return bot.SendMessage(fmt.Sprintf("❌ %s 平仓失败: %v", symbol, result.Error))
+	}
+
+	pnlIcon := "📈"
+	if result.RealizedPnl.IsNegative() {
+		pnlIcon = "📉"
+	}
+
+	message := fmt.Sprintf(`✅ *%s 已紧急平仓*
+
+• 方向: %s
+• 数量: %s
+• 开仓均价: %s
+• 平仓价格: %s
+%s 已实现盈亏: %s USDT`,
+		symbol, result.Side, result.Quantity.String(), result.EntryPrice.String(),
+		result.ExitPrice.String(), pnlIcon, result.RealizedPnl.StringFixed(2))
+
+	return bot.SendMarkdownMessage(message)
+}
+
+// PanicHandler 紧急停止处理器：暂停开仓、撤销全部挂单并市价平掉所有持仓，需 /resume 恢复
+type PanicHandler struct{}
+
+func (h *PanicHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	if err := bot.SendMessage("🚨 正在执行紧急停止..."); err != nil {
+		bot.logger.Errorf("Failed to send panic progress: %v", err)
+	}
+
+	results, err := executor.EmergencyStop(fmt.Sprintf("telegram /panic by %d", update.Message.From.ID))
+	if err != nil {
+		bot.logger.Errorf("Emergency stop failed: %v", err)
+		return bot.SendMessage(fmt.Sprintf("❌ 紧急停止未完成: %v\n\n已暂停开仓，请立即在交易所手动平仓", err))
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+		}
+	}
+
+	status := "✅ 全部平仓完成"
+	if failed > 0 {
+		status = fmt.Sprintf("⚠️ %d 个持仓平仓失败，请立即手动处理", failed)
+	}
+
+	message := fmt.Sprintf(`🚨 *紧急停止已执行*
+
+• 已暂停所有开仓
+• 已撤销全部挂单
+• 平仓持仓: %d 个
+%s
+
+使用 /resume 解除紧急停止`, len(results), status)
+
+	return bot.SendMarkdownMessage(message)
+}
+
+func (h *PanicHandler) Description() string {
+	return "紧急停止：撤销全部挂单并平掉所有持仓"
+}
+
+// AdoptHandler 接管外部持仓处理器
+type AdoptHandler struct{}
+
+func (h *AdoptHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return bot.SendMessage("❌ 请指定交易对\n\n用法: /adopt BTCUSDT [nostop]")
+	}
+
+	symbol := strings.ToUpper(args[0])
+	withProtection := !(len(args) > 1 && strings.EqualFold(args[1], "nostop"))
+
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	var levels trading.ProtectiveLevelFunc
+	if withProtection && bot.services.Strategies != nil {
+		levels = func(isLong bool, entryPrice decimal.Decimal) (decimal.Decimal, decimal.Decimal, bool) {
+			return bot.services.Strategies.ProtectiveLevels(symbol, isLong, entryPrice)
+		}
+	}
+
+	position, err := executor.AdoptPosition(update.Message.From.ID, symbol, levels)
+	if err != nil {
+		bot.logger.Errorf("Failed to adopt %s: %v", symbol, err)
+		return bot.SendMessage(fmt.Sprintf("❌ 接管 %s 失败: %v", symbol, err))
+	}
+
+	protection := "• 保护订单: 未设置"
+	if !position.StopLossPrice.IsZero() {
+		protection = fmt.Sprintf("• 止损: %s\n• 止盈: %s\n• 保护订单设置中，失败时会另行告警",
+			position.StopLossPrice.StringFixed(4), position.TakeProfitPrice.StringFixed(4))
+	}
+
+	message := fmt.Sprintf(`✅ *已接管 %s 持仓*
+
+• 方向: %s
+• 数量: %s
+• 开仓均价: %s
+• 未实现盈亏: %s USDT
+%s`,
+		symbol, position.Side, position.Size.String(), position.EntryPrice.String(),
+		position.UnrealizedPnl.StringFixed(2), protection)
+
+	return bot.SendMarkdownMessage(message)
+}
+
+func (h *AdoptHandler) Description() string {
+	return "接管手动开立的持仓并设置保护订单"
+}
+
+// FeesHandler 手续费等级与费率查询处理器
+type FeesHandler struct{}
+
+func (h *FeesHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	symbol := strings.ToUpper(strings.TrimSpace(update.Message.CommandArguments()))
+	if symbol == "" {
+		symbol = "BTCUSDT"
+	}
+
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	info, err := executor.GetFeeInfo(symbol)
+	if err != nil {
+		bot.logger.Errorf("Failed to get fee info for %s: %v", symbol, err)
+		return bot.SendMessage(fmt.Sprintf("❌ 获取 %s 手续费失败: %v", symbol, err))
+	}
+
+	hundred := decimal.NewFromInt(100)
+	message := fmt.Sprintf(`💸 *手续费信息 %s*
+
+• 手续费等级: VIP %d
+• 挂单费率: %s%%
+• 吃单费率: %s%%
+• 更新时间: %s
+
+风险收益检查和平仓盈亏按吃单费率计算`,
+		symbol, info.FeeTier, info.MakerRate.Mul(hundred).String(), info.TakerRate.Mul(hundred).String(),
+		info.UpdatedAt.Format("2006-01-02 15:04:05"))
+
+	return bot.SendMarkdownMessage(message)
+}
+
+func (h *FeesHandler) Description() string {
+	return "查看当前手续费等级和挂单/吃单费率"
+}
+
+// TradeJournalHandler /trade 指令处理器：参数为交易ID时查询开仓日志，为交易对时恢复该交易对的自动交易
+type TradeJournalHandler struct {
+	journalRepo *database.TradeJournalRepository
+}
+
+func (h *TradeJournalHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	arg := strings.TrimSpace(update.Message.CommandArguments())
+	if arg == "" {
+		return bot.SendMessage("❌ 请指定交易ID或交易对\n\n用法: /trade 42 查看开仓日志\n/trade BTCUSDT 恢复自动交易")
+	}
+
+	tradeID, err := strconv.Atoi(arg)
+	if err != nil {
+		if !bot.IsAdminChat() {
+			return bot.SendMessage("⛔ 恢复交易对的自动交易仅限管理员聊天使用")
+		}
+		return setSymbolTrading(bot, strings.ToUpper(arg), true)
+	}
+	if tradeID <= 0 {
+		return bot.SendMessage("❌ 交易ID必须是正整数\n\n用法: /trade 42")
+	}
+
+	entry, err := h.journalRepo.GetByTradeID(update.Message.From.ID, tradeID)
+	if err != nil {
+		bot.logger.Errorf("Failed to get trade journal %d: %v", tradeID, err)
+		return bot.SendMessage("❌ 获取交易日志失败")
+	}
+	if entry == nil {
+		return bot.SendMessage(fmt.Sprintf("❌ 交易 #%d 没有开仓日志", tradeID))
+	}
+
+	caption := fmt.Sprintf(`📒 *交易 #%d 开仓日志*
+
+• 交易对: %s
+• 方向: %s
+• 入场价: %.4f
+• 止损: %.4f
+• 止盈: %.4f
+• 时间: %s`,
+		entry.TradeID, entry.Symbol, entry.Side, entry.EntryPrice, entry.StopLoss, entry.TakeProfit,
+		entry.CreatedAt.Format("2006-01-02 15:04:05"))
+
+	if entry.ImagePath != "" {
+		if _, err := os.Stat(entry.ImagePath); err == nil {
+			return bot.SendPhoto(entry.ImagePath, caption)
+		}
+		bot.logger.Warnf("Journal chart missing for trade %d: %s", tradeID, entry.ImagePath)
+	}
+
+	return bot.SendMarkdownMessage(caption + "\n\n⚠️ 开仓时未能生成图表")
+}
+
+func (h *TradeJournalHandler) Description() string {
+	return "查看交易的开仓日志和图表，或恢复交易对的自动交易"
+}
+
+// PauseSymbolHandler 暂停交易对自动交易处理器（继续接收行情和信号）
+type PauseSymbolHandler struct{}
+
+func (h *PauseSymbolHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	symbol := strings.ToUpper(strings.TrimSpace(update.Message.CommandArguments()))
+	if symbol == "" {
+		return bot.SendMessage("❌ 请指定交易对\n\n用法: /pause BTCUSDT")
+	}
+	return setSymbolTrading(bot, symbol, false)
+}
+
+func (h *PauseSymbolHandler) Description() string {
+	return "暂停交易对的自动交易，继续接收信号"
+}
+
+// setSymbolTrading 切换交易对的自动交易并回复结果
+func setSymbolTrading(bot *Bot, symbol string, enabled bool) error {
+	streams := bot.services.Streams
+	if streams == nil {
+		return bot.SendMessage("❌ 数据流服务不可用")
+	}
+
+	changed := streams.SetTradingEnabled(symbol, enabled)
+	switch {
+	case enabled && !changed:
+		return bot.SendMessage(fmt.Sprintf("ℹ️ %s 已在自动交易中", symbol))
+	case !enabled && !changed:
+		return bot.SendMessage(fmt.Sprintf("ℹ️ %s 的自动交易已处于暂停状态", symbol))
+	case enabled:
+		return bot.SendMarkdownMessage(fmt.Sprintf("▶️ *%s 已恢复自动交易*\n\n新信号将正常开仓。", symbol))
+	default:
+		return bot.SendMarkdownMessage(fmt.Sprintf("⏸ *%s 已暂停自动交易*\n\n继续接收行情和信号，但不再开仓；已有持仓的止损止盈不受影响。\n重启后以配置 disabled\\_symbols 为准，使用 /trade %s 恢复。", symbol, symbol))
+	}
+}
+
+// SizeHandler 仓位计算预览处理器
+type SizeHandler struct{}
+
+func (h *SizeHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return bot.SendMessage("❌ 请指定交易对\n\n用法: /size BTCUSDT [long|short]")
+	}
+
+	symbol := strings.ToUpper(args[0])
+	isLong := true
+	if len(args) > 1 {
+		switch strings.ToLower(args[1]) {
+		case "long":
+		case "short":
+			isLong = false
+		default:
+			return bot.SendMessage("❌ 方向只能是 long 或 short\n\n用法: /size BTCUSDT [long|short]")
+		}
+	}
+
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	var levels trading.ProtectiveLevelFunc
+	if bot.services.Strategies != nil {
+		levels = func(isLong bool, entryPrice decimal.Decimal) (decimal.Decimal, decimal.Decimal, bool) {
+			return bot.services.Strategies.ProtectiveLevels(symbol, isLong, entryPrice)
+		}
+	}
+
+	preview, err := executor.PreviewSize(update.Message.From.ID, symbol, isLong, levels)
+	if err != nil {
+		bot.logger.Errorf("Failed to preview size for %s: %v", symbol, err)
+		return bot.SendMessage(fmt.Sprintf("❌ 计算 %s 仓位失败: %v", symbol, err))
+	}
+
+	minNotional := "无"
+	if preview.MinNotional.IsPositive() {
+		minNotional = preview.MinNotional.String()
+	}
+
+	protection := "• 止损止盈: 策略数据不足，无法计算"
+	if !preview.StopLoss.IsZero() {
+		protection = fmt.Sprintf(`• 止损: %s
+• 止盈: %s
+• 触发止损亏损: %s %s
+• 风险收益比: %s (计入费用后 %s)`,
+			preview.StopLoss.StringFixed(4), preview.TakeProfit.StringFixed(4),
+			preview.RiskAtStop.StringFixed(2), preview.Asset,
+			preview.RiskReward.StringFixed(2), preview.EffectiveRiskReward.StringFixed(2))
+	}
+
+	verdict := "✅ 满足下单条件"
+	if preview.Rejection != nil {
+		verdict = fmt.Sprintf("⚠️ 实际下单会被拒绝: %v", preview.Rejection)
+	}
+
+	message := fmt.Sprintf(`📐 *仓位预览 %s %s*
+
+• 最新价格: %s
+• 可用余额: %s %s
+• 计算基数: %s %s
+• 风险模型: %s
+• 仓位价值: %s %s
+• 原始数量: %s
+• 下单数量: %s (步长 %s)
+• 名义价值: %s (最小 %s)
+%s
+
+%s
+_仅为预览，未下任何订单_`,
+		symbol, preview.Side,
+		preview.Price.String(),
+		preview.Balance.StringFixed(2), preview.Asset,
+		preview.SizingBase.StringFixed(2), preview.Asset,
+		formatRiskModel(preview.SizingResult),
+		preview.PositionValue.StringFixed(2), preview.Asset,
+		preview.RawQuantity.StringFixed(6),
+		preview.Quantity.String(), preview.StepSize.String(),
+		preview.Notional.StringFixed(2), minNotional,
+		protection, verdict)
+
+	return bot.SendMarkdownMessage(message)
+}
+
+// formatRiskModel 格式化仓位计算采用的风险模型
+func formatRiskModel(result *trading.SizingResult) string {
+	if result.RiskModel == config.RiskModelStopDistance {
+		return fmt.Sprintf("按止损距离 (计划亏损 %s %s)", result.RiskAmount.StringFixed(2), result.Asset)
+	}
+	return "按仓位价值"
+}
+
+func (h *SizeHandler) Description() string {
+	return "按当前余额和风险设置预览仓位计算"
+}
+
+// testSignalStrategy 测试信号使用的策略名称
+const testSignalStrategy = "testsignal"
+
+// TestSignalHandler 注入测试信号处理器：信号经过完整的分发、风控和下单流程，实盘需按钮确认
+type TestSignalHandler struct{}
+
+func (h *TestSignalHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	const usage = "用法: /testsignal BTCUSDT buy|sell [置信度]"
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) < 2 {
+		return bot.SendMessage("❌ 请指定交易对和方向\n\n" + usage)
+	}
+
+	symbol := strings.ToUpper(args[0])
+	side := strings.ToLower(args[1])
+	if side != "buy" && side != "sell" {
+		return bot.SendMessage("❌ 方向只能是 buy 或 sell\n\n" + usage)
+	}
+
+	confidence := 1.0
+	if len(args) > 2 {
+		c, err := strconv.ParseFloat(args[2], 64)
+		if err != nil || c < 0 || c > 1 {
+			return bot.SendMessage("❌ 置信度必须在 0 到 1 之间\n\n" + usage)
+		}
+		confidence = c
+	}
+
+	if bot.services.Strategies == nil {
+		return bot.SendMessage("❌ 策略管理器不可用")
+	}
+
+	// 测试网和非实盘模式直接注入，主网实盘需要确认
+	cfg := bot.services.AppConfig
+	if !cfg.IsProduction() || cfg.Mode != config.ModeLive {
+		return h.inject(bot, symbol, side, confidence)
+	}
+
+	payload := fmt.Sprintf("%s:%s:%s:%d:%d", symbol, side,
+		strconv.FormatFloat(confidence, 'f', -1, 64), update.Message.From.ID, time.Now().Unix())
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ 确认注入", "testsignal:confirm:"+payload),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", "testsignal:cancel:"+payload),
+		),
+	)
+
+	message := fmt.Sprintf(`⚠️ *确认在主网实盘注入测试信号？*
+
+• 交易对: %s
+• 方向: %s
+• 置信度: %.2f
+
+信号会经过全部风控检查，通过后将真实下单。
+请在 %d 秒内确认`, symbol, strings.ToUpper(side), confidence, int(flattenConfirmTimeout.Seconds()))
+
+	return bot.SendMarkdownWithKeyboard(message, keyboard)
+}
+
+func (h *TestSignalHandler) Description() string {
+	return "注入测试信号以验证完整的执行与通知流程"
+}
+
+// HandleCallback 处理主网测试信号确认按钮
+func (h *TestSignalHandler) HandleCallback(ctx context.Context, bot *Bot, query *tgbotapi.CallbackQuery, data string) error {
+	parts := strings.Split(data, ":")
+	if len(parts) != 6 {
+		bot.AnswerCallback(query, "❌ 无效操作")
+		return nil
+	}
+
+	action, symbol, side := parts[0], parts[1], parts[2]
+	confidence, err1 := strconv.ParseFloat(parts[3], 64)
+	userID, err2 := strconv.ParseInt(parts[4], 10, 64)
+	issuedAt, err3 := strconv.ParseInt(parts[5], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		bot.AnswerCallback(query, "❌ 无效操作")
+		return nil
+	}
+
+	if query.From.ID != userID {
+		bot.AnswerCallback(query, "⛔ 只有发起人可以确认")
+		return nil
+	}
+
+	bot.ClearKeyboard(query.Message)
+
+	if action == "cancel" {
+		bot.AnswerCallback(query, "已取消")
+		return bot.SendMessage(fmt.Sprintf("❎ 已取消 %s 测试信号", symbol))
+	}
+
+	if time.Since(time.Unix(issuedAt, 0)) > flattenConfirmTimeout {
+		bot.AnswerCallback(query, "⌛ 确认已过期")
+		return bot.SendMessage(fmt.Sprintf("⌛ %s 测试信号确认已过期，请重新执行 /testsignal", symbol))
+	}
+
+	bot.AnswerCallback(query, "正在注入...")
+	return h.inject(bot, symbol, side, confidence)
+}
+
+// inject 以最新K线收盘价和策略止损止盈构造测试信号并注入分发流程
+func (h *TestSignalHandler) inject(bot *Bot, symbol, side string, confidence float64) error {
+	strategies := bot.services.Strategies
+
+	klines, _, ok := strategies.ChartData(symbol, 1)
+	if !ok {
+		return bot.SendMessage(fmt.Sprintf("❌ %s 没有行情数据，请先订阅并等待数据预热", symbol))
+	}
+	price := klines[len(klines)-1].Close
+
+	isLong := side == "buy"
+	signal := &strategy.TradingSignal{
+		Symbol:     symbol,
+		Type:       strategy.SignalBuy,
+		Price:      price,
+		Confidence: confidence,
+		Reason:     "测试信号（/testsignal）",
+		Timestamp:  time.Now(),
+		Timeframe:  "15M",
+	}
+	if !isLong {
+		signal.Type = strategy.SignalSell
+	}
+	if sl, tp, ok := strategies.ProtectiveLevels(symbol, isLong, price); ok {
+		signal.StopLoss, signal.TakeProfit = sl, tp
+	}
+
+	if err := strategies.InjectSignal(testSignalStrategy, signal); err != nil {
+		bot.logger.Errorf("Failed to inject test signal for %s: %v", symbol, err)
+		return bot.SendMessage(fmt.Sprintf("❌ 注入测试信号失败: %v", err))
+	}
+
+	levels := "• 止损止盈: 策略数据不足，未设置"
+	if !signal.StopLoss.IsZero() {
+		levels = fmt.Sprintf("• 止损: %s\n• 止盈: %s", signal.StopLoss.StringFixed(4), signal.TakeProfit.StringFixed(4))
+	}
+
+	message := fmt.Sprintf(`🧪 *已注入测试信号 %s %s*
+
+• 价格: %s
+• 置信度: %.2f
+%s
+
+信号已进入分发流程，执行结果将通过交易通知推送`,
+		symbol, strings.ToUpper(side), price.String(), confidence, levels)
+
+	return bot.SendMarkdownMessage(message)
+}
@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
+)
+
+// PlanHandler 持仓出场计划处理器：按当前持仓和指标展示止损、止盈和EMA12移动止盈，只读不下单
+type PlanHandler struct{}
+
+func (h *PlanHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	symbol := strings.ToUpper(strings.TrimSpace(update.Message.CommandArguments()))
+	if symbol == "" {
+		return bot.SendMessage("❌ 请指定交易对\n\n用法: /plan BTCUSDT")
+	}
+
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	var position *trading.Position
+	for _, p := range executor.GetPositions() {
+		if p.Symbol == symbol && p.UserID == update.Message.From.ID {
+			position = p
+			break
+		}
+	}
+	if position == nil {
+		return bot.SendMessage(fmt.Sprintf("ℹ️ 当前没有 %s 持仓", symbol))
+	}
+
+	isLong := position.Side == "LONG"
+	side := "🟢 多"
+	if !isLong {
+		side = "🔴 空"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🗺 *%s 出场计划*\n\n", symbol))
+	b.WriteString(fmt.Sprintf("• 方向: %s\n• 数量: %s\n• 开仓价: %s\n", side, position.Size.String(), position.EntryPrice.String()))
+	if position.MarkPrice.IsPositive() {
+		b.WriteString(fmt.Sprintf("• 标记价: %s\n", position.MarkPrice.String()))
+	}
+
+	// 止损：全部数量
+	b.WriteString("\n🛑 *止损：*\n")
+	if position.StopLossPrice.IsPositive() {
+		b.WriteString(fmt.Sprintf("• %s · 全部 %s · 预计 %s USDT\n", position.StopLossPrice.String(), position.Size.String(),
+			formatSignedDecimal(exitPnl(position, position.StopLossPrice, isLong))))
+	} else {
+		b.WriteString("• 未设置\n")
+	}
+
+	// 止盈：当前为单级止盈，全部数量在同一价位平仓
+	b.WriteString("\n🎯 *止盈：*\n")
+	if position.TakeProfitPrice.IsPositive() {
+		b.WriteString(fmt.Sprintf("• %s · 全部 %s · 预计 %s USDT\n", position.TakeProfitPrice.String(), position.Size.String(),
+			formatSignedDecimal(exitPnl(position, position.TakeProfitPrice, isLong))))
+	} else {
+		b.WriteString("• 未设置\n")
+	}
+
+	b.WriteString("\n⚖️ *保本：* 未启用，止损不会自动移至开仓价\n")
+
+	b.WriteString("\n📉 *EMA12移动止盈：*\n")
+	b.WriteString(h.ema12Line(bot, symbol, isLong))
+
+	b.WriteString("\n💡 以上为按当前持仓和指标计算的计划，仅供查看")
+	return bot.SendMarkdownMessage(b.String())
+}
+
+func (h *PlanHandler) Description() string {
+	return "查看持仓的止损、止盈和EMA12移动止盈计划"
+}
+
+// ema12Line 按最新15M EMA12和缓冲计算移动止盈触发价
+func (h *PlanHandler) ema12Line(bot *Bot, symbol string, isLong bool) string {
+	strategies := bot.services.Strategies
+	if strategies == nil {
+		return "• 策略管理器不可用\n"
+	}
+
+	klines, tunnel, ok := strategies.ChartData(symbol, 1)
+	if !ok || len(tunnel) == 0 || tunnel[len(tunnel)-1].EMA12.IsZero() {
+		return "• 暂无指标数据，请等待数据预热\n"
+	}
+
+	ema12 := tunnel[len(tunnel)-1].EMA12
+	closePrice := klines[len(klines)-1].Close
+	buffer := decimal.NewFromFloat(bot.services.AppConfig.Trading.EMA12BufferPercent / 100)
+
+	// 多单收盘价跌破 EMA12×(1-缓冲) 出场，空单收盘价突破 EMA12×(1+缓冲) 出场
+	trigger := ema12.Mul(decimal.NewFromInt(1).Add(buffer))
+	condition := "收盘价突破"
+	if isLong {
+		trigger = ema12.Mul(decimal.NewFromInt(1).Sub(buffer))
+		condition = "收盘价跌破"
+	}
+
+	distance := decimal.Zero
+	if closePrice.IsPositive() {
+		distance = closePrice.Sub(trigger).Abs().Div(closePrice).Mul(decimal.NewFromInt(100))
+	}
+
+	return fmt.Sprintf("• EMA12: %s\n• 15M%s %s 时全部平仓\n• 最新收盘 %s，距触发价 %s%%\n",
+		ema12.StringFixed(4), condition, trigger.StringFixed(4), closePrice.String(), distance.StringFixed(2))
+}
+
+// exitPnl 按出场价估算持仓盈亏（未计手续费）
+func exitPnl(position *trading.Position, price decimal.Decimal, isLong bool) decimal.Decimal {
+	diff := price.Sub(position.EntryPrice)
+	if !isLong {
+		diff = diff.Neg()
+	}
+	return diff.Mul(position.Size)
+}
+
+// formatSignedDecimal 格式化带正负号的金额
+func formatSignedDecimal(d decimal.Decimal) string {
+	if d.IsPositive() {
+		return "+" + d.StringFixed(2)
+	}
+	return d.StringFixed(2)
+}
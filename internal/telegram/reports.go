@@ -0,0 +1,411 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// defaultReportDays /report 默认统计天数
+const defaultReportDays = 7
+
+// ReportHandler 综合报告处理器：交易统计、权益曲线、手续费和持仓风险
+type ReportHandler struct{}
+
+func (h *ReportHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	days := defaultReportDays
+	if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return bot.SendMessage("❌ 天数必须是正整数\n\n用法: /report [天数]")
+		}
+		days = n
+	}
+
+	executor := bot.services.Executor
+	if executor == nil {
+		return bot.SendMessage("❌ 交易执行器不可用")
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	report, err := executor.BuildReport(update.Message.From.ID, since)
+	if err != nil {
+		bot.logger.Errorf("Failed to build report: %v", err)
+		return bot.SendMessage(fmt.Sprintf("❌ 生成报告失败: %v", err))
+	}
+
+	stats := report.Stats
+	var b strings.Builder
+	fmt.Fprintf(&b, "📑 *综合报告（近%d天）*\n\n", days)
+
+	b.WriteString("📊 *交易统计：*\n")
+	fmt.Fprintf(&b, "• 已平仓交易: %d (盈 %d / 亏 %d)\n", stats.Trades, stats.Wins, stats.Losses)
+	fmt.Fprintf(&b, "• 胜率: %.1f%%\n", stats.WinRate()*100)
+	fmt.Fprintf(&b, "• 已实现盈亏: %.2f %s\n", stats.RealizedPnl, report.Asset)
+	if pf, ok := stats.ProfitFactor(); ok {
+		fmt.Fprintf(&b, "• 盈亏因子: %.2f\n", pf)
+	} else {
+		b.WriteString("• 盈亏因子: -\n")
+	}
+	fmt.Fprintf(&b, "• 手续费: %.2f %s\n\n", stats.Commission, report.Asset)
+
+	b.WriteString("📈 *权益曲线：*\n")
+	fmt.Fprintf(&b, "• 当前权益: %s %s\n", report.Equity.StringFixed(2), report.Asset)
+	if change, ok := report.EquityChange(); ok {
+		percent := change.Div(report.StartEquity).Mul(decimal.NewFromInt(100))
+		fmt.Fprintf(&b, "• 期初权益: %s %s\n", report.StartEquity.StringFixed(2), report.Asset)
+		fmt.Fprintf(&b, "• 期间变化: %s %s (%s%%)\n", signed(change), report.Asset, signed(percent))
+	} else {
+		b.WriteString("• 期间变化: 暂无权益快照\n")
+	}
+
+	fmt.Fprintf(&b, "\n💼 *当前持仓（%d）：*\n", len(report.Positions))
+	if len(report.Positions) == 0 {
+		b.WriteString("• 无持仓\n")
+	}
+	for _, p := range report.Positions {
+		fmt.Fprintf(&b, "• %s %s %s @ %s，未实现 %s", p.Symbol, p.Side, p.Size.String(),
+			p.EntryPrice.String(), signed(p.UnrealizedPnl))
+		if p.StopLoss.IsZero() {
+			b.WriteString("，⚠️ 无止损\n")
+		} else {
+			fmt.Fprintf(&b, "，止损 %s，风险 %s\n", p.StopLoss.String(), p.Risk.StringFixed(2))
+		}
+	}
+
+	if len(report.Positions) > 0 {
+		fmt.Fprintf(&b, "\n🛡 *持仓风险：* %s %s，占权益 %s%%", report.OpenRisk.StringFixed(2), report.Asset,
+			report.OpenRiskPercent().StringFixed(2))
+		if report.Unprotected > 0 {
+			fmt.Fprintf(&b, "\n⚠️ %d 个持仓未设置止损，风险未计入", report.Unprotected)
+		}
+	}
+
+	return bot.SendLongMarkdownMessage(b.String())
+}
+
+func (h *ReportHandler) Description() string {
+	return "查看交易统计、权益曲线和持仓风险综合报告"
+}
+
+// StatsHandler 交易统计处理器：按已实现盈亏统计当前用户的交易表现
+type StatsHandler struct {
+	tradeRepo *database.TradeRepository
+}
+
+func (h *StatsHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	// 未指定天数时统计全部历史
+	var since time.Time
+	period := "全部"
+	if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+		days, err := strconv.Atoi(arg)
+		if err != nil || days <= 0 {
+			return bot.SendMessage("❌ 天数必须是正整数\n\n用法: /stats [天数]")
+		}
+		since = time.Now().AddDate(0, 0, -days)
+		period = fmt.Sprintf("近%d天", days)
+	}
+
+	stats, err := h.tradeRepo.GetStats(update.Message.From.ID, since)
+	if err != nil {
+		bot.logger.Errorf("Failed to get trade stats: %v", err)
+		return bot.SendMessage("❌ 获取交易统计失败")
+	}
+
+	if stats.TotalTrades == 0 {
+		return bot.SendMessage(fmt.Sprintf("ℹ️ %s暂无交易记录", period))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📊 *交易统计（%s）*\n\n", period)
+	fmt.Fprintf(&b, "• 交易记录: %d\n", stats.TotalTrades)
+	fmt.Fprintf(&b, "• 已平仓交易: %d (盈 %d / 亏 %d)\n", stats.Trades, stats.Wins, stats.Losses)
+	fmt.Fprintf(&b, "• 胜率: %.1f%%\n", stats.WinRate()*100)
+	fmt.Fprintf(&b, "• 累计已实现盈亏: %s\n", signed(decimal.NewFromFloat(stats.RealizedPnl)))
+	if stats.Trades > 0 {
+		fmt.Fprintf(&b, "• 最佳交易: %s\n", signed(decimal.NewFromFloat(stats.BestTrade)))
+		fmt.Fprintf(&b, "• 最差交易: %s\n", signed(decimal.NewFromFloat(stats.WorstTrade)))
+	}
+	if pf, ok := stats.ProfitFactor(); ok {
+		fmt.Fprintf(&b, "• 盈亏因子: %.2f\n", pf)
+	}
+	fmt.Fprintf(&b, "• 手续费: %.2f", stats.Commission)
+
+	return bot.SendMarkdownMessage(b.String())
+}
+
+func (h *StatsHandler) Description() string {
+	return "查看交易统计"
+}
+
+// PnlHandler 盈亏汇总处理器：按交易对合并已实现盈亏（交易记录）和未实现盈亏（交易所持仓）
+type PnlHandler struct {
+	tradeRepo *database.TradeRepository
+}
+
+// symbolPnl 单个交易对的盈亏汇总
+type symbolPnl struct {
+	realized   decimal.Decimal
+	unrealized decimal.Decimal
+	open       bool
+}
+
+func (h *PnlHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	realized, err := h.tradeRepo.GetRealizedPnlBySymbol(update.Message.From.ID)
+	if err != nil {
+		bot.logger.Errorf("Failed to get realized pnl: %v", err)
+		return bot.SendMessage("❌ 获取已实现盈亏失败")
+	}
+
+	pnls := make(map[string]*symbolPnl)
+	entry := func(symbol string) *symbolPnl {
+		if p, ok := pnls[symbol]; ok {
+			return p
+		}
+		p := &symbolPnl{}
+		pnls[symbol] = p
+		return p
+	}
+	for _, r := range realized {
+		entry(r.Symbol).realized = decimal.NewFromFloat(r.RealizedPnl)
+	}
+
+	// 未实现盈亏以交易所持仓为准，获取失败时只展示已实现部分
+	unrealizedNote := ""
+	if client := bot.services.Binance; client == nil {
+		unrealizedNote = "⚠️ 币安客户端不可用，未包含未实现盈亏"
+	} else if positions, err := client.GetPositions(ctx); err != nil {
+		bot.logger.Warnf("Failed to fetch positions for pnl: %v", err)
+		unrealizedNote = "⚠️ 获取交易所持仓失败，未包含未实现盈亏"
+	} else {
+		for _, position := range positions {
+			amount, err := decimal.NewFromString(position.PositionAmt)
+			if err != nil || amount.IsZero() {
+				continue
+			}
+			unrealized, err := decimal.NewFromString(position.UnRealizedProfit)
+			if err != nil {
+				bot.logger.Warnf("Invalid unrealized profit %q for %s", position.UnRealizedProfit, position.Symbol)
+				continue
+			}
+			p := entry(position.Symbol)
+			p.unrealized = p.unrealized.Add(unrealized)
+			p.open = true
+		}
+	}
+
+	if len(pnls) == 0 {
+		message := "ℹ️ 暂无已实现盈亏或持仓"
+		if unrealizedNote != "" {
+			message += "\n\n" + unrealizedNote
+		}
+		return bot.SendMessage(message)
+	}
+
+	symbols := make([]string, 0, len(pnls))
+	for symbol := range pnls {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var b strings.Builder
+	b.WriteString("💰 *盈亏汇总*\n")
+	var totalRealized, totalUnrealized decimal.Decimal
+	for _, symbol := range symbols {
+		p := pnls[symbol]
+		total := p.realized.Add(p.unrealized)
+		totalRealized = totalRealized.Add(p.realized)
+		totalUnrealized = totalUnrealized.Add(p.unrealized)
+
+		fmt.Fprintf(&b, "\n%s *%s*：%s USDT\n", pnlEmoji(total), symbol, signed(total))
+		fmt.Fprintf(&b, "  已实现 %s", signed(p.realized))
+		if p.open {
+			fmt.Fprintf(&b, " · 未实现 %s", signed(p.unrealized))
+		}
+		b.WriteString("\n")
+	}
+
+	total := totalRealized.Add(totalUnrealized)
+	fmt.Fprintf(&b, "\n%s *合计*：%s USDT\n", pnlEmoji(total), signed(total))
+	fmt.Fprintf(&b, "  已实现 %s · 未实现 %s", signed(totalRealized), signed(totalUnrealized))
+	if unrealizedNote != "" {
+		fmt.Fprintf(&b, "\n\n%s", unrealizedNote)
+	}
+
+	return bot.SendLongMarkdownMessage(b.String())
+}
+
+func (h *PnlHandler) Description() string {
+	return "查看各交易对的已实现和未实现盈亏"
+}
+
+// pnlEmoji 盈亏标识：盈利或持平为绿色，亏损为红色
+func pnlEmoji(value decimal.Decimal) string {
+	if value.IsNegative() {
+		return "🔴"
+	}
+	return "🟢"
+}
+
+// signed 格式化带正负号的金额
+func signed(value decimal.Decimal) string {
+	if value.IsPositive() {
+		return "+" + value.StringFixed(2)
+	}
+	return value.StringFixed(2)
+}
+
+// /signals 默认和最多展示的信号条数
+const (
+	defaultSignalEntries = 10
+	maxSignalEntries     = 50
+)
+
+// SignalsHandler 最近信号查询处理器：展示数据库中记录的策略信号
+type SignalsHandler struct {
+	signalRepo *database.SignalRepository
+}
+
+func (h *SignalsHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	limit := defaultSignalEntries
+	if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return bot.SendMessage("❌ 条数必须是正整数\n\n用法: /signals [条数]")
+		}
+		limit = min(n, maxSignalEntries)
+	}
+
+	signals, err := h.signalRepo.GetRecent(limit)
+	if err != nil {
+		bot.logger.Errorf("Failed to get recent signals: %v", err)
+		return bot.SendMessage("❌ 获取信号记录失败")
+	}
+
+	if len(signals) == 0 {
+		return bot.SendMessage("ℹ️ 暂无信号记录")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📡 *最近信号（%d条）*\n", len(signals))
+	for _, signal := range signals {
+		b.WriteString("\n")
+		b.WriteString(formatSignalRecord(signal))
+	}
+
+	return bot.SendMarkdownMessage(b.String())
+}
+
+func (h *SignalsHandler) Description() string {
+	return "查看最近的策略信号"
+}
+
+// formatSignalRecord 格式化单条信号记录，附带记录中的止损止盈和原因
+func formatSignalRecord(signal *database.Signal) string {
+	icon := "⚪"
+	switch signal.SignalType {
+	case "BUY":
+		icon = "🟢"
+	case "SELL":
+		icon = "🔴"
+	}
+
+	var metadata struct {
+		Reason     string `json:"reason"`
+		StopLoss   string `json:"stop_loss"`
+		TakeProfit string `json:"take_profit"`
+	}
+	if signal.Metadata != "" {
+		_ = json.Unmarshal([]byte(signal.Metadata), &metadata)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s `%s` *%s* %s @ %s · 置信度 %.2f\n", icon, signal.CreatedAt.Local().Format("01-02 15:04"),
+		signal.Symbol, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, signal.SignalType), strconv.FormatFloat(signal.Price, 'f', -1, 64), signal.Confidence)
+	if metadata.StopLoss != "" || metadata.TakeProfit != "" {
+		fmt.Fprintf(&b, "    止损 %s / 止盈 %s\n", orDash(metadata.StopLoss), orDash(metadata.TakeProfit))
+	}
+	if metadata.Reason != "" {
+		fmt.Fprintf(&b, "    %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, metadata.Reason))
+	}
+	return b.String()
+}
+
+// orDash 空值显示为 -
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// /history 默认和最多展示的交易条数
+const (
+	defaultHistoryEntries = 10
+	maxHistoryEntries     = 50
+)
+
+// HistoryHandler 交易历史处理器：展示当前用户最近的交易记录
+type HistoryHandler struct {
+	tradeRepo *database.TradeRepository
+}
+
+func (h *HistoryHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	limit := defaultHistoryEntries
+	if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return bot.SendMessage("❌ 条数必须是正整数\n\n用法: /history [条数]")
+		}
+		limit = min(n, maxHistoryEntries)
+	}
+
+	trades, err := h.tradeRepo.GetByUserID(update.Message.From.ID, limit)
+	if err != nil {
+		bot.logger.Errorf("Failed to get trade history: %v", err)
+		return bot.SendMessage("❌ 获取交易历史失败")
+	}
+
+	if len(trades) == 0 {
+		return bot.SendMessage("ℹ️ 暂无交易记录")
+	}
+
+	return bot.SendMarkdownMessage(fmt.Sprintf("📜 *最近交易（%d条）*\n\n```\n%s```", len(trades), formatTradeTable(trades)))
+}
+
+func (h *HistoryHandler) Description() string {
+	return "查看交易历史"
+}
+
+// formatTradeTable 将交易记录格式化为等宽表格，已成交的记录显示成交均价
+func formatTradeTable(trades []*database.Trade) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "Time\tSymbol\tSide\tQty\tPrice\tStatus\tPnL")
+	for _, trade := range trades {
+		price := trade.Price
+		if trade.AvgPrice > 0 {
+			price = trade.AvgPrice
+		}
+		pnl := "-"
+		if trade.RealizedPnl != 0 {
+			pnl = signed(decimal.NewFromFloat(trade.RealizedPnl))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			trade.CreatedAt.Local().Format("01-02 15:04"), trade.Symbol, trade.Side,
+			strconv.FormatFloat(trade.Quantity, 'f', -1, 64), strconv.FormatFloat(price, 'f', -1, 64),
+			trade.Status, pnl)
+	}
+	w.Flush()
+	return b.String()
+}
@@ -0,0 +1,326 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/trading"
+)
+
+// loadUserConfig 获取发送指令用户的配置，不存在时按全局默认值创建
+func loadUserConfig(bot *Bot, repo *database.UserConfigRepository, update tgbotapi.Update) (*database.UserConfig, error) {
+	from := update.Message.From
+	if from == nil {
+		return nil, fmt.Errorf("message has no sender")
+	}
+
+	userConfig, err := repo.GetByUserID(from.ID)
+	if err != nil {
+		return nil, err
+	}
+	if userConfig != nil {
+		return userConfig, nil
+	}
+
+	appCfg := bot.services.AppConfig
+	userConfig = &database.UserConfig{
+		UserID:          from.ID,
+		Username:        from.UserName,
+		ChatID:          update.Message.Chat.ID,
+		Testnet:         appCfg.Binance.Testnet,
+		MaxPositionSize: appCfg.Trading.MaxOrderValue,
+		RiskPercentage:  appCfg.Trading.DefaultRiskPercent,
+		Leverage:        appCfg.Trading.DefaultLeverage,
+		IsActive:        true,
+	}
+	if err := repo.Create(userConfig); err != nil {
+		return nil, err
+	}
+
+	bot.logger.Infof("Created default user config for user %d", from.ID)
+	return userConfig, nil
+}
+
+// maxLeverage 交易所允许的最大杠杆倍数
+const maxLeverage = 125
+
+// ConfigHandler 配置查看处理器：展示当前用户的交易配置
+type ConfigHandler struct {
+	userConfigRepo *database.UserConfigRepository
+}
+
+func (h *ConfigHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	userConfig, err := loadUserConfig(bot, h.userConfigRepo, update)
+	if err != nil {
+		bot.logger.Errorf("Failed to load user config: %v", err)
+		return bot.SendMessage("❌ 获取用户配置失败，请稍后重试")
+	}
+
+	network := "主网"
+	if userConfig.Testnet {
+		network = "测试网"
+	}
+	state := "🟢 启用"
+	if !userConfig.IsActive {
+		state = "⏸ 停用"
+	}
+	preset := "未使用"
+	if userConfig.RiskPreset != "" {
+		preset = tgbotapi.EscapeText(tgbotapi.ModeMarkdown, userConfig.RiskPreset)
+	}
+
+	message := fmt.Sprintf(`⚙️ *当前配置*
+
+• 风险比例: %.2f%%
+• 最大仓位: %.2f
+• 杠杆倍数: %dx
+• 最低置信度: %.0f%%
+• 开仓冷却: %d分钟
+• 风险预设: %s
+• 网络: %s
+• 状态: %s
+%s
+
+💡 使用 /setlever <倍数> 设置杠杆，/setsize <金额> 设置最大仓位`,
+		userConfig.RiskPercentage, userConfig.MaxPositionSize, userConfig.Leverage,
+		userConfig.MinConfidence*100, userConfig.CooldownMinutes, preset, network, state,
+		formatSessionState(userConfig))
+
+	return bot.SendMarkdownMessage(message)
+}
+
+func (h *ConfigHandler) Description() string {
+	return "查看当前配置"
+}
+
+// SetLeverageHandler 杠杆设置处理器
+type SetLeverageHandler struct {
+	userConfigRepo *database.UserConfigRepository
+}
+
+func (h *SetLeverageHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	arg := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(update.Message.CommandArguments())), "x")
+	if arg == "" {
+		return bot.SendMessage("❌ 请指定杠杆倍数\n\n用法: /setlever <倍数>，例如 /setlever 5")
+	}
+
+	leverage, err := strconv.Atoi(arg)
+	if err != nil || leverage < 1 || leverage > maxLeverage {
+		return bot.SendMessage(fmt.Sprintf("❌ 杠杆倍数必须是 1-%d 之间的整数", maxLeverage))
+	}
+
+	userConfig, err := loadUserConfig(bot, h.userConfigRepo, update)
+	if err != nil {
+		bot.logger.Errorf("Failed to load user config: %v", err)
+		return bot.SendMessage("❌ 获取用户配置失败，请稍后重试")
+	}
+
+	previous := userConfig.Leverage
+	userConfig.Leverage = leverage
+	// 手动调整后不再对应某个风险预设
+	userConfig.RiskPreset = ""
+
+	if err := h.userConfigRepo.Update(userConfig); err != nil {
+		bot.logger.Errorf("Failed to update leverage: %v", err)
+		return bot.SendMessage("❌ 保存杠杆设置失败，请稍后重试")
+	}
+
+	bot.logger.Infof("User %d leverage changed: %dx -> %dx", userConfig.UserID, previous, leverage)
+	return bot.SendMarkdownMessage(fmt.Sprintf("✅ *杠杆倍数已更新*\n\n• %dx → %dx\n\n下次开仓时在交易所生效", previous, leverage))
+}
+
+func (h *SetLeverageHandler) Description() string {
+	return "设置杠杆倍数"
+}
+
+// SetSizeHandler 最大仓位设置处理器
+type SetSizeHandler struct {
+	userConfigRepo *database.UserConfigRepository
+}
+
+func (h *SetSizeHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	arg := strings.TrimSpace(update.Message.CommandArguments())
+	if arg == "" {
+		return bot.SendMessage("❌ 请指定仓位金额\n\n用法: /setsize <金额>，例如 /setsize 500")
+	}
+
+	minValue := bot.services.AppConfig.Trading.MinOrderValue
+	maxValue := bot.services.AppConfig.Trading.MaxOrderValue
+	size, err := strconv.ParseFloat(arg, 64)
+	if err != nil || size < minValue || size > maxValue {
+		return bot.SendMessage(fmt.Sprintf("❌ 仓位金额必须在 %.2f-%.2f 之间", minValue, maxValue))
+	}
+
+	userConfig, err := loadUserConfig(bot, h.userConfigRepo, update)
+	if err != nil {
+		bot.logger.Errorf("Failed to load user config: %v", err)
+		return bot.SendMessage("❌ 获取用户配置失败，请稍后重试")
+	}
+
+	previous := userConfig.MaxPositionSize
+	userConfig.MaxPositionSize = size
+
+	if err := h.userConfigRepo.Update(userConfig); err != nil {
+		bot.logger.Errorf("Failed to update max position size: %v", err)
+		return bot.SendMessage("❌ 保存仓位设置失败，请稍后重试")
+	}
+
+	bot.logger.Infof("User %d max position size changed: %.2f -> %.2f", userConfig.UserID, previous, size)
+	return bot.SendMarkdownMessage(fmt.Sprintf("✅ *最大仓位已更新*\n\n• %.2f → %.2f\n\n单笔仓位价值不超过此金额", previous, size))
+}
+
+func (h *SetSizeHandler) Description() string {
+	return "设置最大仓位金额"
+}
+
+// PresetHandler 风险预设处理器
+type PresetHandler struct {
+	userConfigRepo *database.UserConfigRepository
+}
+
+func (h *PresetHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	presets := bot.services.AppConfig.Trading.RiskPresets
+	name := strings.ToLower(strings.TrimSpace(update.Message.CommandArguments()))
+
+	if name == "" {
+		return bot.SendMarkdownMessage(h.formatPresetList(presets))
+	}
+
+	preset, ok := bot.services.AppConfig.Trading.GetRiskPreset(name)
+	if !ok {
+		return bot.SendMessage(fmt.Sprintf("❌ 未知的风险预设: %s\n\n使用 /preset 查看可用预设", name))
+	}
+
+	userConfig, err := loadUserConfig(bot, h.userConfigRepo, update)
+	if err != nil {
+		bot.logger.Errorf("Failed to load user config: %v", err)
+		return bot.SendMessage("❌ 获取用户配置失败，请稍后重试")
+	}
+
+	userConfig.RiskPercentage = preset.RiskPercent
+	userConfig.Leverage = preset.Leverage
+	userConfig.MinConfidence = preset.MinConfidence
+	userConfig.CooldownMinutes = preset.CooldownMinutes
+	userConfig.RiskPreset = name
+
+	if err := h.userConfigRepo.Update(userConfig); err != nil {
+		bot.logger.Errorf("Failed to apply risk preset %s: %v", name, err)
+		return bot.SendMessage("❌ 保存风险预设失败，请稍后重试")
+	}
+
+	message := fmt.Sprintf(`✅ *已应用风险预设: %s*
+
+%s`, name, formatPreset(preset))
+
+	return bot.SendMarkdownMessage(message)
+}
+
+func (h *PresetHandler) Description() string {
+	return "应用风险预设 (conservative/balanced/aggressive)"
+}
+
+// formatPresetList 格式化预设列表
+func (h *PresetHandler) formatPresetList(presets map[string]config.RiskPreset) string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("🎚 *风险预设*\n\n")
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("*%s*\n%s\n\n", name, formatPreset(presets[name])))
+	}
+	sb.WriteString("💡 使用 /preset <名称> 应用预设")
+
+	return sb.String()
+}
+
+// formatPreset 格式化单个预设参数
+func formatPreset(preset config.RiskPreset) string {
+	return fmt.Sprintf("• 风险比例: %.2f%%\n• 杠杆倍数: %dx\n• 最低置信度: %.0f%%\n• 开仓冷却: %d分钟",
+		preset.RiskPercent, preset.Leverage, preset.MinConfidence*100, preset.CooldownMinutes)
+}
+
+// SessionHandler 交易时段处理器
+type SessionHandler struct {
+	userConfigRepo *database.UserConfigRepository
+}
+
+func (h *SessionHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	args := strings.Fields(update.Message.CommandArguments())
+
+	userConfig, err := loadUserConfig(bot, h.userConfigRepo, update)
+	if err != nil {
+		bot.logger.Errorf("Failed to load user config: %v", err)
+		return bot.SendMessage("❌ 获取用户配置失败，请稍后重试")
+	}
+
+	if len(args) == 0 {
+		return bot.SendMarkdownMessage("🕒 *交易时段*\n\n" + formatSessionState(userConfig) +
+			"\n\n💡 使用 /session 08:00-16:00 [时区] 设置，/session off 关闭")
+	}
+
+	if strings.EqualFold(args[0], "off") {
+		userConfig.SessionStart = ""
+		userConfig.SessionEnd = ""
+		userConfig.SessionTimezone = ""
+	} else {
+		bounds := strings.SplitN(args[0], "-", 2)
+		if len(bounds) != 2 {
+			return bot.SendMessage("❌ 格式错误\n\n用法: /session 08:00-16:00 [时区]，例如 /session 08:00-16:00 Europe/London")
+		}
+
+		tz := "UTC"
+		if len(args) > 1 {
+			tz = args[1]
+		}
+
+		session, err := trading.ParseSessionWindow(bounds[0], bounds[1], tz)
+		if err != nil {
+			return bot.SendMessage(fmt.Sprintf("❌ 无效的交易时段: %v", err))
+		}
+
+		userConfig.SessionStart = bounds[0]
+		userConfig.SessionEnd = bounds[1]
+		userConfig.SessionTimezone = session.Location.String()
+	}
+
+	if err := h.userConfigRepo.Update(userConfig); err != nil {
+		bot.logger.Errorf("Failed to update trading session: %v", err)
+		return bot.SendMessage("❌ 保存交易时段失败，请稍后重试")
+	}
+
+	return bot.SendMarkdownMessage("✅ *交易时段已更新*\n\n" + formatSessionState(userConfig))
+}
+
+func (h *SessionHandler) Description() string {
+	return "设置仅在指定时段内开仓"
+}
+
+// formatSessionState 格式化用户当前的交易时段状态
+func formatSessionState(userConfig *database.UserConfig) string {
+	session, err := trading.SessionForUser(userConfig)
+	if err != nil {
+		return fmt.Sprintf("⚠️ 交易时段配置无效: %v", err)
+	}
+	if session == nil {
+		return "• 时段限制: 未设置（全天开仓）"
+	}
+
+	now := time.Now()
+	if session.Contains(now) {
+		return fmt.Sprintf("• 时段: `%s`\n• 状态: 🟢 时段内，允许开仓", session)
+	}
+
+	return fmt.Sprintf("• 时段: `%s`\n• 状态: ⏸ 时段外，暂停开仓（持仓管理与平仓不受影响）\n• 下次开放: %s",
+		session, session.NextOpen(now).Format("2006-01-02 15:04 MST"))
+}
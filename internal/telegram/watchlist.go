@@ -0,0 +1,160 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// defaultWatchInterval 未指定周期时的监控周期，与策略使用的15M K线一致
+const defaultWatchInterval = "15m"
+
+// WatchHandler 添加监控交易对处理器
+type WatchHandler struct {
+	userConfigRepo *database.UserConfigRepository
+	watchlistRepo  *database.WatchlistRepository
+}
+
+func (h *WatchHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return bot.SendMessage("❌ 请指定交易对\n\n用法: /watch BTCUSDT [15m]")
+	}
+
+	symbol := strings.ToUpper(args[0])
+	interval := defaultWatchInterval
+	if len(args) > 1 {
+		interval = strings.ToLower(args[1])
+	}
+	// 策略只按15M K线计算，其他周期的K线无法用于交易信号
+	if interval != defaultWatchInterval {
+		return bot.SendMessage(fmt.Sprintf("❌ 不支持的周期 %s，策略只使用 %s K线\n\n用法: /watch BTCUSDT [15m]", interval, defaultWatchInterval))
+	}
+
+	// 监控列表关联用户配置，首次使用时按默认值创建
+	userConfig, err := loadUserConfig(bot, h.userConfigRepo, update)
+	if err != nil {
+		bot.logger.Errorf("Failed to load user config: %v", err)
+		return bot.SendMessage("❌ 获取用户配置失败")
+	}
+
+	limits := bot.services.AppConfig.Trading
+	item := &database.WatchlistItem{UserID: userConfig.UserID, Symbol: symbol, Interval: interval}
+	err = h.watchlistRepo.Add(item, limits.MaxWatchedSymbolsPerUser, limits.MaxWatchedSymbols)
+	switch {
+	case errors.Is(err, database.ErrUserWatchLimit):
+		return bot.SendMessage(fmt.Sprintf("❌ 已达到每位用户最多监控 %d 个交易对的上限，请先移除其他交易对", limits.MaxWatchedSymbolsPerUser))
+	case errors.Is(err, database.ErrGlobalWatchLimit):
+		return bot.SendMessage(fmt.Sprintf("❌ 机器人监控的交易对总数已达上限 %d，暂无法添加新交易对", limits.MaxWatchedSymbols))
+	case err != nil:
+		bot.logger.Errorf("Failed to add %s to watchlist: %v", symbol, err)
+		return bot.SendMessage("❌ 添加监控失败")
+	}
+
+	// 其他用户已监控的交易对无需重复订阅
+	if streams := bot.services.Streams; streams != nil && !streams.IsSubscribed(symbol, interval) {
+		if err := streams.Subscribe(symbol, interval); err != nil {
+			bot.logger.Warnf("Failed to subscribe %s %s: %v", symbol, interval, err)
+			return bot.SendMessage(fmt.Sprintf("⚠️ 已加入监控列表，但订阅行情失败：%v", err))
+		}
+	}
+
+	return bot.SendMarkdownMessage(fmt.Sprintf("👀 *已开始监控 %s*\n\n• 周期: %s", symbol, interval))
+}
+
+func (h *WatchHandler) Description() string {
+	return "添加监控交易对"
+}
+
+// UnwatchHandler 移除监控交易对处理器，没有其他用户监控且无持仓时取消行情订阅
+type UnwatchHandler struct {
+	watchlistRepo *database.WatchlistRepository
+}
+
+func (h *UnwatchHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	symbol := strings.ToUpper(strings.TrimSpace(update.Message.CommandArguments()))
+	if symbol == "" {
+		return bot.SendMessage("❌ 请指定交易对\n\n用法: /unwatch BTCUSDT")
+	}
+
+	item, err := h.watchlistRepo.Remove(update.Message.From.ID, symbol)
+	if err != nil {
+		bot.logger.Errorf("Failed to remove %s from watchlist: %v", symbol, err)
+		return bot.SendMessage("❌ 移除监控失败")
+	}
+	if item == nil {
+		return bot.SendMessage(fmt.Sprintf("ℹ️ %s 不在你的监控列表中", symbol))
+	}
+
+	message := fmt.Sprintf("🙈 *已停止监控 %s*\n\n• 周期: %s", symbol, item.Interval)
+
+	streams := bot.services.Streams
+	if streams == nil || !streams.IsSubscribed(symbol, item.Interval) {
+		return bot.SendMarkdownMessage(message)
+	}
+
+	// 其他用户仍在监控，或仍有持仓需要行情管理止盈时保留订阅
+	watchers, err := h.watchlistRepo.CountWatchers(symbol, item.Interval)
+	if err != nil {
+		bot.logger.Errorf("Failed to count watchers of %s: %v", symbol, err)
+		return bot.SendMarkdownMessage(message + "\n• 行情订阅保持不变")
+	}
+	if watchers > 0 {
+		return bot.SendMarkdownMessage(message + "\n• 其他用户仍在监控，行情订阅保持不变")
+	}
+	if executor := bot.services.Executor; executor != nil && executor.HasActivePosition(symbol) {
+		return bot.SendMarkdownMessage(message + "\n• ⚠️ 仍有持仓，行情订阅保持到平仓后")
+	}
+
+	if err := streams.Unsubscribe(symbol, item.Interval); err != nil {
+		bot.logger.Warnf("Failed to unsubscribe %s %s: %v", symbol, item.Interval, err)
+		return bot.SendMessage(fmt.Sprintf("⚠️ 已移出监控列表，但取消行情订阅失败：%v", err))
+	}
+
+	return bot.SendMarkdownMessage(message + "\n• 已取消行情订阅")
+}
+
+func (h *UnwatchHandler) Description() string {
+	return "移除监控交易对"
+}
+
+// WatchlistHandler 监控列表查询处理器
+type WatchlistHandler struct {
+	watchlistRepo *database.WatchlistRepository
+}
+
+func (h *WatchlistHandler) Handle(ctx context.Context, bot *Bot, update tgbotapi.Update) error {
+	items, err := h.watchlistRepo.GetActive(update.Message.From.ID)
+	if err != nil {
+		bot.logger.Errorf("Failed to get watchlist: %v", err)
+		return bot.SendMessage("❌ 获取监控列表失败")
+	}
+	total, err := h.watchlistRepo.CountActiveSymbols()
+	if err != nil {
+		bot.logger.Errorf("Failed to count watched symbols: %v", err)
+		return bot.SendMessage("❌ 获取监控列表失败")
+	}
+
+	limits := bot.services.AppConfig.Trading
+	var b strings.Builder
+	b.WriteString("👀 *监控列表*\n\n")
+	if len(items) == 0 {
+		b.WriteString("暂无监控的交易对，使用 /watch BTCUSDT 添加\n")
+	}
+	for _, item := range items {
+		b.WriteString(fmt.Sprintf("• %s (%s)\n", item.Symbol, item.Interval))
+	}
+	b.WriteString(fmt.Sprintf("\n📏 *用量：* 个人 %d/%d · 全局 %d/%d",
+		len(items), limits.MaxWatchedSymbolsPerUser, total, limits.MaxWatchedSymbols))
+
+	return bot.SendMarkdownMessage(b.String())
+}
+
+func (h *WatchlistHandler) Description() string {
+	return "查看监控列表和数量上限"
+}
@@ -0,0 +1,63 @@
+package trading
+
+import (
+	"fmt"
+)
+
+// setBracket 记录持仓的止损止盈挂单，其中一方成交后撤销另一方
+func (te *TradeExecutor) setBracket(userID int64, symbol, stopLossOrderID, takeProfitOrderID string) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+
+	lifecycle := te.lifecycleLocked(userID, symbol)
+	lifecycle.StopLossOrderID = stopLossOrderID
+	lifecycle.TakeProfitOrderID = takeProfitOrderID
+}
+
+// cancelBracketSibling 止损或止盈成交后撤销同一持仓的另一方挂单，避免残留挂单在平仓后反向开仓
+func (te *TradeExecutor) cancelBracketSibling(filled *ActiveOrder) {
+	te.mu.Lock()
+	lifecycle, ok := te.lifecycles[positionKey(filled.UserID, filled.Symbol)]
+	if !ok {
+		te.mu.Unlock()
+		return
+	}
+
+	var sibling string
+	switch filled.ID {
+	case lifecycle.StopLossOrderID:
+		sibling = lifecycle.TakeProfitOrderID
+	case lifecycle.TakeProfitOrderID:
+		sibling = lifecycle.StopLossOrderID
+	default:
+		te.mu.Unlock()
+		return
+	}
+	lifecycle.StopLossOrderID = ""
+	lifecycle.TakeProfitOrderID = ""
+	te.mu.Unlock()
+
+	if sibling == "" {
+		return
+	}
+
+	if err := te.CancelOrder(filled.Symbol, sibling); err != nil {
+		te.logger.Errorf("Failed to cancel %s order %s after %s order %s filled: %v",
+			filled.Symbol, sibling, filled.SignalType, filled.ID, err)
+		te.notify("warning", "止损止盈挂单撤销失败",
+			fmt.Sprintf("%s %s单 %s 已成交，但另一方挂单 %s 撤销失败，请检查是否有残留挂单: %v",
+				filled.Symbol, filled.SignalType, filled.ID, sibling, err))
+		return
+	}
+
+	te.mu.Lock()
+	delete(te.activeOrders, sibling)
+	te.mu.Unlock()
+
+	te.logger.Infof("%s %s order %s filled, cancelled sibling order %s", filled.Symbol, filled.SignalType, filled.ID, sibling)
+}
+
+// isBracketOrder 判断订单是否为止损或止盈挂单
+func isBracketOrder(order *ActiveOrder) bool {
+	return order.SignalType == "stop_loss" || order.SignalType == "take_profit"
+}
@@ -0,0 +1,148 @@
+package trading
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+func TestBracketCancelsSiblingOnFill(t *testing.T) {
+	tests := []struct {
+		name       string
+		signal     strategy.SignalType
+		stopLoss   string
+		takeProfit string
+		fillStop   bool // true 止损成交，false 止盈成交
+	}{
+		{"long stop loss fills", strategy.SignalBuy, "29500", "31000", true},
+		{"long take profit fills", strategy.SignalBuy, "29500", "31000", false},
+		{"short stop loss fills", strategy.SignalSell, "30500", "29000", true},
+		{"short take profit fills", strategy.SignalSell, "30500", "29000", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := newFakeExchange(t)
+			te := newTestExecutor(t, fx, testTradingConfig())
+			addTestUser(t, te, 1)
+
+			te.placeProtectiveOrders(&TradeRequest{
+				UserID:       1,
+				Symbol:       "BTCUSDT",
+				Quantity:     decimal.RequireFromString("0.01"),
+				StrategyType: "vegas",
+				Signal: &strategy.TradingSignal{
+					Type:       tt.signal,
+					Symbol:     "BTCUSDT",
+					Price:      decimal.RequireFromString("30000"),
+					StopLoss:   decimal.RequireFromString(tt.stopLoss),
+					TakeProfit: decimal.RequireFromString(tt.takeProfit),
+				},
+			})
+
+			lifecycle := te.PositionLifecycles()[positionKey(1, "BTCUSDT")]
+			if lifecycle.StopLossOrderID == "" || lifecycle.TakeProfitOrderID == "" {
+				t.Fatalf("bracket not recorded: %+v", lifecycle)
+			}
+
+			// 两个挂单都必须是平掉该持仓的方向
+			closing := "SELL"
+			if tt.signal == strategy.SignalSell {
+				closing = "BUY"
+			}
+			for _, id := range []string{lifecycle.StopLossOrderID, lifecycle.TakeProfitOrderID} {
+				if side := fx.order(id).Side; side != closing {
+					t.Fatalf("bracket order %s side = %s, want %s", id, side, closing)
+				}
+			}
+
+			filled, sibling := lifecycle.StopLossOrderID, lifecycle.TakeProfitOrderID
+			if !tt.fillStop {
+				filled, sibling = sibling, filled
+			}
+			fx.setOrder(filled, func(order *binance.OrderResponse) {
+				order.Status = string(binance.OrderStatusFilled)
+				order.ExecutedQty = "0.01"
+				order.AvgPrice = order.StopPrice
+			})
+
+			if _, err := te.syncOrder("BTCUSDT", filled); err != nil {
+				t.Fatalf("syncOrder: %v", err)
+			}
+			te.wg.Wait()
+
+			cancelled := fx.cancelledOrders()
+			if len(cancelled) != 1 || strconv.FormatInt(cancelled[0], 10) != sibling {
+				t.Fatalf("cancelled orders = %v, want only sibling %s", cancelled, sibling)
+			}
+			if status := fx.order(filled).Status; status != string(binance.OrderStatusFilled) {
+				t.Errorf("filled order status = %s, want FILLED", status)
+			}
+
+			te.mu.RLock()
+			_, filledTracked := te.activeOrders[filled]
+			_, siblingTracked := te.activeOrders[sibling]
+			after := *te.lifecycles[positionKey(1, "BTCUSDT")]
+			te.mu.RUnlock()
+			if filledTracked || siblingTracked {
+				t.Errorf("active orders still tracked: filled %v, sibling %v", filledTracked, siblingTracked)
+			}
+			if after.StopLossOrderID != "" || after.TakeProfitOrderID != "" {
+				t.Errorf("bracket not cleared: stop %q, take profit %q", after.StopLossOrderID, after.TakeProfitOrderID)
+			}
+		})
+	}
+}
+
+func TestBracketSiblingCancelIsolatedPerUser(t *testing.T) {
+	fx := newFakeExchange(t)
+	te := newTestExecutor(t, fx, testTradingConfig())
+	addTestUser(t, te, 1)
+	addTestUser(t, te, 2)
+
+	for _, userID := range []int64{1, 2} {
+		te.placeProtectiveOrders(&TradeRequest{
+			UserID:       userID,
+			Symbol:       "BTCUSDT",
+			Quantity:     decimal.RequireFromString("0.01"),
+			StrategyType: "vegas",
+			Signal: &strategy.TradingSignal{
+				Type:       strategy.SignalBuy,
+				Symbol:     "BTCUSDT",
+				Price:      decimal.RequireFromString("30000"),
+				StopLoss:   decimal.RequireFromString("29500"),
+				TakeProfit: decimal.RequireFromString("31000"),
+			},
+		})
+	}
+
+	lifecycles := te.PositionLifecycles()
+	first, second := lifecycles[positionKey(1, "BTCUSDT")], lifecycles[positionKey(2, "BTCUSDT")]
+	if first.UserID != 1 || second.UserID != 2 || first.StopLossOrderID == second.StopLossOrderID {
+		t.Fatalf("lifecycles not tracked per user: %+v, %+v", first, second)
+	}
+
+	// 用户1的止损成交只撤销用户1的止盈，用户2的持仓及保护订单不受影响
+	fx.setOrder(first.StopLossOrderID, func(order *binance.OrderResponse) {
+		order.Status = string(binance.OrderStatusFilled)
+		order.ExecutedQty = "0.01"
+		order.AvgPrice = order.StopPrice
+	})
+	if _, err := te.syncOrder("BTCUSDT", first.StopLossOrderID); err != nil {
+		t.Fatalf("syncOrder: %v", err)
+	}
+	te.wg.Wait()
+
+	cancelled := fx.cancelledOrders()
+	if len(cancelled) != 1 || strconv.FormatInt(cancelled[0], 10) != first.TakeProfitOrderID {
+		t.Fatalf("cancelled orders = %v, want only user 1 take profit %s", cancelled, first.TakeProfitOrderID)
+	}
+	after := te.PositionLifecycles()[positionKey(2, "BTCUSDT")]
+	if after.State != StateOpen || after.StopLossOrderID != second.StopLossOrderID || after.TakeProfitOrderID != second.TakeProfitOrderID {
+		t.Errorf("user 2 lifecycle changed: %+v, want %+v", after, second)
+	}
+}
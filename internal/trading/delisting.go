@@ -0,0 +1,138 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+)
+
+// SymbolHaltedFunc 持仓交易对停止交易后的回调（用于清理行情订阅），status 为空表示交易对已从交易所规则中移除
+type SymbolHaltedFunc func(symbol, status string)
+
+// SetSymbolHaltedHandler 设置持仓交易对停止交易后的回调
+func (te *TradeExecutor) SetSymbolHaltedHandler(handler SymbolHaltedFunc) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.symbolHaltedHandler = handler
+}
+
+// monitorHeldSymbols 按状态复查间隔刷新交易规则，检测持仓中的交易对被下架或暂停交易
+func (te *TradeExecutor) monitorHeldSymbols() {
+	ticker := time.NewTicker(time.Duration(te.tradingConfig.SymbolStatusCheckMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-te.ctx.Done():
+			return
+		case <-ticker.C:
+			te.checkHeldSymbols()
+		}
+	}
+}
+
+// checkHeldSymbols 检查持仓交易对的交易状态，非 TRADING 时告警、尝试平仓并清理跟踪状态
+func (te *TradeExecutor) checkHeldSymbols() {
+	te.mu.RLock()
+	held := make(map[string]int64)
+	for _, position := range te.positions {
+		held[position.Symbol] = position.UserID
+	}
+	te.mu.RUnlock()
+
+	if len(held) == 0 {
+		return
+	}
+
+	if err := te.refreshSymbolInfos(); err != nil {
+		te.logger.Warnf("Failed to refresh exchange info for held symbols: %v", err)
+		return
+	}
+
+	for symbol, userID := range held {
+		te.mu.RLock()
+		info, listed := te.symbolInfos[symbol]
+		te.mu.RUnlock()
+
+		if listed && info.IsTrading() {
+			continue
+		}
+
+		status := ""
+		if listed {
+			status = info.Status
+		}
+		te.handleHeldSymbolHalted(userID, symbol, status)
+	}
+}
+
+// handleHeldSymbolHalted 持仓交易对停止交易：按配置尝试平仓，无论平仓是否成功都停止跟踪并回调清理订阅
+func (te *TradeExecutor) handleHeldSymbolHalted(userID int64, symbol, status string) {
+	statusText := "已下架"
+	if status != "" {
+		statusText = fmt.Sprintf("状态变为 %s", status)
+	}
+	te.logger.Errorf("Held symbol %s is no longer trading (status %q)", symbol, status)
+
+	closeText := "未尝试平仓（已关闭自动平仓）"
+	if te.tradingConfig.CloseOnSymbolHalt {
+		result, err := te.FlattenSymbol(userID, symbol, "symbol halted")
+		switch {
+		case err != nil:
+			closeText = fmt.Sprintf("平仓失败：%v\n持仓可能已由交易所结算，请登录交易所确认", err)
+		case result == nil:
+			closeText = "交易所已无该持仓"
+		case result.Error != nil:
+			closeText = fmt.Sprintf("平仓失败：%v\n持仓可能已由交易所结算，请登录交易所确认", result.Error)
+		default:
+			closeText = fmt.Sprintf("已市价平仓 %s，已实现盈亏 %s USDT", result.Quantity.String(), result.RealizedPnl.StringFixed(2))
+		}
+	}
+
+	te.untrackSymbol(symbol)
+
+	te.notify("critical", "🚨 持仓交易对停止交易",
+		fmt.Sprintf("%s %s。\n%s\n已停止跟踪该交易对的持仓和挂单，并取消行情订阅。", symbol, statusText, closeText))
+
+	te.mu.RLock()
+	handler := te.symbolHaltedHandler
+	te.mu.RUnlock()
+	if handler != nil {
+		handler(symbol, status)
+	}
+}
+
+// untrackSymbol 停止跟踪交易对的持仓和挂单，持仓在数据库中标记为已平仓
+func (te *TradeExecutor) untrackSymbol(symbol string) {
+	te.mu.Lock()
+	var closed []*Position
+	for key, position := range te.positions {
+		if position.Symbol != symbol {
+			continue
+		}
+		position.IsOpen = false
+		position.UpdatedAt = time.Now()
+		closed = append(closed, position)
+		delete(te.positions, key)
+	}
+	for id, order := range te.activeOrders {
+		if order.Symbol == symbol {
+			delete(te.activeOrders, id)
+		}
+	}
+	te.mu.Unlock()
+
+	for _, position := range closed {
+		if te.PositionState(position.UserID, symbol) != StateFlat {
+			te.moveTo(position.UserID, symbol, StateFlat, "symbol halted")
+		}
+	}
+
+	for _, position := range closed {
+		if position.RecordID == 0 {
+			continue
+		}
+		if err := te.positionRepo.Update(positionRecord(position)); err != nil {
+			te.logger.Errorf("Failed to mark halted position %s closed: %v", symbol, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package trading
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// EmergencyStop 紧急停止：暂停开仓（需 ClearHalt(HaltEmergency) 显式解除），撤销全部挂单，
+// 并以只减仓市价单平掉所有持仓。暂停写入数据库失败时仍在内存中生效
+func (te *TradeExecutor) EmergencyStop(reason string) ([]*FlattenResult, error) {
+	if err := te.Halt(HaltEmergency, reason, time.Time{}); err != nil {
+		te.logger.Errorf("Failed to persist emergency halt, halting in memory only: %v", err)
+		te.mu.Lock()
+		te.halts[HaltEmergency] = &Halt{Name: HaltEmergency, Reason: reason, Since: time.Now()}
+		te.mu.Unlock()
+	}
+
+	te.logger.Errorf("EMERGENCY STOP triggered: %s", reason)
+
+	results, cancelErrors, err := te.flattenEverything(reason)
+	if err != nil {
+		te.notify("critical", "🚨 紧急停止未完成",
+			fmt.Sprintf("已暂停开仓，但获取持仓失败，请立即在交易所手动平仓。\n原因: %s\n错误: %v", reason, err))
+		return nil, err
+	}
+
+	te.notify("critical", "🚨 紧急停止", formatEmergencyStop(reason, "已暂停所有开仓，需使用 /resume 解除。", results, cancelErrors))
+	return results, nil
+}
+
+// flattenEverything 撤销全部挂单并以只减仓市价单平掉所有持仓，返回各持仓的平仓结果和撤单错误
+func (te *TradeExecutor) flattenEverything(reason string) ([]*FlattenResult, []error, error) {
+	cancelErrors := te.cancelAllOrders()
+
+	positions, err := te.binanceClient.GetPositions(te.ctx)
+	if err != nil {
+		return nil, cancelErrors, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	var results []*FlattenResult
+	for i := range positions {
+		amount, err := decimal.NewFromString(positions[i].PositionAmt)
+		if err != nil || amount.IsZero() {
+			continue
+		}
+		results = append(results, te.flattenPosition(te.positionOwner(positions[i].Symbol), &positions[i], reason))
+	}
+
+	return results, cancelErrors, nil
+}
+
+// cancelAllOrders 撤销所有交易对的挂单，返回撤单失败的错误
+func (te *TradeExecutor) cancelAllOrders() []error {
+	symbols := make(map[string]bool)
+
+	te.mu.RLock()
+	for _, order := range te.activeOrders {
+		symbols[order.Symbol] = true
+	}
+	te.mu.RUnlock()
+
+	// 实盘模式下以交易所挂单为准，包括执行器之外下的单
+	var errs []error
+	if te.isLive() {
+		openOrders, err := te.binanceClient.GetOpenOrders(te.ctx, "")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get open orders: %w", err))
+		}
+		for _, order := range openOrders {
+			symbols[order.Symbol] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(symbols))
+	for symbol := range symbols {
+		sorted = append(sorted, symbol)
+	}
+	sort.Strings(sorted)
+
+	for _, symbol := range sorted {
+		if err := te.cancelSymbolOrders(symbol); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// positionOwner 获取交易对持仓所属的用户，未跟踪时回退到生命周期记录的用户
+func (te *TradeExecutor) positionOwner(symbol string) int64 {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+
+	for _, position := range te.positions {
+		if position.Symbol == symbol && position.IsOpen {
+			return position.UserID
+		}
+	}
+	for _, lifecycle := range te.lifecycles {
+		if lifecycle.Symbol == symbol && lifecycle.State != StateFlat {
+			return lifecycle.UserID
+		}
+	}
+	return 0
+}
+
+// formatEmergencyStop 格式化紧急停止结果，haltNote 说明开仓暂停如何解除
+func formatEmergencyStop(reason, haltNote string, results []*FlattenResult, cancelErrors []error) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("原因: %s\n%s\n", reason, haltNote))
+
+	if len(results) == 0 {
+		b.WriteString("\n没有需要平仓的持仓。\n")
+	} else {
+		failed := 0
+		b.WriteString(fmt.Sprintf("\n平仓 %d 个持仓：\n", len(results)))
+		for _, result := range results {
+			if result.Error != nil {
+				failed++
+				b.WriteString(fmt.Sprintf("• %s: ❌ %v\n", result.Symbol, result.Error))
+				continue
+			}
+			b.WriteString(fmt.Sprintf("• %s: %s %s，盈亏 %s USDT\n",
+				result.Symbol, result.Side, result.Quantity.String(), result.RealizedPnl.StringFixed(2)))
+		}
+		if failed > 0 {
+			b.WriteString(fmt.Sprintf("\n⚠️ %d 个持仓平仓失败，请立即在交易所手动处理。\n", failed))
+		}
+	}
+
+	if len(cancelErrors) > 0 {
+		b.WriteString("\n⚠️ 撤单失败：\n")
+		for _, err := range cancelErrors {
+			b.WriteString(fmt.Sprintf("• %v\n", err))
+		}
+	}
+
+	return b.String()
+}
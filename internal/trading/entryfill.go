@@ -0,0 +1,127 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// entryFillPollInterval 等待开仓单成交时查询订单的间隔
+const entryFillPollInterval = 500 * time.Millisecond
+
+// errEntryFillTimeout 开仓单在等待时间内未完全成交
+var errEntryFillTimeout = errors.New("entry order not filled in time")
+
+// awaitEntryFill 查询开仓单直到完全成交，返回最新成交情况。订单未完全成交即终结时返回错误，
+// 超过配置的等待时间返回 errEntryFillTimeout
+func (te *TradeExecutor) awaitEntryFill(symbol, orderID string) (FillUpdate, error) {
+	timeout := time.Duration(te.tradingConfig.EntryFillTimeoutSeconds) * time.Second
+	deadline := time.Now().Add(timeout)
+
+	var last FillUpdate
+	for {
+		update, err := te.syncOrder(symbol, orderID)
+		switch {
+		case err != nil:
+			te.logger.Debugf("Failed to check entry order %s for %s: %v", orderID, symbol, err)
+		case update.Status == binance.OrderStatusFilled:
+			return update, nil
+		case isTerminalStatus(update.Status):
+			return update, fmt.Errorf("entry order %s is %s", orderID, update.Status)
+		default:
+			last = update
+		}
+
+		if time.Now().After(deadline) {
+			return last, fmt.Errorf("%w: order %s after %v", errEntryFillTimeout, orderID, timeout)
+		}
+
+		select {
+		case <-te.ctx.Done():
+			return last, fmt.Errorf("executor stopped before entry order %s filled", orderID)
+		case <-time.After(entryFillPollInterval):
+		}
+	}
+}
+
+// cancelUnfilledEntry 撤销超时未完全成交的开仓单，返回撤销后的最终成交情况
+func (te *TradeExecutor) cancelUnfilledEntry(symbol, orderID string) (FillUpdate, error) {
+	if err := te.CancelOrder(symbol, orderID); err != nil {
+		// 撤单失败可能是刚好成交，以订单最新状态为准
+		te.logger.Warnf("Failed to cancel unfilled entry order %s for %s: %v", orderID, symbol, err)
+	}
+
+	update, err := te.syncOrder(symbol, orderID)
+	if err != nil {
+		return update, err
+	}
+	if !isTerminalStatus(update.Status) {
+		return update, fmt.Errorf("entry order %s still %s after cancel", orderID, update.Status)
+	}
+	return update, nil
+}
+
+// rebaseOnFill 以实际成交均价和成交数量为准调整保护订单：止损止盈保持与信号价相同的距离，平移到成交均价。
+// 返回的请求携带开仓方向，保护订单按其反方向下单
+func (te *TradeExecutor) rebaseOnFill(request *TradeRequest, fill FillUpdate) *TradeRequest {
+	signal := *request.Signal
+	rebased := *request
+	rebased.Signal = &signal
+	rebased.EntrySide = protectedEntrySide(request)
+	if fill.Filled.IsPositive() {
+		rebased.Quantity = fill.Filled
+	}
+
+	if !fill.AvgPrice.IsPositive() || signal.Price.IsZero() || fill.AvgPrice.Equal(signal.Price) {
+		return &rebased
+	}
+
+	offset := fill.AvgPrice.Sub(signal.Price)
+	if !signal.StopLoss.IsZero() {
+		signal.StopLoss = signal.StopLoss.Add(offset)
+	}
+	if !signal.TakeProfit.IsZero() {
+		signal.TakeProfit = signal.TakeProfit.Add(offset)
+	}
+	signal.Price = fill.AvgPrice
+
+	te.logger.Infof("%s entry filled at %s (signal %s), protective levels moved to stop %s / take profit %s",
+		request.Symbol, fill.AvgPrice, request.Signal.Price, signal.StopLoss, signal.TakeProfit)
+	return &rebased
+}
+
+// protectFilledEntry 确认开仓单成交后按实际成交设置止损止盈。超时未完全成交时撤销剩余部分，
+// 已成交部分照常保护；完全未成交或无法确认成交时告警
+func (te *TradeExecutor) protectFilledEntry(request *TradeRequest, entryOrderID string) {
+	// 非实盘模式下市价单下单即按信号价成交
+	if !te.isLive() {
+		te.placeProtectiveOrders(request)
+		return
+	}
+
+	fill, err := te.awaitEntryFill(request.Symbol, entryOrderID)
+	if errors.Is(err, errEntryFillTimeout) {
+		te.logger.Warnf("Entry order %s for %s not filled in time, cancelling the remainder", entryOrderID, request.Symbol)
+		fill, err = te.cancelUnfilledEntry(request.Symbol, entryOrderID)
+	}
+
+	switch {
+	case fill.Filled.IsPositive():
+		if fill.Status != binance.OrderStatusFilled {
+			te.notify("warning", "开仓单部分成交",
+				fmt.Sprintf("%s 开仓单 %s 超时仅成交 %s / %s，剩余部分已撤销，按已成交数量设置止损止盈",
+					request.Symbol, entryOrderID, fill.Filled, request.Quantity))
+		}
+		te.placeProtectiveOrders(te.rebaseOnFill(request, fill))
+	case isTerminalStatus(fill.Status):
+		te.moveTo(request.UserID, request.Symbol, StateFlat, "entry not filled")
+		te.notify("warning", "开仓单未成交",
+			fmt.Sprintf("%s 开仓单 %s 未成交即终结（%s），未设置止损止盈", request.Symbol, entryOrderID, fill.Status))
+	default:
+		te.logger.Errorf("Entry for %s not confirmed, protective orders not placed: %v", request.Symbol, err)
+		te.notify("critical", "开仓未确认成交",
+			fmt.Sprintf("%s 开仓单 %s 未确认成交，未设置止损止盈，请检查订单和持仓: %v", request.Symbol, entryOrderID, err))
+	}
+}
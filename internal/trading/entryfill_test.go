@@ -0,0 +1,99 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+func TestProtectFilledEntryUsesFillAndEntrySide(t *testing.T) {
+	tests := []struct {
+		name           string
+		signal         strategy.SignalType
+		entrySide      string
+		stopLoss       string
+		takeProfit     string
+		fillPrice      string
+		wantSide       string
+		wantStopLoss   string
+		wantTakeProfit string
+	}{
+		{"long filled above signal", strategy.SignalBuy, "BUY", "29500", "31000", "30050", "SELL", "29550", "31050"},
+		{"short filled below signal", strategy.SignalSell, "SELL", "30500", "29000", "29980", "BUY", "30480", "28980"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := newFakeExchange(t)
+			te := newTestExecutor(t, fx, testTradingConfig())
+			addTestUser(t, te, 1)
+
+			entryID := fx.addFilledOrder("BTCUSDT", tt.entrySide, "0.01", tt.fillPrice)
+			te.protectFilledEntry(&TradeRequest{
+				UserID:       1,
+				Symbol:       "BTCUSDT",
+				Quantity:     decimal.RequireFromString("0.01"),
+				StrategyType: "vegas",
+				Signal: &strategy.TradingSignal{
+					Type:       tt.signal,
+					Symbol:     "BTCUSDT",
+					Price:      decimal.RequireFromString("30000"),
+					StopLoss:   decimal.RequireFromString(tt.stopLoss),
+					TakeProfit: decimal.RequireFromString(tt.takeProfit),
+				},
+			}, entryID)
+
+			placed := fx.placedOrders()
+			if len(placed) != 2 {
+				t.Fatalf("placed %d protective orders, want 2", len(placed))
+			}
+			for i, wantStop := range []string{tt.wantStopLoss, tt.wantTakeProfit} {
+				order := placed[i]
+				if order.Get("side") != tt.wantSide {
+					t.Errorf("%s side = %s, want %s", order.Get("type"), order.Get("side"), tt.wantSide)
+				}
+				if order.Get("stopPrice") != wantStop {
+					t.Errorf("%s stopPrice = %s, want %s (rebased on fill)", order.Get("type"), order.Get("stopPrice"), wantStop)
+				}
+				if order.Get("closePosition") != "true" {
+					t.Errorf("%s closePosition = %q, want true", order.Get("type"), order.Get("closePosition"))
+				}
+			}
+		})
+	}
+}
+
+func TestRebaseOnFillCarriesEntrySide(t *testing.T) {
+	fx := newFakeExchange(t)
+	te := newTestExecutor(t, fx, testTradingConfig())
+
+	request := &TradeRequest{
+		Symbol:   "BTCUSDT",
+		Quantity: decimal.RequireFromString("0.02"),
+		Signal: &strategy.TradingSignal{
+			Type:       strategy.SignalSell,
+			Price:      decimal.RequireFromString("100"),
+			StopLoss:   decimal.RequireFromString("105"),
+			TakeProfit: decimal.RequireFromString("90"),
+		},
+	}
+	rebased := te.rebaseOnFill(request, FillUpdate{
+		Filled:   decimal.RequireFromString("0.01"),
+		AvgPrice: decimal.RequireFromString("99"),
+	})
+
+	if rebased.EntrySide != "SELL" {
+		t.Errorf("EntrySide = %q, want SELL", rebased.EntrySide)
+	}
+	if !rebased.Quantity.Equal(decimal.RequireFromString("0.01")) {
+		t.Errorf("Quantity = %s, want filled quantity 0.01", rebased.Quantity)
+	}
+	if !rebased.Signal.StopLoss.Equal(decimal.RequireFromString("104")) || !rebased.Signal.TakeProfit.Equal(decimal.RequireFromString("89")) {
+		t.Errorf("levels = stop %s / take profit %s, want 104 / 89", rebased.Signal.StopLoss, rebased.Signal.TakeProfit)
+	}
+	if !request.Signal.StopLoss.Equal(decimal.RequireFromString("105")) {
+		t.Errorf("original signal modified: stop %s", request.Signal.StopLoss)
+	}
+}
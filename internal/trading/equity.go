@@ -0,0 +1,57 @@
+package trading
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// equityBase 获取用户指定保证金资产当前周期的权益基数，周期已过或尚未设置时以当前权益重新设置
+func (te *TradeExecutor) equityBase(userID int64, asset string, currentEquity decimal.Decimal) (decimal.Decimal, error) {
+	stored, ok, err := te.storedEquityBase(userID, asset)
+	if err != nil || ok {
+		return stored, err
+	}
+
+	if currentEquity.IsZero() {
+		return decimal.Zero, nil
+	}
+
+	newBase := &database.EquityBase{
+		UserID: userID,
+		Asset:  asset,
+		Equity: currentEquity.InexactFloat64(),
+		SetAt:  time.Now().UTC(),
+	}
+	if err := te.equityBaseRepo.Set(newBase); err != nil {
+		return decimal.Zero, err
+	}
+
+	te.logger.Infof("Equity base for user %d set to %s %s", userID, currentEquity.StringFixed(2), asset)
+	return currentEquity, nil
+}
+
+// storedEquityBase 获取当前周期内仍有效的权益基数，未设置或已过期时 ok 为 false
+func (te *TradeExecutor) storedEquityBase(userID int64, asset string) (decimal.Decimal, bool, error) {
+	base, err := te.equityBaseRepo.Get(userID, asset)
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+
+	period := time.Duration(te.tradingConfig.EquityResetHours) * time.Hour
+	if base == nil || equityPeriodExpired(base.SetAt, time.Now().UTC(), period) {
+		return decimal.Zero, false, nil
+	}
+
+	return decimal.NewFromFloat(base.Equity), true, nil
+}
+
+// equityPeriodExpired 判断权益基数是否已跨入新周期（周期按UTC对齐，0表示永不重置）
+func equityPeriodExpired(setAt, now time.Time, period time.Duration) bool {
+	if period <= 0 {
+		return false
+	}
+	return now.Truncate(period).After(setAt.UTC().Truncate(period))
+}
@@ -0,0 +1,319 @@
+package trading
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
+)
+
+// fakeExchange 模拟币安合约REST接口，记录收到的下单和撤单请求
+type fakeExchange struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu        sync.Mutex
+	nextID    int64
+	symbols   []binance.SymbolInfo
+	orders    map[int64]*binance.OrderResponse
+	placed    []url.Values
+	cancelled []int64
+	account   *binance.AccountInfo
+	positions []binance.Position
+}
+
+// newFakeExchange 启动模拟交易所，默认提供 BTCUSDT 和 ETHUSDT 的交易规则
+func newFakeExchange(t *testing.T) *fakeExchange {
+	t.Helper()
+
+	fx := &fakeExchange{
+		t:      t,
+		nextID: 1000,
+		orders: make(map[int64]*binance.OrderResponse),
+		symbols: []binance.SymbolInfo{
+			testSymbolInfo("BTCUSDT", "BTC", "USDT", "0.10", "0.001"),
+			testSymbolInfo("ETHUSDT", "ETH", "USDT", "0.01", "0.001"),
+		},
+	}
+	fx.server = httptest.NewServer(http.HandlerFunc(fx.handle))
+	t.Cleanup(fx.server.Close)
+	return fx
+}
+
+// testSymbolInfo 构建带价格和数量过滤器的交易规则
+func testSymbolInfo(symbol, base, quote, tickSize, stepSize string) binance.SymbolInfo {
+	return binance.SymbolInfo{
+		Symbol:     symbol,
+		Status:     binance.SymbolStatusTrading,
+		BaseAsset:  base,
+		QuoteAsset: quote,
+		Filters: []map[string]interface{}{
+			{"filterType": "PRICE_FILTER", "tickSize": tickSize},
+			{"filterType": "LOT_SIZE", "stepSize": stepSize, "minQty": stepSize},
+		},
+	}
+}
+
+func (fx *fakeExchange) handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/fapi/v1/time":
+		fx.reply(w, map[string]int64{"serverTime": time.Now().UnixMilli()})
+	case r.URL.Path == "/fapi/v1/exchangeInfo":
+		fx.reply(w, binance.ExchangeInfo{Symbols: fx.symbols})
+	case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodPost:
+		fx.reply(w, fx.placeLocked(r.PostForm))
+	case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodDelete:
+		id, _ := strconv.ParseInt(r.Form.Get("orderId"), 10, 64)
+		order, ok := fx.orders[id]
+		if !ok {
+			fx.fail(w, -2011, "Unknown order sent.")
+			return
+		}
+		order.Status = string(binance.OrderStatusCanceled)
+		fx.cancelled = append(fx.cancelled, id)
+		fx.reply(w, order)
+	case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodGet:
+		id, _ := strconv.ParseInt(r.Form.Get("orderId"), 10, 64)
+		order, ok := fx.orders[id]
+		if !ok {
+			fx.fail(w, -2013, "Order does not exist.")
+			return
+		}
+		fx.reply(w, order)
+	case r.URL.Path == "/fapi/v1/openOrders":
+		fx.reply(w, fx.openOrdersLocked(r.Form.Get("symbol")))
+	case r.URL.Path == "/fapi/v2/account" && fx.account != nil:
+		fx.reply(w, fx.account)
+	case r.URL.Path == "/fapi/v2/positionRisk":
+		fx.reply(w, fx.positions)
+	default:
+		fx.fail(w, -1000, fmt.Sprintf("unsupported endpoint %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// placeLocked 保存订单：市价单立即成交，其余挂单
+func (fx *fakeExchange) placeLocked(form url.Values) *binance.OrderResponse {
+	fx.nextID++
+	fx.placed = append(fx.placed, form)
+
+	order := &binance.OrderResponse{
+		OrderID:       fx.nextID,
+		Symbol:        form.Get("symbol"),
+		Status:        string(binance.OrderStatusNew),
+		ClientOrderID: form.Get("newClientOrderId"),
+		OrigQty:       form.Get("quantity"),
+		ExecutedQty:   "0",
+		Type:          form.Get("type"),
+		Side:          form.Get("side"),
+		StopPrice:     form.Get("stopPrice"),
+		ReduceOnly:    form.Get("reduceOnly") == "true",
+		ClosePosition: form.Get("closePosition") == "true",
+		Time:          time.Now().UnixMilli() + fx.nextID,
+	}
+	if order.Type == string(binance.OrderTypeMarket) {
+		order.Status = string(binance.OrderStatusFilled)
+		order.ExecutedQty = order.OrigQty
+	}
+	fx.orders[order.OrderID] = order
+	return order
+}
+
+func (fx *fakeExchange) openOrdersLocked(symbol string) []binance.OrderResponse {
+	open := []binance.OrderResponse{}
+	for _, order := range fx.orders {
+		if order.Status == string(binance.OrderStatusNew) && (symbol == "" || order.Symbol == symbol) {
+			open = append(open, *order)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].OrderID < open[j].OrderID })
+	return open
+}
+
+func (fx *fakeExchange) reply(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fx.t.Errorf("encode response: %v", err)
+	}
+}
+
+func (fx *fakeExchange) fail(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(binance.APIError{Code: code, Msg: msg})
+}
+
+// placedOrders 返回收到的下单请求
+func (fx *fakeExchange) placedOrders() []url.Values {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	return append([]url.Values(nil), fx.placed...)
+}
+
+// cancelledOrders 返回被撤销的订单号
+func (fx *fakeExchange) cancelledOrders() []int64 {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	return append([]int64(nil), fx.cancelled...)
+}
+
+// order 返回订单当前状态
+func (fx *fakeExchange) order(id string) *binance.OrderResponse {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	orderID, _ := strconv.ParseInt(id, 10, 64)
+	order, ok := fx.orders[orderID]
+	if !ok {
+		fx.t.Fatalf("order %s not found on exchange", id)
+	}
+	copied := *order
+	return &copied
+}
+
+// addOrder 添加一笔交易所上已存在的订单，返回订单号
+func (fx *fakeExchange) addOrder(order binance.OrderResponse) string {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+
+	fx.nextID++
+	order.OrderID = fx.nextID
+	order.Time = time.Now().UnixMilli() + fx.nextID
+	fx.orders[fx.nextID] = &order
+	return strconv.FormatInt(fx.nextID, 10)
+}
+
+// addFilledOrder 添加一笔已完全成交的市价单，返回订单号
+func (fx *fakeExchange) addFilledOrder(symbol, side, quantity, avgPrice string) string {
+	return fx.addOrder(binance.OrderResponse{
+		Symbol:      symbol,
+		Status:      string(binance.OrderStatusFilled),
+		Side:        side,
+		Type:        string(binance.OrderTypeMarket),
+		OrigQty:     quantity,
+		ExecutedQty: quantity,
+		AvgPrice:    avgPrice,
+	})
+}
+
+// setOrder 修改订单状态，模拟交易所侧的成交
+func (fx *fakeExchange) setOrder(id string, update func(order *binance.OrderResponse)) {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	orderID, _ := strconv.ParseInt(id, 10, 64)
+	update(fx.orders[orderID])
+}
+
+// testTradingConfig 测试使用的交易配置
+func testTradingConfig() *config.TradingConfig {
+	return &config.TradingConfig{
+		DefaultRiskPercent:       1,
+		DefaultLeverage:          5,
+		MinOrderValue:            5,
+		MaxOrderValue:            100000,
+		EntryFillTimeoutSeconds:  1,
+		FillDedupMinutes:         60,
+		SymbolStatusCheckMinutes: 5,
+	}
+}
+
+// newTestExecutor 创建连接模拟交易所和临时数据库的交易执行器（实盘模式，未启动）
+func newTestExecutor(t *testing.T, fx *fakeExchange, cfg *config.TradingConfig) *TradeExecutor {
+	t.Helper()
+
+	log := logger.NewLoggerWithLevel("error")
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	client, err := binance.New(&config.BinanceConfig{
+		APIKey:    "test-key",
+		SecretKey: "test-secret",
+		BaseURL:   fx.server.URL,
+		Timeout:   5,
+	}, log)
+	if err != nil {
+		t.Fatalf("create binance client: %v", err)
+	}
+
+	te := NewTradeExecutor(cfg, log, client, db)
+	t.Cleanup(te.cancel)
+	return te
+}
+
+// addTestUser 创建启用交易的用户配置
+func addTestUser(t *testing.T, te *TradeExecutor, userID int64) *database.UserConfig {
+	t.Helper()
+
+	userConfig := &database.UserConfig{
+		UserID:         userID,
+		Username:       fmt.Sprintf("user%d", userID),
+		ChatID:         userID,
+		RiskPercentage: 1,
+		Leverage:       5,
+		IsActive:       true,
+	}
+	if err := te.userConfigRepo.Create(userConfig); err != nil {
+		t.Fatalf("create user config: %v", err)
+	}
+	return userConfig
+}
+
+// sentNotification 记录的系统通知
+type sentNotification struct {
+	level, title, message string
+}
+
+// recordingNotifier 记录发送的通知
+type recordingNotifier struct {
+	mu     sync.Mutex
+	system []sentNotification
+	trades []*TradeResult
+}
+
+func (n *recordingNotifier) SendSystemNotification(level string, title, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.system = append(n.system, sentNotification{level, title, message})
+	return nil
+}
+
+func (n *recordingNotifier) SendTradeNotification(trade *TradeResult) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.trades = append(n.trades, trade)
+	return nil
+}
+
+// titles 返回指定级别的通知标题
+func (n *recordingNotifier) titles(level string) []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var titles []string
+	for _, sent := range n.system {
+		if sent.level == level {
+			titles = append(titles, sent.title)
+		}
+	}
+	return titles
+}
@@ -2,6 +2,7 @@ package trading
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -10,25 +11,88 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/pipeline"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
 	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/pkg/logger"
 )
 
 // TradeExecutor 交易执行器
 type TradeExecutor struct {
+	tradingConfig  *config.TradingConfig
 	logger         logger.Logger
 	binanceClient  *binance.Client
 	db             *database.Database
 	tradeRepo      *database.TradeRepository
 	positionRepo   *database.PositionRepository
 	userConfigRepo *database.UserConfigRepository
+	equityBaseRepo *database.EquityBaseRepository
+	equitySnapshotRepo *database.EquitySnapshotRepository
+	haltRepo           *database.TradingHaltRepository
 	mu             sync.RWMutex
 	isRunning      bool
+	stopping       bool   // Stop 已开始，不再启动新的后台任务
+	mode           string // 运行模式，见 config.ModeAnalysis 等
 	ctx            context.Context
 	cancel         context.CancelFunc
+	wg             sync.WaitGroup // 进行中的后台任务（止损止盈设置、交易日志），停止时等待完成
 	activeOrders   map[string]*ActiveOrder
 	positions      map[string]*Position
+	lifecycles     map[string]*PositionLifecycle // 按 positionKey（用户+交易对）的持仓生命周期状态机
+	fills          map[string]*appliedFill       // 按订单ID记录已处理的成交，避免多个来源重复处理
+	lastEntryTimes map[string]time.Time // 用户+交易对最近一次开仓时间，用于冷却
+	marginRejections map[int64]int     // 用户连续因保证金不足被拒绝开仓的次数
+	halts          map[string]*Halt     // 生效中的开仓暂停（按来源），持久化到数据库
+	notifier       Notifier
+	journal        Journal
+	eventBus       *pipeline.Bus
+	// 交易规则缓存
+	symbolInfos       map[string]*binance.SymbolInfo
+	symbolInfoUpdated time.Time
+	// 手续费缓存
+	feeInfos map[string]*FeeInfo
+	feeTier  int
+	// 最近一次因波动过大被跳过的信号时间（按交易对），用于通知去重
+	volatilityNotified map[string]time.Time
+	// 数据库降级状态
+	dbFailures    int
+	dbDegraded    bool
+	pendingTrades []*database.Trade
+	// 当日权益基准，用于亏损和回撤熔断
+	dailyEquity *dailyEquity
+	// 交易所维护状态
+	maintenance bool
+	// 行情连接抖动状态
+	feedUnstable bool
+	// 实时价格来源
+	priceFeed PriceFeed
+	// 用户数据流连接期间由推送事件驱动订单和持仓更新
+	userStreamConnected bool
+	positionSync        chan struct{}
+	// 已按配置设置过保证金模式和杠杆的交易对
+	marginTypes map[string]string
+	leverages   map[string]int
+	// 持仓交易对停止交易后的回调
+	symbolHaltedHandler SymbolHaltedFunc
+	// 资金费结算前平仓的持仓（按交易对），结算后用于重新开仓
+	fundingExits map[string]*fundingExit
+	reentryCheck ReentryCheckFunc
+	// EMA12移动止盈出场检查
+	exitCheck ExitCheckFunc
+	// 交易对是否自动交易（按交易对暂停时仍接收信号），未设置时全部允许
+	symbolTradingCheck SymbolTradingFunc
+}
+
+// Notifier 交易执行器使用的通知接口
+type Notifier interface {
+	SendSystemNotification(level string, title, message string) error
+	SendTradeNotification(trade *TradeResult) error
+}
+
+// Journal 交易执行器使用的交易日志接口
+type Journal interface {
+	RecordEntry(trade *database.Trade, signal *strategy.TradingSignal) error
 }
 
 // ActiveOrder 活跃订单
@@ -50,6 +114,7 @@ type ActiveOrder struct {
 
 // Position 持仓信息
 type Position struct {
+	RecordID        int // 数据库持仓记录ID
 	UserID          int64
 	Symbol          string
 	Side            string
@@ -72,6 +137,10 @@ type TradeRequest struct {
 	Signal       *strategy.TradingSignal
 	Quantity     decimal.Decimal
 	StrategyType string
+	// ClosePosition 止损止盈单触发时平掉整个持仓（closePosition），不按 Quantity 下单
+	ClosePosition bool
+	// EntrySide 止损止盈单所保护持仓的开仓方向（BUY/SELL），平仓单按其反方向下单；为空时按当前持仓方向
+	EntrySide string
 }
 
 // TradeResult 交易结果
@@ -89,21 +158,157 @@ type TradeResult struct {
 }
 
 // NewTradeExecutor 创建新的交易执行器
-func NewTradeExecutor(log logger.Logger, client *binance.Client, db *database.Database) *TradeExecutor {
+func NewTradeExecutor(cfg *config.TradingConfig, log logger.Logger, client *binance.Client, db *database.Database) *TradeExecutor {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &TradeExecutor{
+		tradingConfig:  cfg,
 		logger:         log,
 		binanceClient:  client,
 		db:             db,
 		tradeRepo:      database.NewTradeRepository(db.GetDB()),
 		positionRepo:   database.NewPositionRepository(db.GetDB()),
 		userConfigRepo: database.NewUserConfigRepository(db.GetDB()),
+		equityBaseRepo: database.NewEquityBaseRepository(db.GetDB()),
+		equitySnapshotRepo: database.NewEquitySnapshotRepository(db.GetDB()),
+		haltRepo:           database.NewTradingHaltRepository(db.GetDB()),
 		ctx:            ctx,
 		cancel:         cancel,
 		activeOrders:   make(map[string]*ActiveOrder),
 		positions:      make(map[string]*Position),
+		lifecycles:     make(map[string]*PositionLifecycle),
+		fills:          make(map[string]*appliedFill),
+		lastEntryTimes: make(map[string]time.Time),
+		marginRejections: make(map[int64]int),
+		halts:          make(map[string]*Halt),
+		symbolInfos:    make(map[string]*binance.SymbolInfo),
+		feeInfos:       make(map[string]*FeeInfo),
+		volatilityNotified: make(map[string]time.Time),
+		fundingExits:       make(map[string]*fundingExit),
+		marginTypes:        make(map[string]string),
+		leverages:          make(map[string]int),
+		positionSync:       make(chan struct{}, 1),
 		isRunning:      false,
+		mode:           config.ModeLive,
+	}
+}
+
+// SetNotifier 设置通知器
+func (te *TradeExecutor) SetNotifier(notifier Notifier) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.notifier = notifier
+}
+
+// SetEventBus 设置数据流事件总线
+func (te *TradeExecutor) SetEventBus(bus *pipeline.Bus) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.eventBus = bus
+}
+
+// SetJournal 设置交易日志记录器
+func (te *TradeExecutor) SetJournal(journal Journal) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.journal = journal
+}
+
+// recordJournal 异步记录开仓日志，失败只记录日志不影响交易
+func (te *TradeExecutor) recordJournal(trade *database.Trade, signal *strategy.TradingSignal) {
+	te.mu.RLock()
+	journal := te.journal
+	te.mu.RUnlock()
+
+	if journal == nil {
+		return
+	}
+
+	te.goEntryTask(func() {
+		if err := journal.RecordEntry(trade, signal); err != nil {
+			te.logger.Errorf("Failed to record trade journal for %s: %v", trade.Symbol, err)
+		}
+	})
+}
+
+// trackEntry 登记一次进行中的开仓，执行器未运行时返回 false。
+// 与 Stop 在同一把锁下检查运行状态，保证 Stop 等待时不会再有新的开仓开始
+func (te *TradeExecutor) trackEntry() bool {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+
+	if !te.isRunning {
+		return false
+	}
+	te.wg.Add(1)
+	return true
+}
+
+// goTracked 启动停止时需要等待完成的后台任务，Stop 开始后不再启动并返回 false。
+// 与 Stop 在同一把锁下检查，保证 Stop 等待期间计数不会从零重新增加
+func (te *TradeExecutor) goTracked(task func()) bool {
+	te.mu.Lock()
+	if te.stopping {
+		te.mu.Unlock()
+		return false
+	}
+	te.wg.Add(1)
+	te.mu.Unlock()
+
+	go func() {
+		defer te.wg.Done()
+		task()
+	}()
+	return true
+}
+
+// goEntryTask 在进行中的开仓内启动后台任务（止损止盈设置、交易日志）。
+// 开仓已由 trackEntry 登记，计数不为零，停止过程中也照常启动，由 Stop 等待完成
+func (te *TradeExecutor) goEntryTask(task func()) {
+	te.wg.Add(1)
+	go func() {
+		defer te.wg.Done()
+		task()
+	}()
+}
+
+// publishOrderEvent 发布下单及成交事件
+func (te *TradeExecutor) publishOrderEvent(request *TradeRequest, orderResp *binance.OrderResponse, err error) {
+	te.mu.RLock()
+	bus := te.eventBus
+	te.mu.RUnlock()
+
+	event := pipeline.Event{
+		Stage:    pipeline.StageOrderPlaced,
+		Symbol:   request.Symbol,
+		Strategy: request.StrategyType,
+		UserID:   request.UserID,
+		Signal:   request.Signal,
+		Err:      err,
+	}
+	if orderResp != nil {
+		event.OrderID = fmt.Sprintf("%d", orderResp.OrderID)
+	}
+	bus.Publish(event)
+
+	if orderResp != nil && orderResp.Status == string(binance.OrderStatusFilled) {
+		event.Stage = pipeline.StageFill
+		bus.Publish(event)
+	}
+}
+
+// notify 发送系统通知（未设置通知器时忽略）
+func (te *TradeExecutor) notify(level, title, message string) {
+	te.mu.RLock()
+	notifier := te.notifier
+	te.mu.RUnlock()
+
+	if notifier == nil {
+		return
+	}
+
+	if err := notifier.SendSystemNotification(level, title, message); err != nil {
+		te.logger.Errorf("Failed to send notification: %v", err)
 	}
 }
 
@@ -116,6 +321,24 @@ func (te *TradeExecutor) Start() error {
 		return fmt.Errorf("trade executor is already running")
 	}
 
+	// 恢复重启前的暂停状态，避免重启后恢复用户已主动停止的交易
+	if err := te.restoreHalts(); err != nil {
+		return fmt.Errorf("failed to restore trading halts: %w", err)
+	}
+
+	// 配置开启紧急停止开关时启动即暂停开仓，直到 /resume 解除
+	if te.tradingConfig.EmergencyStopEnabled {
+		if _, ok := te.halts[HaltEmergency]; !ok {
+			te.halts[HaltEmergency] = &Halt{Name: HaltEmergency, Reason: "emergency stop enabled in config", Since: time.Now()}
+		}
+		te.logger.Warn("Emergency stop enabled in config, new entries paused")
+	}
+
+	// 恢复重启前记录的持仓，并在启动后与交易所持仓核对
+	if err := te.restorePositions(); err != nil {
+		return fmt.Errorf("failed to restore positions: %w", err)
+	}
+
 	te.isRunning = true
 	te.logger.Info("Trade executor started")
 
@@ -125,43 +348,121 @@ func (te *TradeExecutor) Start() error {
 	// 启动持仓监控
 	go te.monitorPositions()
 
+	// 启动数据库健康监控
+	go te.monitorDatabaseHealth()
+
+	// 启动手续费刷新
+	go te.monitorFees()
+
+	// 启动权益快照记录
+	go te.monitorEquity()
+
+	// 启动当日亏损和回撤熔断检查
+	go te.monitorLossLimits()
+
+	// 启动交易所维护恢复探测
+	go te.monitorMaintenance()
+
+	// 启动资金费结算前平仓
+	go te.monitorFunding()
+
+	// 启动持仓交易对状态检测
+	go te.monitorHeldSymbols()
+
+	go func() {
+		if err := te.ReconcilePositions(); err != nil {
+			te.logger.Warnf("Failed to reconcile restored positions: %v", err)
+		}
+	}()
+
 	return nil
 }
 
-// Stop 停止交易执行器
-func (te *TradeExecutor) Stop() {
+// Stop 停止交易执行器：不再接受新的开仓和后台任务，在 ctx 截止前等待进行中的开仓、止损止盈设置和交易日志完成；
+// 截止时间已到则取消上下文，中止等待成交和重试，再停止监控并写回缓存的交易记录。
+// 通知器需在此之后停止，以便送达停止过程中产生的告警
+func (te *TradeExecutor) Stop(ctx context.Context) {
 	te.mu.Lock()
-	defer te.mu.Unlock()
-
 	if !te.isRunning {
+		te.mu.Unlock()
 		return
 	}
-
 	te.isRunning = false
+	te.stopping = true
+	te.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		te.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		te.logger.Warn("In-flight entries and protective orders not finished before shutdown deadline, aborting them")
+		te.cancel()
+		<-done
+	}
 	te.cancel()
+
+	if flushed, err := te.flushPendingTrades(); err != nil {
+		te.logger.Errorf("Failed to flush pending trades on shutdown (%d flushed): %v", flushed, err)
+	} else if flushed > 0 {
+		te.logger.Infof("Flushed %d pending trades on shutdown", flushed)
+	}
+
+	te.mu.RLock()
+	pending := len(te.pendingTrades)
+	te.mu.RUnlock()
+	if pending > 0 {
+		te.notify("critical", "🗄 交易记录未保存",
+			fmt.Sprintf("停止时仍有 %d 条交易记录未能写入数据库，请核对交易所成交记录。", pending))
+	}
+
 	te.logger.Info("Trade executor stopped")
 }
 
+// 执行入口的类型化错误，调用方可用 errors.Is 区分跳过原因
+var (
+	// ErrExecutionDisabled 当前运行模式不允许下单（分析模式）
+	ErrExecutionDisabled = errors.New("trade execution disabled in analysis mode")
+	// ErrUserConfigNotFound 用户没有交易配置
+	ErrUserConfigNotFound = errors.New("user config not found")
+	// ErrUserInactive 用户已关闭交易
+	ErrUserInactive = errors.New("user trading is disabled")
+	// ErrUserConfigUnavailable 读取用户配置失败（数据库异常），与配置缺失不同，属于需要关注的故障
+	ErrUserConfigUnavailable = errors.New("failed to get user config")
+	// ErrExecutorStopped 执行器已停止或正在停止，不再接受新的开仓
+	ErrExecutorStopped = errors.New("trade executor is stopped")
+)
+
 // ExecuteTrade 执行交易
 func (te *TradeExecutor) ExecuteTrade(request *TradeRequest) *TradeResult {
 	result := &TradeResult{
 		ExecutedAt: time.Now(),
 	}
 
+	// 分析模式从不下单
+	if te.Mode() == config.ModeAnalysis {
+		result.Error = ErrExecutionDisabled
+		return result
+	}
+
 	// 验证用户配置
 	userConfig, err := te.userConfigRepo.GetByUserID(request.UserID)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to get user config: %w", err)
+		result.Error = fmt.Errorf("%w: %w", ErrUserConfigUnavailable, err)
 		return result
 	}
 
 	if userConfig == nil {
-		result.Error = fmt.Errorf("user config not found")
+		result.Error = ErrUserConfigNotFound
 		return result
 	}
 
 	if !userConfig.IsActive {
-		result.Error = fmt.Errorf("user trading is disabled")
+		result.Error = ErrUserInactive
 		return result
 	}
 
@@ -171,9 +472,96 @@ func (te *TradeExecutor) ExecuteTrade(request *TradeRequest) *TradeResult {
 		return result
 	}
 
+	// 开仓信号需满足用户的置信度和冷却要求
+	if request.Signal.Type.IsEntry() {
+		// 停止过程中不再开仓，已开始的开仓（含随后的止损止盈设置）由 Stop 等待完成；平仓和保护单不受限制
+		if !te.trackEntry() {
+			result.Error = ErrExecutorStopped
+			return result
+		}
+		defer te.wg.Done()
+
+		if te.IsDatabaseDegraded() {
+			result.Error = fmt.Errorf("new entries paused: database unavailable")
+			return result
+		}
+
+		if err := te.checkHalts(); err != nil {
+			result.Error = err
+			return result
+		}
+
+		if !te.symbolTradingEnabled(request.Symbol) {
+			result.Error = fmt.Errorf("new entries paused for %s", request.Symbol)
+			return result
+		}
+
+		if err := te.checkSymbolTradable(request.Symbol); err != nil {
+			result.Error = err
+			return result
+		}
+
+		if te.InMaintenance() {
+			result.Error = fmt.Errorf("new entries paused: exchange under maintenance")
+			return result
+		}
+
+		if te.FeedUnstable() {
+			result.Error = fmt.Errorf("new entries paused: market data feed unstable")
+			return result
+		}
+
+		if request.Signal.Confidence < userConfig.MinConfidence {
+			result.Error = fmt.Errorf("signal confidence %.2f below user minimum %.2f",
+				request.Signal.Confidence, userConfig.MinConfidence)
+			return result
+		}
+
+		if remaining := te.entryCooldownRemaining(userConfig, request.Symbol); remaining > 0 {
+			result.Error = fmt.Errorf("entry cooldown active for %s, %v remaining",
+				request.Symbol, remaining.Round(time.Second))
+			return result
+		}
+
+		// 仅在用户设定的交易时段内开仓，已有持仓的止损止盈不受影响
+		session, err := SessionForUser(userConfig)
+		if err != nil {
+			result.Error = fmt.Errorf("invalid trading session: %w", err)
+			return result
+		}
+		if now := time.Now(); session != nil && !session.Contains(now) {
+			result.Error = fmt.Errorf("outside trading session %s, next open at %s",
+				session, session.NextOpen(now).Format("2006-01-02 15:04 MST"))
+			return result
+		}
+
+		// 极端波动中策略的容差和止损失效，跳过开仓
+		if err := te.checkVolatility(request.Signal); err != nil {
+			te.notifyVolatilitySuppressed(request.Signal, err)
+			result.Error = err
+			return result
+		}
+
+		// 计入取整、滑点和手续费后风险收益比过低的交易无法盈利
+		if err := te.checkRiskReward(request.Symbol, request.Signal); err != nil {
+			result.Error = err
+			return result
+		}
+
+		// 新交易对开仓不能超过持仓数上限
+		if err := te.checkMaxPositions(request); err != nil {
+			if errors.Is(err, ErrMaxPositionsReached) {
+				te.notifyMaxPositions(request, err)
+				result.Message = fmt.Sprintf("Entry skipped: max positions (%d) reached", te.tradingConfig.MaxPositions)
+			}
+			result.Error = err
+			return result
+		}
+	}
+
 	// 计算交易数量
 	if request.Quantity.IsZero() {
-		quantity, err := te.calculateQuantity(userConfig, request.Symbol, request.Signal.Price)
+		quantity, err := te.calculateQuantity(userConfig, request.Symbol, request.Signal.Price, request.Signal.StopLoss)
 		if err != nil {
 			result.Error = fmt.Errorf("failed to calculate quantity: %w", err)
 			return result
@@ -181,12 +569,36 @@ func (te *TradeExecutor) ExecuteTrade(request *TradeRequest) *TradeResult {
 		request.Quantity = quantity
 	}
 
+	// 开仓、保护或平仓流程进行中时不再开仓
+	if request.Signal.Type.IsEntry() {
+		// 挂单数上限只限制开仓，保护订单不受限制，避免为止盈腾位置撤掉同一持仓的止损
+		if err := te.ensureOrderCapacity(request.UserID, request.Symbol); err != nil {
+			result.Error = err
+			return result
+		}
+
+		if err := te.ensureMarginType(request.Symbol); err != nil {
+			result.Error = err
+			return result
+		}
+
+		if err := te.ensureLeverage(request.Symbol, userConfig.Leverage); err != nil {
+			result.Error = err
+			return result
+		}
+
+		if err := te.beginEntry(request); err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
 	// 执行不同类型的交易
 	switch request.Signal.Type {
 	case strategy.SignalBuy:
-		return te.executeBuyOrder(request)
+		return te.finishEntry(request, te.recordEntry(request, te.recordRejection(request, te.executeBuyOrder(request))))
 	case strategy.SignalSell:
-		return te.executeSellOrder(request)
+		return te.finishEntry(request, te.recordEntry(request, te.recordRejection(request, te.executeSellOrder(request))))
 	case strategy.SignalStopLoss:
 		return te.executeStopLoss(request)
 	case strategy.SignalTakeProfit:
@@ -197,21 +609,61 @@ func (te *TradeExecutor) ExecuteTrade(request *TradeRequest) *TradeResult {
 	}
 }
 
+// entryCooldownKey 获取开仓冷却键
+func entryCooldownKey(userID int64, symbol string) string {
+	return fmt.Sprintf("%d_%s", userID, symbol)
+}
+
+// entryCooldownRemaining 获取开仓冷却剩余时间
+func (te *TradeExecutor) entryCooldownRemaining(userConfig *database.UserConfig, symbol string) time.Duration {
+	if userConfig.CooldownMinutes <= 0 {
+		return 0
+	}
+
+	te.mu.RLock()
+	lastEntry, exists := te.lastEntryTimes[entryCooldownKey(userConfig.UserID, symbol)]
+	te.mu.RUnlock()
+
+	if !exists {
+		return 0
+	}
+
+	cooldown := time.Duration(userConfig.CooldownMinutes) * time.Minute
+	return time.Until(lastEntry.Add(cooldown))
+}
+
+// recordEntry 记录成功开仓的时间
+func (te *TradeExecutor) recordEntry(request *TradeRequest, result *TradeResult) *TradeResult {
+	if result.Success {
+		te.mu.Lock()
+		te.lastEntryTimes[entryCooldownKey(request.UserID, request.Symbol)] = result.ExecutedAt
+		te.mu.Unlock()
+	}
+	return result
+}
+
 // executeBuyOrder 执行买入订单
 func (te *TradeExecutor) executeBuyOrder(request *TradeRequest) *TradeResult {
 	result := &TradeResult{ExecutedAt: time.Now()}
 
+	quantity, err := te.roundQuantity(request.Symbol, request.Quantity)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
 	// 构建订单请求
 	orderReq := &binance.OrderRequest{
 		Symbol:      request.Symbol,
 		Side:        "BUY",
 		Type:        "MARKET",
-		Quantity:    request.Quantity.String(),
+		Quantity:    quantity.String(),
 		TimeInForce: "GTC",
 	}
 
 	// 发送订单
-	orderResp, err := te.binanceClient.PlaceOrder(orderReq)
+	orderResp, err := te.placeOrder(orderReq)
+	te.publishOrderEvent(request, orderResp, err)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to place buy order: %w", err)
 		return result
@@ -225,28 +677,34 @@ func (te *TradeExecutor) executeBuyOrder(request *TradeRequest) *TradeResult {
 		ClientOrderID: orderResp.ClientOrderID,
 		Side:          "BUY",
 		Type:          "MARKET",
-		Quantity:      request.Quantity.InexactFloat64(),
+		Quantity:      quantity.InexactFloat64(),
 		Price:         request.Signal.Price.InexactFloat64(),
 		Status:        orderResp.Status,
 		StrategyType:  request.StrategyType,
 		SignalType:    "entry",
 	}
 
-	if err := te.tradeRepo.Create(trade); err != nil {
-		te.logger.Errorf("Failed to save trade record: %v", err)
-	}
+	te.saveTrade(trade)
+	te.trackOrder(request, orderReq, orderResp, "entry")
+	te.recordJournal(trade, request.Signal)
 
 	// 设置止损止盈订单
 	if !request.Signal.StopLoss.IsZero() || !request.Signal.TakeProfit.IsZero() {
-		go te.setStopLossAndTakeProfit(request, fmt.Sprintf("%d", orderResp.OrderID))
+		entryOrderID := fmt.Sprintf("%d", orderResp.OrderID)
+		te.goEntryTask(func() { te.protectFilledEntry(request, entryOrderID) })
 	}
 
 	result.Success = true
 	result.OrderID = fmt.Sprintf("%d", orderResp.OrderID)
-	result.Message = fmt.Sprintf("Buy order placed successfully: %s", orderResp.OrderID)
+	result.Symbol = request.Symbol
+	result.Side = "BUY"
+	result.Quantity = quantity
+	result.Price = request.Signal.Price
+	result.Status = orderResp.Status
+	result.Message = fmt.Sprintf("Buy order placed successfully: %d", orderResp.OrderID)
 
-	te.logger.Infof("Buy order executed: %s, Quantity: %s, Price: %s", 
-		orderResp.OrderID, request.Quantity.String(), request.Signal.Price.String())
+	te.logger.Infof("Buy order executed: %d, Quantity: %s, Price: %s", 
+		orderResp.OrderID, quantity.String(), request.Signal.Price.String())
 
 	return result
 }
@@ -255,17 +713,24 @@ func (te *TradeExecutor) executeBuyOrder(request *TradeRequest) *TradeResult {
 func (te *TradeExecutor) executeSellOrder(request *TradeRequest) *TradeResult {
 	result := &TradeResult{ExecutedAt: time.Now()}
 
+	quantity, err := te.roundQuantity(request.Symbol, request.Quantity)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
 	// 构建订单请求
 	orderReq := &binance.OrderRequest{
 		Symbol:      request.Symbol,
 		Side:        "SELL",
 		Type:        "MARKET",
-		Quantity:    request.Quantity.String(),
+		Quantity:    quantity.String(),
 		TimeInForce: "GTC",
 	}
 
 	// 发送订单
-	orderResp, err := te.binanceClient.PlaceOrder(orderReq)
+	orderResp, err := te.placeOrder(orderReq)
+	te.publishOrderEvent(request, orderResp, err)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to place sell order: %w", err)
 		return result
@@ -279,28 +744,34 @@ func (te *TradeExecutor) executeSellOrder(request *TradeRequest) *TradeResult {
 		ClientOrderID: orderResp.ClientOrderID,
 		Side:          "SELL",
 		Type:          "MARKET",
-		Quantity:      request.Quantity.InexactFloat64(),
+		Quantity:      quantity.InexactFloat64(),
 		Price:         request.Signal.Price.InexactFloat64(),
 		Status:        orderResp.Status,
 		StrategyType:  request.StrategyType,
 		SignalType:    "entry",
 	}
 
-	if err := te.tradeRepo.Create(trade); err != nil {
-		te.logger.Errorf("Failed to save trade record: %v", err)
-	}
+	te.saveTrade(trade)
+	te.trackOrder(request, orderReq, orderResp, "entry")
+	te.recordJournal(trade, request.Signal)
 
 	// 设置止损止盈订单
 	if !request.Signal.StopLoss.IsZero() || !request.Signal.TakeProfit.IsZero() {
-		go te.setStopLossAndTakeProfit(request, fmt.Sprintf("%d", orderResp.OrderID))
+		entryOrderID := fmt.Sprintf("%d", orderResp.OrderID)
+		te.goEntryTask(func() { te.protectFilledEntry(request, entryOrderID) })
 	}
 
 	result.Success = true
 	result.OrderID = fmt.Sprintf("%d", orderResp.OrderID)
+	result.Symbol = request.Symbol
+	result.Side = "SELL"
+	result.Quantity = quantity
+	result.Price = request.Signal.Price
+	result.Status = orderResp.Status
 	result.Message = fmt.Sprintf("Sell order placed successfully: %d", orderResp.OrderID)
 
 	te.logger.Infof("Sell order executed: %d, Quantity: %s, Price: %s", 
-		orderResp.OrderID, request.Quantity.String(), request.Signal.Price.String())
+		orderResp.OrderID, quantity.String(), request.Signal.Price.String())
 
 	return result
 }
@@ -309,18 +780,29 @@ func (te *TradeExecutor) executeSellOrder(request *TradeRequest) *TradeResult {
 func (te *TradeExecutor) executeStopLoss(request *TradeRequest) *TradeResult {
 	result := &TradeResult{ExecutedAt: time.Now()}
 
-	// 构建止损订单请求
-	orderReq := &binance.OrderRequest{
-		Symbol:      request.Symbol,
-		Side:        te.getOppositeSide(request.Signal),
-		Type:        "STOP_MARKET",
-		Quantity:    request.Quantity.String(),
-		StopPrice:   request.Signal.StopLoss.String(),
-		TimeInForce: "GTC",
+	quantity, err := te.roundQuantity(request.Symbol, request.Quantity)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	stopPrice, err := te.roundPrice(request.Symbol, request.Signal.StopLoss)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	side, err := te.closingSide(request)
+	if err != nil {
+		result.Error = err
+		return result
 	}
 
+	// 构建止损订单请求
+	orderReq := stopLossOrder(request.Symbol, side, quantity, stopPrice, request.ClosePosition)
+
 	// 发送订单
-	orderResp, err := te.binanceClient.PlaceOrder(orderReq)
+	orderResp, err := te.placeOrder(orderReq)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to place stop loss order: %w", err)
 		return result
@@ -333,24 +815,23 @@ func (te *TradeExecutor) executeStopLoss(request *TradeRequest) *TradeResult {
 		OrderID:       fmt.Sprintf("%d", orderResp.OrderID),
 		ClientOrderID: orderResp.ClientOrderID,
 		Side:          orderReq.Side,
-		Type:          "STOP_MARKET",
-		Quantity:      request.Quantity.InexactFloat64(),
-		StopPrice:     request.Signal.StopLoss.InexactFloat64(),
+		Type:          orderReq.Type,
+		Quantity:      quantity.InexactFloat64(),
+		StopPrice:     stopPrice.InexactFloat64(),
 		Status:        orderResp.Status,
 		StrategyType:  request.StrategyType,
 		SignalType:    "stop_loss",
 	}
 
-	if err := te.tradeRepo.Create(trade); err != nil {
-		te.logger.Errorf("Failed to save trade record: %v", err)
-	}
+	te.saveTrade(trade)
+	te.trackOrder(request, orderReq, orderResp, "stop_loss")
 
 	result.Success = true
 	result.OrderID = fmt.Sprintf("%d", orderResp.OrderID)
 	result.Message = fmt.Sprintf("Stop loss order placed successfully: %d", orderResp.OrderID)
 
 	te.logger.Infof("Stop loss order executed: %d, Stop Price: %s", 
-		orderResp.OrderID, request.Signal.StopLoss.String())
+		orderResp.OrderID, stopPrice.String())
 
 	return result
 }
@@ -359,18 +840,29 @@ func (te *TradeExecutor) executeStopLoss(request *TradeRequest) *TradeResult {
 func (te *TradeExecutor) executeTakeProfit(request *TradeRequest) *TradeResult {
 	result := &TradeResult{ExecutedAt: time.Now()}
 
-	// 构建止盈订单请求
-	orderReq := &binance.OrderRequest{
-		Symbol:      request.Symbol,
-		Side:        te.getOppositeSide(request.Signal),
-		Type:        "LIMIT",
-		Quantity:    request.Quantity.String(),
-		Price:       request.Signal.TakeProfit.String(),
-		TimeInForce: "GTC",
+	quantity, err := te.roundQuantity(request.Symbol, request.Quantity)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	takeProfitPrice, err := te.roundPrice(request.Symbol, request.Signal.TakeProfit)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	side, err := te.closingSide(request)
+	if err != nil {
+		result.Error = err
+		return result
 	}
 
+	// 构建止盈订单请求
+	orderReq := takeProfitOrder(request.Symbol, side, quantity, takeProfitPrice, request.ClosePosition)
+
 	// 发送订单
-	orderResp, err := te.binanceClient.PlaceOrder(orderReq)
+	orderResp, err := te.placeOrder(orderReq)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to place take profit order: %w", err)
 		return result
@@ -383,130 +875,126 @@ func (te *TradeExecutor) executeTakeProfit(request *TradeRequest) *TradeResult {
 		OrderID:       fmt.Sprintf("%d", orderResp.OrderID),
 		ClientOrderID: orderResp.ClientOrderID,
 		Side:          orderReq.Side,
-		Type:          "LIMIT",
-		Quantity:      request.Quantity.InexactFloat64(),
-		Price:         request.Signal.TakeProfit.InexactFloat64(),
+		Type:          orderReq.Type,
+		Quantity:      quantity.InexactFloat64(),
+		StopPrice:     takeProfitPrice.InexactFloat64(),
 		Status:        orderResp.Status,
 		StrategyType:  request.StrategyType,
 		SignalType:    "take_profit",
 	}
 
-	if err := te.tradeRepo.Create(trade); err != nil {
-		te.logger.Errorf("Failed to save trade record: %v", err)
-	}
+	te.saveTrade(trade)
+	te.trackOrder(request, orderReq, orderResp, "take_profit")
 
 	result.Success = true
 	result.OrderID = fmt.Sprintf("%d", orderResp.OrderID)
 	result.Message = fmt.Sprintf("Take profit order placed successfully: %d", orderResp.OrderID)
 
 	te.logger.Infof("Take profit order executed: %d, Price: %s", 
-		orderResp.OrderID, request.Signal.TakeProfit.String())
+		orderResp.OrderID, takeProfitPrice.String())
 
 	return result
 }
 
-// setStopLossAndTakeProfit 设置止损止盈订单
-func (te *TradeExecutor) setStopLossAndTakeProfit(request *TradeRequest, parentOrderID string) {
-	// 等待主订单成交
-	time.Sleep(2 * time.Second)
+// placeProtectiveOrders 为已有持仓下止损止盈订单（request.Signal 的方向为持仓方向）
+func (te *TradeExecutor) placeProtectiveOrders(request *TradeRequest) {
+	te.moveTo(request.UserID, request.Symbol, StateProtecting, "placing protective orders")
+	stopLoss, takeProfit := te.roundProtectiveLevels(request)
+	entrySide := protectedEntrySide(request)
 
 	// 设置止损订单
-	if !request.Signal.StopLoss.IsZero() {
+	var stopLossOrderID, takeProfitOrderID string
+	if !stopLoss.IsZero() {
 		stopLossReq := &TradeRequest{
-			UserID:       request.UserID,
-			Symbol:       request.Symbol,
-			Quantity:     request.Quantity,
-			StrategyType: request.StrategyType,
+			UserID:        request.UserID,
+			Symbol:        request.Symbol,
+			Quantity:      request.Quantity,
+			StrategyType:  request.StrategyType,
+			ClosePosition: true,
+			EntrySide:     entrySide,
 			Signal: &strategy.TradingSignal{
 				Type:     strategy.SignalStopLoss,
-				StopLoss: request.Signal.StopLoss,
+				StopLoss: stopLoss,
 			},
 		}
-		te.ExecuteTrade(stopLossReq)
+		result := te.placeProtectiveOrder(stopLossReq, "stop loss")
+		if result.Error != nil {
+			te.moveTo(request.UserID, request.Symbol, StateUnprotected, "stop loss failed")
+			te.handleUnprotected(request, result.Error)
+			return
+		}
+		stopLossOrderID = result.OrderID
 	}
 
 	// 设置止盈订单
-	if !request.Signal.TakeProfit.IsZero() {
+	if !takeProfit.IsZero() {
 		takeProfitReq := &TradeRequest{
-			UserID:       request.UserID,
-			Symbol:       request.Symbol,
-			Quantity:     request.Quantity,
-			StrategyType: request.StrategyType,
+			UserID:        request.UserID,
+			Symbol:        request.Symbol,
+			Quantity:      request.Quantity,
+			StrategyType:  request.StrategyType,
+			ClosePosition: true,
+			EntrySide:     entrySide,
 			Signal: &strategy.TradingSignal{
 				Type:       strategy.SignalTakeProfit,
-				TakeProfit: request.Signal.TakeProfit,
+				TakeProfit: takeProfit,
 			},
 		}
-		te.ExecuteTrade(takeProfitReq)
-	}
-}
-
-// calculateQuantity 计算交易数量
-func (te *TradeExecutor) calculateQuantity(userConfig *database.UserConfig, symbol string, price decimal.Decimal) (decimal.Decimal, error) {
-	// 获取账户信息
-	accountInfo, err := te.binanceClient.GetAccountInfo()
-	if err != nil {
-		return decimal.Zero, fmt.Errorf("failed to get account info: %w", err)
-	}
-
-	// 查找USDT余额
-	var usdtBalance decimal.Decimal
-	for _, asset := range accountInfo.Assets {
-		if asset.Asset == "USDT" {
-			free, err := decimal.NewFromString(asset.AvailableBalance)
-			if err != nil {
-				return decimal.Zero, fmt.Errorf("invalid balance format: %w", err)
-			}
-			usdtBalance = free
-			break
+		if result := te.placeProtectiveOrder(takeProfitReq, "take profit"); result.Error != nil {
+			te.notify("warning", "止盈单设置失败",
+				fmt.Sprintf("%s 止盈单多次重试后仍未设置成功（止损已生效）: %v", request.Symbol, result.Error))
+		} else {
+			takeProfitOrderID = result.OrderID
 		}
 	}
 
-	if usdtBalance.IsZero() {
-		return decimal.Zero, fmt.Errorf("insufficient USDT balance")
-	}
+	te.setBracket(request.UserID, request.Symbol, stopLossOrderID, takeProfitOrderID)
 
-	// 计算风险金额
-	riskAmount := usdtBalance.Mul(decimal.NewFromFloat(userConfig.RiskPercentage / 100))
+	te.moveTo(request.UserID, request.Symbol, StateOpen, "protective orders placed")
+}
 
-	// 限制最大仓位大小
-	maxPositionValue := decimal.NewFromFloat(userConfig.MaxPositionSize)
-	if riskAmount.GreaterThan(maxPositionValue) {
-		riskAmount = maxPositionValue
+// entrySideOf 开仓信号对应的下单方向
+func entrySideOf(signal *strategy.TradingSignal) string {
+	if signal.Type == strategy.SignalSell {
+		return "SELL"
 	}
+	return "BUY"
+}
 
-	// 计算数量
-	quantity := riskAmount.Div(price)
-
-	// 确保数量不为零
-	if quantity.LessThan(decimal.NewFromFloat(0.001)) {
-		return decimal.Zero, fmt.Errorf("calculated quantity too small")
+// protectedEntrySide 保护订单所属持仓的开仓方向：优先使用请求携带的开仓方向，否则按开仓信号方向
+func protectedEntrySide(request *TradeRequest) string {
+	if request.EntrySide != "" {
+		return request.EntrySide
 	}
-
-	return quantity, nil
+	return entrySideOf(request.Signal)
 }
 
-// getOppositeSide 获取相反的交易方向
-func (te *TradeExecutor) getOppositeSide(signal *strategy.TradingSignal) string {
-	if signal.Type == strategy.SignalBuy {
+// oppositeSide 获取相反的交易方向
+func oppositeSide(side string) string {
+	if side == "BUY" {
 		return "SELL"
 	}
 	return "BUY"
 }
 
-// monitorOrders 监控订单状态
-func (te *TradeExecutor) monitorOrders() {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+// closingSide 止损止盈单的下单方向：与所保护持仓的开仓方向相反。
+// 请求未携带开仓方向时（如策略直接发出的止损止盈信号）按当前持仓方向确定
+func (te *TradeExecutor) closingSide(request *TradeRequest) (string, error) {
+	if request.EntrySide != "" {
+		return oppositeSide(request.EntrySide), nil
+	}
 
-	for {
-		select {
-		case <-te.ctx.Done():
-			return
-		case <-ticker.C:
-			te.updateOrderStatus()
-		}
+	te.mu.RLock()
+	position, exists := te.positions[positionKey(request.UserID, request.Symbol)]
+	te.mu.RUnlock()
+	if !exists || !position.IsOpen {
+		return "", fmt.Errorf("no open %s position to protect", request.Symbol)
+	}
+
+	if position.Side == "SHORT" {
+		return "BUY", nil
 	}
+	return "SELL", nil
 }
 
 // monitorPositions 监控持仓状态
@@ -518,22 +1006,32 @@ func (te *TradeExecutor) monitorPositions() {
 		select {
 		case <-te.ctx.Done():
 			return
+		case <-te.positionSync:
+			te.updatePositionStatus()
 		case <-ticker.C:
+			// 用户数据流连接期间持仓变化由推送触发同步
+			if te.userStreamActive() {
+				continue
+			}
 			te.updatePositionStatus()
 		}
 	}
 }
 
-// updateOrderStatus 更新订单状态
-func (te *TradeExecutor) updateOrderStatus() {
-	// TODO: 实现订单状态更新逻辑
-	te.logger.Debug("Updating order status...")
-}
-
 // updatePositionStatus 更新持仓状态
 func (te *TradeExecutor) updatePositionStatus() {
-	// TODO: 实现持仓状态更新逻辑
 	te.logger.Debug("Updating position status...")
+
+	// 非实盘模式下没有交易所持仓可对照
+	if !te.isLive() {
+		return
+	}
+	if err := te.syncPositionStates(); err != nil {
+		te.logger.Warnf("Failed to sync position states: %v", err)
+	}
+	if err := te.ReconcilePositions(); err != nil {
+		te.logger.Warnf("Failed to reconcile positions: %v", err)
+	}
 }
 
 // CancelOrder 取消订单
@@ -542,9 +1040,10 @@ func (te *TradeExecutor) CancelOrder(symbol, orderID string) error {
 	if err != nil {
 		return fmt.Errorf("invalid order ID format: %w", err)
 	}
-	err = te.binanceClient.CancelOrder(symbol, orderIDInt)
-	if err != nil {
-		return fmt.Errorf("failed to cancel order: %w", err)
+	if te.isLive() {
+		if err := te.binanceClient.CancelOrder(te.ctx, symbol, orderIDInt); err != nil {
+			return fmt.Errorf("failed to cancel order: %w", err)
+		}
 	}
 
 	te.logger.Infof("Order cancelled: %s", orderID)
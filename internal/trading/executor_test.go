@@ -0,0 +1,168 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+func TestStopHonoursDeadlineWhileAwaitingEntryFill(t *testing.T) {
+	fx := newFakeExchange(t)
+	cfg := testTradingConfig()
+	cfg.EntryFillTimeoutSeconds = 60
+	te := newTestExecutor(t, fx, cfg)
+	notifier := &recordingNotifier{}
+	te.SetNotifier(notifier)
+	te.isRunning = true
+
+	// 开仓单一直不成交，等待成交只会在上下文取消时提前结束
+	entryID := fx.addOrder(binance.OrderResponse{
+		Symbol: "BTCUSDT", Status: string(binance.OrderStatusNew), Side: "BUY",
+		Type: string(binance.OrderTypeLimit), OrigQty: "0.01", Price: "30000",
+	})
+	if !te.trackEntry() {
+		t.Fatal("entry rejected while running")
+	}
+	go func() {
+		defer te.wg.Done()
+		te.protectFilledEntry(&TradeRequest{
+			UserID:   1,
+			Symbol:   "BTCUSDT",
+			Quantity: decimal.RequireFromString("0.01"),
+			Signal: &strategy.TradingSignal{
+				Type:     strategy.SignalBuy,
+				Price:    decimal.RequireFromString("30000"),
+				StopLoss: decimal.RequireFromString("29500"),
+			},
+		}, entryID)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	te.Stop(ctx)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Stop took %v, want it to return shortly after the 200ms deadline", elapsed)
+	}
+
+	if critical := notifier.titles("critical"); len(critical) != 1 || critical[0] != "开仓未确认成交" {
+		t.Errorf("critical notifications = %v, want the unconfirmed entry alert", critical)
+	}
+	if len(fx.placedOrders()) != 0 {
+		t.Errorf("protective orders placed after entry was aborted: %v", fx.placedOrders())
+	}
+}
+
+func TestStopRejectsNewWork(t *testing.T) {
+	fx := newFakeExchange(t)
+	te := newTestExecutor(t, fx, testTradingConfig())
+	te.isRunning = true
+
+	release := make(chan struct{})
+	if !te.goTracked(func() { <-release }) {
+		t.Fatal("background task rejected while running")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		te.Stop(context.Background())
+		close(stopped)
+	}()
+
+	// Stop 等待进行中的任务期间，新的开仓和后台任务都被拒绝
+	deadline := time.Now().Add(time.Second)
+	for {
+		te.mu.RLock()
+		stopping := te.stopping
+		te.mu.RUnlock()
+		if stopping {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Stop did not begin")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if te.goTracked(func() {}) {
+		t.Error("background task started while stopping")
+	}
+	if te.trackEntry() {
+		t.Error("entry accepted while stopping")
+	}
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before in-flight task finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after in-flight task finished")
+	}
+}
+
+func TestExecuteTradeTypedErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, te *TradeExecutor)
+		want  error
+	}{
+		{"analysis mode", func(t *testing.T, te *TradeExecutor) {
+			addTestUser(t, te, 1)
+			te.SetMode(config.ModeAnalysis)
+		}, ErrExecutionDisabled},
+		{"missing user config", func(t *testing.T, te *TradeExecutor) {}, ErrUserConfigNotFound},
+		{"inactive user", func(t *testing.T, te *TradeExecutor) {
+			userConfig := addTestUser(t, te, 1)
+			userConfig.IsActive = false
+			if err := te.userConfigRepo.Update(userConfig); err != nil {
+				t.Fatalf("update user config: %v", err)
+			}
+		}, ErrUserInactive},
+		{"user config unreadable", func(t *testing.T, te *TradeExecutor) {
+			te.db.Close()
+		}, ErrUserConfigUnavailable},
+		{"executor stopped", func(t *testing.T, te *TradeExecutor) {
+			addTestUser(t, te, 1)
+			te.isRunning = false
+		}, ErrExecutorStopped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := newFakeExchange(t)
+			te := newTestExecutor(t, fx, testTradingConfig())
+			te.isRunning = true
+			tt.setup(t, te)
+
+			result := te.ExecuteTrade(&TradeRequest{
+				UserID: 1,
+				Symbol: "BTCUSDT",
+				Signal: &strategy.TradingSignal{
+					Type:     strategy.SignalBuy,
+					Symbol:   "BTCUSDT",
+					Price:    decimal.RequireFromString("30000"),
+					StopLoss: decimal.RequireFromString("29500"),
+				},
+			})
+			if !errors.Is(result.Error, tt.want) {
+				t.Fatalf("ExecuteTrade error = %v, want %v", result.Error, tt.want)
+			}
+			if len(fx.placedOrders()) != 0 {
+				t.Errorf("orders placed despite %v: %v", tt.want, fx.placedOrders())
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package trading
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// stopLossOrder 构建止损单（STOP_MARKET）
+func stopLossOrder(symbol, side string, quantity, stopPrice decimal.Decimal, closePosition bool) *binance.OrderRequest {
+	return exitOrder(symbol, side, binance.OrderTypeStopMarket, quantity, stopPrice, closePosition)
+}
+
+// takeProfitOrder 构建止盈单（TAKE_PROFIT_MARKET）
+func takeProfitOrder(symbol, side string, quantity, triggerPrice decimal.Decimal, closePosition bool) *binance.OrderRequest {
+	return exitOrder(symbol, side, binance.OrderTypeTakeProfitMarket, quantity, triggerPrice, closePosition)
+}
+
+// exitOrder 构建触发价平仓单，保证只会减少持仓而不会反向开仓：平掉整个持仓时使用 closePosition，
+// 不指定数量，避免数量取整留下残余；部分平仓按数量下只减仓单。交易所不接受 closePosition 与 reduceOnly 同时指定
+func exitOrder(symbol, side string, orderType binance.OrderType, quantity, triggerPrice decimal.Decimal, closePosition bool) *binance.OrderRequest {
+	order := &binance.OrderRequest{
+		Symbol:      symbol,
+		Side:        side,
+		Type:        string(orderType),
+		StopPrice:   triggerPrice.String(),
+		TimeInForce: "GTC",
+	}
+	if closePosition {
+		order.ClosePosition = true
+	} else {
+		order.Quantity = quantity.String()
+		order.ReduceOnly = true
+	}
+	return order
+}
@@ -0,0 +1,145 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+func TestExitOrderFlags(t *testing.T) {
+	quantity := decimal.RequireFromString("0.5")
+	trigger := decimal.RequireFromString("25000")
+
+	tests := []struct {
+		name          string
+		build         func(symbol, side string, quantity, triggerPrice decimal.Decimal, closePosition bool) *binance.OrderRequest
+		closePosition bool
+		wantType      string
+	}{
+		{"stop loss full exit", stopLossOrder, true, "STOP_MARKET"},
+		{"stop loss partial exit", stopLossOrder, false, "STOP_MARKET"},
+		{"take profit full exit", takeProfitOrder, true, "TAKE_PROFIT_MARKET"},
+		{"take profit partial exit", takeProfitOrder, false, "TAKE_PROFIT_MARKET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := tt.build("BTCUSDT", "SELL", quantity, trigger, tt.closePosition)
+
+			if order.Type != tt.wantType || order.Side != "SELL" || order.StopPrice != "25000" {
+				t.Errorf("order = %s %s stop %s, want %s SELL stop 25000", order.Type, order.Side, order.StopPrice, tt.wantType)
+			}
+			// 交易所不接受 closePosition 与 reduceOnly 同时指定
+			if order.ClosePosition && order.ReduceOnly {
+				t.Errorf("order sets both closePosition and reduceOnly")
+			}
+			if tt.closePosition {
+				if !order.ClosePosition || order.Quantity != "" {
+					t.Errorf("full exit: closePosition = %v, quantity = %q; want closePosition without quantity",
+						order.ClosePosition, order.Quantity)
+				}
+				return
+			}
+			if !order.ReduceOnly || order.Quantity != "0.5" {
+				t.Errorf("partial exit: reduceOnly = %v, quantity = %q; want reduceOnly with quantity 0.5",
+					order.ReduceOnly, order.Quantity)
+			}
+		})
+	}
+}
+
+func TestPlaceProtectiveOrdersSide(t *testing.T) {
+	tests := []struct {
+		name       string
+		signal     strategy.SignalType
+		price      string
+		stopLoss   string
+		takeProfit string
+		wantSide   string
+	}{
+		{"long", strategy.SignalBuy, "30000", "29500", "31000", "SELL"},
+		{"short", strategy.SignalSell, "30000", "30500", "29000", "BUY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := newFakeExchange(t)
+			te := newTestExecutor(t, fx, testTradingConfig())
+			addTestUser(t, te, 1)
+
+			te.placeProtectiveOrders(&TradeRequest{
+				UserID:       1,
+				Symbol:       "BTCUSDT",
+				Quantity:     decimal.RequireFromString("0.01"),
+				StrategyType: "vegas",
+				Signal: &strategy.TradingSignal{
+					Type:       tt.signal,
+					Symbol:     "BTCUSDT",
+					Price:      decimal.RequireFromString(tt.price),
+					StopLoss:   decimal.RequireFromString(tt.stopLoss),
+					TakeProfit: decimal.RequireFromString(tt.takeProfit),
+				},
+			})
+
+			placed := fx.placedOrders()
+			if len(placed) != 2 {
+				t.Fatalf("placed %d orders, want stop loss and take profit", len(placed))
+			}
+
+			want := []struct{ orderType, stopPrice string }{
+				{"STOP_MARKET", tt.stopLoss},
+				{"TAKE_PROFIT_MARKET", tt.takeProfit},
+			}
+			for i, order := range placed {
+				if got := order.Get("type"); got != want[i].orderType {
+					t.Errorf("order %d type = %s, want %s", i, got, want[i].orderType)
+				}
+				if got := order.Get("side"); got != tt.wantSide {
+					t.Errorf("%s side = %s, want %s", want[i].orderType, got, tt.wantSide)
+				}
+				if got := order.Get("stopPrice"); got != want[i].stopPrice {
+					t.Errorf("%s stopPrice = %s, want %s", want[i].orderType, got, want[i].stopPrice)
+				}
+				if order.Get("closePosition") != "true" || order.Get("reduceOnly") != "" || order.Get("quantity") != "" {
+					t.Errorf("%s closePosition = %q, reduceOnly = %q, quantity = %q; want closePosition only",
+						want[i].orderType, order.Get("closePosition"), order.Get("reduceOnly"), order.Get("quantity"))
+				}
+			}
+
+			if state := te.PositionState(1, "BTCUSDT"); state != StateOpen {
+				t.Errorf("state = %s, want %s", state, StateOpen)
+			}
+		})
+	}
+}
+
+func TestClosingSideFromPosition(t *testing.T) {
+	fx := newFakeExchange(t)
+	te := newTestExecutor(t, fx, testTradingConfig())
+
+	te.positions[positionKey(1, "BTCUSDT")] = &Position{UserID: 1, Symbol: "BTCUSDT", Side: "LONG", IsOpen: true}
+	te.positions[positionKey(1, "ETHUSDT")] = &Position{UserID: 1, Symbol: "ETHUSDT", Side: "SHORT", IsOpen: true}
+
+	tests := []struct {
+		request *TradeRequest
+		want    string
+		wantErr bool
+	}{
+		{&TradeRequest{UserID: 1, Symbol: "BTCUSDT", EntrySide: "BUY"}, "SELL", false},
+		{&TradeRequest{UserID: 1, Symbol: "BTCUSDT", EntrySide: "SELL"}, "BUY", false},
+		{&TradeRequest{UserID: 1, Symbol: "BTCUSDT"}, "SELL", false},
+		{&TradeRequest{UserID: 1, Symbol: "ETHUSDT"}, "BUY", false},
+		{&TradeRequest{UserID: 2, Symbol: "BTCUSDT"}, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := te.closingSide(tt.request)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("closingSide(user %d %s entry %q) = %q, %v; want %q (error %v)",
+				tt.request.UserID, tt.request.Symbol, tt.request.EntrySide, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
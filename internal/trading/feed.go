@@ -0,0 +1,26 @@
+package trading
+
+// SetFeedUnstable 设置行情连接抖动状态，抖动期间暂停开仓，平仓和止损止盈不受影响
+func (te *TradeExecutor) SetFeedUnstable(unstable bool) {
+	te.mu.Lock()
+	changed := te.feedUnstable != unstable
+	te.feedUnstable = unstable
+	te.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if unstable {
+		te.logger.Warn("Market data feed flapping, new entries paused")
+		return
+	}
+	te.logger.Info("Market data feed stable, new entries resumed")
+}
+
+// FeedUnstable 行情连接是否处于抖动状态
+func (te *TradeExecutor) FeedUnstable() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.feedUnstable
+}
@@ -0,0 +1,151 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// feeRefreshInterval 手续费等级与费率的刷新周期
+const feeRefreshInterval = 6 * time.Hour
+
+// FeeInfo 交易对当前手续费信息
+type FeeInfo struct {
+	Symbol    string
+	FeeTier   int
+	MakerRate decimal.Decimal
+	TakerRate decimal.Decimal
+	UpdatedAt time.Time
+}
+
+// GetFeeInfo 获取交易对手续费信息，缓存过期或缺失时从交易所刷新
+func (te *TradeExecutor) GetFeeInfo(symbol string) (*FeeInfo, error) {
+	te.mu.RLock()
+	info, exists := te.feeInfos[symbol]
+	te.mu.RUnlock()
+
+	if exists && time.Since(info.UpdatedAt) < feeRefreshInterval {
+		return info, nil
+	}
+
+	tier, err := te.fetchFeeTier()
+	if err != nil {
+		if exists {
+			te.logger.Warnf("Failed to refresh fee tier, using cached fees for %s: %v", symbol, err)
+			return info, nil
+		}
+		return nil, err
+	}
+
+	refreshed, err := te.fetchFeeInfo(symbol, tier)
+	if err != nil {
+		if exists {
+			te.logger.Warnf("Failed to refresh commission rate, using cached fees for %s: %v", symbol, err)
+			return info, nil
+		}
+		return nil, err
+	}
+
+	return refreshed, nil
+}
+
+// takerFeeRate 获取用于成本估算的吃单费率，无法获取实际费率时回退到配置的估算值
+func (te *TradeExecutor) takerFeeRate(symbol string) decimal.Decimal {
+	info, err := te.GetFeeInfo(symbol)
+	if err != nil {
+		te.logger.Debugf("Using estimated fee rate for %s: %v", symbol, err)
+		return decimal.NewFromFloat(te.tradingConfig.EstimatedFeeRate)
+	}
+	return info.TakerRate
+}
+
+// monitorFees 定期刷新已缓存交易对的手续费等级与费率
+func (te *TradeExecutor) monitorFees() {
+	ticker := time.NewTicker(feeRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-te.ctx.Done():
+			return
+		case <-ticker.C:
+			te.refreshFees()
+		}
+	}
+}
+
+// refreshFees 刷新所有已缓存交易对的手续费信息
+func (te *TradeExecutor) refreshFees() {
+	te.mu.RLock()
+	symbols := make([]string, 0, len(te.feeInfos))
+	for symbol := range te.feeInfos {
+		symbols = append(symbols, symbol)
+	}
+	previousTier := te.feeTier
+	te.mu.RUnlock()
+
+	if len(symbols) == 0 {
+		return
+	}
+
+	tier, err := te.fetchFeeTier()
+	if err != nil {
+		te.logger.Warnf("Failed to refresh fee tier: %v", err)
+		return
+	}
+	if tier != previousTier {
+		te.logger.Infof("Fee tier changed: %d -> %d", previousTier, tier)
+	}
+
+	for _, symbol := range symbols {
+		if _, err := te.fetchFeeInfo(symbol, tier); err != nil {
+			te.logger.Warnf("Failed to refresh commission rate for %s: %v", symbol, err)
+		}
+	}
+}
+
+// fetchFeeTier 从账户信息获取当前手续费等级
+func (te *TradeExecutor) fetchFeeTier() (int, error) {
+	account, err := te.binanceClient.GetAccountInfo(te.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get account info: %w", err)
+	}
+
+	te.mu.Lock()
+	te.feeTier = account.FeeTier
+	te.mu.Unlock()
+
+	return account.FeeTier, nil
+}
+
+// fetchFeeInfo 从交易所获取交易对费率并写入缓存
+func (te *TradeExecutor) fetchFeeInfo(symbol string, tier int) (*FeeInfo, error) {
+	rate, err := te.binanceClient.GetCommissionRate(te.ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commission rate: %w", err)
+	}
+
+	maker, err := decimal.NewFromString(rate.MakerCommissionRate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maker commission rate: %w", err)
+	}
+	taker, err := decimal.NewFromString(rate.TakerCommissionRate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid taker commission rate: %w", err)
+	}
+
+	info := &FeeInfo{
+		Symbol:    symbol,
+		FeeTier:   tier,
+		MakerRate: maker,
+		TakerRate: taker,
+		UpdatedAt: time.Now(),
+	}
+
+	te.mu.Lock()
+	te.feeInfos[symbol] = info
+	te.mu.Unlock()
+
+	return info, nil
+}
@@ -0,0 +1,98 @@
+package trading
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// 成交上报来源
+const (
+	FillSourcePolling    = "polling"     // 订单轮询
+	FillSourceUserStream = "user_stream" // 用户数据流
+)
+
+// FillUpdate 订单成交或状态变化上报
+type FillUpdate struct {
+	OrderID  string
+	Symbol   string
+	Status   binance.OrderStatus
+	Filled   decimal.Decimal // 累计成交数量
+	AvgPrice decimal.Decimal
+	Source   string
+	At       time.Time
+}
+
+// appliedFill 订单最近一次已处理的成交
+type appliedFill struct {
+	filled   decimal.Decimal
+	terminal bool
+	at       time.Time
+}
+
+// ApplyFill 处理订单成交上报，按订单ID和累计成交数量去重：同一成交无论由哪个来源先上报都只处理一次，
+// 只有累计成交数量增加或订单首次终结时才更新交易记录、活跃订单和通知。返回是否实际处理
+func (te *TradeExecutor) ApplyFill(update FillUpdate) bool {
+	terminal := isTerminalStatus(update.Status)
+
+	te.mu.Lock()
+	te.pruneFillsLocked(update.At)
+	last, seen := te.fills[update.OrderID]
+	if seen && !update.Filled.GreaterThan(last.filled) && (last.terminal || !terminal) {
+		te.mu.Unlock()
+		te.logger.Debugf("Ignoring duplicate fill for order %s from %s (filled %s)",
+			update.OrderID, update.Source, update.Filled.String())
+		return false
+	}
+	te.fills[update.OrderID] = &appliedFill{filled: update.Filled, terminal: terminal, at: update.At}
+
+	order, tracked := te.activeOrders[update.OrderID]
+	var snapshot ActiveOrder
+	if tracked {
+		if string(update.Status) != order.Status {
+			te.logger.Debugf("Order %s for %s status %s -> %s (%s)",
+				update.OrderID, update.Symbol, order.Status, update.Status, update.Source)
+		}
+		order.Status = string(update.Status)
+		order.UpdatedAt = update.At
+		if terminal {
+			delete(te.activeOrders, update.OrderID)
+		}
+		snapshot = *order
+	}
+	te.mu.Unlock()
+
+	if _, err := te.tradeRepo.UpdateFill(update.OrderID, string(update.Status),
+		update.Filled.InexactFloat64(), update.AvgPrice.InexactFloat64()); err != nil {
+		te.logger.Errorf("Failed to update trade record for order %s: %v", update.OrderID, err)
+	}
+
+	if terminal {
+		te.logger.Infof("Order %s for %s is %s", update.OrderID, update.Symbol, update.Status)
+	}
+
+	if tracked && update.Status == binance.OrderStatusFilled {
+		te.notifyFilled(&snapshot, update.Filled, update.AvgPrice, update.At)
+		// 止损或止盈成交后撤销另一方挂单
+		// 停止过程中不再启动后台任务，直接撤销
+		if isBracketOrder(&snapshot) {
+			if !te.goTracked(func() { te.cancelBracketSibling(&snapshot) }) {
+				te.cancelBracketSibling(&snapshot)
+			}
+		}
+	}
+	return true
+}
+
+// pruneFillsLocked 清理超过保留时长的已终结（或已不再跟踪）订单的成交记录，调用方需持有 te.mu
+func (te *TradeExecutor) pruneFillsLocked(now time.Time) {
+	retention := time.Duration(te.tradingConfig.FillDedupMinutes) * time.Minute
+	for id, fill := range te.fills {
+		_, tracked := te.activeOrders[id]
+		if (fill.terminal || !tracked) && now.Sub(fill.at) > retention {
+			delete(te.fills, id)
+		}
+	}
+}
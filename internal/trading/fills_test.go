@@ -0,0 +1,133 @@
+package trading
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+func TestFillFromBothSourcesAppliedOnce(t *testing.T) {
+	for _, streamFirst := range []bool{true, false} {
+		name := "polling first"
+		if streamFirst {
+			name = "user stream first"
+		}
+		streamFirst := streamFirst
+		t.Run(name, func(t *testing.T) {
+			fx := newFakeExchange(t)
+			te := newTestExecutor(t, fx, testTradingConfig())
+			notifier := &recordingNotifier{}
+			te.SetNotifier(notifier)
+			addTestUser(t, te, 1)
+
+			te.placeProtectiveOrders(&TradeRequest{
+				UserID:       1,
+				Symbol:       "BTCUSDT",
+				Quantity:     decimal.RequireFromString("0.01"),
+				StrategyType: "vegas",
+				Signal: &strategy.TradingSignal{
+					Type:       strategy.SignalBuy,
+					Symbol:     "BTCUSDT",
+					Price:      decimal.RequireFromString("30000"),
+					StopLoss:   decimal.RequireFromString("29500"),
+					TakeProfit: decimal.RequireFromString("31000"),
+				},
+			})
+			lifecycle := te.PositionLifecycles()[positionKey(1, "BTCUSDT")]
+			stopID, takeProfitID := lifecycle.StopLossOrderID, lifecycle.TakeProfitOrderID
+
+			fx.setOrder(stopID, func(order *binance.OrderResponse) {
+				order.Status = string(binance.OrderStatusFilled)
+				order.ExecutedQty = "0.01"
+				order.AvgPrice = "29500"
+			})
+
+			event := &binance.OrderTradeUpdateEvent{TransactionTime: time.Now().UnixMilli()}
+			event.Order.Symbol = "BTCUSDT"
+			event.Order.OrderID, _ = strconv.ParseInt(stopID, 10, 64)
+			event.Order.Status = string(binance.OrderStatusFilled)
+			event.Order.FilledQty = "0.01"
+			event.Order.AvgPrice = "29500"
+
+			deliver := []func(){
+				func() {
+					if err := te.HandleOrderUpdate(event); err != nil {
+						t.Fatalf("HandleOrderUpdate: %v", err)
+					}
+				},
+				func() {
+					if _, err := te.syncOrder("BTCUSDT", stopID); err != nil {
+						t.Fatalf("syncOrder: %v", err)
+					}
+				},
+			}
+			if !streamFirst {
+				deliver[0], deliver[1] = deliver[1], deliver[0]
+			}
+			for _, d := range deliver {
+				d()
+				te.wg.Wait()
+			}
+
+			// 同一成交只通知一次，只撤销一次另一方挂单
+			notifier.mu.Lock()
+			trades := len(notifier.trades)
+			var quantity, price decimal.Decimal
+			if trades > 0 {
+				quantity, price = notifier.trades[0].Quantity, notifier.trades[0].Price
+			}
+			notifier.mu.Unlock()
+			if trades != 1 || !quantity.Equal(decimal.RequireFromString("0.01")) || !price.Equal(decimal.RequireFromString("29500")) {
+				t.Errorf("fill notifications = %d (quantity %s at %s), want one for 0.01 at 29500", trades, quantity, price)
+			}
+			cancelled := fx.cancelledOrders()
+			if len(cancelled) != 1 || strconv.FormatInt(cancelled[0], 10) != takeProfitID {
+				t.Errorf("cancelled orders = %v, want only take profit %s once", cancelled, takeProfitID)
+			}
+
+			// 之后任一来源重复上报都被忽略
+			if te.ApplyFill(FillUpdate{
+				OrderID: stopID, Symbol: "BTCUSDT", Status: binance.OrderStatusFilled,
+				Filled: decimal.RequireFromString("0.01"), AvgPrice: decimal.RequireFromString("29500"),
+				Source: FillSourceUserStream, At: time.Now(),
+			}) {
+				t.Error("repeated fill applied again")
+			}
+		})
+	}
+}
+
+func TestFillQuantityIncreaseAppliedAcrossSources(t *testing.T) {
+	fx := newFakeExchange(t)
+	te := newTestExecutor(t, fx, testTradingConfig())
+
+	update := FillUpdate{
+		OrderID: "42", Symbol: "BTCUSDT", Status: binance.OrderStatusPartiallyFilled,
+		Filled: decimal.RequireFromString("0.004"), AvgPrice: decimal.RequireFromString("30000"),
+		Source: FillSourceUserStream, At: time.Now(),
+	}
+	if !te.ApplyFill(update) {
+		t.Fatal("first partial fill not applied")
+	}
+
+	update.Source = FillSourcePolling
+	if te.ApplyFill(update) {
+		t.Error("same partial fill from polling applied again")
+	}
+
+	update.Status = binance.OrderStatusFilled
+	update.Filled = decimal.RequireFromString("0.01")
+	if !te.ApplyFill(update) {
+		t.Error("larger cumulative fill from polling not applied")
+	}
+
+	update.Source = FillSourceUserStream
+	if te.ApplyFill(update) {
+		t.Error("final fill from user stream applied again")
+	}
+}
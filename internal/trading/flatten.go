@@ -0,0 +1,164 @@
+package trading
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// FlattenResult 单个交易对的平仓结果
+type FlattenResult struct {
+	Symbol      string
+	Side        string
+	Quantity    decimal.Decimal
+	EntryPrice  decimal.Decimal
+	ExitPrice   decimal.Decimal
+	RealizedPnl decimal.Decimal
+	OrderID     int64
+	Error       error
+}
+
+// FlattenAll 撤销全部挂单并以市价平掉所有持仓
+func (te *TradeExecutor) FlattenAll(userID int64, reason string) ([]*FlattenResult, error) {
+	positions, err := te.binanceClient.GetPositions(te.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	var results []*FlattenResult
+	for i := range positions {
+		amount, err := decimal.NewFromString(positions[i].PositionAmt)
+		if err != nil || amount.IsZero() {
+			continue
+		}
+		results = append(results, te.flattenPosition(userID, &positions[i], reason))
+	}
+
+	return results, nil
+}
+
+// FlattenSymbol 撤销交易对的全部挂单并以市价平掉其持仓，无持仓时返回 nil
+func (te *TradeExecutor) FlattenSymbol(userID int64, symbol, reason string) (*FlattenResult, error) {
+	positions, err := te.binanceClient.GetPositions(te.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	for i := range positions {
+		if positions[i].Symbol != symbol {
+			continue
+		}
+		amount, err := decimal.NewFromString(positions[i].PositionAmt)
+		if err != nil || amount.IsZero() {
+			continue
+		}
+		return te.flattenPosition(userID, &positions[i], reason), nil
+	}
+
+	// 没有持仓也要清理残留挂单
+	if err := te.cancelSymbolOrders(symbol); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// flattenPosition 撤销挂单并以只减仓市价单平掉单个持仓
+func (te *TradeExecutor) flattenPosition(userID int64, position *binance.Position, reason string) *FlattenResult {
+	amount, _ := decimal.NewFromString(position.PositionAmt)
+	entryPrice, _ := decimal.NewFromString(position.EntryPrice)
+
+	result := &FlattenResult{
+		Symbol:     position.Symbol,
+		Side:       "SELL",
+		Quantity:   amount.Abs(),
+		EntryPrice: entryPrice,
+	}
+	if amount.IsNegative() {
+		result.Side = "BUY"
+	}
+
+	owner := te.lifecycleOwner(userID, position.Symbol)
+	te.moveTo(owner, position.Symbol, StateExitPending, reason)
+	defer func() {
+		if result.Error != nil {
+			te.revertTransition(owner, position.Symbol, StateExitPending, "exit failed")
+			return
+		}
+		te.moveTo(owner, position.Symbol, StateFlat, reason)
+	}()
+
+	if err := te.cancelSymbolOrders(position.Symbol); err != nil {
+		result.Error = err
+		return result
+	}
+
+	orderReq := &binance.OrderRequest{
+		Symbol:     position.Symbol,
+		Side:       result.Side,
+		Type:       "MARKET",
+		Quantity:   result.Quantity.String(),
+		ReduceOnly: true,
+	}
+
+	orderResp, err := te.placeOrder(orderReq)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to place close order for %s: %w", position.Symbol, err)
+		return result
+	}
+	result.OrderID = orderResp.OrderID
+
+	// 已成交时按成交均价计算已实现盈亏，否则以标记价格估算
+	exitPrice, err := decimal.NewFromString(orderResp.AvgPrice)
+	if err != nil || exitPrice.IsZero() {
+		exitPrice, _ = decimal.NewFromString(position.MarkPrice)
+	}
+	result.ExitPrice = exitPrice
+	// 平仓手续费按市价单吃单费率计入已实现盈亏
+	commission := exitPrice.Mul(result.Quantity).Mul(te.takerFeeRate(position.Symbol))
+	result.RealizedPnl = exitPrice.Sub(entryPrice).Mul(amount).Sub(commission)
+
+	te.saveTrade(&database.Trade{
+		UserID:        userID,
+		Symbol:        position.Symbol,
+		OrderID:       fmt.Sprintf("%d", orderResp.OrderID),
+		ClientOrderID: orderResp.ClientOrderID,
+		Side:          result.Side,
+		Type:          "MARKET",
+		Quantity:      result.Quantity.InexactFloat64(),
+		Price:         exitPrice.InexactFloat64(),
+		Status:        orderResp.Status,
+		AvgPrice:      exitPrice.InexactFloat64(),
+		Commission:    commission.InexactFloat64(),
+		RealizedPnl:   result.RealizedPnl.InexactFloat64(),
+		StrategyType:  "manual",
+		SignalType:    "flatten",
+	})
+
+	te.logger.Warnf("Flattened %s position %s (%s): order %d, pnl %s",
+		position.Symbol, amount.String(), reason, orderResp.OrderID, result.RealizedPnl.StringFixed(2))
+
+	return result
+}
+
+// cancelSymbolOrders 撤销交易对的全部挂单并清理本地活跃订单
+func (te *TradeExecutor) cancelSymbolOrders(symbol string) error {
+	// 非实盘模式下挂单只存在于本地
+	if te.isLive() {
+		if err := te.binanceClient.CancelAllOrders(te.ctx, symbol); err != nil {
+			return fmt.Errorf("failed to cancel open orders for %s: %w", symbol, err)
+		}
+	}
+
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	for id, order := range te.activeOrders {
+		if order.Symbol == symbol {
+			delete(te.activeOrders, id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// 资金费结算前平仓
+const (
+	fundingCheckInterval = time.Minute
+	fundingReopenDelay   = time.Minute // 结算后等待多久再重新开仓
+)
+
+// ReentryCheckFunc 判断交易对在指定方向上的开仓条件是否仍然成立
+type ReentryCheckFunc func(symbol string, isLong bool) bool
+
+// fundingExit 因资金费率不利而在结算前平掉的持仓，结算后用于重新开仓
+type fundingExit struct {
+	UserID       int64
+	Symbol       string
+	IsLong       bool
+	Quantity     decimal.Decimal
+	StopLoss     decimal.Decimal
+	TakeProfit   decimal.Decimal
+	StrategyType string
+	Rate         decimal.Decimal
+	FundingTime  time.Time
+}
+
+// SetReentryCheck 设置资金费结算后重新开仓前的信号确认，未设置时不重新开仓
+func (te *TradeExecutor) SetReentryCheck(check ReentryCheckFunc) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.reentryCheck = check
+}
+
+// monitorFunding 在资金费率不利时于结算前平仓，并在结算后按需重新开仓
+func (te *TradeExecutor) monitorFunding() {
+	if !te.tradingConfig.FundingCloseEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(fundingCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-te.ctx.Done():
+			return
+		case now := <-ticker.C:
+			// 非实盘模式下没有真实持仓，也不产生资金费
+			if !te.isLive() {
+				continue
+			}
+			te.reopenAfterFunding(now)
+			if err := te.closeBeforeFunding(now); err != nil {
+				te.logger.Warnf("Funding check failed: %v", err)
+			}
+		}
+	}
+}
+
+// isAdverseFunding 持仓方需支付的资金费率是否超过阈值（正费率多头支付，负费率空头支付）
+func isAdverseFunding(isLong bool, rate, threshold decimal.Decimal) bool {
+	if isLong {
+		return rate.GreaterThan(threshold)
+	}
+	return rate.Neg().GreaterThan(threshold)
+}
+
+// closeBeforeFunding 平掉临近结算且资金费率不利的持仓
+func (te *TradeExecutor) closeBeforeFunding(now time.Time) error {
+	positions, err := te.binanceClient.GetPositions(te.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	window := time.Duration(te.tradingConfig.FundingCloseMinutes) * time.Minute
+	threshold := decimal.NewFromFloat(te.tradingConfig.FundingRateThreshold)
+
+	for i := range positions {
+		position := &positions[i]
+		amount, err := decimal.NewFromString(position.PositionAmt)
+		if err != nil || amount.IsZero() {
+			continue
+		}
+
+		index, err := te.binanceClient.GetPremiumIndex(te.ctx, position.Symbol)
+		if err != nil {
+			te.logger.Warnf("Failed to get funding rate for %s: %v", position.Symbol, err)
+			continue
+		}
+
+		fundingTime := time.UnixMilli(index.NextFundingTime)
+		until := fundingTime.Sub(now)
+		isLong := amount.IsPositive()
+		if until <= 0 || until > window || !isAdverseFunding(isLong, index.LastFundingRate, threshold) {
+			continue
+		}
+
+		exit := te.fundingExitFor(position.Symbol, isLong, amount.Abs(), index.LastFundingRate, fundingTime)
+		result := te.flattenPosition(exit.UserID, position, "adverse funding")
+
+		rate := index.LastFundingRate.Mul(decimal.NewFromInt(100)).StringFixed(4)
+		if result.Error != nil {
+			te.logger.Errorf("Failed to close %s before funding: %v", position.Symbol, result.Error)
+			te.notify("critical", "资金费结算前平仓失败",
+				fmt.Sprintf("%s 资金费率 %s%%，%v 后结算\n错误: %v", position.Symbol, rate, until.Round(time.Second), result.Error))
+			continue
+		}
+
+		reopen := "结算后不重新开仓"
+		if te.tradingConfig.FundingReopen {
+			te.mu.Lock()
+			te.fundingExits[position.Symbol] = exit
+			te.mu.Unlock()
+			reopen = "结算后若信号仍然成立将按原数量和止损止盈重新开仓"
+		}
+
+		te.notify("warning", "💸 资金费结算前平仓",
+			fmt.Sprintf("%s %s %s 已平仓\n资金费率: %s%%（%v 后结算）\n已实现盈亏: %s\n%s",
+				position.Symbol, sideName(isLong), exit.Quantity.String(), rate, until.Round(time.Second),
+				result.RealizedPnl.StringFixed(2), reopen))
+	}
+
+	return nil
+}
+
+// fundingExitFor 根据本地跟踪的止损止盈挂单记录平仓前的持仓信息
+func (te *TradeExecutor) fundingExitFor(symbol string, isLong bool, quantity, rate decimal.Decimal, fundingTime time.Time) *fundingExit {
+	exit := &fundingExit{
+		Symbol:      symbol,
+		IsLong:      isLong,
+		Quantity:    quantity,
+		Rate:        rate,
+		FundingTime: fundingTime,
+	}
+
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+
+	for _, order := range te.activeOrders {
+		if order.Symbol != symbol {
+			continue
+		}
+		switch order.SignalType {
+		case "stop_loss":
+			exit.StopLoss = order.StopPrice
+		case "take_profit":
+			exit.TakeProfit = order.StopPrice
+		default:
+			continue
+		}
+		exit.UserID = order.UserID
+		exit.StrategyType = order.StrategyType
+	}
+
+	// 没有保护订单时按最近一次开仓的用户归属
+	if exit.UserID == 0 {
+		var latest time.Time
+		for key, at := range te.lastEntryTimes {
+			var userID int64
+			var entrySymbol string
+			if _, err := fmt.Sscanf(key, "%d_%s", &userID, &entrySymbol); err != nil || entrySymbol != symbol {
+				continue
+			}
+			if at.After(latest) {
+				latest, exit.UserID = at, userID
+			}
+		}
+	}
+
+	return exit
+}
+
+// reopenAfterFunding 结算完成后，对信号仍然成立的已平持仓按原数量和止损止盈重新开仓
+func (te *TradeExecutor) reopenAfterFunding(now time.Time) {
+	te.mu.Lock()
+	check := te.reentryCheck
+	var due []*fundingExit
+	for symbol, exit := range te.fundingExits {
+		if now.Sub(exit.FundingTime) >= fundingReopenDelay {
+			due = append(due, exit)
+			delete(te.fundingExits, symbol)
+		}
+	}
+	te.mu.Unlock()
+
+	for _, exit := range due {
+		if reason := te.reentryBlocked(exit, check); reason != "" {
+			te.logger.Infof("Not reopening %s after funding: %s", exit.Symbol, reason)
+			te.notify("info", "资金费结算后未重新开仓", fmt.Sprintf("%s %s: %s", exit.Symbol, sideName(exit.IsLong), reason))
+			continue
+		}
+
+		signalType := strategy.SignalBuy
+		if !exit.IsLong {
+			signalType = strategy.SignalSell
+		}
+		price, err := te.latestPrice(exit.Symbol)
+		if err != nil {
+			te.notify("warning", "资金费结算后重新开仓失败", fmt.Sprintf("%s 获取价格失败: %v", exit.Symbol, err))
+			continue
+		}
+
+		// 止损已被越过时原有的交易计划失效
+		if exit.IsLong && price.LessThanOrEqual(exit.StopLoss) || !exit.IsLong && price.GreaterThanOrEqual(exit.StopLoss) {
+			te.notify("info", "资金费结算后未重新开仓",
+				fmt.Sprintf("%s 现价 %s 已越过原止损 %s", exit.Symbol, price.String(), exit.StopLoss.String()))
+			continue
+		}
+
+		result := te.ExecuteTrade(&TradeRequest{
+			UserID:       exit.UserID,
+			Symbol:       exit.Symbol,
+			Quantity:     exit.Quantity,
+			StrategyType: exit.StrategyType,
+			Signal: &strategy.TradingSignal{
+				Symbol:     exit.Symbol,
+				Type:       signalType,
+				Price:      price,
+				StopLoss:   exit.StopLoss,
+				TakeProfit: exit.TakeProfit,
+				Confidence: 1,
+				Reason:     "资金费结算后重新开仓",
+				Timestamp:  now,
+				Timeframe:  "15M",
+			},
+		})
+		if result.Error != nil {
+			te.logger.Warnf("Failed to reopen %s after funding: %v", exit.Symbol, result.Error)
+			te.notify("warning", "资金费结算后重新开仓失败", fmt.Sprintf("%s %s: %v", exit.Symbol, sideName(exit.IsLong), result.Error))
+			continue
+		}
+
+		te.notify("info", "🔁 资金费结算后已重新开仓",
+			fmt.Sprintf("%s %s %s @ %s\n止损: %s\n止盈: %s", exit.Symbol, sideName(exit.IsLong),
+				exit.Quantity.String(), price.String(), exit.StopLoss.String(), exit.TakeProfit.String()))
+	}
+}
+
+// reentryBlocked 返回不能重新开仓的原因，可以开仓时返回空字符串
+func (te *TradeExecutor) reentryBlocked(exit *fundingExit, check ReentryCheckFunc) string {
+	switch {
+	case exit.UserID == 0:
+		return "无法确定持仓所属用户"
+	case exit.StopLoss.IsZero():
+		return "原持仓没有止损，无法沿用交易计划"
+	case check == nil:
+		return "未配置信号确认"
+	case !check(exit.Symbol, exit.IsLong):
+		return "信号已不再成立"
+	}
+	return ""
+}
+
+// sideName 持仓方向的中文名称
+func sideName(isLong bool) string {
+	if isLong {
+		return "多头"
+	}
+	return "空头"
+}
@@ -0,0 +1,176 @@
+package trading
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// 开仓暂停来源
+const (
+	HaltManual     = "manual"      // 用户手动暂停自动交易
+	HaltDailyLoss  = "daily_loss"  // 单日亏损熔断
+	HaltDrawdown   = "drawdown"    // 单日回撤熔断
+	HaltLossStreak = "loss_streak" // 连续亏损熔断
+	HaltEmergency  = "emergency"   // 紧急停止，已平掉所有持仓，需手动恢复
+)
+
+// Halt 生效中的开仓暂停，Until 为零表示需手动恢复
+type Halt struct {
+	Name   string
+	Reason string
+	Since  time.Time
+	Until  time.Time
+}
+
+// expired 暂停是否已到自动解除时间
+func (h *Halt) expired(now time.Time) bool {
+	return !h.Until.IsZero() && !now.Before(h.Until)
+}
+
+// Halt 暂停开仓并写入数据库，重启后仍然生效；until 为零表示需手动恢复。已有持仓的止损止盈不受影响
+func (te *TradeExecutor) Halt(name, reason string, until time.Time) error {
+	halt := &Halt{Name: name, Reason: reason, Since: time.Now(), Until: until}
+
+	record := &database.TradingHalt{
+		Name:      name,
+		Reason:    reason,
+		CreatedAt: halt.Since.UTC(),
+	}
+	if !until.IsZero() {
+		u := until.UTC()
+		record.HaltedUntil = &u
+	}
+	if err := te.haltRepo.Set(record); err != nil {
+		return fmt.Errorf("failed to persist trading halt: %w", err)
+	}
+
+	te.mu.Lock()
+	te.halts[name] = halt
+	te.mu.Unlock()
+
+	te.logger.Warnf("New entries halted (%s): %s", name, reason)
+	return nil
+}
+
+// ClearHalt 解除指定来源的开仓暂停
+func (te *TradeExecutor) ClearHalt(name string) error {
+	if err := te.haltRepo.Delete(name); err != nil {
+		return fmt.Errorf("failed to clear trading halt: %w", err)
+	}
+
+	te.mu.Lock()
+	_, existed := te.halts[name]
+	delete(te.halts, name)
+	te.mu.Unlock()
+
+	if existed {
+		te.logger.Infof("Trading halt %s cleared", name)
+	}
+	return nil
+}
+
+// SetTradingEnabled 手动开关自动开仓：关闭时以手动暂停阻止新开仓，已有持仓的止损止盈不受影响；
+// 开启时同时解除紧急停止。其他熔断暂停不受影响，需等待其自动解除
+func (te *TradeExecutor) SetTradingEnabled(enabled bool) error {
+	if !enabled {
+		return te.Halt(HaltManual, "trading stopped by user", time.Time{})
+	}
+
+	if err := te.ClearHalt(HaltManual); err != nil {
+		return err
+	}
+	return te.ClearHalt(HaltEmergency)
+}
+
+// TradingEnabled 是否未被手动暂停或紧急停止
+func (te *TradeExecutor) TradingEnabled() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+
+	_, manual := te.halts[HaltManual]
+	_, emergency := te.halts[HaltEmergency]
+	return !manual && !emergency
+}
+
+// ActiveHalts 获取生效中的开仓暂停，按开始时间排序
+func (te *TradeExecutor) ActiveHalts() []*Halt {
+	now := time.Now()
+
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+
+	halts := make([]*Halt, 0, len(te.halts))
+	for _, halt := range te.halts {
+		if !halt.expired(now) {
+			h := *halt
+			halts = append(halts, &h)
+		}
+	}
+	sort.Slice(halts, func(i, j int) bool { return halts[i].Since.Before(halts[j].Since) })
+	return halts
+}
+
+// checkHalts 存在生效中的暂停时拒绝开仓
+func (te *TradeExecutor) checkHalts() error {
+	halts := te.ActiveHalts()
+	if len(halts) == 0 {
+		return nil
+	}
+
+	halt := halts[0]
+	if halt.Until.IsZero() {
+		return fmt.Errorf("new entries paused (%s): %s", halt.Name, halt.Reason)
+	}
+	return fmt.Errorf("new entries paused (%s) until %s: %s",
+		halt.Name, halt.Until.Format("2006-01-02 15:04 MST"), halt.Reason)
+}
+
+// restoreHalts 从数据库恢复重启前的开仓暂停，已到期的直接清除；调用方需持有 te.mu
+func (te *TradeExecutor) restoreHalts() error {
+	records, err := te.haltRepo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, record := range records {
+		halt := &Halt{Name: record.Name, Reason: record.Reason, Since: record.CreatedAt}
+		if record.HaltedUntil != nil {
+			halt.Until = *record.HaltedUntil
+		}
+
+		if halt.expired(now) {
+			if err := te.haltRepo.Delete(halt.Name); err != nil {
+				te.logger.Warnf("Failed to remove expired trading halt %s: %v", halt.Name, err)
+			}
+			continue
+		}
+
+		te.halts[halt.Name] = halt
+		te.logger.Warnf("Restored trading halt (%s): %s", halt.Name, halt.Reason)
+	}
+
+	return nil
+}
+
+// SymbolTradingFunc 判断交易对是否允许自动开仓
+type SymbolTradingFunc func(symbol string) bool
+
+// SetSymbolTradingCheck 设置按交易对的自动交易开关，暂停的交易对不再开仓，已有持仓的止损止盈不受影响
+func (te *TradeExecutor) SetSymbolTradingCheck(check SymbolTradingFunc) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.symbolTradingCheck = check
+}
+
+// symbolTradingEnabled 交易对是否允许自动开仓
+func (te *TradeExecutor) symbolTradingEnabled(symbol string) bool {
+	te.mu.RLock()
+	check := te.symbolTradingCheck
+	te.mu.RUnlock()
+
+	return check == nil || check(symbol)
+}
@@ -0,0 +1,140 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// lossGuardInterval 检查当日亏损和回撤的间隔
+const lossGuardInterval = time.Minute
+
+// dailyEquity 当日（UTC）的权益基准
+type dailyEquity struct {
+	day      time.Time       // 当日UTC零点
+	baseline decimal.Decimal // 当日开盘权益
+	peak     decimal.Decimal // 当日最高权益
+}
+
+// monitorLossLimits 定期按账户权益检查当日亏损和回撤，超过配置的上限时熔断
+func (te *TradeExecutor) monitorLossLimits() {
+	if te.tradingConfig.MaxDailyLossPercent <= 0 && te.tradingConfig.MaxDrawdownPercent <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(lossGuardInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-te.ctx.Done():
+			return
+		case <-ticker.C:
+			// 非实盘模式下账户权益不随交易变化
+			if !te.isLive() {
+				continue
+			}
+			if err := te.checkLossLimits(); err != nil {
+				te.logger.Warnf("Failed to check loss limits: %v", err)
+			}
+		}
+	}
+}
+
+// checkLossLimits 以账户保证金余额（含未实现盈亏）为权益，计算自当日UTC零点以来的亏损和自当日最高点的回撤
+func (te *TradeExecutor) checkLossLimits() error {
+	account, err := te.binanceClient.GetAccountInfo(te.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get account info: %w", err)
+	}
+	equity, err := decimal.NewFromString(account.TotalMarginBalance)
+	if err != nil {
+		return fmt.Errorf("invalid total margin balance: %w", err)
+	}
+
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour)
+
+	// 跨入新的UTC日时重置基准（只有本协程写入 dailyEquity）
+	te.mu.RLock()
+	daily := te.dailyEquity
+	te.mu.RUnlock()
+	if daily == nil || !daily.day.Equal(day) {
+		baseline := te.dayOpenEquity(day, equity)
+		daily = &dailyEquity{day: day, baseline: baseline, peak: decimal.Max(baseline, equity)}
+		te.logger.Infof("Daily equity baseline set to %s", baseline.StringFixed(2))
+	}
+
+	te.mu.Lock()
+	te.dailyEquity = daily
+	if equity.GreaterThan(daily.peak) {
+		daily.peak = equity
+	}
+	baseline, peak := daily.baseline, daily.peak
+	halted := false
+	for _, name := range []string{HaltDailyLoss, HaltDrawdown} {
+		if halt, ok := te.halts[name]; ok && !halt.expired(now) {
+			halted = true
+		}
+	}
+	te.mu.Unlock()
+
+	if halted || !baseline.IsPositive() {
+		return nil
+	}
+
+	hundred := decimal.NewFromInt(100)
+	loss := baseline.Sub(equity).Div(baseline).Mul(hundred)
+	drawdown := peak.Sub(equity).Div(peak).Mul(hundred)
+
+	if limit := te.tradingConfig.MaxDailyLossPercent; limit > 0 && loss.GreaterThanOrEqual(decimal.NewFromFloat(limit)) {
+		te.tripLossGuard(HaltDailyLoss, fmt.Sprintf("当日亏损 %s%% 超过上限 %.2f%%（开盘权益 %s，当前 %s）",
+			loss.StringFixed(2), limit, baseline.StringFixed(2), equity.StringFixed(2)), day)
+		return nil
+	}
+
+	if limit := te.tradingConfig.MaxDrawdownPercent; limit > 0 && drawdown.GreaterThanOrEqual(decimal.NewFromFloat(limit)) {
+		te.tripLossGuard(HaltDrawdown, fmt.Sprintf("当日回撤 %s%% 超过上限 %.2f%%（最高权益 %s，当前 %s）",
+			drawdown.StringFixed(2), limit, peak.StringFixed(2), equity.StringFixed(2)), day)
+	}
+	return nil
+}
+
+// dayOpenEquity 获取当日开盘权益：优先使用当日最早的权益快照，重启后不会丢失当日已发生的亏损；
+// 没有快照时以当前权益为基准
+func (te *TradeExecutor) dayOpenEquity(day time.Time, current decimal.Decimal) decimal.Decimal {
+	users, err := te.userConfigRepo.GetActiveUsers()
+	if err != nil || len(users) == 0 {
+		return current
+	}
+
+	snapshot, err := te.equitySnapshotRepo.GetFirstSince(users[0].UserID, reportAsset, day)
+	if err != nil || snapshot == nil {
+		return current
+	}
+	return decimal.NewFromFloat(snapshot.Equity)
+}
+
+// tripLossGuard 熔断：暂停开仓至次日UTC零点，撤销全部挂单并平掉所有持仓
+func (te *TradeExecutor) tripLossGuard(name, reason string, day time.Time) {
+	until := day.Add(24 * time.Hour)
+	if err := te.Halt(name, reason, until); err != nil {
+		te.logger.Errorf("Failed to persist %s halt, halting in memory only: %v", name, err)
+		te.mu.Lock()
+		te.halts[name] = &Halt{Name: name, Reason: reason, Since: time.Now(), Until: until}
+		te.mu.Unlock()
+	}
+
+	te.logger.Errorf("Loss limit breached (%s): %s", name, reason)
+
+	results, cancelErrors, err := te.flattenEverything(reason)
+	if err != nil {
+		te.notify("critical", "🚨 亏损熔断未完成",
+			fmt.Sprintf("已暂停开仓，但获取持仓失败，请立即在交易所手动平仓。\n原因: %s\n错误: %v", reason, err))
+		return
+	}
+
+	haltNote := fmt.Sprintf("已暂停开仓，%s 自动解除。", until.Local().Format("01-02 15:04"))
+	te.notify("critical", "🚨 亏损熔断", formatEmergencyStop(reason, haltNote, results, cancelErrors))
+}
@@ -0,0 +1,57 @@
+package trading
+
+import (
+	"time"
+)
+
+// maintenanceProbeInterval 维护期间探测接口是否恢复的间隔（实际请求频率受客户端退避限制）
+const maintenanceProbeInterval = 15 * time.Second
+
+// SetMaintenance 设置交易所维护状态：维护期间暂停开仓，恢复后自动继续
+func (te *TradeExecutor) SetMaintenance(active bool) {
+	te.mu.Lock()
+	changed := te.maintenance != active
+	te.maintenance = active
+	te.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if active {
+		te.logger.Warn("Exchange maintenance detected, new entries paused")
+		te.notify("critical", "🔧 交易所维护中",
+			"检测到币安接口维护，已暂停开仓并自动退避重试。\n现有止损止盈挂单仍在交易所有效，接口恢复后将自动继续。")
+		return
+	}
+
+	te.logger.Info("Exchange maintenance ended, new entries resumed")
+	te.notify("info", "✅ 交易所维护结束", "币安接口已恢复，自动交易继续运行。")
+}
+
+// InMaintenance 交易所是否处于维护状态
+func (te *TradeExecutor) InMaintenance() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.maintenance
+}
+
+// monitorMaintenance 维护期间定期探测接口，恢复时由客户端回调 SetMaintenance 清除状态
+func (te *TradeExecutor) monitorMaintenance() {
+	ticker := time.NewTicker(maintenanceProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-te.ctx.Done():
+			return
+		case <-ticker.C:
+			if !te.InMaintenance() {
+				continue
+			}
+			if err := te.binanceClient.TestConnection(te.ctx); err != nil {
+				te.logger.Debugf("Maintenance probe failed: %v", err)
+			}
+		}
+	}
+}
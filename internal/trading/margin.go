@@ -0,0 +1,69 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// ensureMarginType 首次交易某交易对时按配置设置保证金模式，设置成功后不再重复请求
+func (te *TradeExecutor) ensureMarginType(symbol string) error {
+	marginType := te.tradingConfig.MarginTypeFor(symbol)
+	if marginType == "" || !te.isLive() {
+		return nil
+	}
+
+	te.mu.RLock()
+	applied := te.marginTypes[symbol] == marginType
+	te.mu.RUnlock()
+	if applied {
+		return nil
+	}
+
+	err := te.binanceClient.SetMarginType(te.ctx, symbol, binance.MarginType(marginType))
+	if errors.Is(err, binance.ErrMarginTypeLocked) {
+		// 存在持仓或挂单时交易所不允许切换，沿用当前模式，下次开仓时再尝试
+		te.logger.Warnf("Cannot switch %s to %s margin while a position or open orders exist, keeping current margin type", symbol, marginType)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply %s margin type for %s: %w", marginType, symbol, err)
+	}
+
+	te.mu.Lock()
+	te.marginTypes[symbol] = marginType
+	te.mu.Unlock()
+
+	te.logger.Infof("Margin type for %s set to %s", symbol, marginType)
+	return nil
+}
+
+// ensureLeverage 首次交易某交易对或杠杆设置变化时在交易所设置杠杆倍数，未设置时使用默认杠杆
+func (te *TradeExecutor) ensureLeverage(symbol string, leverage int) error {
+	if leverage <= 0 {
+		leverage = te.tradingConfig.DefaultLeverage
+	}
+	if leverage <= 0 || !te.isLive() {
+		return nil
+	}
+
+	te.mu.RLock()
+	applied := te.leverages[symbol] == leverage
+	te.mu.RUnlock()
+	if applied {
+		return nil
+	}
+
+	resp, err := te.binanceClient.SetLeverage(te.ctx, symbol, leverage)
+	if err != nil {
+		return fmt.Errorf("failed to apply %dx leverage for %s: %w", leverage, symbol, err)
+	}
+
+	te.mu.Lock()
+	te.leverages[symbol] = leverage
+	te.mu.Unlock()
+
+	te.logger.Infof("Leverage for %s set to %dx (max notional %s)", symbol, resp.Leverage, resp.MaxNotionalValue)
+	return nil
+}
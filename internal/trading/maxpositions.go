@@ -0,0 +1,64 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMaxPositionsReached 用户持仓数已达上限，新的开仓被跳过
+var ErrMaxPositionsReached = errors.New("max positions reached")
+
+// openPositionSymbols 统计用户当前占用仓位的交易对：内存中跟踪的持仓、开仓进行中的交易对，
+// 以及数据库中仍标记为开放但尚未恢复到内存的持仓
+func (te *TradeExecutor) openPositionSymbols(userID int64) (map[string]bool, error) {
+	symbols := make(map[string]bool)
+
+	te.mu.RLock()
+	for _, position := range te.positions {
+		if position.UserID == userID && position.IsOpen {
+			symbols[position.Symbol] = true
+		}
+	}
+	for symbol, lifecycle := range te.lifecycles {
+		if lifecycle.UserID == userID && lifecycle.State == StateEntryPending {
+			symbols[symbol] = true
+		}
+	}
+	te.mu.RUnlock()
+
+	records, err := te.positionRepo.GetOpenPositions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open positions: %w", err)
+	}
+	for _, record := range records {
+		symbols[record.Symbol] = true
+	}
+
+	return symbols, nil
+}
+
+// checkMaxPositions 在新交易对开仓前检查用户持仓数上限，已持有该交易对时视为加仓不受限制
+func (te *TradeExecutor) checkMaxPositions(request *TradeRequest) error {
+	limit := te.tradingConfig.MaxPositions
+	if limit <= 0 {
+		return nil
+	}
+
+	symbols, err := te.openPositionSymbols(request.UserID)
+	if err != nil {
+		return err
+	}
+	if symbols[request.Symbol] || len(symbols) < limit {
+		return nil
+	}
+
+	return fmt.Errorf("%w for user %d (%d/%d)", ErrMaxPositionsReached, request.UserID, len(symbols), limit)
+}
+
+// notifyMaxPositions 通知开仓因持仓数达到上限被跳过
+func (te *TradeExecutor) notifyMaxPositions(request *TradeRequest, reason error) {
+	te.logger.Warnf("Entry for %s skipped for user %d: %v", request.Symbol, request.UserID, reason)
+	te.notify("warning", "📦 持仓数已达上限，跳过开仓",
+		fmt.Sprintf("用户 %d 的 %s 开仓信号未执行\n当前持仓已达上限 %d 个",
+			request.UserID, request.Symbol, te.tradingConfig.MaxPositions))
+}
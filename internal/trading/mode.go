@@ -0,0 +1,82 @@
+package trading
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/metrics"
+)
+
+// paperOrderSeq 模拟盘订单号序列
+var paperOrderSeq atomic.Int64
+
+// SetMode 设置运行模式（analysis / paper / live），需在 Start 之前调用
+func (te *TradeExecutor) SetMode(mode string) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.mode = mode
+}
+
+// Mode 获取运行模式
+func (te *TradeExecutor) Mode() string {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.mode
+}
+
+// isLive 是否为实盘模式
+func (te *TradeExecutor) isLive() bool {
+	return te.Mode() == config.ModeLive
+}
+
+// placeOrder 统一下单入口：分析模式拒绝下单，模拟盘模式不请求交易所，在本地模拟订单
+func (te *TradeExecutor) placeOrder(order *binance.OrderRequest) (*binance.OrderResponse, error) {
+	switch te.Mode() {
+	case config.ModeAnalysis:
+		return nil, fmt.Errorf("order placement disabled in analysis mode")
+	case config.ModePaper:
+		metrics.RecordOrder(order.Symbol, order.Type, nil)
+		return te.simulateOrder(order), nil
+	}
+	resp, err := te.binanceClient.PlaceOrder(te.ctx, order)
+	metrics.RecordOrder(order.Symbol, order.Type, err)
+	return resp, err
+}
+
+// simulateOrder 模拟订单：市价单立即按委托价成交（无价格时由调用方按标记价或信号价记录），条件单保持挂单状态
+func (te *TradeExecutor) simulateOrder(order *binance.OrderRequest) *binance.OrderResponse {
+	now := time.Now().UnixMilli()
+	id := paperOrderSeq.Add(1)
+
+	resp := &binance.OrderResponse{
+		OrderID:       id,
+		Symbol:        order.Symbol,
+		ClientOrderID: fmt.Sprintf("paper-%d", id),
+		Price:         order.Price,
+		OrigQty:       order.Quantity,
+		TimeInForce:   order.TimeInForce,
+		Type:          order.Type,
+		ReduceOnly:    order.ReduceOnly,
+		ClosePosition: order.ClosePosition,
+		Side:          order.Side,
+		StopPrice:     order.StopPrice,
+		OrigType:      order.Type,
+		Time:          now,
+		UpdateTime:    now,
+	}
+
+	if binance.OrderType(order.Type) == binance.OrderTypeMarket {
+		resp.Status = string(binance.OrderStatusFilled)
+		resp.ExecutedQty = order.Quantity
+		resp.AvgPrice = order.Price
+	} else {
+		resp.Status = string(binance.OrderStatusNew)
+	}
+
+	te.logger.Infof("[paper] Simulated %s %s order %d for %s: qty %s",
+		order.Side, order.Type, id, order.Symbol, order.Quantity)
+	return resp
+}
@@ -0,0 +1,125 @@
+package trading
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// trackOrder 将未终结的订单加入活跃订单列表
+func (te *TradeExecutor) trackOrder(request *TradeRequest, orderReq *binance.OrderRequest, orderResp *binance.OrderResponse, signalType string) {
+	switch binance.OrderStatus(orderResp.Status) {
+	case binance.OrderStatusFilled, binance.OrderStatusCanceled, binance.OrderStatusRejected, binance.OrderStatusExpired:
+		return
+	}
+
+	quantity, _ := decimal.NewFromString(orderReq.Quantity)
+	price, _ := decimal.NewFromString(orderReq.Price)
+	stopPrice, _ := decimal.NewFromString(orderReq.StopPrice)
+	now := time.Now()
+
+	order := &ActiveOrder{
+		ID:           fmt.Sprintf("%d", orderResp.OrderID),
+		UserID:       request.UserID,
+		Symbol:       request.Symbol,
+		Side:         orderReq.Side,
+		Type:         orderReq.Type,
+		Quantity:     quantity,
+		Price:        price,
+		StopPrice:    stopPrice,
+		Status:       orderResp.Status,
+		StrategyType: request.StrategyType,
+		SignalType:   signalType,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	te.mu.Lock()
+	te.activeOrders[order.ID] = order
+	te.mu.Unlock()
+}
+
+// ensureOrderCapacity 开仓前确保交易对挂单数未超过上限，必要时撤销最早的挂单。
+// 只减仓和平仓（closePosition）的止损止盈单保护现有持仓，计入挂单数但不会被撤销
+func (te *TradeExecutor) ensureOrderCapacity(userID int64, symbol string) error {
+	limit := te.tradingConfig.MaxOpenOrdersPerSymbol
+	if limit <= 0 || !te.isLive() {
+		return nil
+	}
+
+	openOrders, err := te.binanceClient.GetOpenOrders(te.ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get open orders: %w", err)
+	}
+
+	te.reconcileActiveOrders(userID, symbol, openOrders)
+
+	excess := len(openOrders) + 1 - limit
+	if excess <= 0 {
+		return nil
+	}
+
+	if !te.tradingConfig.CancelOldestOnLimit {
+		return fmt.Errorf("open order limit reached for %s (%d/%d)", symbol, len(openOrders), limit)
+	}
+
+	var evictable []binance.OrderResponse
+	for _, order := range openOrders {
+		if !isProtectiveOrder(&order) {
+			evictable = append(evictable, order)
+		}
+	}
+	if len(evictable) < excess {
+		return fmt.Errorf("open order limit reached for %s (%d/%d), remaining orders protect the position",
+			symbol, len(openOrders), limit)
+	}
+
+	// 按创建时间排序，撤销最早的挂单
+	sort.Slice(evictable, func(i, j int) bool {
+		return evictable[i].Time < evictable[j].Time
+	})
+
+	for _, order := range evictable[:excess] {
+		if err := te.binanceClient.CancelOrder(te.ctx, symbol, order.OrderID); err != nil {
+			return fmt.Errorf("failed to cancel oldest order %d: %w", order.OrderID, err)
+		}
+
+		te.mu.Lock()
+		delete(te.activeOrders, fmt.Sprintf("%d", order.OrderID))
+		te.mu.Unlock()
+
+		te.logger.Warnf("Open order limit reached for %s, cancelled oldest order %d", symbol, order.OrderID)
+	}
+
+	return nil
+}
+
+// isProtectiveOrder 判断挂单是否为保护持仓的只减仓或平仓单
+func isProtectiveOrder(order *binance.OrderResponse) bool {
+	return order.ReduceOnly || order.ClosePosition
+}
+
+// reconcileActiveOrders 以交易所挂单为准，移除本地已不存在的活跃订单
+func (te *TradeExecutor) reconcileActiveOrders(userID int64, symbol string, openOrders []binance.OrderResponse) {
+	onExchange := make(map[string]bool, len(openOrders))
+	for _, order := range openOrders {
+		onExchange[fmt.Sprintf("%d", order.OrderID)] = true
+	}
+
+	te.mu.Lock()
+	defer te.mu.Unlock()
+
+	for id, order := range te.activeOrders {
+		if order.UserID != userID || order.Symbol != symbol {
+			continue
+		}
+		if !onExchange[id] {
+			delete(te.activeOrders, id)
+			te.logger.Debugf("Removed stale active order %s for %s", id, symbol)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package trading
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// addProtectiveOrders 在交易所添加一组平掉多头持仓的止损止盈挂单
+func addProtectiveOrders(fx *fakeExchange, symbol string) (stopLossID, takeProfitID string) {
+	stopLossID = fx.addOrder(binance.OrderResponse{
+		Symbol: symbol, Status: string(binance.OrderStatusNew), Side: "SELL",
+		Type: string(binance.OrderTypeStopMarket), StopPrice: "29500", ClosePosition: true,
+	})
+	takeProfitID = fx.addOrder(binance.OrderResponse{
+		Symbol: symbol, Status: string(binance.OrderStatusNew), Side: "SELL",
+		Type: string(binance.OrderTypeTakeProfitMarket), StopPrice: "31000", ClosePosition: true,
+	})
+	return stopLossID, takeProfitID
+}
+
+func TestEnsureOrderCapacityNeverEvictsProtectiveOrders(t *testing.T) {
+	fx := newFakeExchange(t)
+	cfg := testTradingConfig()
+	cfg.MaxOpenOrdersPerSymbol = 3
+	cfg.CancelOldestOnLimit = true
+	te := newTestExecutor(t, fx, cfg)
+
+	// 保护订单比开仓挂单更早创建，按时间撤销时也不能选中
+	addProtectiveOrders(fx, "BTCUSDT")
+	entryID := fx.addOrder(binance.OrderResponse{
+		Symbol: "BTCUSDT", Status: string(binance.OrderStatusNew), Side: "BUY",
+		Type: string(binance.OrderTypeLimit), OrigQty: "0.01", Price: "29000",
+	})
+
+	if err := te.ensureOrderCapacity(1, "BTCUSDT"); err != nil {
+		t.Fatalf("ensureOrderCapacity: %v", err)
+	}
+
+	cancelled := fx.cancelledOrders()
+	if len(cancelled) != 1 || strconv.FormatInt(cancelled[0], 10) != entryID {
+		t.Fatalf("cancelled orders = %v, want only entry order %s", cancelled, entryID)
+	}
+}
+
+func TestEnsureOrderCapacityOnlyProtectiveOrdersLeft(t *testing.T) {
+	fx := newFakeExchange(t)
+	cfg := testTradingConfig()
+	cfg.MaxOpenOrdersPerSymbol = 2
+	cfg.CancelOldestOnLimit = true
+	te := newTestExecutor(t, fx, cfg)
+
+	addProtectiveOrders(fx, "BTCUSDT")
+
+	err := te.ensureOrderCapacity(1, "BTCUSDT")
+	if err == nil || !strings.Contains(err.Error(), "open order limit reached") {
+		t.Fatalf("ensureOrderCapacity error = %v, want open order limit reached", err)
+	}
+	if cancelled := fx.cancelledOrders(); len(cancelled) != 0 {
+		t.Fatalf("cancelled protective orders %v", cancelled)
+	}
+}
+
+func TestProtectiveOrderBypassesOrderCap(t *testing.T) {
+	fx := newFakeExchange(t)
+	cfg := testTradingConfig()
+	cfg.MaxOpenOrdersPerSymbol = 1
+	cfg.CancelOldestOnLimit = true
+	te := newTestExecutor(t, fx, cfg)
+	addTestUser(t, te, 1)
+
+	stopLossID := fx.addOrder(binance.OrderResponse{
+		Symbol: "BTCUSDT", Status: string(binance.OrderStatusNew), Side: "SELL",
+		Type: string(binance.OrderTypeStopMarket), StopPrice: "29500", ClosePosition: true,
+	})
+
+	result := te.ExecuteTrade(&TradeRequest{
+		UserID:        1,
+		Symbol:        "BTCUSDT",
+		Quantity:      decimal.RequireFromString("0.01"),
+		ClosePosition: true,
+		EntrySide:     "BUY",
+		Signal: &strategy.TradingSignal{
+			Type:       strategy.SignalTakeProfit,
+			TakeProfit: decimal.RequireFromString("31000"),
+		},
+	})
+	if result.Error != nil {
+		t.Fatalf("take profit rejected at order cap: %v", result.Error)
+	}
+	if cancelled := fx.cancelledOrders(); len(cancelled) != 0 {
+		t.Fatalf("cancelled orders %v while placing take profit, stop loss %s must stay", cancelled, stopLossID)
+	}
+}
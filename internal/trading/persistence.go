@@ -0,0 +1,126 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+const (
+	// dbFailureThreshold 连续写入失败多少次后进入降级模式
+	dbFailureThreshold = 3
+	// maxPendingTrades 降级期间内存中最多缓存的交易记录数
+	maxPendingTrades = 1000
+	// dbHealthCheckInterval 降级期间数据库健康检查间隔
+	dbHealthCheckInterval = 30 * time.Second
+)
+
+// saveTrade 保存交易记录，写入失败时缓存到内存并在持续失败时进入降级模式
+func (te *TradeExecutor) saveTrade(trade *database.Trade) {
+	err := te.tradeRepo.Create(trade)
+
+	te.mu.Lock()
+	if err == nil {
+		te.dbFailures = 0
+		te.mu.Unlock()
+		return
+	}
+
+	te.dbFailures++
+	if len(te.pendingTrades) < maxPendingTrades {
+		te.pendingTrades = append(te.pendingTrades, trade)
+	} else {
+		te.logger.Errorf("Pending trade buffer full, dropping record for order %s", trade.OrderID)
+	}
+	enterDegraded := !te.dbDegraded && te.dbFailures >= dbFailureThreshold
+	if enterDegraded {
+		te.dbDegraded = true
+	}
+	pending := len(te.pendingTrades)
+	te.mu.Unlock()
+
+	te.logger.Errorf("Failed to save trade record (buffered, %d pending): %v", pending, err)
+
+	if enterDegraded {
+		te.logger.Error("Database writes failing persistently, pausing new entries")
+		te.notify("critical", "🗄 数据库不可用",
+			fmt.Sprintf("交易记录连续写入失败，已暂停新开仓（平仓仍可执行）。\n已缓存记录: %d\n错误: %v", pending, err))
+	}
+}
+
+// IsDatabaseDegraded 检查是否处于数据库降级模式
+func (te *TradeExecutor) IsDatabaseDegraded() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.dbDegraded
+}
+
+// monitorDatabaseHealth 监控数据库健康状态，恢复后写回缓存记录
+func (te *TradeExecutor) monitorDatabaseHealth() {
+	ticker := time.NewTicker(dbHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-te.ctx.Done():
+			return
+		case <-ticker.C:
+			te.checkDatabaseRecovery()
+		}
+	}
+}
+
+// checkDatabaseRecovery 检查数据库是否恢复并写回缓存的交易记录
+func (te *TradeExecutor) checkDatabaseRecovery() {
+	te.mu.RLock()
+	degraded := te.dbDegraded
+	pendingCount := len(te.pendingTrades)
+	te.mu.RUnlock()
+
+	if !degraded && pendingCount == 0 {
+		return
+	}
+
+	if err := te.db.Health(); err != nil {
+		te.logger.Warnf("Database still unhealthy: %v", err)
+		return
+	}
+
+	flushed, err := te.flushPendingTrades()
+	if err != nil {
+		te.logger.Warnf("Failed to flush pending trades (%d flushed): %v", flushed, err)
+		return
+	}
+
+	te.mu.Lock()
+	wasDegraded := te.dbDegraded
+	te.dbDegraded = false
+	te.dbFailures = 0
+	te.mu.Unlock()
+
+	if wasDegraded {
+		te.logger.Infof("Database recovered, flushed %d pending trades", flushed)
+		te.notify("info", "🗄 数据库已恢复",
+			fmt.Sprintf("数据库写入恢复正常，已补写 %d 条交易记录，新开仓已恢复。", flushed))
+	}
+}
+
+// flushPendingTrades 写回缓存的交易记录，遇到错误时保留未写入的记录
+func (te *TradeExecutor) flushPendingTrades() (int, error) {
+	te.mu.Lock()
+	pending := te.pendingTrades
+	te.pendingTrades = nil
+	te.mu.Unlock()
+
+	for i, trade := range pending {
+		if err := te.tradeRepo.Create(trade); err != nil {
+			te.mu.Lock()
+			te.pendingTrades = append(pending[i:], te.pendingTrades...)
+			te.mu.Unlock()
+			return i, err
+		}
+	}
+
+	return len(pending), nil
+}
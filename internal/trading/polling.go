@@ -0,0 +1,187 @@
+package trading
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// monitorOrders 监控订单状态：有未成交开仓单的交易对按活跃间隔轮询，仅有止损止盈挂单的按基础间隔轮询
+func (te *TradeExecutor) monitorOrders() {
+	// 非实盘模式下订单不在交易所，无需同步
+	if !te.isLive() {
+		return
+	}
+
+	baseInterval := time.Duration(te.tradingConfig.OrderPollInterval) * time.Second
+	activeInterval := time.Duration(te.tradingConfig.ActiveOrderPollInterval) * time.Second
+	if !te.tradingConfig.AdaptiveOrderPolling {
+		activeInterval = baseInterval
+	}
+
+	ticker := time.NewTicker(activeInterval)
+	defer ticker.Stop()
+
+	lastPolled := make(map[string]time.Time)
+
+	for {
+		select {
+		case <-te.ctx.Done():
+			return
+		case now := <-ticker.C:
+			// 用户数据流连接期间订单状态由推送更新
+			if te.userStreamActive() {
+				continue
+			}
+			activity := te.orderActivity()
+
+			for symbol, active := range activity {
+				interval := baseInterval
+				if active {
+					interval = activeInterval
+				}
+				if now.Sub(lastPolled[symbol]) < interval {
+					continue
+				}
+
+				if err := te.updateOrderStatus(symbol); err != nil {
+					te.logger.Warnf("Failed to update order status for %s: %v", symbol, err)
+				}
+				lastPolled[symbol] = now
+			}
+
+			// 清理已无挂单的交易对
+			for symbol := range lastPolled {
+				if _, ok := activity[symbol]; !ok {
+					delete(lastPolled, symbol)
+				}
+			}
+		}
+	}
+}
+
+// orderActivity 统计有活跃订单的交易对，值为 true 表示存在等待成交的开仓单或部分成交的订单
+func (te *TradeExecutor) orderActivity() map[string]bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+
+	activity := make(map[string]bool)
+	for _, order := range te.activeOrders {
+		active := activity[order.Symbol] || isAwaitingFill(order)
+		activity[order.Symbol] = active
+	}
+	return activity
+}
+
+// isAwaitingFill 判断订单是否在等待成交（止损止盈等条件单挂着等待触发，不算活跃）
+func isAwaitingFill(order *ActiveOrder) bool {
+	if binance.OrderStatus(order.Status) == binance.OrderStatusPartiallyFilled {
+		return true
+	}
+
+	switch binance.OrderType(order.Type) {
+	case binance.OrderTypeLimit, binance.OrderTypeMarket:
+		return true
+	}
+	return false
+}
+
+// updateOrderStatus 逐个查询交易对活跃订单的最新状态，同步到交易记录；终结的订单从活跃列表移除，成交时发送交易通知
+func (te *TradeExecutor) updateOrderStatus(symbol string) error {
+	te.mu.RLock()
+	var ids []string
+	for id, order := range te.activeOrders {
+		if order.Symbol == symbol {
+			ids = append(ids, id)
+		}
+	}
+	te.mu.RUnlock()
+
+	var failed int
+	var lastErr error
+	for _, id := range ids {
+		if _, err := te.syncOrder(symbol, id); err != nil {
+			te.logger.Debugf("Failed to sync order %s for %s: %v", id, symbol, err)
+			failed++
+			lastErr = err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to sync %d/%d orders: %w", failed, len(ids), lastErr)
+	}
+	return nil
+}
+
+// syncOrder 查询单个订单并同步状态，返回订单最新的成交情况
+func (te *TradeExecutor) syncOrder(symbol, id string) (FillUpdate, error) {
+	orderID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return FillUpdate{}, fmt.Errorf("invalid order ID format: %w", err)
+	}
+
+	resp, err := te.binanceClient.GetOrder(te.ctx, symbol, orderID)
+	if err != nil {
+		return FillUpdate{}, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	filled, _ := decimal.NewFromString(resp.ExecutedQty)
+	avgPrice, _ := decimal.NewFromString(resp.AvgPrice)
+
+	update := FillUpdate{
+		OrderID:  id,
+		Symbol:   symbol,
+		Status:   binance.OrderStatus(resp.Status),
+		Filled:   filled,
+		AvgPrice: avgPrice,
+		Source:   FillSourcePolling,
+		At:       time.Now(),
+	}
+	te.ApplyFill(update)
+	return update, nil
+}
+
+// isTerminalStatus 判断订单状态是否已终结
+func isTerminalStatus(status binance.OrderStatus) bool {
+	switch status {
+	case binance.OrderStatusFilled, binance.OrderStatusCanceled, binance.OrderStatusRejected, binance.OrderStatusExpired:
+		return true
+	}
+	return false
+}
+
+// notifyFilled 挂单成交后发送交易通知
+func (te *TradeExecutor) notifyFilled(order *ActiveOrder, filled, avgPrice decimal.Decimal, at time.Time) {
+	te.mu.RLock()
+	notifier := te.notifier
+	te.mu.RUnlock()
+
+	if notifier == nil {
+		return
+	}
+
+	price := avgPrice
+	if price.IsZero() {
+		price = order.Price
+	}
+
+	result := &TradeResult{
+		Success:    true,
+		OrderID:    order.ID,
+		Symbol:     order.Symbol,
+		Side:       order.Side,
+		Quantity:   filled,
+		Price:      price,
+		Status:     string(binance.OrderStatusFilled),
+		Message:    fmt.Sprintf("%s order filled", order.SignalType),
+		ExecutedAt: at,
+	}
+
+	if err := notifier.SendTradeNotification(result); err != nil {
+		te.logger.Errorf("Failed to send fill notification for order %s: %v", order.ID, err)
+	}
+}
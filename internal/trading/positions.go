@@ -0,0 +1,274 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// adoptedStrategyType 接管的外部持仓使用的策略标记
+const adoptedStrategyType = "adopted"
+
+// ProtectiveLevelFunc 根据持仓方向和开仓均价计算止损止盈，ok 为 false 表示无法计算
+type ProtectiveLevelFunc func(isLong bool, entryPrice decimal.Decimal) (stopLoss, takeProfit decimal.Decimal, ok bool)
+
+// positionKey 获取持仓键
+func positionKey(userID int64, symbol string) string {
+	return fmt.Sprintf("%d_%s", userID, symbol)
+}
+
+// AdoptPosition 接管交易所上手动开立的持仓，levels 非空时按其结果设置止损止盈保护订单
+func (te *TradeExecutor) AdoptPosition(userID int64, symbol string, levels ProtectiveLevelFunc) (*Position, error) {
+	key := positionKey(userID, symbol)
+
+	te.mu.RLock()
+	_, tracked := te.positions[key]
+	te.mu.RUnlock()
+	if tracked {
+		return nil, fmt.Errorf("position %s is already managed", symbol)
+	}
+
+	exchangePositions, err := te.binanceClient.GetPositions(te.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	var position *Position
+	for _, p := range exchangePositions {
+		if p.Symbol != symbol {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(p.PositionAmt)
+		if err != nil || amount.IsZero() {
+			continue
+		}
+
+		entryPrice, _ := decimal.NewFromString(p.EntryPrice)
+		markPrice, _ := decimal.NewFromString(p.MarkPrice)
+		unrealizedPnl, _ := decimal.NewFromString(p.UnRealizedProfit)
+
+		side := "LONG"
+		if amount.IsNegative() {
+			side = "SHORT"
+		}
+
+		var stopLoss, takeProfit decimal.Decimal
+		if levels != nil {
+			if sl, tp, ok := levels(side == "LONG", entryPrice); ok {
+				stopLoss, takeProfit = sl, tp
+			}
+		}
+
+		now := time.Now()
+		position = &Position{
+			UserID:          userID,
+			Symbol:          symbol,
+			Side:            side,
+			Size:            amount.Abs(),
+			EntryPrice:      entryPrice,
+			MarkPrice:       markPrice,
+			UnrealizedPnl:   unrealizedPnl,
+			StopLossPrice:   stopLoss,
+			TakeProfitPrice: takeProfit,
+			StrategyType:    adoptedStrategyType,
+			IsOpen:          true,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		break
+	}
+
+	if position == nil {
+		return nil, fmt.Errorf("no open position found for %s", symbol)
+	}
+
+	record := &database.Position{
+		UserID:          userID,
+		Symbol:          symbol,
+		Side:            position.Side,
+		Size:            position.Size.InexactFloat64(),
+		EntryPrice:      position.EntryPrice.InexactFloat64(),
+		MarkPrice:       position.MarkPrice.InexactFloat64(),
+		UnrealizedPnl:   position.UnrealizedPnl.InexactFloat64(),
+		StopLossPrice:   position.StopLossPrice.InexactFloat64(),
+		TakeProfitPrice: position.TakeProfitPrice.InexactFloat64(),
+		StrategyType:    adoptedStrategyType,
+		IsOpen:          true,
+	}
+	if err := te.positionRepo.Create(record); err != nil {
+		return nil, fmt.Errorf("failed to save adopted position: %w", err)
+	}
+	position.RecordID = record.ID
+
+	te.mu.Lock()
+	te.positions[key] = position
+	te.mu.Unlock()
+	te.moveTo(userID, symbol, StateOpen, "adopted")
+
+	te.logger.Infof("Adopted %s position for user %d: %s @ %s",
+		position.Side, userID, position.Size.String(), position.EntryPrice.String())
+
+	if !position.StopLossPrice.IsZero() || !position.TakeProfitPrice.IsZero() {
+		signalType := strategy.SignalBuy
+		if position.Side == "SHORT" {
+			signalType = strategy.SignalSell
+		}
+
+		request := &TradeRequest{
+			UserID:       userID,
+			Symbol:       symbol,
+			Quantity:     position.Size,
+			StrategyType: adoptedStrategyType,
+			Signal: &strategy.TradingSignal{
+				Symbol:     symbol,
+				Type:       signalType,
+				Price:      position.EntryPrice,
+				StopLoss:   position.StopLossPrice,
+				TakeProfit: position.TakeProfitPrice,
+			},
+		}
+		if !te.goTracked(func() { te.placeProtectiveOrders(request) }) {
+			te.notify("warning", "接管持仓未设置止损止盈",
+				fmt.Sprintf("%s 接管时执行器正在停止，未设置止损止盈，请在重启后检查", symbol))
+		}
+	}
+
+	return position, nil
+}
+
+// restorePositions 从数据库恢复所有活跃用户的开放持仓，调用方需持有 te.mu
+func (te *TradeExecutor) restorePositions() error {
+	users, err := te.userConfigRepo.GetActiveUsers()
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	for _, user := range users {
+		records, err := te.positionRepo.GetOpenPositions(user.UserID)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			position := &Position{
+				RecordID:        record.ID,
+				UserID:          record.UserID,
+				Symbol:          record.Symbol,
+				Side:            record.Side,
+				Size:            decimal.NewFromFloat(record.Size),
+				EntryPrice:      decimal.NewFromFloat(record.EntryPrice),
+				MarkPrice:       decimal.NewFromFloat(record.MarkPrice),
+				UnrealizedPnl:   decimal.NewFromFloat(record.UnrealizedPnl),
+				StopLossPrice:   decimal.NewFromFloat(record.StopLossPrice),
+				TakeProfitPrice: decimal.NewFromFloat(record.TakeProfitPrice),
+				StrategyType:    record.StrategyType,
+				IsOpen:          true,
+				CreatedAt:       record.CreatedAt,
+				UpdatedAt:       record.UpdatedAt,
+			}
+			te.positions[positionKey(record.UserID, record.Symbol)] = position
+
+			lifecycle := te.lifecycleLocked(record.UserID, record.Symbol)
+			lifecycle.State = StateOpen
+			lifecycle.Reason = "restored"
+			lifecycle.Since = time.Now()
+
+			te.logger.Infof("Restored %s position for user %d: %s %s @ %s",
+				position.Side, position.UserID, position.Symbol, position.Size.String(), position.EntryPrice.String())
+		}
+	}
+
+	return nil
+}
+
+// ReconcilePositions 将跟踪的持仓与交易所持仓核对：交易所已不存在的持仓在数据库中标记为已平仓并停止跟踪，
+// 仍存在的持仓按交易所数据更新数量、均价和浮动盈亏。非实盘模式下没有交易所持仓可对照
+func (te *TradeExecutor) ReconcilePositions() error {
+	te.mu.RLock()
+	tracked := len(te.positions)
+	te.mu.RUnlock()
+	if !te.isLive() || tracked == 0 {
+		return nil
+	}
+
+	exchangePositions, err := te.binanceClient.GetPositions(te.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	live := make(map[string]binance.Position, len(exchangePositions))
+	for _, p := range exchangePositions {
+		if amount, err := decimal.NewFromString(p.PositionAmt); err == nil && !amount.IsZero() {
+			live[p.Symbol] = p
+		}
+	}
+
+	te.mu.Lock()
+	var closed, updated []*Position
+	for key, position := range te.positions {
+		p, ok := live[position.Symbol]
+		if !ok {
+			position.IsOpen = false
+			position.UpdatedAt = time.Now()
+			closed = append(closed, position)
+			delete(te.positions, key)
+			continue
+		}
+
+		amount, _ := decimal.NewFromString(p.PositionAmt)
+		position.Size = amount.Abs()
+		position.Side = "LONG"
+		if amount.IsNegative() {
+			position.Side = "SHORT"
+		}
+		position.EntryPrice, _ = decimal.NewFromString(p.EntryPrice)
+		position.MarkPrice, _ = decimal.NewFromString(p.MarkPrice)
+		position.UnrealizedPnl, _ = decimal.NewFromString(p.UnRealizedProfit)
+		position.UpdatedAt = time.Now()
+		copied := *position
+		updated = append(updated, &copied)
+	}
+	te.mu.Unlock()
+
+	for _, position := range closed {
+		te.logger.Infof("Position %s for user %d no longer exists on the exchange, marking closed",
+			position.Symbol, position.UserID)
+		te.moveTo(position.UserID, position.Symbol, StateFlat, "closed on exchange")
+	}
+
+	var errs []error
+	for _, position := range append(closed, updated...) {
+		if position.RecordID == 0 {
+			continue
+		}
+		if err := te.positionRepo.Update(positionRecord(position)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", position.Symbol, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// positionRecord 将持仓转换为数据库记录
+func positionRecord(position *Position) *database.Position {
+	return &database.Position{
+		ID:              position.RecordID,
+		UserID:          position.UserID,
+		Symbol:          position.Symbol,
+		Side:            position.Side,
+		Size:            position.Size.InexactFloat64(),
+		EntryPrice:      position.EntryPrice.InexactFloat64(),
+		MarkPrice:       position.MarkPrice.InexactFloat64(),
+		UnrealizedPnl:   position.UnrealizedPnl.InexactFloat64(),
+		StopLossPrice:   position.StopLossPrice.InexactFloat64(),
+		TakeProfitPrice: position.TakeProfitPrice.InexactFloat64(),
+		StrategyType:    position.StrategyType,
+		IsOpen:          position.IsOpen,
+	}
+}
@@ -0,0 +1,225 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PositionState 交易对持仓生命周期状态
+type PositionState string
+
+const (
+	StateFlat         PositionState = "flat"          // 无持仓
+	StateEntryPending PositionState = "entry_pending" // 开仓单已提交，等待结果
+	StateProtecting   PositionState = "protecting"    // 已开仓，正在设置止损止盈
+	StateOpen         PositionState = "open"          // 持仓中且保护订单已就绪（或无需保护）
+	StateUnprotected  PositionState = "unprotected"   // 持仓中但止损单设置失败
+	StateExitPending  PositionState = "exit_pending"  // 平仓单已提交，等待结果
+)
+
+// positionTransitions 允许的状态迁移，未列出的迁移一律拒绝
+var positionTransitions = map[PositionState][]PositionState{
+	// 无持仓：开仓，或接管/平掉执行器之外开立的持仓
+	StateFlat: {StateEntryPending, StateOpen, StateExitPending},
+	// 开仓结果：失败回到无持仓，成交后按是否带止损止盈进入保护或持仓
+	StateEntryPending: {StateFlat, StateProtecting, StateOpen},
+	// 保护订单结果：全部就绪、止损失败，或期间已在交易所被平仓
+	StateProtecting: {StateOpen, StateUnprotected, StateExitPending, StateFlat},
+	// 持仓中：加仓、重新设置保护、主动平仓，或止损止盈/手动在交易所平仓
+	StateOpen: {StateEntryPending, StateProtecting, StateExitPending, StateFlat},
+	// 无止损持仓：只允许平仓或重新设置保护
+	StateUnprotected: {StateProtecting, StateExitPending, StateFlat},
+	// 平仓结果：成功回到无持仓，失败回到进入平仓前的状态
+	StateExitPending: {StateFlat, StateOpen, StateProtecting, StateUnprotected},
+}
+
+// PositionLifecycle 用户在单个交易对上的持仓生命周期
+type PositionLifecycle struct {
+	Symbol string
+	UserID int64
+	State  PositionState
+	Prior  PositionState // 进入当前状态前的状态，提交失败时据此回退
+	Reason string
+	Since  time.Time
+
+	// 持仓的止损止盈挂单，一方成交后撤销另一方，回到无持仓时清空
+	StopLossOrderID   string
+	TakeProfitOrderID string
+}
+
+// canTransition 判断状态迁移是否允许
+func canTransition(from, to PositionState) bool {
+	for _, next := range positionTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// lifecycleLocked 获取用户在交易对上的生命周期，未跟踪时视为无持仓。调用方需持有 te.mu
+func (te *TradeExecutor) lifecycleLocked(userID int64, symbol string) *PositionLifecycle {
+	key := positionKey(userID, symbol)
+	lifecycle, ok := te.lifecycles[key]
+	if !ok {
+		lifecycle = &PositionLifecycle{Symbol: symbol, UserID: userID, State: StateFlat, Prior: StateFlat}
+		te.lifecycles[key] = lifecycle
+	}
+	return lifecycle
+}
+
+// transition 将用户在交易对上的持仓迁移到新状态，迁移不合法时返回错误且状态不变
+func (te *TradeExecutor) transition(userID int64, symbol string, to PositionState, reason string) error {
+	te.mu.Lock()
+	lifecycle := te.lifecycleLocked(userID, symbol)
+	from := lifecycle.State
+	if from == to {
+		te.mu.Unlock()
+		return nil
+	}
+	if !canTransition(from, to) {
+		te.mu.Unlock()
+		return fmt.Errorf("position %s of user %d is %s, cannot move to %s", symbol, userID, from, to)
+	}
+
+	lifecycle.Prior = from
+	lifecycle.State = to
+	lifecycle.Reason = reason
+	lifecycle.Since = time.Now()
+	if to == StateFlat {
+		lifecycle.StopLossOrderID = ""
+		lifecycle.TakeProfitOrderID = ""
+	}
+	te.mu.Unlock()
+
+	te.logger.Debugf("Position %s of user %d: %s -> %s (%s)", symbol, userID, from, to, reason)
+	return nil
+}
+
+// moveTo 执行流程中的状态迁移，不合法时只记录日志，不中断已在交易所发生的操作
+func (te *TradeExecutor) moveTo(userID int64, symbol string, to PositionState, reason string) {
+	if err := te.transition(userID, symbol, to, reason); err != nil {
+		te.logger.Warnf("Unexpected position transition: %v", err)
+	}
+}
+
+// revertTransition 提交失败时回退到进入 from 状态之前的状态
+func (te *TradeExecutor) revertTransition(userID int64, symbol string, from PositionState, reason string) {
+	te.mu.Lock()
+	lifecycle := te.lifecycleLocked(userID, symbol)
+	if lifecycle.State != from {
+		te.mu.Unlock()
+		return
+	}
+	prior := lifecycle.Prior
+	te.mu.Unlock()
+
+	te.moveTo(userID, symbol, prior, reason)
+}
+
+// PositionState 获取用户在交易对上当前的持仓生命周期状态
+func (te *TradeExecutor) PositionState(userID int64, symbol string) PositionState {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	if lifecycle, ok := te.lifecycles[positionKey(userID, symbol)]; ok {
+		return lifecycle.State
+	}
+	return StateFlat
+}
+
+// HasActivePosition 是否有任一用户在交易对上处于非空仓状态
+func (te *TradeExecutor) HasActivePosition(symbol string) bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	for _, lifecycle := range te.lifecycles {
+		if lifecycle.Symbol == symbol && lifecycle.State != StateFlat {
+			return true
+		}
+	}
+	return false
+}
+
+// PositionLifecycles 获取所有非空仓的生命周期副本，按 positionKey（用户+交易对）索引
+func (te *TradeExecutor) PositionLifecycles() map[string]PositionLifecycle {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+
+	result := make(map[string]PositionLifecycle)
+	for key, lifecycle := range te.lifecycles {
+		if lifecycle.State != StateFlat {
+			result[key] = *lifecycle
+		}
+	}
+	return result
+}
+
+// lifecycleOwner 获取平仓时应迁移的生命周期所属用户：发起平仓的用户（如死人开关）
+// 在该交易对上没有跟踪中的持仓时，回退到持仓所属用户
+func (te *TradeExecutor) lifecycleOwner(userID int64, symbol string) int64 {
+	if te.PositionState(userID, symbol) != StateFlat {
+		return userID
+	}
+
+	if owner := te.positionOwner(symbol); owner != 0 {
+		return owner
+	}
+	return userID
+}
+
+// beginEntry 开仓前进入等待开仓状态，已有开仓、保护或平仓流程进行中时拒绝
+func (te *TradeExecutor) beginEntry(request *TradeRequest) error {
+	if err := te.transition(request.UserID, request.Symbol, StateEntryPending, "entry signal"); err != nil {
+		return fmt.Errorf("entry rejected: %w", err)
+	}
+	return nil
+}
+
+// finishEntry 根据开仓结果迁移状态：失败回退，成交后按是否带止损止盈进入保护或持仓
+func (te *TradeExecutor) finishEntry(request *TradeRequest, result *TradeResult) *TradeResult {
+	if !result.Success {
+		te.revertTransition(request.UserID, request.Symbol, StateEntryPending, "entry failed")
+		return result
+	}
+
+	if !request.Signal.StopLoss.IsZero() || !request.Signal.TakeProfit.IsZero() {
+		te.moveTo(request.UserID, request.Symbol, StateProtecting, "entry placed")
+	} else {
+		te.moveTo(request.UserID, request.Symbol, StateOpen, "entry placed without protection")
+	}
+	return result
+}
+
+// syncPositionStates 以交易所持仓为准：已在交易所平掉（止损止盈触发、手动平仓）的交易对回到无持仓
+func (te *TradeExecutor) syncPositionStates() error {
+	positions, err := te.binanceClient.GetPositions(te.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	open := make(map[string]bool, len(positions))
+	for _, p := range positions {
+		if amount, err := decimal.NewFromString(p.PositionAmt); err == nil && !amount.IsZero() {
+			open[p.Symbol] = true
+		}
+	}
+
+	te.mu.RLock()
+	var closed []PositionLifecycle
+	for _, lifecycle := range te.lifecycles {
+		switch lifecycle.State {
+		case StateOpen, StateProtecting, StateUnprotected:
+			if !open[lifecycle.Symbol] {
+				closed = append(closed, *lifecycle)
+			}
+		}
+	}
+	te.mu.RUnlock()
+
+	for _, lifecycle := range closed {
+		te.logger.Infof("Position %s of user %d was closed on the exchange (was %s)", lifecycle.Symbol, lifecycle.UserID, lifecycle.State)
+		te.moveTo(lifecycle.UserID, lifecycle.Symbol, StateFlat, "closed on exchange")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package trading
+
+import (
+	"testing"
+)
+
+func TestLifecyclesIsolatedPerUser(t *testing.T) {
+	fx := newFakeExchange(t)
+	te := newTestExecutor(t, fx, testTradingConfig())
+	addTestUser(t, te, 1)
+	addTestUser(t, te, 2)
+
+	te.moveTo(2, "BTCUSDT", StateOpen, "adopted")
+
+	// 用户2持仓中时用户1仍可开仓
+	if err := te.beginEntry(&TradeRequest{UserID: 1, Symbol: "BTCUSDT"}); err != nil {
+		t.Fatalf("beginEntry for user 1 while user 2 is open: %v", err)
+	}
+	if state := te.PositionState(2, "BTCUSDT"); state != StateOpen {
+		t.Errorf("user 2 state = %s, want %s", state, StateOpen)
+	}
+
+	lifecycles := te.PositionLifecycles()
+	first, second := lifecycles[positionKey(1, "BTCUSDT")], lifecycles[positionKey(2, "BTCUSDT")]
+	if first.UserID != 1 || first.State != StateEntryPending || second.UserID != 2 || second.State != StateOpen {
+		t.Fatalf("lifecycles not tracked per user: %+v, %+v", first, second)
+	}
+	if !te.HasActivePosition("BTCUSDT") || te.HasActivePosition("ETHUSDT") {
+		t.Error("HasActivePosition does not reflect per-user lifecycles")
+	}
+}
+
+func TestLifecycleOwnerFallsBackToPositionOwner(t *testing.T) {
+	fx := newFakeExchange(t)
+	te := newTestExecutor(t, fx, testTradingConfig())
+
+	te.moveTo(2, "BTCUSDT", StateOpen, "adopted")
+
+	// 死人开关等不属于任何用户的平仓迁移持仓所属用户的生命周期
+	if owner := te.lifecycleOwner(0, "BTCUSDT"); owner != 2 {
+		t.Errorf("lifecycleOwner(0) = %d, want 2", owner)
+	}
+	if owner := te.lifecycleOwner(1, "ETHUSDT"); owner != 1 {
+		t.Errorf("lifecycleOwner without position = %d, want 1", owner)
+	}
+}
@@ -0,0 +1,52 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// priceMaxAge 缓存价格的有效期，超过后回退到REST查询
+const priceMaxAge = 10 * time.Second
+
+// PriceFeed 实时价格来源，由行情推送维护
+type PriceFeed interface {
+	GetPrice(symbol string) (price decimal.Decimal, updatedAt time.Time, ok bool)
+}
+
+// SetPriceFeed 设置实时价格来源，未设置时每次通过REST查询最新价格
+func (te *TradeExecutor) SetPriceFeed(feed PriceFeed) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.priceFeed = feed
+}
+
+// cachedPrice 获取未过期的缓存价格
+func (te *TradeExecutor) cachedPrice(symbol string) (decimal.Decimal, bool) {
+	te.mu.RLock()
+	feed := te.priceFeed
+	te.mu.RUnlock()
+
+	if feed == nil {
+		return decimal.Zero, false
+	}
+	price, updatedAt, ok := feed.GetPrice(symbol)
+	if !ok || !price.IsPositive() || time.Since(updatedAt) > priceMaxAge {
+		return decimal.Zero, false
+	}
+	return price, true
+}
+
+// latestPrice 获取最新价格：优先使用未过期的缓存价格，否则通过REST查询
+func (te *TradeExecutor) latestPrice(symbol string) (decimal.Decimal, error) {
+	if price, ok := te.cachedPrice(symbol); ok {
+		return price, nil
+	}
+
+	ticker, err := te.binanceClient.GetTickerPrice(te.ctx, symbol)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get ticker price: %w", err)
+	}
+	return ticker.Price, nil
+}
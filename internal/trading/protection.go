@@ -0,0 +1,99 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// protectiveRetryBaseDelay 止损止盈重试的初始退避时间，每次失败后翻倍
+const protectiveRetryBaseDelay = time.Second
+
+// placeProtectiveOrder 下止损/止盈单，失败时按配置次数指数退避重试
+func (te *TradeExecutor) placeProtectiveOrder(request *TradeRequest, kind string) *TradeResult {
+	attempts := te.tradingConfig.ProtectiveOrderRetries + 1
+	delay := protectiveRetryBaseDelay
+
+	var result *TradeResult
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result = te.ExecuteTrade(request)
+		if result.Error == nil {
+			if attempt > 1 {
+				te.logger.Infof("Placed %s order for %s after %d attempts", kind, request.Symbol, attempt)
+			}
+			return result
+		}
+
+		te.logger.Warnf("Failed to place %s order for %s (attempt %d/%d): %v",
+			kind, request.Symbol, attempt, attempts, result.Error)
+		if attempt == attempts {
+			break
+		}
+
+		select {
+		case <-te.ctx.Done():
+			return result
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	return result
+}
+
+// roundProtectiveLevels 下保护订单前将止损止盈取整到最小变动单位，并按配置修正因取整越过开仓价/标记价的价格
+func (te *TradeExecutor) roundProtectiveLevels(request *TradeRequest) (stopLoss, takeProfit decimal.Decimal) {
+	signal := request.Signal
+	info, err := te.getSymbolInfo(request.Symbol)
+	if err != nil {
+		te.logger.Warnf("Failed to get symbol info for %s, protective levels not rounded: %v", request.Symbol, err)
+		return signal.StopLoss, signal.TakeProfit
+	}
+	tick := info.TickSize()
+
+	if !te.tradingConfig.NudgeRoundedLevels {
+		return roundToTick(signal.StopLoss, tick), roundToTick(signal.TakeProfit, tick)
+	}
+
+	// 标记价获取失败时只以开仓价为参考
+	var mark decimal.Decimal
+	if index, err := te.binanceClient.GetPremiumIndex(te.ctx, request.Symbol); err == nil {
+		mark = index.MarkPrice
+	} else {
+		te.logger.Debugf("Failed to get mark price for %s: %v", request.Symbol, err)
+	}
+
+	isLong := protectedEntrySide(request) == "BUY"
+	stopLoss, takeProfit, nudged := nudgeRoundedLevels(isLong, signal.Price, mark, signal.StopLoss, signal.TakeProfit, tick)
+	if nudged {
+		te.logger.Infof("Nudged %s protective levels after tick rounding: stop %s -> %s, take profit %s -> %s",
+			request.Symbol, signal.StopLoss, stopLoss, signal.TakeProfit, takeProfit)
+	}
+	return stopLoss, takeProfit
+}
+
+// handleUnprotected 止损最终下单失败：发送严重告警，并按配置自动平仓
+func (te *TradeExecutor) handleUnprotected(request *TradeRequest, cause error) {
+	te.logger.Errorf("Position %s is UNPROTECTED: %v", request.Symbol, cause)
+
+	if !te.tradingConfig.CloseUnprotected {
+		te.notify("critical", "持仓无止损保护",
+			fmt.Sprintf("%s 已开仓但止损单多次重试后仍未设置成功，请立即手动处理！\n原因: %v\n\n可使用 /flatten %s 平仓",
+				request.Symbol, cause, request.Symbol))
+		return
+	}
+
+	result, err := te.FlattenSymbol(request.UserID, request.Symbol, "unprotected position")
+	if err == nil && result != nil {
+		err = result.Error
+	}
+	if err != nil {
+		te.notify("critical", "持仓无止损保护且自动平仓失败",
+			fmt.Sprintf("%s 止损单设置失败: %v\n自动平仓也失败: %v\n请立即手动处理！", request.Symbol, cause, err))
+		return
+	}
+
+	te.notify("critical", "无止损持仓已自动平仓",
+		fmt.Sprintf("%s 止损单设置失败（%v），已按配置自动平仓", request.Symbol, cause))
+}
@@ -0,0 +1,65 @@
+package trading
+
+import (
+	"fmt"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// maxMarginRejections 用户连续因保证金不足被拒绝开仓的次数达到该值后停用该用户
+const maxMarginRejections = 3
+
+// recordRejection 按币安错误码处理开仓拒绝：连续保证金不足时停用用户，临时错误和精度错误只记录原因，
+// 成功开仓后清零计数
+func (te *TradeExecutor) recordRejection(request *TradeRequest, result *TradeResult) *TradeResult {
+	if result.Success {
+		te.mu.Lock()
+		delete(te.marginRejections, request.UserID)
+		te.mu.Unlock()
+		return result
+	}
+
+	switch err := result.Error; {
+	case binance.IsInsufficientMargin(err):
+		te.mu.Lock()
+		te.marginRejections[request.UserID]++
+		count := te.marginRejections[request.UserID]
+		te.mu.Unlock()
+
+		te.logger.Warnf("Entry for user %d on %s rejected for insufficient margin (%d/%d)", request.UserID, request.Symbol, count, maxMarginRejections)
+		if count >= maxMarginRejections {
+			te.disableUser(request.UserID, count)
+		}
+	case binance.IsTransient(err):
+		te.logger.Warnf("Entry for user %d on %s failed with transient error: %v", request.UserID, request.Symbol, err)
+	case binance.IsPrecisionError(err):
+		te.logger.Errorf("Entry for user %d on %s rejected by symbol filters, check exchange rules: %v", request.UserID, request.Symbol, err)
+	}
+	return result
+}
+
+// disableUser 停用连续保证金不足的用户，需人工补充保证金后重新启用
+func (te *TradeExecutor) disableUser(userID int64, rejections int) {
+	te.mu.Lock()
+	delete(te.marginRejections, userID)
+	te.mu.Unlock()
+
+	userConfig, err := te.userConfigRepo.GetByUserID(userID)
+	if err != nil || userConfig == nil {
+		te.logger.Errorf("Failed to load user %d for disabling: %v", userID, err)
+		return
+	}
+	if !userConfig.IsActive {
+		return
+	}
+
+	userConfig.IsActive = false
+	if err := te.userConfigRepo.Update(userConfig); err != nil {
+		te.logger.Errorf("Failed to disable user %d: %v", userID, err)
+		return
+	}
+
+	te.logger.Errorf("User %d disabled after %d consecutive insufficient margin rejections", userID, rejections)
+	te.notify("critical", "用户已停用",
+		fmt.Sprintf("用户 %d 连续 %d 次因保证金不足开仓被拒，已停用自动交易。请补充保证金后重新启用", userID, rejections))
+}
@@ -0,0 +1,193 @@
+package trading
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// 权益快照
+const (
+	equitySnapshotInterval = time.Hour
+	reportAsset            = "USDT" // 报告与权益曲线使用的计价资产
+)
+
+// Report 综合报告：期间交易统计、权益曲线变化、手续费和当前持仓风险
+type Report struct {
+	Since       time.Time
+	Stats       *database.TradeStats
+	Asset       string
+	Equity      decimal.Decimal // 当前权益（保证金余额）
+	StartEquity decimal.Decimal // 期间内最早的权益快照，没有快照时为零
+	Positions   []*ReportPosition
+	OpenRisk    decimal.Decimal // 所有带止损持仓触发止损时的亏损合计
+	Unprotected int             // 未跟踪到止损价的持仓数量
+}
+
+// ReportPosition 报告中的持仓
+type ReportPosition struct {
+	Symbol        string
+	Side          string
+	Size          decimal.Decimal
+	EntryPrice    decimal.Decimal
+	MarkPrice     decimal.Decimal
+	UnrealizedPnl decimal.Decimal
+	StopLoss      decimal.Decimal
+	Risk          decimal.Decimal // 触发止损时的亏损，未设置止损时为零
+}
+
+// EquityChange 期间权益变化，没有期初快照时 ok 为 false
+func (r *Report) EquityChange() (decimal.Decimal, bool) {
+	if r.StartEquity.IsZero() {
+		return decimal.Zero, false
+	}
+	return r.Equity.Sub(r.StartEquity), true
+}
+
+// OpenRiskPercent 持仓风险占当前权益的百分比
+func (r *Report) OpenRiskPercent() decimal.Decimal {
+	if !r.Equity.IsPositive() {
+		return decimal.Zero
+	}
+	return r.OpenRisk.Div(r.Equity).Mul(decimal.NewFromInt(100))
+}
+
+// BuildReport 汇总交易记录、权益快照和实时账户数据生成用户自 since 起的报告
+func (te *TradeExecutor) BuildReport(userID int64, since time.Time) (*Report, error) {
+	stats, err := te.tradeRepo.GetStats(userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade stats: %w", err)
+	}
+
+	report := &Report{Since: since, Stats: stats, Asset: reportAsset}
+
+	accountInfo, err := te.binanceClient.GetAccountInfo(te.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+	if asset := accountInfo.FindAsset(reportAsset); asset != nil {
+		report.Equity, _ = decimal.NewFromString(asset.MarginBalance)
+	}
+
+	start, err := te.equitySnapshotRepo.GetFirstSince(userID, reportAsset, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get equity snapshot: %w", err)
+	}
+	if start != nil {
+		report.StartEquity = decimal.NewFromFloat(start.Equity)
+	}
+
+	exchangePositions, err := te.binanceClient.GetPositions(te.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+
+	tracked := te.GetPositions()
+	for _, p := range exchangePositions {
+		amount, err := decimal.NewFromString(p.PositionAmt)
+		if err != nil || amount.IsZero() {
+			continue
+		}
+
+		position := &ReportPosition{
+			Symbol: p.Symbol,
+			Side:   "LONG",
+			Size:   amount.Abs(),
+		}
+		if amount.IsNegative() {
+			position.Side = "SHORT"
+		}
+		position.EntryPrice, _ = decimal.NewFromString(p.EntryPrice)
+		position.MarkPrice, _ = decimal.NewFromString(p.MarkPrice)
+		position.UnrealizedPnl, _ = decimal.NewFromString(p.UnRealizedProfit)
+
+		if t, ok := tracked[positionKey(userID, p.Symbol)]; ok && !t.StopLossPrice.IsZero() {
+			position.StopLoss = t.StopLossPrice
+			multiplier := decimal.NewFromInt(1)
+			if info, err := te.getSymbolInfo(p.Symbol); err == nil {
+				multiplier = info.ContractMultiplier()
+			}
+			// 按标记价格到止损价的距离计算，止损已越过标记价格时为零
+			distance := position.MarkPrice.Sub(position.StopLoss)
+			if position.Side == "SHORT" {
+				distance = distance.Neg()
+			}
+			if distance.IsPositive() {
+				position.Risk = distance.Mul(position.Size).Mul(multiplier)
+			}
+			report.OpenRisk = report.OpenRisk.Add(position.Risk)
+		} else {
+			report.Unprotected++
+		}
+
+		report.Positions = append(report.Positions, position)
+	}
+
+	sort.Slice(report.Positions, func(i, j int) bool {
+		return report.Positions[i].Symbol < report.Positions[j].Symbol
+	})
+
+	return report, nil
+}
+
+// monitorEquity 定期记录活跃用户的账户权益快照，用于权益曲线
+func (te *TradeExecutor) monitorEquity() {
+	ticker := time.NewTicker(equitySnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-te.ctx.Done():
+			return
+		case <-ticker.C:
+			// 非实盘模式下账户权益不随交易变化
+			if !te.isLive() {
+				continue
+			}
+			if err := te.recordEquitySnapshots(); err != nil {
+				te.logger.Warnf("Failed to record equity snapshots: %v", err)
+			}
+		}
+	}
+}
+
+// recordEquitySnapshots 为所有活跃用户记录当前权益
+func (te *TradeExecutor) recordEquitySnapshots() error {
+	users, err := te.userConfigRepo.GetActiveUsers()
+	if err != nil {
+		return fmt.Errorf("failed to get active users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	accountInfo, err := te.binanceClient.GetAccountInfo(te.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get account info: %w", err)
+	}
+	asset := accountInfo.FindAsset(reportAsset)
+	if asset == nil {
+		return nil
+	}
+	equity, err := decimal.NewFromString(asset.MarginBalance)
+	if err != nil {
+		return fmt.Errorf("invalid margin balance format: %w", err)
+	}
+
+	for _, user := range users {
+		snapshot := &database.EquitySnapshot{
+			UserID: user.UserID,
+			Asset:  reportAsset,
+			Equity: equity.InexactFloat64(),
+		}
+		if err := te.equitySnapshotRepo.Create(snapshot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
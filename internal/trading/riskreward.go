@@ -0,0 +1,118 @@
+package trading
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// roundToTick 将价格取整到最小变动单位，tick 为零时原样返回
+func roundToTick(price, tick decimal.Decimal) decimal.Decimal {
+	if tick.IsZero() {
+		return price
+	}
+	return price.Div(tick).Round(0).Mul(tick)
+}
+
+// nudgeRoundedLevels 将止损止盈取整到最小变动单位。若取整使其越过参考价（多单止损需低于开仓价和标记价、
+// 止盈需高于两者，空单相反），向安全方向移动一个单位；取整前就已越过的价格不做调整。价格为零表示未设置
+func nudgeRoundedLevels(isLong bool, entry, mark, stopLoss, takeProfit, tick decimal.Decimal) (decimal.Decimal, decimal.Decimal, bool) {
+	if tick.IsZero() {
+		return stopLoss, takeProfit, false
+	}
+
+	// 止损需在开仓价和标记价中更近的一侧之外，止盈需在更远的一侧之外
+	low, high := entry, entry
+	if mark.IsPositive() {
+		low = decimal.Min(entry, mark)
+		high = decimal.Max(entry, mark)
+	}
+
+	nudged := false
+	roundedSL := roundToTick(stopLoss, tick)
+	roundedTP := roundToTick(takeProfit, tick)
+
+	if isLong {
+		if !stopLoss.IsZero() && stopLoss.LessThan(low) && roundedSL.GreaterThanOrEqual(low) {
+			roundedSL = roundedSL.Sub(tick)
+			nudged = true
+		}
+		if !takeProfit.IsZero() && takeProfit.GreaterThan(high) && roundedTP.LessThanOrEqual(high) {
+			roundedTP = roundedTP.Add(tick)
+			nudged = true
+		}
+	} else {
+		if !stopLoss.IsZero() && stopLoss.GreaterThan(high) && roundedSL.LessThanOrEqual(high) {
+			roundedSL = roundedSL.Add(tick)
+			nudged = true
+		}
+		if !takeProfit.IsZero() && takeProfit.LessThan(low) && roundedTP.GreaterThanOrEqual(low) {
+			roundedTP = roundedTP.Sub(tick)
+			nudged = true
+		}
+	}
+
+	return roundedSL, roundedTP, nudged
+}
+
+// effectiveRiskReward 计算计入价格取整、滑点和双边手续费后的实际风险收益比
+func (te *TradeExecutor) effectiveRiskReward(symbol string, signal *strategy.TradingSignal) (decimal.Decimal, error) {
+	info, err := te.getSymbolInfo(symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	tick := info.TickSize()
+
+	// SlippageTolerance 以百分比表示，入场和止损出场均按不利方向计入滑点
+	slippage := decimal.NewFromFloat(te.tradingConfig.SlippageTolerance / 100)
+	feeRate := te.takerFeeRate(symbol)
+	one := decimal.NewFromInt(1)
+
+	stopLoss := roundToTick(signal.StopLoss, tick)
+	takeProfit := roundToTick(signal.TakeProfit, tick)
+
+	var entry, stopExit, risk, reward decimal.Decimal
+	if signal.Type == strategy.SignalBuy {
+		entry = signal.Price.Mul(one.Add(slippage))
+		stopExit = stopLoss.Mul(one.Sub(slippage))
+		risk = entry.Sub(stopExit)
+		reward = takeProfit.Sub(entry)
+	} else {
+		entry = signal.Price.Mul(one.Sub(slippage))
+		stopExit = stopLoss.Mul(one.Add(slippage))
+		risk = stopExit.Sub(entry)
+		reward = entry.Sub(takeProfit)
+	}
+
+	// 双边手续费：亏损时加到风险上，盈利时从收益中扣除
+	risk = risk.Add(entry.Add(stopExit).Mul(feeRate))
+	reward = reward.Sub(entry.Add(takeProfit).Mul(feeRate))
+
+	if !risk.IsPositive() {
+		return decimal.Zero, fmt.Errorf("stop loss %s is not beyond entry %s after rounding", stopLoss, entry)
+	}
+
+	return reward.Div(risk), nil
+}
+
+// checkRiskReward 拒绝计入成本后风险收益比低于配置下限的开仓信号
+func (te *TradeExecutor) checkRiskReward(symbol string, signal *strategy.TradingSignal) error {
+	minR := te.tradingConfig.MinRiskReward
+	if minR <= 0 || signal.StopLoss.IsZero() || signal.TakeProfit.IsZero() {
+		return nil
+	}
+
+	r, err := te.effectiveRiskReward(symbol, signal)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate risk reward: %w", err)
+	}
+
+	if r.LessThan(decimal.NewFromFloat(minR)) {
+		return fmt.Errorf("effective risk reward %s below minimum %.2f after rounding, slippage and fees",
+			r.StringFixed(2), minR)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+func TestNudgeRoundedLevels(t *testing.T) {
+	tests := []struct {
+		name                 string
+		isLong               bool
+		entry, mark          string
+		stopLoss, takeProfit string
+		wantSL, wantTP       string
+		wantNudged           bool
+	}{
+		// 止损距开仓价不足半个单位，取整后落在开仓价上，需向外移动一个单位
+		{"long stop rounds onto entry", true, "30000", "0", "29999.97", "31000", "29999.9", "31000", true},
+		{"short stop rounds onto entry", false, "30000", "0", "30000.03", "29000", "30000.1", "29000", true},
+		{"long take profit rounds onto entry", true, "30000", "0", "29500", "30000.02", "29500", "30000.1", true},
+		{"short take profit rounds onto entry", false, "30000", "0", "30500", "29999.98", "30500", "29999.9", true},
+		// 标记价比开仓价更接近止损时以标记价为参考
+		{"long stop rounds onto mark", true, "30000", "29990", "29989.96", "31000", "29989.9", "31000", true},
+		{"short stop rounds onto mark", false, "30000", "30010", "30010.04", "29000", "30010.1", "29000", true},
+		// 取整后仍在安全一侧时只取整
+		{"long levels far enough", true, "30000", "0", "29500.04", "31000.06", "29500", "31000.1", false},
+		{"short levels far enough", false, "30000", "0", "30499.96", "28999.94", "30500", "28999.9", false},
+		// 取整前已越过开仓价的价格不做调整
+		{"long stop already above entry", true, "30000", "0", "30000.02", "31000", "30000", "31000", false},
+		{"short stop already below entry", false, "30000", "0", "29999.98", "29000", "30000", "29000", false},
+		// 价格为零表示未设置
+		{"long without take profit", true, "30000", "0", "29999.97", "0", "29999.9", "0", true},
+	}
+
+	tick := decimal.RequireFromString("0.1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sl, tp, nudged := nudgeRoundedLevels(tt.isLong,
+				decimal.RequireFromString(tt.entry), decimal.RequireFromString(tt.mark),
+				decimal.RequireFromString(tt.stopLoss), decimal.RequireFromString(tt.takeProfit), tick)
+			if !sl.Equal(decimal.RequireFromString(tt.wantSL)) || !tp.Equal(decimal.RequireFromString(tt.wantTP)) || nudged != tt.wantNudged {
+				t.Errorf("nudgeRoundedLevels = %s, %s, %v; want %s, %s, %v", sl, tp, nudged, tt.wantSL, tt.wantTP, tt.wantNudged)
+			}
+		})
+	}
+}
+
+func TestProtectiveStopNudgedAwayFromEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		signal   strategy.SignalType
+		stopLoss string
+		want     string
+	}{
+		{"long", strategy.SignalBuy, "29999.97", "29999.90"},
+		{"short", strategy.SignalSell, "30000.03", "30000.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := newFakeExchange(t)
+			cfg := testTradingConfig()
+			cfg.NudgeRoundedLevels = true
+			te := newTestExecutor(t, fx, cfg)
+			addTestUser(t, te, 1)
+
+			te.placeProtectiveOrders(&TradeRequest{
+				UserID:       1,
+				Symbol:       "BTCUSDT",
+				Quantity:     decimal.RequireFromString("0.01"),
+				StrategyType: "vegas",
+				Signal: &strategy.TradingSignal{
+					Type:     tt.signal,
+					Symbol:   "BTCUSDT",
+					Price:    decimal.RequireFromString("30000"),
+					StopLoss: decimal.RequireFromString(tt.stopLoss),
+				},
+			})
+
+			placed := fx.placedOrders()
+			if len(placed) != 1 {
+				t.Fatalf("placed %d orders, want the stop loss only", len(placed))
+			}
+			if got := decimal.RequireFromString(placed[0].Get("stopPrice")); !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("stopPrice = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // 内嵌时区数据，避免运行环境缺少 zoneinfo
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+)
+
+// SessionWindow 每日交易时段（支持跨越午夜，例如 22:00-06:00）
+type SessionWindow struct {
+	Start    time.Duration // 距当日零点的开始偏移
+	End      time.Duration // 距当日零点的结束偏移
+	Location *time.Location
+}
+
+// ParseSessionWindow 解析交易时段，start/end 格式为 HH:MM，tz 为 IANA 时区名（空则为 UTC）
+func ParseSessionWindow(start, end, tz string) (*SessionWindow, error) {
+	startOffset, err := parseClock(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session start: %w", err)
+	}
+
+	endOffset, err := parseClock(end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session end: %w", err)
+	}
+
+	if startOffset == endOffset {
+		return nil, fmt.Errorf("session start and end cannot be equal")
+	}
+
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session timezone: %w", err)
+	}
+
+	return &SessionWindow{Start: startOffset, End: endOffset, Location: loc}, nil
+}
+
+// SessionForUser 获取用户配置的交易时段，未配置时返回 nil
+func SessionForUser(userConfig *database.UserConfig) (*SessionWindow, error) {
+	if userConfig.SessionStart == "" || userConfig.SessionEnd == "" {
+		return nil, nil
+	}
+	return ParseSessionWindow(userConfig.SessionStart, userConfig.SessionEnd, userConfig.SessionTimezone)
+}
+
+// Contains 判断时间是否处于交易时段内
+func (w *SessionWindow) Contains(t time.Time) bool {
+	offset := w.offsetOf(t)
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	// 跨越午夜的时段
+	return offset >= w.Start || offset < w.End
+}
+
+// NextOpen 获取下一次交易时段开始的时间
+func (w *SessionWindow) NextOpen(t time.Time) time.Time {
+	local := t.In(w.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.Location)
+	open := midnight.Add(w.Start)
+	if !open.After(local) {
+		open = midnight.AddDate(0, 0, 1).Add(w.Start)
+	}
+	return open
+}
+
+// String 格式化交易时段
+func (w *SessionWindow) String() string {
+	return fmt.Sprintf("%s-%s %s", formatClock(w.Start), formatClock(w.End), w.Location.String())
+}
+
+// offsetOf 获取时间在时段所在时区内距零点的偏移
+func (w *SessionWindow) offsetOf(t time.Time) time.Duration {
+	local := t.In(w.Location)
+	return time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+}
+
+// parseClock 解析 HH:MM 格式的时间
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// formatClock 格式化为 HH:MM
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
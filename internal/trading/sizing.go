@@ -0,0 +1,243 @@
+package trading
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/database"
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// SizingResult 仓位计算过程及结果
+type SizingResult struct {
+	Symbol             string
+	Asset              string          // 保证金资产
+	Price              decimal.Decimal // 计算所用价格
+	Balance            decimal.Decimal // 可用余额
+	SizingBase         decimal.Decimal // 计算基数（复利模式为可用余额，固定基数模式为周期权益基数）
+	RiskModel          string          // 实际采用的风险模型，止损模式缺少有效止损时退回仓位价值模式
+	RiskAmount         decimal.Decimal // 止损模式下计划的止损亏损（基数 × 风险比例）
+	PositionValue      decimal.Decimal // 按风险比例、可用余额和最大仓位限制后的仓位价值
+	RawQuantity        decimal.Decimal // 取整前数量
+	Quantity           decimal.Decimal // 按 LOT_SIZE 步长向下取整后的数量
+	StepSize           decimal.Decimal
+	ContractMultiplier decimal.Decimal
+	Notional           decimal.Decimal // 取整后数量的名义价值
+	MinNotional        decimal.Decimal
+}
+
+// SizePreview 假设交易的仓位预览
+type SizePreview struct {
+	*SizingResult
+	Side                string // LONG / SHORT
+	StopLoss            decimal.Decimal
+	TakeProfit          decimal.Decimal
+	RiskAtStop          decimal.Decimal // 触发止损时的亏损金额（不含费用）
+	RiskReward          decimal.Decimal // 止盈距离 / 止损距离
+	EffectiveRiskReward decimal.Decimal // 计入价格取整、滑点和手续费后的风险收益比
+	Rejection           error           // 实际下单时会被拒绝的原因，为空表示可以下单
+}
+
+// calculateQuantity 计算交易数量，stopLoss 为信号止损价（止损模式下用于计算止损距离，为零时按仓位价值计算）
+func (te *TradeExecutor) calculateQuantity(userConfig *database.UserConfig, symbol string, price, stopLoss decimal.Decimal) (decimal.Decimal, error) {
+	// 有未过期的实时价格时按实时价格计算，信号价格可能已是数秒前的收盘价
+	if cached, ok := te.cachedPrice(symbol); ok {
+		price = cached
+	}
+	result, err := te.sizePosition(userConfig, symbol, price, stopLoss, true)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return result.Quantity, nil
+}
+
+// sizePosition 按用户风险设置计算仓位，persistBase 为 false 时不写入新的权益基数（用于预览）。
+// 取整后数量为零或名义价值低于交易所下限时，返回已填充的结果和错误
+func (te *TradeExecutor) sizePosition(userConfig *database.UserConfig, symbol string, price, stopLoss decimal.Decimal, persistBase bool) (*SizingResult, error) {
+	// 获取账户信息
+	accountInfo, err := te.binanceClient.GetAccountInfo(te.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account info: %w", err)
+	}
+
+	// 按交易对的保证金资产查找余额
+	asset, err := te.marginAsset(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve margin asset: %w", err)
+	}
+
+	result := &SizingResult{Symbol: symbol, Asset: asset, Price: price}
+
+	var equity decimal.Decimal
+	if assetInfo := accountInfo.FindAsset(asset); assetInfo != nil {
+		result.Balance, err = decimal.NewFromString(assetInfo.AvailableBalance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance format: %w", err)
+		}
+		equity, _ = decimal.NewFromString(assetInfo.MarginBalance)
+	}
+
+	if result.Balance.IsZero() {
+		return nil, fmt.Errorf("insufficient %s balance", asset)
+	}
+
+	// 计算风险金额：复利模式按实时余额，固定基数模式按周期权益基数
+	result.SizingBase = result.Balance
+	if te.tradingConfig.SizingMode == config.SizingModeFixedBase {
+		if persistBase {
+			result.SizingBase, err = te.equityBase(userConfig.UserID, asset, equity)
+		} else {
+			var ok bool
+			if result.SizingBase, ok, err = te.storedEquityBase(userConfig.UserID, asset); err == nil && !ok {
+				result.SizingBase = equity
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get equity base: %w", err)
+		}
+	}
+	riskAmount := result.SizingBase.Mul(decimal.NewFromFloat(userConfig.RiskPercentage / 100))
+
+	var positionValue decimal.Decimal
+	stopDistance := price.Sub(stopLoss).Abs()
+	if te.tradingConfig.RiskModel == config.RiskModelStopDistance && stopLoss.IsPositive() && stopDistance.IsPositive() {
+		// 止损模式：触发止损时亏损恰为风险金额，仓位价值 = 风险金额 / 止损距离 × 价格；
+		// 所需保证金不能超出可用余额
+		result.RiskModel = config.RiskModelStopDistance
+		result.RiskAmount = riskAmount
+		positionValue = riskAmount.Div(stopDistance).Mul(price)
+		if maxValue := result.Balance.Mul(decimal.NewFromInt(int64(te.sizingLeverage(userConfig)))); positionValue.GreaterThan(maxValue) {
+			positionValue = maxValue
+		}
+	} else {
+		if te.tradingConfig.RiskModel == config.RiskModelStopDistance {
+			te.logger.Infof("No valid stop loss for %s, sizing by position value", symbol)
+		}
+		result.RiskModel = config.RiskModelPositionValue
+		positionValue = riskAmount
+
+		// 固定基数可能高于当前可用余额，不能超出可用余额
+		if positionValue.GreaterThan(result.Balance) {
+			positionValue = result.Balance
+		}
+	}
+
+	// 限制最大仓位大小
+	maxPositionValue := decimal.NewFromFloat(userConfig.MaxPositionSize)
+	if positionValue.GreaterThan(maxPositionValue) {
+		positionValue = maxPositionValue
+	}
+	result.PositionValue = positionValue
+
+	// 计算数量：每张合约的名义价值 = 价格 × 合约乘数
+	info, err := te.getSymbolInfo(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol info: %w", err)
+	}
+	result.ContractMultiplier = info.ContractMultiplier()
+	contractValue := price.Mul(result.ContractMultiplier)
+	if contractValue.IsZero() {
+		return nil, fmt.Errorf("invalid contract value for %s", symbol)
+	}
+	result.RawQuantity = positionValue.Div(contractValue)
+
+	// 按数量步长向下取整，避免超出计划仓位
+	result.StepSize = info.StepSize()
+	result.Quantity = result.RawQuantity
+	if result.StepSize.IsPositive() {
+		result.Quantity = result.RawQuantity.Div(result.StepSize).Floor().Mul(result.StepSize)
+	}
+	result.Notional = result.Quantity.Mul(contractValue)
+	result.MinNotional = info.MinNotional()
+
+	// 确保数量不为零
+	if result.Quantity.IsZero() || result.Quantity.LessThan(decimal.NewFromFloat(0.001)) && result.StepSize.IsZero() {
+		return result, fmt.Errorf("calculated quantity too small")
+	}
+
+	if minQty := info.MinQty(); minQty.IsPositive() && result.Quantity.LessThan(minQty) {
+		return result, fmt.Errorf("quantity %s below exchange minimum %s", result.Quantity.String(), minQty.String())
+	}
+
+	if result.MinNotional.IsPositive() && result.Notional.LessThan(result.MinNotional) {
+		return result, fmt.Errorf("notional %s below exchange minimum %s", result.Notional.StringFixed(2), result.MinNotional.String())
+	}
+
+	return result, nil
+}
+
+// sizingLeverage 计算止损模式仓位上限所用的杠杆倍数，与下单时设置的杠杆一致
+func (te *TradeExecutor) sizingLeverage(userConfig *database.UserConfig) int {
+	leverage := userConfig.Leverage
+	if leverage <= 0 {
+		leverage = te.tradingConfig.DefaultLeverage
+	}
+	return max(leverage, 1)
+}
+
+// PreviewSize 按用户当前余额、风险设置、最新价格和策略止损预览一笔假设交易，不下单也不写入任何状态
+func (te *TradeExecutor) PreviewSize(userID int64, symbol string, isLong bool, levels ProtectiveLevelFunc) (*SizePreview, error) {
+	userConfig, err := te.userConfigRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user config: %w", err)
+	}
+	if userConfig == nil {
+		return nil, ErrUserConfigNotFound
+	}
+
+	price, err := te.latestPrice(symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price: %w", err)
+	}
+
+	// 先取策略止损，止损模式按止损距离计算仓位
+	var stopLoss, takeProfit decimal.Decimal
+	hasLevels := false
+	if levels != nil {
+		stopLoss, takeProfit, hasLevels = levels(isLong, price)
+	}
+
+	result, sizingErr := te.sizePosition(userConfig, symbol, price, stopLoss, false)
+	if result == nil {
+		return nil, sizingErr
+	}
+
+	preview := &SizePreview{SizingResult: result, Side: "LONG", Rejection: sizingErr}
+	if !isLong {
+		preview.Side = "SHORT"
+	}
+
+	if !hasLevels {
+		return preview, nil
+	}
+	preview.StopLoss = stopLoss
+	preview.TakeProfit = takeProfit
+
+	stopDistance := price.Sub(stopLoss).Abs()
+	preview.RiskAtStop = stopDistance.Mul(result.Quantity).Mul(result.ContractMultiplier)
+	if stopDistance.IsPositive() {
+		preview.RiskReward = takeProfit.Sub(price).Abs().Div(stopDistance)
+	}
+
+	signalType := strategy.SignalBuy
+	if !isLong {
+		signalType = strategy.SignalSell
+	}
+	signal := &strategy.TradingSignal{
+		Symbol:     symbol,
+		Type:       signalType,
+		Price:      price,
+		StopLoss:   stopLoss,
+		TakeProfit: takeProfit,
+	}
+	if effective, err := te.effectiveRiskReward(symbol, signal); err == nil {
+		preview.EffectiveRiskReward = effective
+	}
+	if preview.Rejection == nil {
+		preview.Rejection = te.checkRiskReward(symbol, signal)
+	}
+
+	return preview, nil
+}
@@ -0,0 +1,133 @@
+package trading
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// testAccount 各资产可用余额构成的账户信息
+func testAccount(balances map[string]string) *binance.AccountInfo {
+	account := &binance.AccountInfo{}
+	for asset, balance := range balances {
+		account.Assets = append(account.Assets, binance.AccountAsset{
+			Asset:            asset,
+			AvailableBalance: balance,
+			MarginBalance:    balance,
+		})
+	}
+	return account
+}
+
+func TestSizePositionUsesSymbolMarginAsset(t *testing.T) {
+	busdMargined := testSymbolInfo("ETHBUSD", "ETH", "BUSD", "0.01", "0.001")
+	busdMargined.MarginAsset = "USDT"
+
+	tests := []struct {
+		name        string
+		symbol      binance.SymbolInfo
+		price       string
+		balances    map[string]string
+		wantAsset   string
+		wantBalance string
+		wantQty     string
+		wantErr     string
+	}{
+		{
+			name:        "USDC quoted symbol sized from USDC balance",
+			symbol:      testSymbolInfo("BTCUSDC", "BTC", "USDC", "0.10", "0.001"),
+			price:       "50000",
+			balances:    map[string]string{"USDT": "500", "USDC": "10000"},
+			wantAsset:   "USDC",
+			wantBalance: "10000",
+			wantQty:     "0.002", // 10000 × 1% / 50000
+		},
+		{
+			name:        "margin asset overrides quote asset",
+			symbol:      busdMargined,
+			price:       "2000",
+			balances:    map[string]string{"USDT": "20000", "BUSD": "100"},
+			wantAsset:   "USDT",
+			wantBalance: "20000",
+			wantQty:     "0.1", // 20000 × 1% / 2000
+		},
+		{
+			name:     "no balance in margin asset",
+			symbol:   testSymbolInfo("BTCUSDC", "BTC", "USDC", "0.10", "0.001"),
+			price:    "50000",
+			balances: map[string]string{"USDT": "10000"},
+			wantErr:  "insufficient USDC balance",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := newFakeExchange(t)
+			fx.symbols = append(fx.symbols, tt.symbol)
+			fx.account = testAccount(tt.balances)
+			te := newTestExecutor(t, fx, testTradingConfig())
+			userConfig := addTestUser(t, te, 1)
+			userConfig.MaxPositionSize = 100000
+
+			result, err := te.sizePosition(userConfig, tt.symbol.Symbol, decimal.RequireFromString(tt.price), decimal.Zero, false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("sizePosition error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sizePosition: %v", err)
+			}
+			if result.Asset != tt.wantAsset || !result.Balance.Equal(decimal.RequireFromString(tt.wantBalance)) {
+				t.Errorf("sized from %s %s, want %s %s", result.Balance, result.Asset, tt.wantBalance, tt.wantAsset)
+			}
+			if !result.Quantity.Equal(decimal.RequireFromString(tt.wantQty)) {
+				t.Errorf("quantity = %s, want %s", result.Quantity, tt.wantQty)
+			}
+		})
+	}
+}
+
+func TestSizePositionContractMultiplier(t *testing.T) {
+	tests := []struct {
+		name         string
+		contractSize float64
+		wantQty      string
+		wantNotional string
+	}{
+		{"multiplier 1", 0, "5", "100"},
+		{"multiplier 10", 10, "0.5", "100"},
+		{"multiplier 0.1", 0.1, "50", "100"},
+		{"multiplier 3 rounds down to step", 3, "1.6", "96"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			symbol := testSymbolInfo("XYZUSDT", "XYZ", "USDT", "0.01", "0.1")
+			symbol.ContractSize = tt.contractSize
+
+			fx := newFakeExchange(t)
+			fx.symbols = append(fx.symbols, symbol)
+			fx.account = testAccount(map[string]string{"USDT": "10000"})
+			te := newTestExecutor(t, fx, testTradingConfig())
+			userConfig := addTestUser(t, te, 1)
+			userConfig.MaxPositionSize = 100000
+
+			// 仓位价值 10000 × 1% = 100 USDT，每张合约价值 = 20 × 合约乘数
+			result, err := te.sizePosition(userConfig, "XYZUSDT", decimal.RequireFromString("20"), decimal.Zero, false)
+			if err != nil {
+				t.Fatalf("sizePosition: %v", err)
+			}
+			if !result.Quantity.Equal(decimal.RequireFromString(tt.wantQty)) {
+				t.Errorf("quantity = %s, want %s", result.Quantity, tt.wantQty)
+			}
+			if !result.Notional.Equal(decimal.RequireFromString(tt.wantNotional)) {
+				t.Errorf("notional = %s, want %s", result.Notional, tt.wantNotional)
+			}
+		})
+	}
+}
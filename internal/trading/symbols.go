@@ -0,0 +1,152 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// symbolInfoTTL 交易规则缓存有效期
+const symbolInfoTTL = 1 * time.Hour
+
+// getSymbolInfo 获取交易对规则，缓存过期或缺失时从交易所刷新
+func (te *TradeExecutor) getSymbolInfo(symbol string) (*binance.SymbolInfo, error) {
+	te.mu.RLock()
+	info, exists := te.symbolInfos[symbol]
+	fresh := time.Since(te.symbolInfoUpdated) < symbolInfoTTL
+	te.mu.RUnlock()
+
+	if exists && fresh {
+		return info, nil
+	}
+
+	if err := te.refreshSymbolInfos(); err != nil {
+		// 刷新失败时退回使用旧缓存
+		if exists {
+			te.logger.Warnf("Failed to refresh exchange info, using cached rules for %s: %v", symbol, err)
+			return info, nil
+		}
+		return nil, err
+	}
+
+	te.mu.RLock()
+	info, exists = te.symbolInfos[symbol]
+	te.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("symbol %s not found in exchange info", symbol)
+	}
+
+	return info, nil
+}
+
+// refreshSymbolInfos 刷新交易规则缓存
+func (te *TradeExecutor) refreshSymbolInfos() error {
+	exchangeInfo, err := te.binanceClient.GetExchangeInfo(te.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get exchange info: %w", err)
+	}
+
+	infos := make(map[string]*binance.SymbolInfo, len(exchangeInfo.Symbols))
+	for i := range exchangeInfo.Symbols {
+		info := &exchangeInfo.Symbols[i]
+		infos[info.Symbol] = info
+	}
+
+	te.mu.Lock()
+	te.symbolInfos = infos
+	te.symbolInfoUpdated = time.Now()
+	te.mu.Unlock()
+
+	te.logger.Debugf("Exchange info refreshed: %d symbols", len(infos))
+	return nil
+}
+
+// checkSymbolTradable 拒绝在不可交易（PENDING_TRADING、BREAK等）的交易对上开仓。
+// 不可交易时按状态复查间隔刷新缓存，交易对恢复后即可继续开仓
+func (te *TradeExecutor) checkSymbolTradable(symbol string) error {
+	info, err := te.getSymbolInfo(symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get symbol info: %w", err)
+	}
+	if info.IsTrading() {
+		return nil
+	}
+
+	te.mu.RLock()
+	age := time.Since(te.symbolInfoUpdated)
+	te.mu.RUnlock()
+
+	if age >= time.Duration(te.tradingConfig.SymbolStatusCheckMinutes)*time.Minute {
+		if err := te.refreshSymbolInfos(); err != nil {
+			te.logger.Warnf("Failed to re-check status of %s: %v", symbol, err)
+		} else if info, err = te.getSymbolInfo(symbol); err != nil {
+			return fmt.Errorf("failed to get symbol info: %w", err)
+		}
+		if info.IsTrading() {
+			te.logger.Infof("Symbol %s is trading again", symbol)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("symbol %s is not tradeable (status %s)", symbol, info.Status)
+}
+
+// roundQuantity 下单前将数量按 LOT_SIZE 步长向下取整，取整后低于最小下单数量时返回错误
+func (te *TradeExecutor) roundQuantity(symbol string, quantity decimal.Decimal) (decimal.Decimal, error) {
+	info, err := te.getSymbolInfo(symbol)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get symbol info: %w", err)
+	}
+
+	if step := info.StepSize(); step.IsPositive() {
+		quantity = quantity.Div(step).Floor().Mul(step)
+	}
+	if !quantity.IsPositive() {
+		return decimal.Zero, fmt.Errorf("quantity for %s rounds to zero", symbol)
+	}
+	if minQty := info.MinQty(); minQty.IsPositive() && quantity.LessThan(minQty) {
+		return decimal.Zero, fmt.Errorf("quantity %s below exchange minimum %s for %s", quantity.String(), minQty.String(), symbol)
+	}
+	return quantity, nil
+}
+
+// roundPrice 下单前将价格按 PRICE_FILTER 最小变动单位取整
+func (te *TradeExecutor) roundPrice(symbol string, price decimal.Decimal) (decimal.Decimal, error) {
+	info, err := te.getSymbolInfo(symbol)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get symbol info: %w", err)
+	}
+
+	price = roundToTick(price, info.TickSize())
+	if !price.IsPositive() {
+		return decimal.Zero, fmt.Errorf("price for %s rounds to zero", symbol)
+	}
+	return price, nil
+}
+
+// contractMultiplier 获取交易对的合约乘数
+func (te *TradeExecutor) contractMultiplier(symbol string) (decimal.Decimal, error) {
+	info, err := te.getSymbolInfo(symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return info.ContractMultiplier(), nil
+}
+
+// marginAsset 获取交易对的保证金资产（如USDT、USDC、BUSD）
+func (te *TradeExecutor) marginAsset(symbol string) (string, error) {
+	info, err := te.getSymbolInfo(symbol)
+	if err != nil {
+		return "", err
+	}
+
+	asset := info.SettlementAsset()
+	if asset == "" {
+		return "", fmt.Errorf("no margin asset for %s", symbol)
+	}
+	return asset, nil
+}
@@ -0,0 +1,77 @@
+package trading
+
+import (
+	"fmt"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// ExitCheckFunc 检查持仓方向上的EMA12移动止盈出场信号，无信号时返回 nil
+type ExitCheckFunc func(symbol string, isLong bool) *strategy.TradingSignal
+
+// SetExitCheck 设置EMA12移动止盈出场检查，未设置时只依靠交易所的止损止盈挂单出场
+func (te *TradeExecutor) SetExitCheck(check ExitCheckFunc) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.exitCheck = check
+}
+
+// CheckTrailingExits 在交易对15M K线收盘后检查其持仓是否触发EMA12移动止盈，只在收盘时检查以免盘中噪音反复触发
+func (te *TradeExecutor) CheckTrailingExits(symbol string) {
+	if !te.tradingConfig.EMA12Exit {
+		return
+	}
+
+	te.mu.RLock()
+	check := te.exitCheck
+	var positions []Position
+	for _, position := range te.positions {
+		if position.Symbol == symbol && position.IsOpen {
+			positions = append(positions, *position)
+		}
+	}
+	te.mu.RUnlock()
+
+	if check == nil || len(positions) == 0 {
+		return
+	}
+
+	for _, position := range positions {
+		// 开仓、设置保护或平仓进行中时不处理
+		switch te.PositionState(position.UserID, symbol) {
+		case StateOpen, StateUnprotected:
+		default:
+			continue
+		}
+
+		isLong := position.Side == "LONG"
+		signal := check(symbol, isLong)
+		if signal == nil || signal.Type != strategy.SignalTakeProfit {
+			continue
+		}
+		position := position
+		if !te.goTracked(func() { te.exitOnEMA12(position, signal) }) {
+			te.logger.Warnf("Executor stopping, EMA12 trailing exit for %s not started", symbol)
+		}
+	}
+}
+
+// exitOnEMA12 撤销止损止盈挂单并以只减仓市价单平仓
+func (te *TradeExecutor) exitOnEMA12(position Position, signal *strategy.TradingSignal) {
+	te.logger.Infof("EMA12 trailing exit for %s %s: %s", position.Symbol, position.Side, signal.Reason)
+
+	result, err := te.FlattenSymbol(position.UserID, position.Symbol, "ema12 trailing exit")
+	switch {
+	case err != nil:
+		te.notify("critical", "EMA12移动止盈平仓失败", fmt.Sprintf("%s %s: %v", position.Symbol, position.Side, err))
+	case result == nil:
+		te.logger.Infof("No exchange position left for %s at EMA12 exit", position.Symbol)
+	case result.Error != nil:
+		te.notify("critical", "EMA12移动止盈平仓失败", fmt.Sprintf("%s %s: %v", position.Symbol, position.Side, result.Error))
+	default:
+		te.notify("info", "📉 EMA12移动止盈",
+			fmt.Sprintf("%s %s %s 已市价平仓\n%s（收盘价 %s）\n已实现盈亏: %s",
+				position.Symbol, position.Side, result.Quantity.String(), signal.Reason,
+				signal.Price.String(), result.RealizedPnl.StringFixed(2)))
+	}
+}
@@ -0,0 +1,90 @@
+package trading
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/binance"
+)
+
+// SetUserStreamConnected 设置用户数据流连接状态：连接期间订单和持仓由推送事件更新，暂停定时轮询；
+// 状态变化时立即完整同步一次，补齐断开期间或连接建立前错过的事件
+func (te *TradeExecutor) SetUserStreamConnected(connected bool) {
+	te.mu.Lock()
+	changed := te.userStreamConnected != connected
+	te.userStreamConnected = connected
+	te.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if connected {
+		te.logger.Info("User data stream connected, order and position polling paused")
+	} else {
+		te.logger.Warn("User data stream disconnected, falling back to polling")
+	}
+	go te.resyncOrders()
+	te.requestPositionSync()
+}
+
+// userStreamActive 用户数据流是否连接
+func (te *TradeExecutor) userStreamActive() bool {
+	te.mu.RLock()
+	defer te.mu.RUnlock()
+	return te.userStreamConnected
+}
+
+// HandleOrderUpdate 处理用户数据流推送的订单更新，与轮询结果共用去重逻辑
+func (te *TradeExecutor) HandleOrderUpdate(event *binance.OrderTradeUpdateEvent) error {
+	filled, _ := decimal.NewFromString(event.Order.FilledQty)
+	avgPrice, _ := decimal.NewFromString(event.Order.AvgPrice)
+
+	at := time.Now()
+	if event.TransactionTime > 0 {
+		at = time.UnixMilli(event.TransactionTime)
+	}
+
+	te.ApplyFill(FillUpdate{
+		OrderID:  strconv.FormatInt(event.Order.OrderID, 10),
+		Symbol:   event.Order.Symbol,
+		Status:   binance.OrderStatus(event.Order.Status),
+		Filled:   filled,
+		AvgPrice: avgPrice,
+		Source:   FillSourceUserStream,
+		At:       at,
+	})
+	return nil
+}
+
+// HandleAccountUpdate 处理用户数据流推送的账户更新，持仓变化时立即同步持仓状态
+func (te *TradeExecutor) HandleAccountUpdate(event *binance.AccountUpdateEvent) error {
+	if len(event.Account.Positions) == 0 {
+		return nil
+	}
+	te.logger.Debugf("Account update (%s) for %d positions", event.Account.Reason, len(event.Account.Positions))
+	te.requestPositionSync()
+	return nil
+}
+
+// requestPositionSync 请求持仓监控立即同步一次，已有待处理的请求时合并
+func (te *TradeExecutor) requestPositionSync() {
+	select {
+	case te.positionSync <- struct{}{}:
+	default:
+	}
+}
+
+// resyncOrders 立即查询所有活跃订单的状态
+func (te *TradeExecutor) resyncOrders() {
+	if !te.isLive() {
+		return
+	}
+	for symbol := range te.orderActivity() {
+		if err := te.updateOrderStatus(symbol); err != nil {
+			te.logger.Warnf("Failed to resync orders for %s: %v", symbol, err)
+		}
+	}
+}
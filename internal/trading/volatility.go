@@ -0,0 +1,38 @@
+package trading
+
+import (
+	"fmt"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/strategy"
+)
+
+// checkVolatility 拒绝在极端波动中产生的开仓信号：策略的固定容差和止损在急涨急跌中表现很差
+func (te *TradeExecutor) checkVolatility(signal *strategy.TradingSignal) error {
+	maxATR := te.tradingConfig.MaxATRPercent
+	if maxATR > 0 && signal.ATRPercent > maxATR {
+		return fmt.Errorf("volatility too high: ATR %.2f%% of price exceeds maximum %.2f%%", signal.ATRPercent, maxATR)
+	}
+
+	maxRange := te.tradingConfig.MaxCandleRangePercent
+	if maxRange > 0 && signal.RangePercent > maxRange {
+		return fmt.Errorf("volatility too high: candle range %.2f%% of price exceeds maximum %.2f%%", signal.RangePercent, maxRange)
+	}
+
+	return nil
+}
+
+// notifyVolatilitySuppressed 通知开仓因波动过大被跳过，同一信号（多个用户）只通知一次
+func (te *TradeExecutor) notifyVolatilitySuppressed(signal *strategy.TradingSignal, reason error) {
+	te.mu.Lock()
+	if last, ok := te.volatilityNotified[signal.Symbol]; ok && last.Equal(signal.Timestamp) {
+		te.mu.Unlock()
+		return
+	}
+	te.volatilityNotified[signal.Symbol] = signal.Timestamp
+	te.mu.Unlock()
+
+	te.logger.Warnf("Entry for %s suppressed: %v", signal.Symbol, reason)
+	te.notify("warning", "🌪 波动过大，跳过开仓",
+		fmt.Sprintf("%s 信号价格 %s\nATR: %.2f%%，K线振幅: %.2f%%\n原因: %v",
+			signal.Symbol, signal.Price.String(), signal.ATRPercent, signal.RangePercent, reason))
+}
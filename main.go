@@ -15,15 +15,19 @@ import (
 )
 
 func main() {
-	// 初始化日志
-	logger := logger.NewLogger()
-	logger.Info("Starting Vegas Dual Tunnel Trading Bot...")
-
-	// 加载配置
+	// 加载配置，日志配置加载前使用控制台日志
 	cfg, err := config.Load("config.json")
 	if err != nil {
-		logger.Fatalf("Failed to load config: %v", err)
+		logger.NewLogger().Fatalf("Failed to load config: %v", err)
+	}
+
+	// 初始化日志
+	logger, err := logger.NewLoggerFromConfig(cfg.Logging)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
 	}
+	logger.Info("Starting Vegas Dual Tunnel Trading Bot...")
 
 	// 创建应用实例
 	app, err := app.New(cfg, logger)
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/pengxuan37/vegas-dual-tunnel-trading-bot/internal/config"
+)
+
+// 日志输出格式
+const (
+	FormatText = "text" // 文本格式，适合控制台阅读
+	FormatJSON = "json" // JSON格式，适合日志采集
+)
+
+// NewLoggerFromConfig 按配置创建日志实例：写入按大小轮转的日志文件，Console 为 true 时同时输出到控制台；
+// 未配置文件路径时只输出到控制台
+func NewLoggerFromConfig(cfg config.LoggingConfig) (Logger, error) {
+	logger := logrus.New()
+
+	// 设置输出格式
+	switch strings.ToLower(cfg.Format) {
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: "2006-01-02 15:04:05",
+		})
+	case "", FormatText:
+		logger.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: "2006-01-02 15:04:05",
+		})
+	default:
+		return nil, fmt.Errorf("unsupported log format %q", cfg.Format)
+	}
+
+	// 设置输出目标
+	var writers []io.Writer
+	if cfg.FilePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.FilePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+		writers = append(writers, &lumberjack.Logger{
+			Filename:   cfg.FilePath,
+			MaxSize:    cfg.MaxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+			Compress:   cfg.Compress,
+			LocalTime:  true,
+		})
+	}
+	if cfg.Console || len(writers) == 0 {
+		writers = append(writers, os.Stdout)
+	}
+	logger.SetOutput(io.MultiWriter(writers...))
+
+	// 解析并设置日志级别
+	logLevel, err := logrus.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+	logger.SetLevel(logLevel)
+
+	return &logrusLogger{Logger: logger}, nil
+}
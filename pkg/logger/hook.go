@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordBufferSize 待写入日志记录的缓冲数，写入跟不上时丢弃新记录而不阻塞日志调用
+const recordBufferSize = 256
+
+// Record 镜像到外部存储的日志记录
+type Record struct {
+	Time         time.Time
+	Level        string
+	Message      string
+	Module       string // 输出日志的模块（包名），如 trading、binance
+	ErrorDetails string
+}
+
+// RecordWriter 写入一条日志记录
+type RecordWriter func(record Record) error
+
+// recordHook 将 Warn 及以上级别的日志异步交给 RecordWriter
+type recordHook struct {
+	mu      sync.Mutex
+	closed  bool
+	records chan Record
+}
+
+// MirrorWarnings 将 Warn 及以上级别的日志异步镜像到 write（如写入数据库），返回停止函数；
+// 停止时写完缓冲中的记录。write 的失败不会再记录日志，避免存储故障时循环写入
+func MirrorWarnings(log Logger, write RecordWriter) (stop func()) {
+	l, ok := log.(*logrusLogger)
+	if !ok {
+		return func() {}
+	}
+
+	hook := &recordHook{records: make(chan Record, recordBufferSize)}
+	l.AddHook(hook)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for record := range hook.records {
+			_ = write(record)
+		}
+	}()
+
+	return func() {
+		hook.mu.Lock()
+		if hook.closed {
+			hook.mu.Unlock()
+			return
+		}
+		hook.closed = true
+		close(hook.records)
+		hook.mu.Unlock()
+		<-done
+	}
+}
+
+// Levels 镜像的日志级别
+func (h *recordHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+// Fire 将日志条目放入缓冲，缓冲已满时丢弃
+func (h *recordHook) Fire(entry *logrus.Entry) error {
+	record := Record{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Module:  callerModule(),
+	}
+	if err, ok := entry.Data[logrus.ErrorKey]; ok {
+		record.ErrorDetails = fmt.Sprint(err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+
+	select {
+	case h.records <- record:
+	default:
+	}
+	return nil
+}
+
+// callerModule 获取调用日志方法的包名，跳过 logrus 和本包的调用帧
+func callerModule() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		function := frame.Function
+		if !strings.Contains(function, "github.com/sirupsen/logrus") &&
+			!strings.Contains(function, "/pkg/logger.") {
+			return packageName(function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// packageName 从完整函数名中取出包名，如 ".../internal/trading.(*TradeExecutor).Start" 得到 "trading"
+func packageName(function string) string {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		function = function[i+1:]
+	}
+	if i := strings.Index(function, "."); i >= 0 {
+		function = function[:i]
+	}
+	return function
+}